@@ -2,8 +2,8 @@ package main
 
 import (
 	"context"
-	"fmt"
 	"log"
+	"log/slog"
 	"net"
 	"net/http"
 	"os"
@@ -11,39 +11,97 @@ import (
 	"syscall"
 	"time"
 
+	"github.com/benjamin-rood/protogo-values-validation-demo/internal/buildinfo"
+	"github.com/benjamin-rood/protogo-values-validation-demo/internal/config"
+	"github.com/benjamin-rood/protogo-values-validation-demo/internal/openapi"
 	"github.com/benjamin-rood/protogo-values-validation-demo/internal/server"
 	v1 "github.com/benjamin-rood/protogo-values-validation-demo/gen/api/validation/v1"
 	
 	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials"
 	"google.golang.org/grpc/health"
 	"google.golang.org/grpc/health/grpc_health_v1"
-	"google.golang.org/grpc/reflection"
-)
-
-const (
-	defaultPort = "8080"
-	defaultGRPCPort = "9090"
 )
 
 func main() {
-	// Get ports from environment or use defaults
-	port := getEnvOrDefault("PORT", defaultPort)
-	grpcPort := getEnvOrDefault("GRPC_PORT", defaultGRPCPort)
+	startTime := time.Now()
+
+	// Resolve configuration from environment
+	cfg, err := config.Load()
+	if err != nil {
+		log.Fatalf("Invalid configuration: %v", err)
+	}
+	slog.SetLogLoggerLevel(cfg.LogLevel)
+	cfg.LogEffective()
+
+	// Surface the value-slice marshaling limitation at boot, not at first request
+	if err := server.CheckMarshalHazards(cfg.FailOnMarshalUnsafe); err != nil {
+		log.Fatalf("Startup marshal check failed: %v", err)
+	}
+
+	port := cfg.Port
+	grpcPort := cfg.GRPCPort
 
 	// Create validation server
-	validationServer := server.NewValidationServer()
+	serverOptions := []server.Option{
+		server.WithCache(cfg.ValidationCache),
+		server.WithStreamIdleTimeout(cfg.StreamIdleTimeout),
+		server.WithMaxStreams(cfg.MaxStreams),
+		server.WithMaxStreamMessages(cfg.MaxStreamMessages),
+		server.WithGOMAXPROCSPinning(cfg.AllowPinGOMAXPROCS),
+	}
+	if cfg.BenchmarkSinkURL != "" {
+		serverOptions = append(serverOptions, server.WithBenchmarkSink(server.NewInfluxSink(cfg.BenchmarkSinkURL, server.WithInfluxToken(cfg.BenchmarkSinkToken))))
+	}
+	if cfg.ExpectedTypesFile != "" {
+		expectations, constraints, err := server.LoadExpectations(cfg.ExpectedTypesFile)
+		if err != nil {
+			log.Fatalf("Invalid expected types: %v", err)
+		}
+		serverOptions = append(serverOptions,
+			server.WithTypeExpectations(expectations),
+			server.WithCountConstraints(constraints))
+	}
+	validationServer := server.NewValidationServer(serverOptions...)
+
+	// Message sizes for logs and /metrics, measured when sampled or at debug level
+	payloadSizes := server.NewPayloadSizeMetrics()
+	sizeOption := server.WithPayloadSizes(payloadSizes, cfg.LogPayloadSizes)
+
+	// Connections, RPCs and wire bytes for /metrics, seen by a stats handler
+	wireMetrics := server.NewWireMetrics()
 
 	// Setup gRPC server
-	grpcServer := grpc.NewServer()
+	serverOpts := []grpc.ServerOption{
+		grpc.ChainUnaryInterceptor(server.UnaryRequestIDInterceptor(sizeOption), server.UnaryValidationInterceptor(), server.UnaryCompressionThresholdInterceptor(cfg.CompressionMinBytes)),
+		grpc.ChainStreamInterceptor(server.StreamRequestIDInterceptor(sizeOption)),
+		grpc.StatsHandler(server.NewWireStatsHandler(wireMetrics)),
+	}
+
+	// Serve TLS (mutual when a client CA is configured), otherwise insecure for local dev
+	if cfg.TLSEnabled() {
+		tlsConfig, err := server.NewServerTLSConfig(cfg.TLSCertFile, cfg.TLSKeyFile, cfg.TLSCAFile)
+		if err != nil {
+			log.Fatalf("Failed to configure TLS: %v", err)
+		}
+		serverOpts = append(serverOpts, grpc.Creds(credentials.NewTLS(tlsConfig)))
+	}
+
+	grpcServer := grpc.NewServer(serverOpts...)
 	v1.RegisterValidationServiceServer(grpcServer, validationServer)
 	
 	// Add health check service
 	healthServer := health.NewServer()
 	grpc_health_v1.RegisterHealthServer(grpcServer, healthServer)
 	healthServer.SetServingStatus("validation.v1.ValidationService", grpc_health_v1.HealthCheckResponse_SERVING)
+
+	// Admin service is only exposed when a shared secret is configured
+	if cfg.AdminSecret != "" {
+		v1.RegisterAdminServiceServer(grpcServer, server.NewAdminServer(cfg.AdminSecret, healthServer, validationServer))
+	}
 	
-	// Enable reflection for debugging
-	reflection.Register(grpcServer)
+	// Reflection exposes the schema, so it is only enabled on request
+	server.RegisterReflection(grpcServer, cfg.EnableReflection)
 
 	// Start gRPC server
 	grpcListener, err := net.Listen("tcp", ":"+grpcPort)
@@ -58,13 +116,22 @@ func main() {
 		}
 	}()
 
-	// Setup HTTP health check endpoint
-	http.HandleFunc("/health", healthCheckHandler)
-	http.HandleFunc("/ready", readinessHandler(validationServer))
+	// Setup HTTP health check endpoint. An explicit mux keeps the pprof
+	// handlers registered on http.DefaultServeMux off the public port.
+	mux := http.NewServeMux()
+	mux.HandleFunc("/health", server.HealthHandler(startTime, validationServer))
+	mux.HandleFunc("/version", buildinfo.Handler())
+	mux.HandleFunc("/ready", server.ReadinessHandler(validationServer, cfg.ReadyAttempts, cfg.ReadyRetryDelay,
+		server.WithFailOnMarshalUnsafe(cfg.FailReadyOnMarshalUnsafe)))
+	mux.HandleFunc("/openapi.json", openapi.Handler())
+	mux.HandleFunc("/benchmarks.csv", server.BenchmarksCSVHandler(validationServer))
+	mux.HandleFunc("GET /examples/{type}", server.ExampleHandler(validationServer))
+	mux.HandleFunc("GET /demo", server.DemoHandler())
+	mux.HandleFunc("/metrics", server.MetricsHandler(payloadSizes, wireMetrics))
 
 	httpServer := &http.Server{
 		Addr:    ":" + port,
-		Handler: http.DefaultServeMux,
+		Handler: mux,
 	}
 
 	go func() {
@@ -74,6 +141,22 @@ func main() {
 		}
 	}()
 
+	// Profiling is served on its own port, only when configured
+	var pprofServer *http.Server
+	if cfg.PprofEnabled() {
+		pprofServer = &http.Server{
+			Addr:    ":" + cfg.PprofPort,
+			Handler: server.PprofHandler(),
+		}
+
+		go func() {
+			log.Printf("Starting pprof server on port %s", cfg.PprofPort)
+			if err := pprofServer.ListenAndServe(); err != nil && err != http.ErrServerClosed {
+				log.Fatalf("Failed to serve pprof: %v", err)
+			}
+		}()
+	}
+
 	// Wait for interrupt signal to gracefully shutdown the server
 	quit := make(chan os.Signal, 1)
 	signal.Notify(quit, syscall.SIGINT, syscall.SIGTERM)
@@ -82,7 +165,7 @@ func main() {
 	log.Println("Shutting down servers...")
 
 	// Graceful shutdown
-	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	ctx, cancel := context.WithTimeout(context.Background(), cfg.ShutdownTimeout)
 	defer cancel()
 
 	// Shutdown HTTP server
@@ -90,61 +173,14 @@ func main() {
 		log.Printf("HTTP server shutdown error: %v", err)
 	}
 
+	if pprofServer != nil {
+		if err := pprofServer.Shutdown(ctx); err != nil {
+			log.Printf("pprof server shutdown error: %v", err)
+		}
+	}
+
 	// Graceful stop gRPC server
 	grpcServer.GracefulStop()
 
 	log.Println("Servers stopped")
 }
-
-func healthCheckHandler(w http.ResponseWriter, r *http.Request) {
-	w.WriteHeader(http.StatusOK)
-	w.Header().Set("Content-Type", "application/json")
-	
-	response := `{
-		"status": "healthy",
-		"timestamp": "%s",
-		"service": "protogo-values-validation-demo",
-		"version": "1.0.0"
-	}`
-	
-	fmt.Fprintf(w, response, time.Now().UTC().Format(time.RFC3339))
-}
-
-func readinessHandler(validationServer *server.ValidationServer) http.HandlerFunc {
-	return func(w http.ResponseWriter, r *http.Request) {
-		// Perform readiness checks
-		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
-		defer cancel()
-		
-		// Test the validation service
-		req := &v1.ValidateTypesRequest{
-			TestScenarios:  []string{"basic"},
-			DeepValidation: false,
-		}
-		
-		_, err := validationServer.ValidateTypes(ctx, req)
-		if err != nil {
-			w.WriteHeader(http.StatusServiceUnavailable)
-			fmt.Fprintf(w, `{"status": "not ready", "error": "%s"}`, err.Error())
-			return
-		}
-		
-		w.WriteHeader(http.StatusOK)
-		w.Header().Set("Content-Type", "application/json")
-		
-		response := `{
-			"status": "ready",
-			"timestamp": "%s",
-			"service": "protogo-values-validation-demo"
-		}`
-		
-		fmt.Fprintf(w, response, time.Now().UTC().Format(time.RFC3339))
-	}
-}
-
-func getEnvOrDefault(key, defaultValue string) string {
-	if value := os.Getenv(key); value != "" {
-		return value
-	}
-	return defaultValue
-}
\ No newline at end of file
@@ -0,0 +1,368 @@
+// Package config resolves the server configuration from environment variables.
+package config
+
+import (
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/url"
+	"os"
+	"strconv"
+	"time"
+)
+
+const (
+	defaultPort                = "8080"
+	defaultGRPCPort            = "9090"
+	defaultLogLevel            = "info"
+	defaultShutdownTimeout     = 10 * time.Second
+	defaultReadyAttempts       = 3
+	defaultReadyRetryDelay     = 200 * time.Millisecond
+	defaultStreamIdleTimeout   = 5 * time.Minute
+	defaultCompressionMinBytes = 1024
+	defaultMaxStreamMessages   = 1000000
+)
+
+// Config is the typed server configuration resolved at startup
+type Config struct {
+	// Port is the HTTP port serving health, readiness and docs (PORT)
+	Port string
+	// GRPCPort is the gRPC listener port (GRPC_PORT)
+	GRPCPort string
+	// AdminSecret enables the admin service when set (ADMIN_SECRET)
+	AdminSecret string
+	// LogLevel is the minimum structured log level (LOG_LEVEL)
+	LogLevel slog.Level
+	// ShutdownTimeout bounds graceful shutdown (SHUTDOWN_TIMEOUT)
+	ShutdownTimeout time.Duration
+	// TLSCertFile is the gRPC server certificate path (TLS_CERT_FILE)
+	TLSCertFile string
+	// TLSKeyFile is the gRPC server private key path (TLS_KEY_FILE)
+	TLSKeyFile string
+	// TLSCAFile enables mutual TLS, verifying client certs against this CA (TLS_CA_FILE)
+	TLSCAFile string
+	// ValidationCache caches ValidateTypes results for the process lifetime (VALIDATION_CACHE)
+	ValidationCache bool
+	// ReadyAttempts is how many validations /ready tries before reporting not ready (READY_ATTEMPTS)
+	ReadyAttempts int
+	// ReadyRetryDelay is the pause between /ready validation attempts (READY_RETRY_DELAY)
+	ReadyRetryDelay time.Duration
+	// PprofPort serves net/http/pprof on a separate private port when set (PPROF_PORT)
+	PprofPort string
+	// BenchmarkSinkURL receives RunBenchmarks results as InfluxDB line protocol when set (BENCHMARK_SINK_URL)
+	BenchmarkSinkURL string
+	// BenchmarkSinkToken is sent as an InfluxDB API token with every benchmark sink write (BENCHMARK_SINK_TOKEN)
+	BenchmarkSinkToken string
+	// LogPayloadSizes sizes every gRPC message for logs and /metrics, not only at debug level (LOG_PAYLOAD_SIZES)
+	LogPayloadSizes bool
+	// ExpectedTypesFile is a JSON or YAML file of expected field types and count constraints (EXPECTED_TYPES_FILE)
+	ExpectedTypesFile string
+	// StreamIdleTimeout closes streams that receive no message for this long, 0 disables (STREAM_IDLE_TIMEOUT)
+	StreamIdleTimeout time.Duration
+	// MaxStreams caps concurrent StreamValidation streams across all connections, 0 for no cap (MAX_STREAMS)
+	MaxStreams int64
+	// MaxStreamMessages caps the messages processed per StreamValidation stream, 0 for no cap (MAX_STREAM_MESSAGES)
+	MaxStreamMessages int64
+	// CompressionMinBytes sends smaller unary responses uncompressed even to gzip clients, 0 compresses all (COMPRESSION_MIN_BYTES)
+	CompressionMinBytes int
+	// EnableReflection registers the gRPC reflection service, exposing the schema; for local development (ENABLE_REFLECTION)
+	EnableReflection bool
+	// FailOnMarshalUnsafe exits at startup when a validated message type cannot be marshaled, instead of only warning (FAIL_ON_MARSHAL_UNSAFE)
+	FailOnMarshalUnsafe bool
+	// FailReadyOnMarshalUnsafe makes /ready report 503 when a validated message type cannot be marshaled (FAIL_READY_ON_MARSHAL_UNSAFE)
+	FailReadyOnMarshalUnsafe bool
+	// AllowPinGOMAXPROCS lets RunBenchmarks requests pin the process-wide GOMAXPROCS, slowing other RPCs while they run (ALLOW_PIN_GOMAXPROCS)
+	AllowPinGOMAXPROCS bool
+}
+
+// TLSEnabled reports whether the gRPC listener should serve TLS
+func (c *Config) TLSEnabled() bool {
+	return c.TLSCertFile != "" && c.TLSKeyFile != ""
+}
+
+// PprofEnabled reports whether the profiling listener should be started
+func (c *Config) PprofEnabled() bool {
+	return c.PprofPort != ""
+}
+
+// redacted replaces secret values in the logged configuration
+const redacted = "[REDACTED]"
+
+// LogValue renders the configuration as structured attributes for logging,
+// with the admin secret, TLS private key path and any benchmark sink password
+// redacted
+func (c *Config) LogValue() slog.Value {
+	return slog.GroupValue(
+		slog.String("port", c.Port),
+		slog.String("grpc_port", c.GRPCPort),
+		slog.String("log_level", c.LogLevel.String()),
+		slog.Duration("shutdown_timeout", c.ShutdownTimeout),
+		slog.Bool("admin_enabled", c.AdminSecret != ""),
+		slog.String("admin_secret", redactSet(c.AdminSecret)),
+		slog.Bool("tls", c.TLSEnabled()),
+		slog.String("tls_cert_file", c.TLSCertFile),
+		slog.String("tls_key_file", redactSet(c.TLSKeyFile)),
+		slog.String("tls_ca_file", c.TLSCAFile),
+		slog.Bool("enable_reflection", c.EnableReflection),
+		slog.String("pprof_port", c.PprofPort),
+		slog.Bool("validation_cache", c.ValidationCache),
+		slog.Int("ready_attempts", c.ReadyAttempts),
+		slog.Duration("ready_retry_delay", c.ReadyRetryDelay),
+		slog.Duration("stream_idle_timeout", c.StreamIdleTimeout),
+		slog.Int64("max_streams", c.MaxStreams),
+		slog.Int64("max_stream_messages", c.MaxStreamMessages),
+		slog.Int("compression_min_bytes", c.CompressionMinBytes),
+		slog.Bool("log_payload_sizes", c.LogPayloadSizes),
+		slog.String("expected_types_file", c.ExpectedTypesFile),
+		slog.String("benchmark_sink_url", redactURL(c.BenchmarkSinkURL)),
+		slog.String("benchmark_sink_token", redactSet(c.BenchmarkSinkToken)),
+		slog.Bool("fail_on_marshal_unsafe", c.FailOnMarshalUnsafe),
+		slog.Bool("fail_ready_on_marshal_unsafe", c.FailReadyOnMarshalUnsafe),
+		slog.Bool("allow_pin_gomaxprocs", c.AllowPinGOMAXPROCS),
+	)
+}
+
+// LogEffective logs the resolved configuration, so a misconfigured deployment
+// shows what it is running with
+func (c *Config) LogEffective() {
+	slog.Info("effective configuration", "config", c)
+}
+
+// redactSet hides a secret value, keeping whether it was set visible
+func redactSet(value string) string {
+	if value == "" {
+		return ""
+	}
+	return redacted
+}
+
+// redactURL hides the password of a URL and every query value, since sinks
+// such as InfluxDB 1.x take credentials as query parameters
+func redactURL(value string) string {
+	u, err := url.Parse(value)
+	if err != nil {
+		return redactSet(value)
+	}
+	if u.RawQuery != "" {
+		query := u.Query()
+		for key := range query {
+			query[key] = []string{"xxxxx"}
+		}
+		u.RawQuery = query.Encode()
+	}
+	return u.Redacted()
+}
+
+// Load parses and validates all environment variables, falling back to
+// defaults for unset values. All problems are reported together.
+func Load() (*Config, error) {
+	var errs []error
+
+	cfg := &Config{
+		Port:               getEnvOrDefault("PORT", defaultPort),
+		GRPCPort:           getEnvOrDefault("GRPC_PORT", defaultGRPCPort),
+		AdminSecret:        os.Getenv("ADMIN_SECRET"),
+		TLSCertFile:        os.Getenv("TLS_CERT_FILE"),
+		TLSKeyFile:         os.Getenv("TLS_KEY_FILE"),
+		TLSCAFile:          os.Getenv("TLS_CA_FILE"),
+		PprofPort:          os.Getenv("PPROF_PORT"),
+		BenchmarkSinkURL:   os.Getenv("BENCHMARK_SINK_URL"),
+		BenchmarkSinkToken: os.Getenv("BENCHMARK_SINK_TOKEN"),
+		ExpectedTypesFile:  os.Getenv("EXPECTED_TYPES_FILE"),
+	}
+
+	if err := validatePort("PORT", cfg.Port); err != nil {
+		errs = append(errs, err)
+	}
+
+	if err := validatePort("GRPC_PORT", cfg.GRPCPort); err != nil {
+		errs = append(errs, err)
+	}
+
+	if cfg.Port == cfg.GRPCPort {
+		errs = append(errs, fmt.Errorf("PORT and GRPC_PORT must differ, both are %s", cfg.Port))
+	}
+
+	if cfg.PprofEnabled() {
+		if err := validatePort("PPROF_PORT", cfg.PprofPort); err != nil {
+			errs = append(errs, err)
+		}
+		if cfg.PprofPort == cfg.Port || cfg.PprofPort == cfg.GRPCPort {
+			errs = append(errs, fmt.Errorf("PPROF_PORT must differ from PORT and GRPC_PORT, got %s", cfg.PprofPort))
+		}
+	}
+
+	if err := cfg.LogLevel.UnmarshalText([]byte(getEnvOrDefault("LOG_LEVEL", defaultLogLevel))); err != nil {
+		errs = append(errs, fmt.Errorf("LOG_LEVEL: %w", err))
+	}
+
+	cfg.ShutdownTimeout = defaultShutdownTimeout
+	if value := os.Getenv("SHUTDOWN_TIMEOUT"); value != "" {
+		timeout, err := time.ParseDuration(value)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("SHUTDOWN_TIMEOUT: %w", err))
+		} else if timeout <= 0 {
+			errs = append(errs, fmt.Errorf("SHUTDOWN_TIMEOUT must be > 0, got %s", value))
+		} else {
+			cfg.ShutdownTimeout = timeout
+		}
+	}
+
+	cfg.ValidationCache = true
+	if value := os.Getenv("VALIDATION_CACHE"); value != "" {
+		enabled, err := strconv.ParseBool(value)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("VALIDATION_CACHE must be a boolean, got %q", value))
+		} else {
+			cfg.ValidationCache = enabled
+		}
+	}
+
+	if value := os.Getenv("LOG_PAYLOAD_SIZES"); value != "" {
+		enabled, err := strconv.ParseBool(value)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("LOG_PAYLOAD_SIZES must be a boolean, got %q", value))
+		} else {
+			cfg.LogPayloadSizes = enabled
+		}
+	}
+
+	if value := os.Getenv("ENABLE_REFLECTION"); value != "" {
+		enabled, err := strconv.ParseBool(value)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("ENABLE_REFLECTION must be a boolean, got %q", value))
+		} else {
+			cfg.EnableReflection = enabled
+		}
+	}
+
+	if value := os.Getenv("FAIL_ON_MARSHAL_UNSAFE"); value != "" {
+		enabled, err := strconv.ParseBool(value)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("FAIL_ON_MARSHAL_UNSAFE must be a boolean, got %q", value))
+		} else {
+			cfg.FailOnMarshalUnsafe = enabled
+		}
+	}
+
+	if value := os.Getenv("FAIL_READY_ON_MARSHAL_UNSAFE"); value != "" {
+		enabled, err := strconv.ParseBool(value)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("FAIL_READY_ON_MARSHAL_UNSAFE must be a boolean, got %q", value))
+		} else {
+			cfg.FailReadyOnMarshalUnsafe = enabled
+		}
+	}
+
+	if value := os.Getenv("ALLOW_PIN_GOMAXPROCS"); value != "" {
+		enabled, err := strconv.ParseBool(value)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("ALLOW_PIN_GOMAXPROCS must be a boolean, got %q", value))
+		} else {
+			cfg.AllowPinGOMAXPROCS = enabled
+		}
+	}
+
+	cfg.ReadyAttempts = defaultReadyAttempts
+	if value := os.Getenv("READY_ATTEMPTS"); value != "" {
+		attempts, err := strconv.Atoi(value)
+		if err != nil || attempts < 1 {
+			errs = append(errs, fmt.Errorf("READY_ATTEMPTS must be a positive integer, got %q", value))
+		} else {
+			cfg.ReadyAttempts = attempts
+		}
+	}
+
+	cfg.ReadyRetryDelay = defaultReadyRetryDelay
+	if value := os.Getenv("READY_RETRY_DELAY"); value != "" {
+		delay, err := time.ParseDuration(value)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("READY_RETRY_DELAY: %w", err))
+		} else if delay < 0 {
+			errs = append(errs, fmt.Errorf("READY_RETRY_DELAY must be >= 0, got %s", value))
+		} else {
+			cfg.ReadyRetryDelay = delay
+		}
+	}
+
+	cfg.StreamIdleTimeout = defaultStreamIdleTimeout
+	if value := os.Getenv("STREAM_IDLE_TIMEOUT"); value != "" {
+		timeout, err := time.ParseDuration(value)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("STREAM_IDLE_TIMEOUT: %w", err))
+		} else if timeout < 0 {
+			errs = append(errs, fmt.Errorf("STREAM_IDLE_TIMEOUT must be >= 0, got %s", value))
+		} else {
+			cfg.StreamIdleTimeout = timeout
+		}
+	}
+
+	cfg.CompressionMinBytes = defaultCompressionMinBytes
+	if value := os.Getenv("COMPRESSION_MIN_BYTES"); value != "" {
+		minBytes, err := strconv.Atoi(value)
+		if err != nil || minBytes < 0 {
+			errs = append(errs, fmt.Errorf("COMPRESSION_MIN_BYTES must be a non-negative integer, got %q", value))
+		} else {
+			cfg.CompressionMinBytes = minBytes
+		}
+	}
+
+	if value := os.Getenv("MAX_STREAMS"); value != "" {
+		limit, err := strconv.ParseInt(value, 10, 64)
+		if err != nil || limit < 0 {
+			errs = append(errs, fmt.Errorf("MAX_STREAMS must be a non-negative integer, got %q", value))
+		} else {
+			cfg.MaxStreams = limit
+		}
+	}
+
+	cfg.MaxStreamMessages = defaultMaxStreamMessages
+	if value := os.Getenv("MAX_STREAM_MESSAGES"); value != "" {
+		limit, err := strconv.ParseInt(value, 10, 64)
+		if err != nil || limit < 0 {
+			errs = append(errs, fmt.Errorf("MAX_STREAM_MESSAGES must be a non-negative integer, got %q", value))
+		} else {
+			cfg.MaxStreamMessages = limit
+		}
+	}
+
+	if cfg.BenchmarkSinkURL != "" {
+		if u, err := url.Parse(cfg.BenchmarkSinkURL); err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			errs = append(errs, fmt.Errorf("BENCHMARK_SINK_URL must be an http(s) URL, got %q", cfg.BenchmarkSinkURL))
+		}
+	}
+
+	if (cfg.TLSCertFile == "") != (cfg.TLSKeyFile == "") {
+		errs = append(errs, errors.New("TLS_CERT_FILE and TLS_KEY_FILE must be set together"))
+	}
+
+	if cfg.TLSCAFile != "" && !cfg.TLSEnabled() {
+		errs = append(errs, errors.New("TLS_CA_FILE requires TLS_CERT_FILE and TLS_KEY_FILE"))
+	}
+
+	if len(errs) > 0 {
+		return nil, errors.Join(errs...)
+	}
+
+	return cfg, nil
+}
+
+func validatePort(key, value string) error {
+	port, err := strconv.Atoi(value)
+	if err != nil {
+		return fmt.Errorf("%s must be numeric, got %q", key, value)
+	}
+
+	if port < 1 || port > 65535 {
+		return fmt.Errorf("%s must be between 1 and 65535, got %d", key, port)
+	}
+
+	return nil
+}
+
+func getEnvOrDefault(key, defaultValue string) string {
+	if value := os.Getenv(key); value != "" {
+		return value
+	}
+	return defaultValue
+}
@@ -0,0 +1,242 @@
+// Package convert copies messages with value-slice fields into forms the
+// protobuf runtime can marshal, and back from the dynamic messages it can
+// unmarshal.
+package convert
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/types/dynamicpb"
+)
+
+// ErrNilMessage is returned when there is no message to copy
+var ErrNilMessage = errors.New("nil message")
+
+// MarshalSafeCopy returns a deep copy of msg that proto.Marshal, proto.Size
+// and protojson accept. The generated Go type of a message with value-slice
+// fields cannot hold pointer elements, so the copy is a dynamic message of the
+// same type in which every repeated message field, at any depth, holds
+// pointers. It shares no memory with msg.
+func MarshalSafeCopy(msg proto.Message) (proto.Message, error) {
+	if msg == nil {
+		return nil, ErrNilMessage
+	}
+	if rv := reflect.ValueOf(msg); rv.Kind() == reflect.Pointer && rv.IsNil() {
+		return nil, fmt.Errorf("%w: %T", ErrNilMessage, msg)
+	}
+	return copyMessage(msg)
+}
+
+// copyMessage copies msg field by field into a dynamic message
+func copyMessage(msg proto.Message) (*dynamicpb.Message, error) {
+	src := msg.ProtoReflect()
+	desc := src.Descriptor()
+	out := dynamicpb.NewMessage(desc)
+
+	fields := desc.Fields()
+	for i := 0; i < fields.Len(); i++ {
+		fd := fields.Get(i)
+
+		switch {
+		case fd.IsList() && fd.Message() != nil:
+			elems, err := repeatedMessages(msg, fd)
+			if err != nil {
+				return nil, err
+			}
+			list := out.Mutable(fd).List()
+			for _, elem := range elems {
+				elemCopy, err := copyMessage(elem)
+				if err != nil {
+					return nil, err
+				}
+				list.Append(protoreflect.ValueOfMessage(elemCopy))
+			}
+		case !src.Has(fd):
+		case fd.IsList():
+			srcList, list := src.Get(fd).List(), out.Mutable(fd).List()
+			for j := 0; j < srcList.Len(); j++ {
+				list.Append(copyScalar(srcList.Get(j)))
+			}
+		case fd.IsMap():
+			var err error
+			m := out.Mutable(fd).Map()
+			src.Get(fd).Map().Range(func(k protoreflect.MapKey, v protoreflect.Value) bool {
+				if fd.MapValue().Message() == nil {
+					m.Set(k, copyScalar(v))
+					return true
+				}
+				var valueCopy *dynamicpb.Message
+				if valueCopy, err = copyMessage(v.Message().Interface()); err != nil {
+					return false
+				}
+				m.Set(k, protoreflect.ValueOfMessage(valueCopy))
+				return true
+			})
+			if err != nil {
+				return nil, err
+			}
+		case fd.Message() != nil:
+			fieldCopy, err := copyMessage(src.Get(fd).Message().Interface())
+			if err != nil {
+				return nil, err
+			}
+			out.Set(fd, protoreflect.ValueOfMessage(fieldCopy))
+		default:
+			out.Set(fd, copyScalar(src.Get(fd)))
+		}
+	}
+
+	if unknown := src.GetUnknown(); len(unknown) > 0 {
+		out.SetUnknown(bytes.Clone(unknown))
+	}
+	return out, nil
+}
+
+// copyScalar copies bytes values, which would otherwise share their backing
+// array; all other scalars are immutable
+func copyScalar(v protoreflect.Value) protoreflect.Value {
+	if b, ok := v.Interface().([]byte); ok {
+		return protoreflect.ValueOfBytes(bytes.Clone(b))
+	}
+	return v
+}
+
+// repeatedMessages returns the elements of a repeated message field. Value
+// slices cannot be read through protoreflect, so generated messages are read
+// through their Go struct field; dynamic messages have no value slices.
+func repeatedMessages(msg proto.Message, fd protoreflect.FieldDescriptor) ([]proto.Message, error) {
+	if _, ok := msg.(*dynamicpb.Message); ok {
+		list := msg.ProtoReflect().Get(fd).List()
+		elems := make([]proto.Message, list.Len())
+		for i := range elems {
+			elems[i] = list.Get(i).Message().Interface()
+		}
+		return elems, nil
+	}
+
+	slice, err := goField(msg, fd)
+	if err != nil {
+		return nil, err
+	}
+	elems := make([]proto.Message, 0, slice.Len())
+	for j := 0; j < slice.Len(); j++ {
+		elem := slice.Index(j)
+		if elem.Kind() != reflect.Pointer {
+			elem = elem.Addr()
+		} else if elem.IsNil() {
+			return nil, fmt.Errorf("%s[%d]: nil element", fd.FullName(), j)
+		}
+		elems = append(elems, elem.Interface().(proto.Message))
+	}
+	return elems, nil
+}
+
+// goField returns the Go struct field of the generated message msg that holds
+// fd, found by the field name in its protobuf tag
+func goField(msg proto.Message, fd protoreflect.FieldDescriptor) (reflect.Value, error) {
+	rv := reflect.ValueOf(msg).Elem()
+	tag := fmt.Sprintf("name=%s,", fd.Name())
+
+	for i := 0; i < rv.NumField(); i++ {
+		if strings.Contains(rv.Type().Field(i).Tag.Get("protobuf"), tag) {
+			return rv.Field(i), nil
+		}
+	}
+	return reflect.Value{}, fmt.Errorf("%s: no Go field found in %T", fd.FullName(), msg)
+}
+
+// FromDynamic copies src, a dynamic message, into dst, a generated message of
+// the same type. proto.Unmarshal panics on populated value slices, so callers
+// unmarshal into a dynamic message and convert it; repeated message fields of
+// dst, at any depth, are filled through their Go struct field.
+func FromDynamic(src *dynamicpb.Message, dst proto.Message) error {
+	if src == nil || dst == nil {
+		return ErrNilMessage
+	}
+	if rv := reflect.ValueOf(dst); rv.Kind() == reflect.Pointer && rv.IsNil() {
+		return fmt.Errorf("%w: %T", ErrNilMessage, dst)
+	}
+	if srcName, dstName := src.Descriptor().FullName(), dst.ProtoReflect().Descriptor().FullName(); srcName != dstName {
+		return fmt.Errorf("cannot copy %s into %s", srcName, dstName)
+	}
+	return fillMessage(src, dst)
+}
+
+// fillMessage copies src field by field into the generated message dst
+func fillMessage(src protoreflect.Message, dst proto.Message) error {
+	out := dst.ProtoReflect()
+
+	fields := src.Descriptor().Fields()
+	for i := 0; i < fields.Len(); i++ {
+		fd := fields.Get(i)
+		if !src.Has(fd) {
+			continue
+		}
+
+		switch {
+		case fd.IsList() && fd.Message() != nil:
+			slice, err := goField(dst, fd)
+			if err != nil {
+				return err
+			}
+			srcList := src.Get(fd).List()
+			elems := reflect.MakeSlice(slice.Type(), 0, srcList.Len())
+			elemType := slice.Type().Elem()
+			for j := 0; j < srcList.Len(); j++ {
+				// elem points at a new message; value slices hold the message itself
+				elem := reflect.New(elemType)
+				if elemType.Kind() == reflect.Pointer {
+					elem = reflect.New(elemType.Elem())
+				}
+				if err := fillMessage(srcList.Get(j).Message(), elem.Interface().(proto.Message)); err != nil {
+					return err
+				}
+				if elemType.Kind() != reflect.Pointer {
+					elem = elem.Elem()
+				}
+				elems = reflect.Append(elems, elem)
+			}
+			slice.Set(elems)
+		case fd.IsList():
+			srcList, list := src.Get(fd).List(), out.Mutable(fd).List()
+			for j := 0; j < srcList.Len(); j++ {
+				list.Append(copyScalar(srcList.Get(j)))
+			}
+		case fd.IsMap():
+			var err error
+			m := out.Mutable(fd).Map()
+			src.Get(fd).Map().Range(func(k protoreflect.MapKey, v protoreflect.Value) bool {
+				if fd.MapValue().Message() == nil {
+					m.Set(k, copyScalar(v))
+					return true
+				}
+				value := m.NewValue()
+				if err = fillMessage(v.Message(), value.Message().Interface()); err != nil {
+					return false
+				}
+				m.Set(k, value)
+				return true
+			})
+			if err != nil {
+				return err
+			}
+		case fd.Message() != nil:
+			if err := fillMessage(src.Get(fd).Message(), out.Mutable(fd).Message().Interface()); err != nil {
+				return err
+			}
+		default:
+			out.Set(fd, copyScalar(src.Get(fd)))
+		}
+	}
+
+	if unknown := src.GetUnknown(); len(unknown) > 0 {
+		out.SetUnknown(bytes.Clone(unknown))
+	}
+	return nil
+}
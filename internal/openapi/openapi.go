@@ -0,0 +1,205 @@
+// Package openapi serves the OpenAPI document generated from the
+// ValidationService protobuf definition.
+package openapi
+
+import (
+	_ "embed"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+)
+
+// swagger is the Swagger 2.0 document produced by protoc-gen-openapiv2 (see
+// the openapiv2 plugin in buf.gen.yaml). It is committed, so the binary
+// builds from a clean checkout; regenerate it with make generate whenever
+// the service definition changes.
+//
+//go:embed validation.swagger.json
+var swagger []byte
+
+// Document is the OpenAPI 3.0 document served at /openapi.json. The
+// protoc-gen-openapiv2 plugin only emits Swagger 2.0, so its output is
+// converted when the package is initialized.
+var Document = mustConvert(swagger)
+
+// Handler serves the OpenAPI document as JSON
+func Handler() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusOK)
+		w.Write(Document)
+	}
+}
+
+func mustConvert(doc []byte) []byte {
+	converted, err := ConvertToV3(doc)
+	if err != nil {
+		panic(fmt.Sprintf("openapi: converting the embedded document: %v", err))
+	}
+	return converted
+}
+
+// ConvertToV3 converts a Swagger 2.0 document, as protoc-gen-openapiv2 writes
+// it, to OpenAPI 3.0. Definitions become component schemas, body parameters
+// become request bodies and response schemas move under their media type.
+func ConvertToV3(doc []byte) ([]byte, error) {
+	var v2 map[string]any
+	if err := json.Unmarshal(doc, &v2); err != nil {
+		return nil, err
+	}
+	if version, _ := v2["swagger"].(string); version != "2.0" {
+		return nil, fmt.Errorf("expected a Swagger 2.0 document, got version %q", version)
+	}
+
+	mediaTypes := stringList(v2["consumes"], "application/json")
+	v3 := map[string]any{
+		"openapi": "3.0.3",
+		"info":    v2["info"],
+		"paths":   map[string]any{},
+		"components": map[string]any{
+			"schemas": rewriteRefs(v2["definitions"]),
+		},
+	}
+	if tags, ok := v2["tags"]; ok {
+		v3["tags"] = tags
+	}
+
+	paths, _ := v2["paths"].(map[string]any)
+	for path, item := range paths {
+		operations, _ := item.(map[string]any)
+		converted := make(map[string]any, len(operations))
+		for method, operation := range operations {
+			op, ok := operation.(map[string]any)
+			if !ok {
+				return nil, fmt.Errorf("%s %s: operation is not an object", method, path)
+			}
+			converted[method] = convertOperation(op, mediaTypes)
+		}
+		v3["paths"].(map[string]any)[path] = converted
+	}
+
+	return json.MarshalIndent(v3, "", "  ")
+}
+
+// convertOperation moves a body parameter to requestBody, the type of other
+// parameters into their schema, and each response schema under its media types
+func convertOperation(op map[string]any, mediaTypes []string) map[string]any {
+	converted := make(map[string]any, len(op))
+	for key, value := range op {
+		if key != "parameters" && key != "responses" && key != "consumes" && key != "produces" {
+			converted[key] = rewriteRefs(value)
+		}
+	}
+
+	var parameters []any
+	params, _ := op["parameters"].([]any)
+	for _, param := range params {
+		p, _ := param.(map[string]any)
+		if p["in"] == "body" {
+			converted["requestBody"] = map[string]any{
+				"required": p["required"] == true,
+				"content":  content(p["schema"], mediaTypes),
+			}
+			continue
+		}
+		parameters = append(parameters, convertParameter(p))
+	}
+	if len(parameters) > 0 {
+		converted["parameters"] = parameters
+	}
+
+	responses := map[string]any{}
+	v2Responses, _ := op["responses"].(map[string]any)
+	for code, response := range v2Responses {
+		r, _ := response.(map[string]any)
+		convertedResponse := map[string]any{"description": r["description"]}
+		if schema, ok := r["schema"]; ok {
+			convertedResponse["content"] = content(schema, stringList(op["produces"], mediaTypes...))
+		}
+		responses[code] = convertedResponse
+	}
+	converted["responses"] = responses
+
+	return converted
+}
+
+// schemaKeys are the fields of a non-body Swagger 2.0 parameter that describe
+// its type, which OpenAPI 3.0 nests under schema
+var schemaKeys = []string{"type", "format", "items", "enum", "default"}
+
+func convertParameter(p map[string]any) map[string]any {
+	converted := map[string]any{}
+	schema := map[string]any{}
+	for key, value := range p {
+		switch {
+		case key == "collectionFormat":
+			// Repeated query parameters are the OpenAPI 3.0 default
+		case contains(schemaKeys, key):
+			schema[key] = rewriteRefs(value)
+		default:
+			converted[key] = value
+		}
+	}
+	if len(schema) > 0 {
+		converted["schema"] = schema
+	}
+	return converted
+}
+
+func content(schema any, mediaTypes []string) map[string]any {
+	c := make(map[string]any, len(mediaTypes))
+	for _, mediaType := range mediaTypes {
+		c[mediaType] = map[string]any{"schema": rewriteRefs(schema)}
+	}
+	return c
+}
+
+// rewriteRefs returns v with every Swagger 2.0 definition reference pointing
+// at the matching component schema
+func rewriteRefs(v any) any {
+	switch v := v.(type) {
+	case map[string]any:
+		rewritten := make(map[string]any, len(v))
+		for key, value := range v {
+			if ref, ok := value.(string); ok && key == "$ref" {
+				rewritten[key] = strings.Replace(ref, "#/definitions/", "#/components/schemas/", 1)
+				continue
+			}
+			rewritten[key] = rewriteRefs(value)
+		}
+		return rewritten
+	case []any:
+		rewritten := make([]any, len(v))
+		for i, value := range v {
+			rewritten[i] = rewriteRefs(value)
+		}
+		return rewritten
+	default:
+		return v
+	}
+}
+
+// stringList returns v as a list of strings, or fallback when it is empty
+func stringList(v any, fallback ...string) []string {
+	values, _ := v.([]any)
+	var list []string
+	for _, value := range values {
+		if s, ok := value.(string); ok {
+			list = append(list, s)
+		}
+	}
+	if len(list) == 0 {
+		return fallback
+	}
+	return list
+}
+
+func contains(list []string, s string) bool {
+	for _, item := range list {
+		if item == s {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,95 @@
+package server
+
+import (
+	"context"
+	"crypto/subtle"
+	"time"
+
+	v1 "github.com/benjamin-rood/protogo-values-validation-demo/gen/api/validation/v1"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/health"
+	"google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+// AdminSecretHeader is the metadata key carrying the shared admin secret
+const AdminSecretHeader = "x-admin-secret"
+
+// MaxUnavailableDuration is the longest window SetServingStatus may make
+// ValidateTypes unavailable for
+const MaxUnavailableDuration = 24 * time.Hour
+
+// AdminServer implements the AdminService gRPC service
+type AdminServer struct {
+	v1.UnimplementedAdminServiceServer
+
+	secret           string
+	healthServer     *health.Server
+	validationServer *ValidationServer
+}
+
+// NewAdminServer creates a new admin service server protected by secret
+func NewAdminServer(secret string, healthServer *health.Server, validationServer *ValidationServer) *AdminServer {
+	return &AdminServer{
+		secret:           secret,
+		healthServer:     healthServer,
+		validationServer: validationServer,
+	}
+}
+
+// SetServingStatus flips the health status of ValidationService
+func (s *AdminServer) SetServingStatus(ctx context.Context, req *v1.SetServingStatusRequest) (*v1.SetServingStatusResponse, error) {
+	if err := s.authorize(ctx); err != nil {
+		return nil, err
+	}
+
+	if req.UnavailableDurationMs < 0 || req.UnavailableDurationMs > MaxUnavailableDuration.Milliseconds() {
+		return nil, status.Errorf(codes.InvalidArgument, "unavailable_duration_ms must be between 0 and %d, got %d",
+			MaxUnavailableDuration.Milliseconds(), req.UnavailableDurationMs)
+	}
+
+	servingStatus := grpc_health_v1.HealthCheckResponse_NOT_SERVING
+	if req.Serving {
+		servingStatus = grpc_health_v1.HealthCheckResponse_SERVING
+	}
+	s.healthServer.SetServingStatus(v1.ValidationService_ServiceDesc.ServiceName, servingStatus)
+
+	// A zero duration clears any previously configured unavailability
+	s.validationServer.SetUnavailableFor(time.Duration(req.UnavailableDurationMs) * time.Millisecond)
+
+	return &v1.SetServingStatusResponse{
+		Status: servingStatus.String(),
+	}, nil
+}
+
+// ClearCache flushes the ValidateTypes result cache, e.g. after the expected
+// types change, so results are recomputed without a restart
+func (s *AdminServer) ClearCache(ctx context.Context, req *v1.ClearCacheRequest) (*v1.ClearCacheResponse, error) {
+	if err := s.authorize(ctx); err != nil {
+		return nil, err
+	}
+
+	return &v1.ClearCacheResponse{
+		Evicted: int32(s.validationServer.ClearCache()),
+	}, nil
+}
+
+// authorize checks the shared secret carried in the incoming metadata
+func (s *AdminServer) authorize(ctx context.Context) error {
+	md, ok := metadata.FromIncomingContext(ctx)
+	if !ok {
+		return status.Errorf(codes.Unauthenticated, "missing %s header", AdminSecretHeader)
+	}
+
+	values := md.Get(AdminSecretHeader)
+	if len(values) == 0 {
+		return status.Errorf(codes.Unauthenticated, "missing %s header", AdminSecretHeader)
+	}
+
+	if s.secret == "" || subtle.ConstantTimeCompare([]byte(values[0]), []byte(s.secret)) != 1 {
+		return status.Errorf(codes.PermissionDenied, "invalid admin secret")
+	}
+
+	return nil
+}
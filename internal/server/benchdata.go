@@ -0,0 +1,133 @@
+package server
+
+import (
+	"context"
+	"fmt"
+
+	v1 "github.com/benjamin-rood/protogo-values-validation-demo/gen/api/validation/v1"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
+)
+
+// generateCheckInterval is how many elements are generated between checks
+// of the request context
+const generateCheckInterval = 1024
+
+// MaxEntriesPerItem caps tags_per_item and attributes_per_item, keeping a
+// single request from generating data_size times an unbounded payload
+const MaxEntriesPerItem = 64
+
+// payloadShape sets how rich each generated element is. The zero value gives
+// untagged data points and metadata with the default two attributes.
+type payloadShape struct {
+	tagsPerItem       int
+	attributesPerItem int
+}
+
+// newPayloadShape builds the shape a request asks for. The counts are checked
+// by ValidateRequest.
+func newPayloadShape(tagsPerItem, attributesPerItem int32) payloadShape {
+	return payloadShape{tagsPerItem: int(tagsPerItem), attributesPerItem: int(attributesPerItem)}
+}
+
+// tags builds the tags for element i
+func (p payloadShape) tags(i int) []string {
+	if p.tagsPerItem == 0 {
+		return nil
+	}
+	tags := make([]string, p.tagsPerItem)
+	for t := range tags {
+		tags[t] = fmt.Sprintf("tag_%d_%d", i, t)
+	}
+	return tags
+}
+
+// attributes builds the attributes for element i, starting with the default
+// "index" and "source" entries
+func (p payloadShape) attributes(i int) map[string]string {
+	n := p.attributesPerItem
+	if n == 0 {
+		n = 2
+	}
+	attributes := make(map[string]string, n)
+	for a := 0; a < n; a++ {
+		switch a {
+		case 0:
+			attributes["index"] = fmt.Sprintf("%d", i)
+		case 1:
+			attributes["source"] = "benchmark"
+		default:
+			attributes[fmt.Sprintf("attr_%d", a)] = fmt.Sprintf("value_%d_%d", i, a)
+		}
+	}
+	return attributes
+}
+
+// benchmarkData is the input shared by the RunBenchmarks stages. It is built
+// once, before any stage is timed, and only read afterwards.
+type benchmarkData struct {
+	// values and pointers hold the same data points in each representation
+	values   []v1.DataPoint
+	pointers []*v1.DataPoint
+	// serialization pairs value-slice points with attribute-carrying metadata
+	serialization *v1.PerformanceTestMessage
+	// serializedBytes is the wire size of serialization
+	serializedBytes int
+	// encoded is the pointer-slice message, marshaled for Deserialization
+	encoded []byte
+}
+
+// generateBenchmarkData builds dataSize elements of every benchmark input,
+// shaped by shape. For large sizes this alone can take longer than the
+// caller's deadline, so it stops with the context's status as soon as the
+// context is done.
+func generateBenchmarkData(ctx context.Context, dataSize int, shape payloadShape) (*benchmarkData, error) {
+	data := &benchmarkData{
+		values:   make([]v1.DataPoint, dataSize),
+		pointers: make([]*v1.DataPoint, dataSize),
+	}
+	metadata := make([]*v1.Metadata, dataSize)
+
+	for i := 0; i < dataSize; i++ {
+		if i%generateCheckInterval == 0 && ctx.Err() != nil {
+			return nil, status.FromContextError(ctx.Err()).Err()
+		}
+
+		data.values[i] = v1.DataPoint{
+			Id:        fmt.Sprintf("dp_%d", i),
+			Value:     float64(i) * 1.5,
+			Timestamp: int64(1000000 + i),
+			Tags:      shape.tags(i),
+		}
+		data.pointers[i] = &v1.DataPoint{
+			Id:        data.values[i].Id,
+			Value:     data.values[i].Value,
+			Timestamp: data.values[i].Timestamp,
+			Tags:      data.values[i].Tags,
+		}
+		// Attribute maps make non-deterministic ordering observable
+		metadata[i] = &v1.Metadata{
+			Key:        fmt.Sprintf("key_%d", i),
+			Value:      fmt.Sprintf("value_%d", i),
+			Attributes: shape.attributes(i),
+		}
+	}
+
+	data.serialization = &v1.PerformanceTestMessage{
+		ValueSliceData:   data.values,
+		PointerSliceData: metadata,
+	}
+	data.serializedBytes, _ = MessageSize(data.serialization)
+
+	// Marshal once using the pointer-slice representation, which protobuf supports
+	encoded, err := proto.Marshal(&v1.ValidationTestMessage{PointerSliceData: data.pointers})
+	if err != nil {
+		return nil, fmt.Errorf("encoding deserialization input: %w", err)
+	}
+	data.encoded = encoded
+
+	if ctx.Err() != nil {
+		return nil, status.FromContextError(ctx.Err()).Err()
+	}
+	return data, nil
+}
@@ -0,0 +1,87 @@
+package server
+
+import (
+	"errors"
+	"io"
+
+	v1 "github.com/benjamin-rood/protogo-values-validation-demo/gen/api/validation/v1"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// StreamBenchmarks runs the RunBenchmarks stages one at a time, sending each
+// result as its stage completes and a final response after the last. A stop
+// message from the client is a graceful end, not an error: the stage in
+// progress finishes, no further stages run and the final response is marked
+// truncated. Canceling the stream instead discards the run.
+func (s *ValidationServer) StreamBenchmarks(stream v1.ValidationService_StreamBenchmarksServer) error {
+	first, err := stream.Recv()
+	if errors.Is(err, io.EOF) {
+		return status.Errorf(codes.InvalidArgument, "stream closed before a start message")
+	}
+	if err != nil {
+		return err
+	}
+	req := first.GetStart()
+	if req == nil {
+		return status.Errorf(codes.InvalidArgument, "the first message must be start")
+	}
+	if err := ValidateRequest(req); err != nil {
+		return err
+	}
+
+	ctx := stream.Context()
+	if req.PinGomaxprocs {
+		restore, err := s.pinGOMAXPROCS(ctx, pinnedProcs(req))
+		if err != nil {
+			return err
+		}
+		defer restore()
+	}
+
+	run, err := s.prepareBenchmarks(ctx, req)
+	if err != nil {
+		return err
+	}
+
+	// Reader: watches for a stop message; it exits when the stream ends,
+	// which also unblocks its pending Recv once the handler returns
+	stop := make(chan struct{})
+	go func() {
+		for {
+			msg, err := stream.Recv()
+			if err != nil {
+				return
+			}
+			if msg.GetStop() != nil {
+				close(stop)
+				return
+			}
+		}
+	}()
+
+	results := make([]*v1.BenchmarkResult, 0, len(run.stages))
+	truncated := false
+	for _, stage := range run.stages {
+		if stageCanceled(stop) {
+			truncated = true
+			break
+		}
+
+		stageResults, err := runBenchmarkStages(ctx, []benchmarkStage{stage}, false)
+		if err != nil {
+			return err
+		}
+		result := stageResults[0]
+		result.OperationsPerSecondHuman = FormatRate(result.OperationsPerSecond, "ops")
+		results = append(results, result)
+
+		if err := stream.Send(&v1.BenchmarkEvent{Event: &v1.BenchmarkEvent_Result{Result: result}}); err != nil {
+			return err
+		}
+	}
+
+	final := s.benchmarkResponse(req, run, results)
+	final.Truncated = truncated
+	return stream.Send(&v1.BenchmarkEvent{Event: &v1.BenchmarkEvent_Final{Final: final}})
+}
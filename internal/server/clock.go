@@ -0,0 +1,16 @@
+package server
+
+import "time"
+
+// Clock supplies the current time for timing measurements, so tests can
+// substitute a controllable implementation
+type Clock interface {
+	Now() time.Time
+	Since(t time.Time) time.Duration
+}
+
+// realClock is the wall clock
+type realClock struct{}
+
+func (realClock) Now() time.Time                  { return time.Now() }
+func (realClock) Since(t time.Time) time.Duration { return time.Since(t) }
@@ -0,0 +1,39 @@
+package server
+
+import (
+	"context"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/encoding"
+
+	// Registering the gzip compressor lets the server decompress gzip
+	// requests and compress responses for clients that opt in with
+	//
+	//	grpc.WithDefaultCallOptions(grpc.UseCompressor(gzip.Name))
+	//
+	// or per call with grpc.UseCompressor(gzip.Name). Clients that do not opt
+	// in keep receiving uncompressed responses.
+	_ "google.golang.org/grpc/encoding/gzip"
+)
+
+// UnaryCompressionThresholdInterceptor sends responses smaller than minBytes
+// uncompressed, even to clients that asked for gzip, since compressing a few
+// bytes costs more CPU than it saves on the wire. Larger responses keep the
+// client's compressor. A minBytes of 0 or less compresses every response.
+//
+// Streams keep one compressor for all their messages, so only unary calls are
+// affected.
+func UnaryCompressionThresholdInterceptor(minBytes int) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+		resp, err := handler(ctx, req)
+		if err != nil || minBytes <= 0 {
+			return resp, err
+		}
+
+		if size, ok := MessageSize(resp); ok && size < minBytes {
+			// Only fails outside a gRPC call, where there is nothing to compress
+			_ = grpc.SetSendCompressor(ctx, encoding.Identity)
+		}
+		return resp, nil
+	}
+}
@@ -0,0 +1,37 @@
+package server
+
+import (
+	"context"
+
+	v1 "github.com/benjamin-rood/protogo-values-validation-demo/gen/api/validation/v1"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// GenerateDataset returns the PerformanceTestMessage RunBenchmarks serializes,
+// generated at the requested size and marshaled through its marshal-safe
+// copy, so clients can benchmark against the same data. req must have passed
+// ValidateRequest.
+func (s *ValidationServer) GenerateDataset(ctx context.Context, req *v1.GenerateDatasetRequest) (*v1.GenerateDatasetResponse, error) {
+	if req.DataSize > s.maxDataSize {
+		return nil, invalidArgument(ErrDataSizeTooLarge, "got %d, max %d", req.DataSize, s.maxDataSize)
+	}
+
+	data, err := generateBenchmarkData(ctx, int(req.DataSize), newPayloadShape(req.TagsPerItem, req.AttributesPerItem))
+	if err != nil {
+		if _, ok := status.FromError(err); !ok {
+			err = status.Errorf(codes.Internal, "%v", err)
+		}
+		return nil, err
+	}
+
+	dataset, err := SafeMarshal(data.serialization, v1.MarshalFormat_MARSHAL_FORMAT_BINARY)
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "failed to marshal dataset: %v", err)
+	}
+	if err := ctx.Err(); err != nil {
+		return nil, status.FromContextError(err).Err()
+	}
+
+	return &v1.GenerateDatasetResponse{Dataset: dataset}, nil
+}
@@ -0,0 +1,95 @@
+package server
+
+import (
+	"encoding/json"
+	"net/http"
+	"reflect"
+
+	v1 "github.com/benjamin-rood/protogo-values-validation-demo/gen/api/validation/v1"
+)
+
+// DemoReport is the JSON body served by DemoHandler: the demonstrate-limitation
+// scenario, with its marshaling panic recovered
+type DemoReport struct {
+	// Fields shows which repeated fields the plugin turned into value slices
+	Fields []DemoField `json:"fields"`
+	// MarshalSafe is false when marshaling a message with populated value
+	// slices panics
+	MarshalSafe  bool   `json:"marshal_safe"`
+	PanicMessage string `json:"panic_message,omitempty"`
+	MarshalError string `json:"marshal_error,omitempty"`
+}
+
+// DemoField is one generated field and whether it was transformed
+type DemoField struct {
+	Field        string `json:"field"`
+	GoType       string `json:"go_type"`
+	ExpectedType string `json:"expected_type"`
+	// Transformed reports a slice of message values rather than pointers
+	Transformed bool `json:"transformed"`
+	// Correct reports the Go type matching ExpectedType
+	Correct bool `json:"correct"`
+}
+
+// demoFields are the fields the scenario inspects: the plugin must transform
+// exactly those carrying the value_slice option
+var demoFields = []struct {
+	field, expectedType string
+}{
+	{"ValidationTestMessage.ValueSliceData", "[]v1.DataPoint"},
+	{"ValidationTestMessage.PointerSliceData", "[]*v1.DataPoint"},
+	{"ValidationTestMessage.Metrics", "[]v1.MetricPoint"},
+	{"ValidateTypesResponse.Results", "[]*v1.ValidationResult"},
+}
+
+// NewDemoReport runs the demonstrate-limitation scenario. The marshal attempt
+// is recovered, so it never panics.
+func NewDemoReport() DemoReport {
+	var report DemoReport
+	for _, f := range demoFields {
+		goType := fieldTypeString(f.field)
+		report.Fields = append(report.Fields, DemoField{
+			Field:        f.field,
+			GoType:       goType,
+			ExpectedType: f.expectedType,
+			Transformed:  isValueSliceField(f.field),
+			Correct:      goType == f.expectedType,
+		})
+	}
+
+	panicMessage, err := tryMarshal(&v1.ValidationTestMessage{
+		ValueSliceData: []v1.DataPoint{{Id: "test1", Value: 42, Timestamp: 1234567890, Tags: []string{"demo"}}},
+		Metrics:        []v1.MetricPoint{{Name: "test_metric", Measurement: 0.95, Labels: map[string]string{"type": "demo"}}},
+	})
+	report.PanicMessage = panicMessage
+	if err != nil {
+		report.MarshalError = err.Error()
+	}
+	report.MarshalSafe = panicMessage == "" && err == nil
+
+	return report
+}
+
+// isValueSliceField reports whether the field named by a "Message.Field" key
+// is a slice of structs
+func isValueSliceField(key string) bool {
+	message, field, ok := splitFieldKey(key)
+	if !ok {
+		return false
+	}
+	mt, err := findMessageType(message)
+	if err != nil {
+		return false
+	}
+	sf, ok := reflect.TypeOf(mt.Zero().Interface()).Elem().FieldByName(field)
+	return ok && sf.Type.Kind() == reflect.Slice && sf.Type.Elem().Kind() == reflect.Struct
+}
+
+// DemoHandler serves NewDemoReport as JSON at /demo
+func DemoHandler() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusOK)
+		json.NewEncoder(w).Encode(NewDemoReport())
+	}
+}
@@ -0,0 +1,256 @@
+package server
+
+import (
+	"context"
+
+	v1 "github.com/benjamin-rood/protogo-values-validation-demo/gen/api/validation/v1"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/encoding/protowire"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protodesc"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/reflect/protoregistry"
+	"google.golang.org/protobuf/types/descriptorpb"
+)
+
+// Names of the plugin's field option extensions
+const (
+	valueSliceExtension = "protogo_values.value_slice"
+	fieldOptsExtension  = "protogo_values.field_opts"
+)
+
+// Option forms reported in FieldAnnotation.Option
+const (
+	optionValueSlice          = "value_slice"
+	optionFieldOptsValueSlice = "field_opts.value_slice"
+)
+
+// Representation decisions reported in FieldAnnotation.Decision
+const (
+	DecisionTransformed     = "transformed"
+	DecisionExplicitPointer = "explicit_pointer"
+	DecisionUndecided       = "undecided"
+)
+
+// valueSliceOptions holds the wire numbers of the plugin's field options.
+// A zero number means the extension could not be resolved.
+type valueSliceOptions struct {
+	valueSlice     protowire.Number
+	fieldOpts      protowire.Number
+	fieldOptsValue protowire.Number
+}
+
+// AnalyzeDescriptorSet reports, per message field of a serialized
+// FileDescriptorSet, whether the value-slice option is present
+func (s *ValidationServer) AnalyzeDescriptorSet(ctx context.Context, req *v1.AnalyzeDescriptorSetRequest) (*v1.AnalyzeDescriptorSetResponse, error) {
+	if len(req.GetDescriptorSet()) == 0 {
+		return nil, status.Errorf(codes.InvalidArgument, "descriptor_set must not be empty")
+	}
+
+	var set descriptorpb.FileDescriptorSet
+	if err := proto.Unmarshal(req.GetDescriptorSet(), &set); err != nil {
+		return nil, status.Errorf(codes.InvalidArgument, "malformed descriptor set: %v", err)
+	}
+
+	files, err := newDescriptorFiles(&set)
+	if err != nil {
+		return nil, status.Errorf(codes.InvalidArgument, "invalid descriptor set: %v", err)
+	}
+
+	return AnalyzeDescriptorFiles(files), nil
+}
+
+// newDescriptorFiles builds a registry from set. Imports absent from the set,
+// such as google/protobuf/descriptor.proto, are tolerated so sets built
+// without --include_imports can still be analyzed.
+func newDescriptorFiles(set *descriptorpb.FileDescriptorSet) (*protoregistry.Files, error) {
+	files := new(protoregistry.Files)
+	opts := protodesc.FileOptions{AllowUnresolvable: true}
+
+	for _, fdp := range set.GetFile() {
+		fd, err := opts.New(fdp, files)
+		if err != nil {
+			return nil, err
+		}
+		if err := files.RegisterFile(fd); err != nil {
+			return nil, err
+		}
+	}
+
+	return files, nil
+}
+
+// AnalyzeDescriptorFiles reports the value-slice annotation of every message
+// field in files, flagging annotations the plugin cannot honour
+func AnalyzeDescriptorFiles(files *protoregistry.Files) *v1.AnalyzeDescriptorSetResponse {
+	opts := resolveValueSliceOptions(files)
+	resp := &v1.AnalyzeDescriptorSetResponse{}
+
+	var walk func(protoreflect.MessageDescriptors)
+	walk = func(messages protoreflect.MessageDescriptors) {
+		for i := 0; i < messages.Len(); i++ {
+			md := messages.Get(i)
+			if md.IsMapEntry() {
+				continue
+			}
+
+			fields := md.Fields()
+			for j := 0; j < fields.Len(); j++ {
+				annotation := analyzeField(fields.Get(j), opts)
+				if annotation.ValueSlice {
+					resp.AnnotatedCount++
+				}
+				if annotation.Issue != "" {
+					resp.IssueCount++
+				}
+				if annotation.Decision == DecisionUndecided {
+					resp.UndecidedCount++
+				}
+				resp.Fields = append(resp.Fields, annotation)
+			}
+
+			walk(md.Messages())
+		}
+	}
+
+	files.RangeFiles(func(fd protoreflect.FileDescriptor) bool {
+		walk(fd.Messages())
+		return true
+	})
+
+	return resp
+}
+
+// analyzeField reads the value-slice option of fd. Repeated message fields
+// also get a decision: setting the option to false explicitly keeps a field
+// as a pointer slice, and a field without the option is undecided.
+func analyzeField(fd protoreflect.FieldDescriptor, opts valueSliceOptions) *v1.FieldAnnotation {
+	annotation := &v1.FieldAnnotation{
+		Message: string(fd.Parent().FullName()),
+		Field:   string(fd.Name()),
+	}
+
+	form, valueSlice := opts.find(fd.Options())
+	if valueSlice {
+		annotation.Option = form
+	}
+	annotation.ValueSlice = valueSlice
+
+	repeatedMessage := fd.Cardinality() == protoreflect.Repeated && fd.Message() != nil && !fd.IsMap()
+	if annotation.ValueSlice && !repeatedMessage {
+		annotation.Issue = "value-slice option requires a repeated message field"
+	}
+
+	if repeatedMessage {
+		switch {
+		case valueSlice:
+			annotation.Decision = DecisionTransformed
+		case form != "":
+			annotation.Decision = DecisionExplicitPointer
+		default:
+			annotation.Decision = DecisionUndecided
+		}
+	}
+
+	return annotation
+}
+
+// resolveValueSliceOptions looks the plugin's extensions up in files first,
+// falling back to those linked into this binary
+func resolveValueSliceOptions(files *protoregistry.Files) valueSliceOptions {
+	var opts valueSliceOptions
+
+	if xd := findExtension(files, valueSliceExtension); xd != nil {
+		opts.valueSlice = xd.Number()
+	}
+
+	if xd := findExtension(files, fieldOptsExtension); xd != nil && xd.Message() != nil {
+		opts.fieldOpts = xd.Number()
+		if field := xd.Message().Fields().ByName("value_slice"); field != nil {
+			opts.fieldOptsValue = field.Number()
+		}
+	}
+
+	return opts
+}
+
+// findExtension resolves an extension by full name, or returns nil
+func findExtension(files *protoregistry.Files, name protoreflect.FullName) protoreflect.ExtensionDescriptor {
+	if d, err := files.FindDescriptorByName(name); err == nil {
+		if xd, ok := d.(protoreflect.ExtensionDescriptor); ok {
+			return xd
+		}
+	}
+
+	if xt, err := protoregistry.GlobalTypes.FindExtensionByName(name); err == nil {
+		return xt.TypeDescriptor()
+	}
+
+	return nil
+}
+
+// find returns the option form setting value_slice in options and its value.
+// A form setting it to true wins over one setting it to false; form is ""
+// when neither is set. Options are scanned on the wire so that extensions
+// parsed as unknown fields are read the same way as resolved ones.
+func (o valueSliceOptions) find(options proto.Message) (form string, valueSlice bool) {
+	if options == nil {
+		return "", false
+	}
+
+	b, err := proto.MarshalOptions{Deterministic: true}.Marshal(options)
+	if err != nil {
+		return "", false
+	}
+
+	set := func(setForm string, value []byte) {
+		v, n := protowire.ConsumeVarint(value)
+		if n < 0 || valueSlice {
+			return
+		}
+		if v != 0 {
+			form, valueSlice = setForm, true
+		} else if form == "" {
+			form = setForm
+		}
+	}
+
+	rangeWireFields(b, func(num protowire.Number, typ protowire.Type, value []byte) {
+		switch {
+		case o.valueSlice != 0 && num == o.valueSlice && typ == protowire.VarintType:
+			set(optionValueSlice, value)
+		case o.fieldOpts != 0 && num == o.fieldOpts && typ == protowire.BytesType:
+			nested, n := protowire.ConsumeBytes(value)
+			if n < 0 {
+				return
+			}
+			rangeWireFields(nested, func(num protowire.Number, typ protowire.Type, value []byte) {
+				if num == o.fieldOptsValue && typ == protowire.VarintType {
+					set(optionFieldOptsValueSlice, value)
+				}
+			})
+		}
+	})
+
+	return form, valueSlice
+}
+
+// rangeWireFields calls fn with each field of the encoded message b, passing
+// the field value still in its wire encoding. It stops at malformed input.
+func rangeWireFields(b []byte, fn func(num protowire.Number, typ protowire.Type, value []byte)) {
+	for len(b) > 0 {
+		num, typ, n := protowire.ConsumeTag(b)
+		if n < 0 {
+			return
+		}
+		b = b[n:]
+
+		m := protowire.ConsumeFieldValue(num, typ, b)
+		if m < 0 {
+			return
+		}
+		fn(num, typ, b[:m])
+		b = b[m:]
+	}
+}
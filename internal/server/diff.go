@@ -0,0 +1,199 @@
+package server
+
+import (
+	"bytes"
+	"cmp"
+	"context"
+	"encoding/json"
+	"fmt"
+	"slices"
+	"strconv"
+
+	v1 "github.com/benjamin-rood/protogo-values-validation-demo/gen/api/validation/v1"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/types/dynamicpb"
+)
+
+// FieldDiff kinds
+const (
+	DiffChanged = "changed"
+	DiffAdded   = "added"
+	DiffRemoved = "removed"
+)
+
+// DiffMessages decodes both instances as the requested message type and
+// lists every field where they differ. Repeated fields are compared by
+// position and maps by key. Decoding goes through a dynamic message, so types
+// with value-slice fields are supported.
+func (s *ValidationServer) DiffMessages(ctx context.Context, req *v1.DiffMessagesRequest) (*v1.DiffMessagesResponse, error) {
+	if req.MessageType == "" {
+		return nil, status.Errorf(codes.InvalidArgument, "message_type is required")
+	}
+	mt, err := findMessageType(req.MessageType)
+	if err != nil {
+		return nil, status.Errorf(codes.NotFound, "unknown message type %q", req.MessageType)
+	}
+
+	a, b := dynamicpb.NewMessage(mt.Descriptor()), dynamicpb.NewMessage(mt.Descriptor())
+	if err := proto.Unmarshal(req.A, a); err != nil {
+		return nil, status.Errorf(codes.InvalidArgument, "decoding a as %s: %v", mt.Descriptor().FullName(), err)
+	}
+	if err := proto.Unmarshal(req.B, b); err != nil {
+		return nil, status.Errorf(codes.InvalidArgument, "decoding b as %s: %v", mt.Descriptor().FullName(), err)
+	}
+
+	diffs := diffMessage("", a, b)
+	return &v1.DiffMessagesResponse{Equal: len(diffs) == 0, Diffs: diffs}, nil
+}
+
+// diffMessage compares two messages of one type in field declaration order,
+// prefixing reported paths with path
+func diffMessage(path string, a, b protoreflect.Message) []*v1.FieldDiff {
+	var diffs []*v1.FieldDiff
+
+	fields := a.Descriptor().Fields()
+	for i := 0; i < fields.Len(); i++ {
+		fd := fields.Get(i)
+		fieldPath := joinPath(path, string(fd.Name()))
+
+		switch {
+		case fd.IsList():
+			diffs = append(diffs, diffList(fieldPath, fd, a.Get(fd).List(), b.Get(fd).List())...)
+		case fd.IsMap():
+			diffs = append(diffs, diffMap(fieldPath, fd, a.Get(fd).Map(), b.Get(fd).Map())...)
+		case fd.HasPresence() && a.Has(fd) != b.Has(fd):
+			if a.Has(fd) {
+				diffs = append(diffs, &v1.FieldDiff{Path: fieldPath, Kind: DiffRemoved, A: renderValue(fd, a.Get(fd))})
+			} else {
+				diffs = append(diffs, &v1.FieldDiff{Path: fieldPath, Kind: DiffAdded, B: renderValue(fd, b.Get(fd))})
+			}
+		case fd.HasPresence() && !a.Has(fd):
+		default:
+			diffs = append(diffs, diffValue(fieldPath, fd, a.Get(fd), b.Get(fd))...)
+		}
+	}
+
+	if ua, ub := a.GetUnknown(), b.GetUnknown(); !bytes.Equal(ua, ub) {
+		diffs = append(diffs, &v1.FieldDiff{
+			Path: joinPath(path, "<unknown fields>"),
+			Kind: DiffChanged,
+			A:    fmt.Sprintf("%x", []byte(ua)),
+			B:    fmt.Sprintf("%x", []byte(ub)),
+		})
+	}
+	return diffs
+}
+
+// diffList compares two lists position by position; elements past the end of
+// the shorter list are added or removed
+func diffList(path string, fd protoreflect.FieldDescriptor, a, b protoreflect.List) []*v1.FieldDiff {
+	var diffs []*v1.FieldDiff
+	for i := 0; i < max(a.Len(), b.Len()); i++ {
+		elemPath := fmt.Sprintf("%s[%d]", path, i)
+		switch {
+		case i >= a.Len():
+			diffs = append(diffs, &v1.FieldDiff{Path: elemPath, Kind: DiffAdded, B: renderValue(fd, b.Get(i))})
+		case i >= b.Len():
+			diffs = append(diffs, &v1.FieldDiff{Path: elemPath, Kind: DiffRemoved, A: renderValue(fd, a.Get(i))})
+		default:
+			diffs = append(diffs, diffValue(elemPath, fd, a.Get(i), b.Get(i))...)
+		}
+	}
+	return diffs
+}
+
+// diffMap compares two maps key by key, in key order
+func diffMap(path string, fd protoreflect.FieldDescriptor, a, b protoreflect.Map) []*v1.FieldDiff {
+	keys := make(map[any]protoreflect.MapKey)
+	for _, m := range []protoreflect.Map{a, b} {
+		m.Range(func(k protoreflect.MapKey, _ protoreflect.Value) bool {
+			keys[k.Interface()] = k
+			return true
+		})
+	}
+	sorted := make([]protoreflect.MapKey, 0, len(keys))
+	for _, k := range keys {
+		sorted = append(sorted, k)
+	}
+	slices.SortFunc(sorted, compareMapKeys)
+
+	var diffs []*v1.FieldDiff
+	valueFd := fd.MapValue()
+	for _, k := range sorted {
+		keyPath := fmt.Sprintf("%s[%s]", path, renderValue(fd.MapKey(), k.Value()))
+		switch {
+		case !a.Has(k):
+			diffs = append(diffs, &v1.FieldDiff{Path: keyPath, Kind: DiffAdded, B: renderValue(valueFd, b.Get(k))})
+		case !b.Has(k):
+			diffs = append(diffs, &v1.FieldDiff{Path: keyPath, Kind: DiffRemoved, A: renderValue(valueFd, a.Get(k))})
+		default:
+			diffs = append(diffs, diffValue(keyPath, valueFd, a.Get(k), b.Get(k))...)
+		}
+	}
+	return diffs
+}
+
+// compareMapKeys orders map keys, which within one map share a kind
+func compareMapKeys(a, b protoreflect.MapKey) int {
+	switch av := a.Interface().(type) {
+	case string:
+		return cmp.Compare(av, b.String())
+	case bool:
+		return cmp.Compare(strconv.FormatBool(av), strconv.FormatBool(b.Bool()))
+	case int32, int64:
+		return cmp.Compare(a.Int(), b.Int())
+	default:
+		return cmp.Compare(a.Uint(), b.Uint())
+	}
+}
+
+// diffValue compares one singular value, descending into messages
+func diffValue(path string, fd protoreflect.FieldDescriptor, a, b protoreflect.Value) []*v1.FieldDiff {
+	if fd.Message() != nil {
+		return diffMessage(path, a.Message(), b.Message())
+	}
+	if a.Equal(b) {
+		return nil
+	}
+	return []*v1.FieldDiff{{Path: path, Kind: DiffChanged, A: renderValue(fd, a), B: renderValue(fd, b)}}
+}
+
+// renderValue formats a value for display: strings quoted, bytes in hex,
+// enums by name and messages as compact JSON
+func renderValue(fd protoreflect.FieldDescriptor, v protoreflect.Value) string {
+	switch fd.Kind() {
+	case protoreflect.MessageKind, protoreflect.GroupKind:
+		out, err := protojson.Marshal(v.Message().Interface())
+		if err != nil {
+			return fmt.Sprintf("<%v>", err)
+		}
+		var buf bytes.Buffer
+		if err := json.Compact(&buf, out); err != nil {
+			return string(out)
+		}
+		return buf.String()
+	case protoreflect.StringKind:
+		return strconv.Quote(v.String())
+	case protoreflect.BytesKind:
+		return fmt.Sprintf("%x", v.Bytes())
+	case protoreflect.EnumKind:
+		if value := fd.Enum().Values().ByNumber(v.Enum()); value != nil {
+			return string(value.Name())
+		}
+		return strconv.Itoa(int(v.Enum()))
+	default:
+		return fmt.Sprint(v.Interface())
+	}
+}
+
+// joinPath appends a field name to a path
+func joinPath(path, name string) string {
+	if path == "" {
+		return name
+	}
+	return path + "." + name
+}
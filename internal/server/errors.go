@@ -0,0 +1,118 @@
+package server
+
+import (
+	"errors"
+	"fmt"
+
+	v1 "github.com/benjamin-rood/protogo-values-validation-demo/gen/api/validation/v1"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// Sentinel errors for invalid request parameters. Handlers return them wrapped
+// with a gRPC status code, so in-process callers can match them with errors.Is
+// while remote callers still receive the status code.
+var (
+	ErrInvalidIterations      = errors.New("iterations must be > 0")
+	ErrInvalidDataSize        = errors.New("data_size must be > 0")
+	ErrDataSizeTooLarge       = errors.New("data_size exceeds the server maximum")
+	ErrTooManyMetricGroups    = errors.New("too many distinct label sets")
+	ErrInvalidPageToken       = errors.New("invalid page_token")
+	ErrInvalidPageSize        = errors.New("page_size must be >= 0")
+	ErrUnknownScenario        = errors.New("unknown test scenario")
+	ErrInvalidItemEntries     = fmt.Errorf("tags_per_item and attributes_per_item must be between 0 and %d", MaxEntriesPerItem)
+	ErrInvalidDurationBuckets = fmt.Errorf("duration_buckets_ms must hold at most %d finite, strictly ascending bounds", maxDurationBuckets)
+	ErrInvalidTimeRange       = errors.New("start_timestamp must not be after end_timestamp")
+	ErrInvalidGOMAXPROCS      = errors.New("gomaxprocs must be between 0 and the number of CPUs")
+	ErrInvalidWatchInterval   = fmt.Errorf("interval_ms must be 0 or at least %d", MinWatchInterval.Milliseconds())
+	ErrInvalidSamples         = fmt.Errorf("samples must be between 0 and %d", MaxSamples)
+	ErrTooManyDataSizes       = fmt.Errorf("data_sizes must hold at most %d entries", maxCompareDataSizes)
+	ErrTooManyIterations      = fmt.Errorf("iterations must be at most %d", MaxRecommendIterations)
+)
+
+// statusError attaches a gRPC status code to an error chain
+type statusError struct {
+	code codes.Code
+	err  error
+}
+
+func (e *statusError) Error() string { return e.err.Error() }
+
+func (e *statusError) Unwrap() error { return e.err }
+
+// GRPCStatus lets status.FromError and status.Code recover the code
+func (e *statusError) GRPCStatus() *status.Status {
+	return status.New(e.code, e.err.Error())
+}
+
+// invalidArgument wraps err with codes.InvalidArgument, adding optional context
+func invalidArgument(err error, format string, args ...any) error {
+	if format != "" {
+		err = fmt.Errorf("%w: "+format, append([]any{err}, args...)...)
+	}
+	return &statusError{code: codes.InvalidArgument, err: err}
+}
+
+// resourceExhausted wraps err with codes.ResourceExhausted
+func resourceExhausted(err error, format string, args ...any) error {
+	if format != "" {
+		err = fmt.Errorf("%w: "+format, append([]any{err}, args...)...)
+	}
+	return &statusError{code: codes.ResourceExhausted, err: err}
+}
+
+// ValidationError aggregates the failing results of a type validation, so
+// in-process callers can inspect them with errors.As instead of scanning the
+// results. As a gRPC status it is FailedPrecondition carrying every failing
+// result as a google.rpc.BadRequest field violation.
+type ValidationError struct {
+	// Failures are the results that did not pass, in result order
+	Failures []*v1.ValidationResult
+}
+
+// NewValidationError returns a *ValidationError holding the results that did
+// not pass, or nil when all of them did
+func NewValidationError(results []*v1.ValidationResult) error {
+	var failures []*v1.ValidationResult
+	for _, result := range results {
+		if !result.Passed {
+			failures = append(failures, result)
+		}
+	}
+	if len(failures) == 0 {
+		return nil
+	}
+	return &ValidationError{Failures: failures}
+}
+
+func (e *ValidationError) Error() string {
+	return fmt.Sprintf("type validation failed: %d failing results", len(e.Failures))
+}
+
+// Scenarios returns the scenario of each failing result
+func (e *ValidationError) Scenarios() []string {
+	scenarios := make([]string, len(e.Failures))
+	for i, result := range e.Failures {
+		scenarios[i] = result.Scenario
+	}
+	return scenarios
+}
+
+// GRPCStatus lets status.FromError and status.Code recover the code and details
+func (e *ValidationError) GRPCStatus() *status.Status {
+	badRequest := &errdetails.BadRequest{}
+	for _, result := range e.Failures {
+		badRequest.FieldViolations = append(badRequest.FieldViolations, &errdetails.BadRequest_FieldViolation{
+			Field:       result.Scenario,
+			Description: fmt.Sprintf("expected %s, got %s", result.ExpectedType, result.ActualType),
+		})
+	}
+
+	st := status.New(codes.FailedPrecondition, e.Error())
+	withDetails, err := st.WithDetails(badRequest)
+	if err != nil {
+		return st
+	}
+	return withDetails
+}
@@ -0,0 +1,193 @@
+package server
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"reflect"
+	"strings"
+
+	v1 "github.com/benjamin-rood/protogo-values-validation-demo/gen/api/validation/v1"
+	"github.com/benjamin-rood/protogo-values-validation-demo/internal/convert"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protoreflect"
+)
+
+// exampleMessages builds a deterministic, valid instance of each message type
+// integrators send or receive, in the order they are listed to clients
+var exampleMessages = []func() proto.Message{
+	func() proto.Message {
+		return &v1.ValidationTestMessage{
+			ValueSliceData: []v1.DataPoint{
+				{Id: "dp_0", Value: 1.5, Timestamp: 1700000000, Tags: []string{"example", "value_slice"}},
+				{Id: "dp_1", Value: 3, Timestamp: 1700000001, Tags: []string{"example"}},
+			},
+			PointerSliceData: []*v1.DataPoint{
+				{Id: "dp_2", Value: 4.5, Timestamp: 1700000002, Tags: []string{"example", "pointer_slice"}},
+			},
+			Metrics: []v1.MetricPoint{
+				{Name: "latency_ms", Measurement: 12.5, Labels: map[string]string{"env": "example"}},
+			},
+		}
+	},
+	func() proto.Message {
+		return &v1.PerformanceTestMessage{
+			ValueSliceData: []v1.DataPoint{
+				{Id: "dp_0", Value: 1.5, Timestamp: 1700000000, Tags: []string{"performance"}},
+			},
+			PointerSliceData: []*v1.Metadata{
+				{Key: "source", Value: "example", Attributes: map[string]string{"type": "metadata"}},
+			},
+			Results: []v1.ProcessingResult{
+				{OperationId: "op_0", Success: true, DurationMs: 0.8},
+				{OperationId: "op_1", Success: false, DurationMs: 2.4, ErrorMessages: []string{"timeout"}},
+			},
+		}
+	},
+	func() proto.Message {
+		return &v1.DataPoint{Id: "dp_0", Value: 1.5, Timestamp: 1700000000, Tags: []string{"example"}}
+	},
+	func() proto.Message {
+		return &v1.MetricPoint{Name: "latency_ms", Measurement: 12.5, Labels: map[string]string{"env": "example"}}
+	},
+	func() proto.Message {
+		return &v1.Metadata{Key: "source", Value: "example", Attributes: map[string]string{"type": "metadata"}}
+	},
+	func() proto.Message {
+		return &v1.ProcessingResult{OperationId: "op_0", Success: false, DurationMs: 2.4, ErrorMessages: []string{"timeout"}}
+	},
+	func() proto.Message {
+		return &v1.ValidateTypesRequest{TestScenarios: []string{ScenarioValidationTestMessage, ScenarioPerformanceTestMessage}, DeepValidation: true}
+	},
+	func() proto.Message {
+		return &v1.BenchmarkRequest{Iterations: 1000, DataSize: 100, BenchmarkNames: []string{"ValueSlice_Iteration", "PointerSlice_Iteration"}}
+	},
+	func() proto.Message {
+		return &v1.StreamRequest{
+			RequestId:      "req_0",
+			SequenceNumber: 0,
+			TestData: &v1.ValidationTestMessage{
+				ValueSliceData: []v1.DataPoint{{Id: "dp_0", Value: 1.5, Timestamp: 1700000000}},
+			},
+		}
+	},
+}
+
+// ExampleMessage returns the example for messageType, which may be the short
+// ("DataPoint") or fully-qualified ("validation.v1.DataPoint") name
+func ExampleMessage(messageType string) (proto.Message, bool) {
+	for _, example := range exampleMessages {
+		msg := example()
+		name := msg.ProtoReflect().Descriptor().FullName()
+		if messageType == string(name) || messageType == string(name.Name()) {
+			return msg, true
+		}
+	}
+	return nil, false
+}
+
+// ExampleTypes lists the fully-qualified names of every available example
+func ExampleTypes() []string {
+	types := make([]string, 0, len(exampleMessages))
+	for _, example := range exampleMessages {
+		types = append(types, string(example().ProtoReflect().Descriptor().FullName()))
+	}
+	return types
+}
+
+// GetExample returns a populated example of the requested message type as JSON
+func (s *ValidationServer) GetExample(ctx context.Context, req *v1.GetExampleRequest) (*v1.GetExampleResponse, error) {
+	if req.MessageType == "" {
+		return nil, status.Errorf(codes.InvalidArgument, "message_type is required")
+	}
+
+	msg, ok := ExampleMessage(req.MessageType)
+	if !ok {
+		return nil, status.Errorf(codes.NotFound, "no example for %q, available: %s",
+			req.MessageType, strings.Join(ExampleTypes(), ", "))
+	}
+
+	out, err := MarshalExampleJSON(msg)
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "failed to marshal example: %v", err)
+	}
+
+	return &v1.GetExampleResponse{
+		MessageType: string(msg.ProtoReflect().Descriptor().FullName()),
+		Json:        out,
+	}, nil
+}
+
+// ExampleHandler serves GetExample over HTTP at /examples/{type}
+func ExampleHandler(s *ValidationServer) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		resp, err := s.GetExample(r.Context(), &v1.GetExampleRequest{MessageType: r.PathValue("type")})
+		if err != nil {
+			code := http.StatusInternalServerError
+			switch status.Code(err) {
+			case codes.InvalidArgument:
+				code = http.StatusBadRequest
+			case codes.NotFound:
+				code = http.StatusNotFound
+			}
+			http.Error(w, status.Convert(err).Message(), code)
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusOK)
+		w.Write([]byte(resp.Json))
+	}
+}
+
+// MarshalExampleJSON renders msg as indented protojson. protojson cannot
+// reflect over populated value slices, so msg is copied into a pointer-backed
+// dynamic message of the same type first. The output is re-indented because
+// protojson deliberately varies its whitespace between builds.
+func MarshalExampleJSON(msg proto.Message) (string, error) {
+	safe, err := convert.MarshalSafeCopy(msg)
+	if err != nil {
+		return "", err
+	}
+	out, err := protojson.Marshal(safe)
+	if err != nil {
+		return "", err
+	}
+
+	var buf bytes.Buffer
+	if err := json.Indent(&buf, out, "", "  "); err != nil {
+		return "", err
+	}
+	return buf.String(), nil
+}
+
+// repeatedMessageElements reads a repeated message field through the Go
+// struct, since value-slice fields cannot be read through protoreflect
+func repeatedMessageElements(msg proto.Message, fd protoreflect.FieldDescriptor) []proto.Message {
+	rv := reflect.ValueOf(msg).Elem()
+	tag := fmt.Sprintf("name=%s,", fd.Name())
+
+	for i := 0; i < rv.NumField(); i++ {
+		if !strings.Contains(rv.Type().Field(i).Tag.Get("protobuf"), tag) {
+			continue
+		}
+
+		slice := rv.Field(i)
+		elems := make([]proto.Message, slice.Len())
+		for j := range elems {
+			elem := slice.Index(j)
+			if elem.Kind() != reflect.Pointer {
+				elem = elem.Addr()
+			}
+			elems[j] = elem.Interface().(proto.Message)
+		}
+		return elems
+	}
+
+	return nil
+}
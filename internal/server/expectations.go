@@ -0,0 +1,227 @@
+package server
+
+import (
+	"fmt"
+	"os"
+	"reflect"
+	"sort"
+	"strings"
+
+	v1 "github.com/benjamin-rood/protogo-values-validation-demo/gen/api/validation/v1"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/reflect/protoregistry"
+	"gopkg.in/yaml.v3"
+)
+
+// TypeExpectations maps "Message.Field" to the Go type string ValidateTypes
+// expects the generated field to have, e.g. "DataPoint.Tags": "[]string"
+type TypeExpectations map[string]string
+
+// DefaultTypeExpectations returns the expectations for this demo's schema,
+// used when no expectations file is configured
+func DefaultTypeExpectations() TypeExpectations {
+	return TypeExpectations{
+		"ValidationTestMessage.ValueSliceData":    "[]v1.DataPoint",
+		"ValidationTestMessage.PointerSliceData":  "[]*v1.DataPoint",
+		"ValidationTestMessage.Metrics":           "[]v1.MetricPoint",
+		"PerformanceTestMessage.ValueSliceData":   "[]v1.DataPoint",
+		"PerformanceTestMessage.PointerSliceData": "[]*v1.Metadata",
+		"PerformanceTestMessage.Results":          "[]v1.ProcessingResult",
+		"DataPoint.Tags":                          "[]string",
+		"ProcessingResult.ErrorMessages":          "[]string",
+	}
+}
+
+// CountConstraint bounds the number of entries in a repeated field. A nil
+// bound is not checked.
+type CountConstraint struct {
+	Min *int `yaml:"min_count"`
+	Max *int `yaml:"max_count"`
+}
+
+// CountConstraints maps "Message.Field" to the entry count the field must have
+type CountConstraints map[string]CountConstraint
+
+// expectationEntry is the mapping form of an expectations file entry
+type expectationEntry struct {
+	Type            string `yaml:"type"`
+	CountConstraint `yaml:",inline"`
+}
+
+// LoadTypeExpectations reads the expected types from a JSON or YAML
+// expectations file, ignoring any count constraints
+func LoadTypeExpectations(path string) (TypeExpectations, error) {
+	expectations, _, err := LoadExpectations(path)
+	return expectations, err
+}
+
+// LoadExpectations reads a JSON or YAML file holding a single object keyed by
+// "Message.Field". Each value is either the expected type, or an object with
+// an optional "type" and optional "min_count" and "max_count" bounds:
+//
+//	DataPoint.Tags: "[]string"
+//	ValidationTestMessage.Metrics: {type: "[]v1.MetricPoint", min_count: 1}
+func LoadExpectations(path string) (TypeExpectations, CountConstraints, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, nil, fmt.Errorf("reading type expectations: %w", err)
+	}
+
+	// JSON is valid YAML, so one decoder handles both
+	var loaded map[string]yaml.Node
+	if err := yaml.Unmarshal(data, &loaded); err != nil {
+		return nil, nil, fmt.Errorf("parsing type expectations %s: %w", path, err)
+	}
+	if len(loaded) == 0 {
+		return nil, nil, fmt.Errorf("type expectations %s: no entries", path)
+	}
+
+	expectations := make(TypeExpectations)
+	constraints := make(CountConstraints)
+	for key, node := range loaded {
+		message, field, ok := splitFieldKey(key)
+		if !ok || message == "" || field == "" {
+			return nil, nil, fmt.Errorf("type expectations %s: key %q must be Message.Field", path, key)
+		}
+
+		var entry expectationEntry
+		if node.Kind == yaml.MappingNode {
+			err = node.Decode(&entry)
+		} else {
+			err = node.Decode(&entry.Type)
+		}
+		if err != nil {
+			return nil, nil, fmt.Errorf("type expectations %s: %s: %w", path, key, err)
+		}
+
+		hasCount := entry.Min != nil || entry.Max != nil
+		if entry.Type == "" && !hasCount {
+			return nil, nil, fmt.Errorf("type expectations %s: %s has no expected type or count", path, key)
+		}
+		if err := entry.CountConstraint.validate(); err != nil {
+			return nil, nil, fmt.Errorf("type expectations %s: %s: %w", path, key, err)
+		}
+
+		if entry.Type != "" {
+			expectations[key] = entry.Type
+		}
+		if hasCount {
+			constraints[key] = entry.CountConstraint
+		}
+	}
+	return expectations, constraints, nil
+}
+
+func (c CountConstraint) validate() error {
+	switch {
+	case c.Min != nil && *c.Min < 0:
+		return fmt.Errorf("min_count %d is negative", *c.Min)
+	case c.Max != nil && *c.Max < 0:
+		return fmt.Errorf("max_count %d is negative", *c.Max)
+	case c.Min != nil && c.Max != nil && *c.Min > *c.Max:
+		return fmt.Errorf("min_count %d exceeds max_count %d", *c.Min, *c.Max)
+	}
+	return nil
+}
+
+// String describes the accepted counts, e.g. ">= 1" or "1..10"
+func (c CountConstraint) String() string {
+	switch {
+	case c.Min != nil && c.Max != nil:
+		return fmt.Sprintf("%d..%d", *c.Min, *c.Max)
+	case c.Min != nil:
+		return fmt.Sprintf(">= %d", *c.Min)
+	case c.Max != nil:
+		return fmt.Sprintf("<= %d", *c.Max)
+	default:
+		return "any"
+	}
+}
+
+// Check reports whether count satisfies the constraint, describing the
+// violation when it does not
+func (c CountConstraint) Check(count int) (ok bool, violation string) {
+	switch {
+	case c.Min != nil && count < *c.Min:
+		return false, fmt.Sprintf("has %d entries, expected at least %d", count, *c.Min)
+	case c.Max != nil && count > *c.Max:
+		return false, fmt.Sprintf("has %d entries, expected at most %d", count, *c.Max)
+	}
+	return true, ""
+}
+
+// splitFieldKey splits "Message.Field" at its last dot, so fully-qualified
+// message names are accepted
+func splitFieldKey(key string) (message, field string, ok bool) {
+	i := strings.LastIndexByte(key, '.')
+	if i < 0 {
+		return "", "", false
+	}
+	return key[:i], key[i+1:], true
+}
+
+// validateAdditionalExpectations validates configured fields the built-in
+// validators do not cover, in key order
+func (s *ValidationServer) validateAdditionalExpectations() []*v1.ValidationResult {
+	defaults := DefaultTypeExpectations()
+
+	keys := make([]string, 0, len(s.expectations))
+	for key := range s.expectations {
+		if _, ok := defaults[key]; !ok {
+			keys = append(keys, key)
+		}
+	}
+	sort.Strings(keys)
+
+	results := make([]*v1.ValidationResult, 0, len(keys))
+	for _, key := range keys {
+		results = append(results, NewValidationResult(key, fieldTypeString(key), s.expectations[key]))
+	}
+	return results
+}
+
+// fieldTypeString reflects the Go type of a generated field named by a
+// "Message.Field" key, where Field is the Go field name
+func fieldTypeString(key string) string {
+	t, ok := fieldGoType(key)
+	if !ok {
+		return InvalidTypeString
+	}
+	return t.String()
+}
+
+// fieldGoType resolves the Go type of a generated field named by a
+// "Message.Field" key
+func fieldGoType(key string) (reflect.Type, bool) {
+	message, field, ok := splitFieldKey(key)
+	if !ok {
+		return nil, false
+	}
+
+	mt, err := findMessageType(message)
+	if err != nil {
+		return nil, false
+	}
+
+	goType := reflect.TypeOf(mt.Zero().Interface())
+	if goType.Kind() != reflect.Pointer || goType.Elem().Kind() != reflect.Struct {
+		return nil, false
+	}
+
+	sf, ok := goType.Elem().FieldByName(field)
+	if !ok || !sf.IsExported() {
+		return nil, false
+	}
+	return sf.Type, true
+}
+
+// findMessageType looks up a linked-in message type by fully-qualified name,
+// or by short name within this service's proto package
+func findMessageType(name string) (protoreflect.MessageType, error) {
+	fullName := protoreflect.FullName(name)
+	if !strings.Contains(name, ".") {
+		pkg := (&v1.DataPoint{}).ProtoReflect().Descriptor().ParentFile().Package()
+		fullName = pkg.Append(protoreflect.Name(name))
+	}
+	return protoregistry.GlobalTypes.FindMessageByName(fullName)
+}
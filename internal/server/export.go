@@ -0,0 +1,219 @@
+package server
+
+import (
+	"bytes"
+	"encoding/csv"
+	"fmt"
+	"net/http"
+	"runtime"
+	"strconv"
+	"strings"
+	"time"
+
+	v1 "github.com/benjamin-rood/protogo-values-validation-demo/gen/api/validation/v1"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// Defaults for /benchmarks.csv when query parameters are omitted, and the
+// largest values a query may ask for. The endpoint is unauthenticated, so the
+// caps sit well below what RunBenchmarks accepts over gRPC.
+const (
+	defaultCSVIterations = 1000
+	defaultCSVDataSize   = 100
+	maxCSVIterations     = 10000
+	maxCSVDataSize       = 1000
+)
+
+// FormatBenchstat renders benchmark results in the `go test -bench` text format
+// so they can be compared across runs with benchstat. Durations, bytes and
+// allocations are totals over iterations, so they are reported per operation.
+// Failed stages are omitted.
+func FormatBenchstat(results []*v1.BenchmarkResult, iterations int32) string {
+	var b strings.Builder
+
+	fmt.Fprintf(&b, "goos: %s\n", runtime.GOOS)
+	fmt.Fprintf(&b, "goarch: %s\n", runtime.GOARCH)
+
+	if iterations <= 0 {
+		return b.String()
+	}
+
+	procs := runtime.GOMAXPROCS(0)
+	n := float64(iterations)
+
+	for _, result := range results {
+		// Failed stages have no measurements to compare
+		if result.Error != "" {
+			continue
+		}
+		fmt.Fprintf(&b, "Benchmark%s-%d\t%d\t%s ns/op\t%s B/op\t%s allocs/op\n",
+			result.Name,
+			procs,
+			iterations,
+			formatBenchValue(result.DurationNs/n),
+			formatBenchValue(float64(result.BytesAllocated)/n),
+			formatBenchValue(float64(result.Allocations)/n),
+		)
+	}
+
+	return b.String()
+}
+
+// formatBenchValue formats a float without exponent or precision loss
+func formatBenchValue(v float64) string {
+	return strconv.FormatFloat(v, 'f', -1, 64)
+}
+
+// openMetricsFamilies are the gauges FormatOpenMetrics renders for each
+// successful stage
+var openMetricsFamilies = []struct {
+	name, help string
+	value      func(*v1.BenchmarkResult) string
+}{
+	{"benchmark_duration_ns", "Total duration of the stage's iterations in nanoseconds.",
+		func(r *v1.BenchmarkResult) string { return formatBenchValue(r.DurationNs) }},
+	{"benchmark_allocations", "Heap allocations made by the stage.",
+		func(r *v1.BenchmarkResult) string { return strconv.FormatInt(r.Allocations, 10) }},
+	{"benchmark_allocated_bytes", "Bytes allocated by the stage.",
+		func(r *v1.BenchmarkResult) string { return strconv.FormatInt(r.BytesAllocated, 10) }},
+	{"benchmark_operations_per_second", "Iterations completed per second.",
+		func(r *v1.BenchmarkResult) string { return formatBenchValue(r.OperationsPerSecond) }},
+}
+
+var openMetricsLabelEscaper = strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`)
+
+// FormatOpenMetrics renders benchmark results as an OpenMetrics exposition:
+// a point-in-time snapshot of one run, every sample stamped with at. Each
+// stage is labelled by name; failed stages appear only in benchmark_failed.
+func FormatOpenMetrics(results []*v1.BenchmarkResult, at time.Time) string {
+	var b strings.Builder
+	timestamp := fmt.Sprintf("%d.%03d", at.Unix(), at.Nanosecond()/int(time.Millisecond))
+
+	for _, family := range openMetricsFamilies {
+		fmt.Fprintf(&b, "# TYPE %s gauge\n", family.name)
+		fmt.Fprintf(&b, "# HELP %s %s\n", family.name, family.help)
+		for _, result := range results {
+			if result.Error != "" {
+				continue
+			}
+			fmt.Fprintf(&b, "%s{name=\"%s\"} %s %s\n",
+				family.name, openMetricsLabelEscaper.Replace(result.Name), family.value(result), timestamp)
+		}
+	}
+
+	b.WriteString("# TYPE benchmark_failed gauge\n")
+	b.WriteString("# HELP benchmark_failed Whether the stage failed (1) or completed (0).\n")
+	for _, result := range results {
+		failed := 0
+		if result.Error != "" {
+			failed = 1
+		}
+		fmt.Fprintf(&b, "benchmark_failed{name=\"%s\"} %d %s\n",
+			openMetricsLabelEscaper.Replace(result.Name), failed, timestamp)
+	}
+
+	b.WriteString("# EOF\n")
+	return b.String()
+}
+
+// FormatCSV renders a benchmark response as CSV: a header row, one row per
+// result, then a blank line and a two-column summary section. Numbers are
+// formatted with strconv, so output does not depend on locale.
+func FormatCSV(resp *v1.BenchmarkResponse) (string, error) {
+	var buf bytes.Buffer
+	w := csv.NewWriter(&buf)
+
+	records := [][]string{
+		{"name", "duration_ns", "allocations", "bytes_allocated", "ops_per_sec", "error"},
+	}
+	for _, result := range resp.Results {
+		records = append(records, []string{
+			result.Name,
+			formatBenchValue(result.DurationNs),
+			strconv.FormatInt(result.Allocations, 10),
+			strconv.FormatInt(result.BytesAllocated, 10),
+			formatBenchValue(result.OperationsPerSecond),
+			result.Error,
+		})
+	}
+
+	summary := resp.Summary
+	if summary == nil {
+		summary = &v1.BenchmarkSummary{}
+	}
+	records = append(records,
+		[]string{},
+		[]string{"summary", "value"},
+		[]string{"value_slice_avg_duration", formatBenchValue(summary.ValueSliceAvgDuration)},
+		[]string{"pointer_slice_avg_duration", formatBenchValue(summary.PointerSliceAvgDuration)},
+		[]string{"performance_improvement_ratio", formatBenchValue(summary.PerformanceImprovementRatio)},
+		[]string{"memory_savings_bytes", strconv.FormatInt(summary.MemorySavingsBytes, 10)},
+	)
+
+	if err := w.WriteAll(records); err != nil {
+		return "", err
+	}
+	return buf.String(), nil
+}
+
+// BenchmarksCSVHandler runs the benchmarks and serves the results as CSV.
+// The iterations and data_size query parameters override the defaults, up to
+// maxCSVIterations and maxCSVDataSize.
+func BenchmarksCSVHandler(s *ValidationServer) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		req := &v1.BenchmarkRequest{
+			Iterations: defaultCSVIterations,
+			DataSize:   defaultCSVDataSize,
+		}
+
+		params := []struct {
+			name  string
+			field *int32
+			max   int64
+		}{
+			{"iterations", &req.Iterations, maxCSVIterations},
+			{"data_size", &req.DataSize, maxCSVDataSize},
+		}
+		for _, param := range params {
+			value := r.URL.Query().Get(param.name)
+			if value == "" {
+				continue
+			}
+			n, err := strconv.ParseInt(value, 10, 32)
+			if err != nil {
+				http.Error(w, fmt.Sprintf("%s must be an integer", param.name), http.StatusBadRequest)
+				return
+			}
+			if n > param.max {
+				http.Error(w, fmt.Sprintf("%s must be <= %d, got %d", param.name, param.max, n), http.StatusBadRequest)
+				return
+			}
+			*param.field = int32(n)
+		}
+
+		err := ValidateRequest(req)
+		var resp *v1.BenchmarkResponse
+		if err == nil {
+			resp, err = s.RunBenchmarks(r.Context(), req)
+		}
+		if err != nil {
+			code := http.StatusInternalServerError
+			if status.Code(err) == codes.InvalidArgument {
+				code = http.StatusBadRequest
+			}
+			http.Error(w, err.Error(), code)
+			return
+		}
+
+		body, err := FormatCSV(resp)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+
+		w.Header().Set("Content-Type", "text/csv")
+		w.WriteHeader(http.StatusOK)
+		w.Write([]byte(body))
+	}
+}
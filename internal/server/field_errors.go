@@ -0,0 +1,66 @@
+package server
+
+import (
+	"fmt"
+
+	v1 "github.com/benjamin-rood/protogo-values-validation-demo/gen/api/validation/v1"
+	"google.golang.org/protobuf/encoding/protowire"
+	"google.golang.org/protobuf/proto"
+)
+
+// wireTypeNames names the protobuf wire types for FieldError.ActualType
+var wireTypeNames = map[protowire.Type]string{
+	protowire.VarintType:     "varint",
+	protowire.Fixed32Type:    "fixed32",
+	protowire.Fixed64Type:    "fixed64",
+	protowire.BytesType:      "bytes",
+	protowire.StartGroupType: "group",
+}
+
+// unknownFieldErrors reports, recursively, every declared field of msg that
+// was received with a wire type its declared type cannot be decoded from.
+// protobuf keeps such fields as unknown fields. Field numbers the schema does
+// not declare are left alone, since they are how newer clients stay forward
+// compatible.
+func unknownFieldErrors(path string, msg proto.Message) []*v1.FieldError {
+	m := msg.ProtoReflect()
+	fields := m.Descriptor().Fields()
+
+	var fieldErrors []*v1.FieldError
+	rangeWireFields(m.GetUnknown(), func(num protowire.Number, typ protowire.Type, _ []byte) {
+		fd := fields.ByNumber(num)
+		if fd == nil {
+			return
+		}
+
+		actual, ok := wireTypeNames[typ]
+		if !ok {
+			actual = fmt.Sprintf("wire type %d", typ)
+		}
+		fieldErrors = append(fieldErrors, &v1.FieldError{
+			FieldPath:    path + "." + string(fd.Name()),
+			ExpectedType: fd.Kind().String(),
+			ActualType:   actual,
+			Description:  fmt.Sprintf("field %d received as %s, declared %s", num, actual, fd.Kind()),
+		})
+	})
+
+	for i := 0; i < fields.Len(); i++ {
+		fd := fields.Get(i)
+		if fd.Message() == nil || fd.IsMap() {
+			continue
+		}
+
+		fieldPath := path + "." + string(fd.Name())
+		if fd.IsList() {
+			// Read through the Go struct, since value slices cannot be reflected
+			for j, elem := range repeatedMessageElements(msg, fd) {
+				fieldErrors = append(fieldErrors, unknownFieldErrors(fmt.Sprintf("%s[%d]", fieldPath, j), elem)...)
+			}
+		} else if m.Has(fd) {
+			fieldErrors = append(fieldErrors, unknownFieldErrors(fieldPath, m.Get(fd).Message().Interface())...)
+		}
+	}
+
+	return fieldErrors
+}
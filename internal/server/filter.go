@@ -0,0 +1,80 @@
+package server
+
+import (
+	"context"
+
+	v1 "github.com/benjamin-rood/protogo-values-validation-demo/gen/api/validation/v1"
+)
+
+// TimestampRange selects data points by timestamp. Start is always inclusive;
+// End is inclusive unless EndExclusive is set, giving [Start, End) instead of
+// [Start, End].
+type TimestampRange struct {
+	Start        int64
+	End          int64
+	EndExclusive bool
+}
+
+// Contains reports whether timestamp falls within the range
+func (r TimestampRange) Contains(timestamp int64) bool {
+	if timestamp < r.Start {
+		return false
+	}
+	if r.EndExclusive {
+		return timestamp < r.End
+	}
+	return timestamp <= r.End
+}
+
+// FilterDataPoints returns the points whose timestamps fall within the
+// requested range, in input order, with the number of points included and
+// excluded. An empty input yields an empty result; a range whose start is
+// after its end is rejected.
+func (s *ValidationServer) FilterDataPoints(ctx context.Context, req *v1.FilterDataPointsRequest) (*v1.FilterDataPointsResponse, error) {
+	r := TimestampRange{Start: req.StartTimestamp, End: req.EndTimestamp, EndExclusive: req.EndExclusive}
+	if r.Start > r.End {
+		return nil, invalidArgument(ErrInvalidTimeRange, "start %d is after end %d", r.Start, r.End)
+	}
+
+	// The wire format only carries pointer slices, so the points are held in
+	// a value slice the way ValidationTestMessage.ValueSliceData holds them
+	values := valueSliceOf(req.Points)
+	indices := FilterByTimestamp(values, r)
+
+	// Responses point into the value slice's backing array; nothing is copied
+	included := make([]*v1.DataPoint, len(indices))
+	for i, index := range indices {
+		included[i] = &values[index]
+	}
+
+	return &v1.FilterDataPointsResponse{
+		Points:        included,
+		IncludedCount: int64(len(indices)),
+		ExcludedCount: int64(len(values) - len(indices)),
+	}, nil
+}
+
+// FilterByTimestamp returns the indices of the points within r, in order.
+// Points are read in place through their index, so no element is copied or
+// boxed.
+func FilterByTimestamp(points []v1.DataPoint, r TimestampRange) []int {
+	var indices []int
+	for i := range points {
+		if r.Contains(points[i].Timestamp) {
+			indices = append(indices, i)
+		}
+	}
+	return indices
+}
+
+// valueSliceOf copies points into one contiguous value slice
+func valueSliceOf(points []*v1.DataPoint) []v1.DataPoint {
+	values := make([]v1.DataPoint, len(points))
+	for i, point := range points {
+		values[i].Id = point.GetId()
+		values[i].Value = point.GetValue()
+		values[i].Timestamp = point.GetTimestamp()
+		values[i].Tags = point.GetTags()
+	}
+	return values
+}
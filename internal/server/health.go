@@ -0,0 +1,170 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"runtime"
+	"strings"
+	"time"
+
+	v1 "github.com/benjamin-rood/protogo-values-validation-demo/gen/api/validation/v1"
+	"github.com/benjamin-rood/protogo-values-validation-demo/internal/buildinfo"
+)
+
+// HealthStatus is the JSON body served by HealthHandler
+type HealthStatus struct {
+	Status         string  `json:"status"`
+	Timestamp      string  `json:"timestamp"`
+	Service        string  `json:"service"`
+	Version        string  `json:"version"`
+	UptimeSeconds  float64 `json:"uptime_seconds"`
+	Goroutines     int     `json:"goroutines"`
+	HeapInUseBytes uint64  `json:"heap_inuse_bytes"`
+	ActiveStreams  int64   `json:"active_streams"`
+}
+
+// StreamCounter is the subset of ValidationServer used by the health endpoint
+type StreamCounter interface {
+	ActiveStreams() int64
+}
+
+// HealthHandler reports liveness together with runtime resource usage, so
+// goroutine leaks from stuck streams are visible
+func HealthHandler(startTime time.Time, streams StreamCounter) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		var mem runtime.MemStats
+		runtime.ReadMemStats(&mem)
+
+		now := time.Now()
+		status := HealthStatus{
+			Status:         "healthy",
+			Timestamp:      now.UTC().Format(time.RFC3339),
+			Service:        "protogo-values-validation-demo",
+			Version:        buildinfo.Version,
+			UptimeSeconds:  now.Sub(startTime).Seconds(),
+			Goroutines:     runtime.NumGoroutine(),
+			HeapInUseBytes: mem.HeapInuse,
+			ActiveStreams:  streams.ActiveStreams(),
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusOK)
+		json.NewEncoder(w).Encode(status)
+	}
+}
+
+// TypesValidator is the subset of ValidationServer used by the readiness probe
+type TypesValidator interface {
+	ValidateTypes(ctx context.Context, req *v1.ValidateTypesRequest) (*v1.ValidateTypesResponse, error)
+}
+
+// ReadinessOption configures ReadinessHandler
+type ReadinessOption func(*readinessConfig)
+
+type readinessConfig struct {
+	failOnMarshalUnsafe bool
+}
+
+// WithFailOnMarshalUnsafe makes the probe report not ready when the startup
+// marshal self-check finds marshal-unsafe message types, for deployments run
+// as a cautionary example of the value-slice limitation
+func WithFailOnMarshalUnsafe(enabled bool) ReadinessOption {
+	return func(c *readinessConfig) {
+		c.failOnMarshalUnsafe = enabled
+	}
+}
+
+// ReadinessHandler reports ready once an in-process ValidateTypes call
+// succeeds with every result passing. Failed calls are retried up to attempts times, delay apart, so a
+// transient error does not take the instance out of rotation.
+func ReadinessHandler(validator TypesValidator, attempts int, delay time.Duration, opts ...ReadinessOption) http.HandlerFunc {
+	if attempts < 1 {
+		attempts = 1
+	}
+
+	var cfg readinessConfig
+	for _, opt := range opts {
+		opt(&cfg)
+	}
+
+	// Marshal safety is fixed for a given binary, so it is checked once
+	var unsafe []string
+	if cfg.failOnMarshalUnsafe {
+		unsafe = MarshalUnsafeTypes()
+	}
+
+	return func(w http.ResponseWriter, r *http.Request) {
+		if len(unsafe) > 0 {
+			w.Header().Set("Content-Type", "application/json")
+			w.WriteHeader(http.StatusServiceUnavailable)
+			json.NewEncoder(w).Encode(map[string]string{
+				"status": "not ready",
+				"error": fmt.Sprintf("marshaling panics for message types with value-slice fields: %s; use convert.MarshalSafeCopy before proto.Marshal",
+					strings.Join(unsafe, ", ")),
+			})
+			return
+		}
+
+		ctx, cancel := context.WithTimeout(r.Context(), 5*time.Second)
+		defer cancel()
+
+		// No scenarios: readiness checks every scenario
+		req := &v1.ValidateTypesRequest{
+			DeepValidation: false,
+		}
+
+		err := validateWithRetries(ctx, validator, req, attempts, delay)
+
+		w.Header().Set("Content-Type", "application/json")
+
+		if err != nil {
+			body := map[string]string{
+				"status": "not ready",
+				"error":  err.Error(),
+			}
+			var validationErr *ValidationError
+			if errors.As(err, &validationErr) {
+				body["failing_scenarios"] = strings.Join(validationErr.Scenarios(), ", ")
+			}
+			w.WriteHeader(http.StatusServiceUnavailable)
+			json.NewEncoder(w).Encode(body)
+			return
+		}
+
+		w.WriteHeader(http.StatusOK)
+		json.NewEncoder(w).Encode(map[string]string{
+			"status":    "ready",
+			"timestamp": time.Now().UTC().Format(time.RFC3339),
+			"service":   "protogo-values-validation-demo",
+		})
+	}
+}
+
+// validateWithRetries calls ValidateTypes until it succeeds, attempts are
+// exhausted or ctx is done, returning the last error. Failing results are
+// returned as a *ValidationError without retrying, since field types are
+// fixed for a given binary.
+func validateWithRetries(ctx context.Context, validator TypesValidator, req *v1.ValidateTypesRequest, attempts int, delay time.Duration) error {
+	var err error
+	for attempt := 1; attempt <= attempts; attempt++ {
+		var resp *v1.ValidateTypesResponse
+		if resp, err = validator.ValidateTypes(ctx, req); err == nil {
+			return NewValidationError(resp.Results)
+		}
+
+		var validationErr *ValidationError
+		if errors.As(err, &validationErr) || attempt == attempts {
+			return err
+		}
+
+		select {
+		case <-time.After(delay):
+		case <-ctx.Done():
+			return err
+		}
+	}
+	return err
+}
@@ -0,0 +1,159 @@
+package server
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	v1 "github.com/benjamin-rood/protogo-values-validation-demo/gen/api/validation/v1"
+	"github.com/benjamin-rood/protogo-values-validation-demo/internal/buildinfo"
+)
+
+// benchmarkSinkTimeout bounds a single report to the benchmark sink
+const benchmarkSinkTimeout = 5 * time.Second
+
+// influxMeasurement is the line-protocol measurement benchmark results are written to
+const influxMeasurement = "benchmark"
+
+// BenchmarkSink receives the results of every RunBenchmarks call, stamped
+// with the time the run finished. Reports run concurrently, so
+// implementations must be safe for concurrent use.
+type BenchmarkSink interface {
+	ReportBenchmarks(ctx context.Context, results []*v1.BenchmarkResult, at time.Time) error
+}
+
+// reportBenchmarks sends results of the run finished at at to the configured
+// sink in the background. Reporting is best-effort: failures are logged and
+// never reach the caller.
+func (s *ValidationServer) reportBenchmarks(results []*v1.BenchmarkResult, at time.Time) {
+	if s.benchmarkSink == nil {
+		return
+	}
+
+	go func() {
+		ctx, cancel := context.WithTimeout(context.Background(), benchmarkSinkTimeout)
+		defer cancel()
+
+		if err := s.benchmarkSink.ReportBenchmarks(ctx, results, at); err != nil {
+			slog.Warn("failed to report benchmarks", "error", err)
+		}
+	}()
+}
+
+// InfluxSink writes benchmark results as InfluxDB line protocol to an HTTP
+// write endpoint, e.g. http://influx:8086/api/v2/write?org=o&bucket=b
+type InfluxSink struct {
+	url    string
+	token  string
+	client *http.Client
+	tags   string
+}
+
+// InfluxSinkOption configures an InfluxSink
+type InfluxSinkOption func(*InfluxSink)
+
+// WithInfluxToken authenticates every write with an InfluxDB API token, sent
+// as an Authorization header rather than in the URL, where it would be logged
+func WithInfluxToken(token string) InfluxSinkOption {
+	return func(sink *InfluxSink) {
+		sink.token = token
+	}
+}
+
+// NewInfluxSink creates a sink posting to url. Every point is tagged with the
+// running build's version and commit.
+func NewInfluxSink(url string, opts ...InfluxSinkOption) *InfluxSink {
+	info := buildinfo.Get()
+	sink := &InfluxSink{
+		url:    url,
+		client: &http.Client{Timeout: benchmarkSinkTimeout},
+		tags:   ",version=" + escapeInfluxTag(info.Version) + ",commit=" + escapeInfluxTag(info.Commit),
+	}
+	for _, opt := range opts {
+		opt(sink)
+	}
+	return sink
+}
+
+// ReportBenchmarks posts one line per result
+func (sink *InfluxSink) ReportBenchmarks(ctx context.Context, results []*v1.BenchmarkResult, at time.Time) error {
+	if len(results) == 0 {
+		return nil
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, sink.url, strings.NewReader(sink.FormatLines(results, at)))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "text/plain; charset=utf-8")
+	if sink.token != "" {
+		req.Header.Set("Authorization", "Token "+sink.token)
+	}
+
+	resp, err := sink.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode/100 != 2 {
+		return fmt.Errorf("benchmark sink returned %s", resp.Status)
+	}
+	return nil
+}
+
+// FormatLines renders results in line protocol, one point per result with
+// nanosecond timestamps. Failed stages carry only their error message.
+func (sink *InfluxSink) FormatLines(results []*v1.BenchmarkResult, at time.Time) string {
+	var buf bytes.Buffer
+	for _, r := range results {
+		buf.WriteString(influxMeasurement)
+		buf.WriteString(",name=")
+		buf.WriteString(escapeInfluxTag(r.Name))
+		buf.WriteString(sink.tags)
+		buf.WriteByte(' ')
+
+		if r.Error != "" {
+			buf.WriteString(`error="`)
+			buf.WriteString(escapeInfluxString(r.Error))
+			buf.WriteByte('"')
+		} else {
+			buf.WriteString("duration_ns=")
+			buf.WriteString(strconv.FormatFloat(r.DurationNs, 'f', -1, 64))
+			buf.WriteString(",allocations=")
+			buf.WriteString(strconv.FormatInt(r.Allocations, 10))
+			buf.WriteString("i,bytes_allocated=")
+			buf.WriteString(strconv.FormatInt(r.BytesAllocated, 10))
+			buf.WriteString("i,operations_per_second=")
+			buf.WriteString(strconv.FormatFloat(r.OperationsPerSecond, 'f', -1, 64))
+		}
+
+		buf.WriteByte(' ')
+		buf.WriteString(strconv.FormatInt(at.UnixNano(), 10))
+		buf.WriteByte('\n')
+	}
+	return buf.String()
+}
+
+var influxTagEscaper = strings.NewReplacer(`,`, `\,`, `=`, `\=`, ` `, `\ `, "\n", `\n`)
+
+// escapeInfluxTag escapes a tag value, substituting "unknown" for an empty
+// value, which line protocol does not allow
+func escapeInfluxTag(s string) string {
+	if s == "" {
+		return "unknown"
+	}
+	return influxTagEscaper.Replace(s)
+}
+
+var influxStringEscaper = strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`)
+
+// escapeInfluxString escapes a string field value
+func escapeInfluxString(s string) string {
+	return influxStringEscaper.Replace(s)
+}
@@ -0,0 +1,144 @@
+package server
+
+import (
+	"context"
+	"log/slog"
+	"sync/atomic"
+	"time"
+
+	"github.com/google/uuid"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+// RequestIDHeader is the metadata key carrying the transport-level correlation ID
+const RequestIDHeader = "x-request-id"
+
+type requestIDKey struct{}
+
+// RequestIDFromContext returns the correlation ID attached by the request ID interceptors
+func RequestIDFromContext(ctx context.Context) string {
+	id, _ := ctx.Value(requestIDKey{}).(string)
+	return id
+}
+
+// UnaryRequestIDInterceptor attaches a request ID to the context, logs the call
+// and echoes the ID back in the response header and trailer
+func UnaryRequestIDInterceptor(opts ...InterceptorOption) grpc.UnaryServerInterceptor {
+	cfg := newInterceptorConfig(opts)
+	return func(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+		id := incomingRequestID(ctx)
+		ctx = context.WithValue(ctx, requestIDKey{}, id)
+
+		md := metadata.Pairs(RequestIDHeader, id)
+		grpc.SetHeader(ctx, md)
+		grpc.SetTrailer(ctx, md)
+
+		start := time.Now()
+		resp, err := handler(ctx, req)
+
+		attrs := []any{
+			"method", info.FullMethod,
+			"request_id", id,
+			"code", status.Code(err).String(),
+			"duration", time.Since(start),
+		}
+		if cfg.measureSizes(ctx) {
+			if size, ok := cfg.observeSize(info.FullMethod, "request", req); ok {
+				attrs = append(attrs, "request_bytes", size)
+			}
+			if err == nil {
+				if size, ok := cfg.observeSize(info.FullMethod, "response", resp); ok {
+					attrs = append(attrs, "response_bytes", size)
+				}
+			}
+		}
+		slog.Info("unary call completed", attrs...)
+
+		return resp, err
+	}
+}
+
+// StreamRequestIDInterceptor attaches a request ID to the stream context, logs the
+// call and echoes the ID back in the response header and trailer
+func StreamRequestIDInterceptor(opts ...InterceptorOption) grpc.StreamServerInterceptor {
+	cfg := newInterceptorConfig(opts)
+	return func(srv any, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		id := incomingRequestID(ss.Context())
+
+		md := metadata.Pairs(RequestIDHeader, id)
+		ss.SetHeader(md)
+		ss.SetTrailer(md)
+
+		stream := &requestIDStream{
+			ServerStream: ss,
+			ctx:          context.WithValue(ss.Context(), requestIDKey{}, id),
+		}
+		if cfg.measureSizes(ss.Context()) {
+			stream.sizes = cfg
+			stream.method = info.FullMethod
+		}
+
+		start := time.Now()
+		err := handler(srv, stream)
+
+		attrs := []any{
+			"method", info.FullMethod,
+			"request_id", id,
+			"code", status.Code(err).String(),
+			"duration", time.Since(start),
+		}
+		if stream.sizes != nil {
+			attrs = append(attrs, "request_bytes", stream.received.Load(), "response_bytes", stream.sent.Load())
+		}
+		slog.Info("stream call completed", attrs...)
+
+		return err
+	}
+}
+
+// requestIDStream overrides the stream context to carry the request ID and,
+// when sizes is set, totals the size of every message in each direction
+type requestIDStream struct {
+	grpc.ServerStream
+	ctx context.Context
+
+	sizes    *interceptorConfig
+	method   string
+	received atomic.Int64
+	sent     atomic.Int64
+}
+
+func (s *requestIDStream) Context() context.Context {
+	return s.ctx
+}
+
+func (s *requestIDStream) RecvMsg(m any) error {
+	err := s.ServerStream.RecvMsg(m)
+	if err == nil && s.sizes != nil {
+		if size, ok := s.sizes.observeSize(s.method, "request", m); ok {
+			s.received.Add(int64(size))
+		}
+	}
+	return err
+}
+
+func (s *requestIDStream) SendMsg(m any) error {
+	if s.sizes != nil {
+		if size, ok := s.sizes.observeSize(s.method, "response", m); ok {
+			s.sent.Add(int64(size))
+		}
+	}
+	return s.ServerStream.SendMsg(m)
+}
+
+// incomingRequestID reads the request ID from incoming metadata or generates one
+func incomingRequestID(ctx context.Context) string {
+	if md, ok := metadata.FromIncomingContext(ctx); ok {
+		if values := md.Get(RequestIDHeader); len(values) > 0 && values[0] != "" {
+			return values[0]
+		}
+	}
+	return uuid.NewString()
+}
@@ -0,0 +1,25 @@
+package server
+
+import (
+	"reflect"
+	"strings"
+
+	v1 "github.com/benjamin-rood/protogo-values-validation-demo/gen/api/validation/v1"
+)
+
+// setResultKinds sets the reflect.Kind of each result's field, and of its
+// elements for slices, so consumers can tell []DataPoint from []*DataPoint
+// without parsing type strings. Results that do not name a generated field
+// are left without kinds.
+func setResultKinds(results []*v1.ValidationResult) {
+	for _, result := range results {
+		t, ok := fieldGoType(strings.TrimPrefix(result.Scenario, UntransformedScenarioPrefix))
+		if !ok {
+			continue
+		}
+		result.Kind = t.Kind().String()
+		if t.Kind() == reflect.Slice {
+			result.ElementKind = t.Elem().Kind().String()
+		}
+	}
+}
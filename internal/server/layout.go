@@ -0,0 +1,81 @@
+package server
+
+import (
+	"context"
+	"reflect"
+
+	v1 "github.com/benjamin-rood/protogo-values-validation-demo/gen/api/validation/v1"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// layoutMessageTypes are reported when a GetMemoryLayout request names none
+var layoutMessageTypes = []string{
+	"ValidationTestMessage",
+	"PerformanceTestMessage",
+	"DataPoint",
+	"MetricPoint",
+	"Metadata",
+	"ProcessingResult",
+}
+
+// GetMemoryLayout reports how the generated Go structs are laid out in memory.
+// A value slice stores each element's full struct inline, one stride apart,
+// whereas a pointer slice stores 8-byte pointers to separately allocated
+// structs, so iterating it chases a pointer per element.
+func (s *ValidationServer) GetMemoryLayout(ctx context.Context, req *v1.GetMemoryLayoutRequest) (*v1.GetMemoryLayoutResponse, error) {
+	messageTypes := req.MessageTypes
+	if len(messageTypes) == 0 {
+		messageTypes = layoutMessageTypes
+	}
+
+	resp := &v1.GetMemoryLayoutResponse{}
+	for _, name := range messageTypes {
+		mt, err := findMessageType(name)
+		if err != nil {
+			return nil, status.Errorf(codes.NotFound, "unknown message type %q", name)
+		}
+
+		layout := StructLayout(reflect.TypeOf(mt.Zero().Interface()).Elem())
+		layout.MessageType = string(mt.Descriptor().FullName())
+		resp.Layouts = append(resp.Layouts, layout)
+	}
+
+	return resp, nil
+}
+
+// StructLayout describes the memory layout of struct type t. reflect reports
+// the same sizes, alignments and offsets as unsafe.Sizeof, unsafe.Alignof and
+// unsafe.Offsetof would for a value of t.
+func StructLayout(t reflect.Type) *v1.MessageLayout {
+	layout := &v1.MessageLayout{
+		GoType:     t.String(),
+		SizeBytes:  int64(t.Size()),
+		AlignBytes: int64(t.Align()),
+	}
+
+	var end int64
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		field := &v1.FieldLayout{
+			Name:               f.Name,
+			GoType:             f.Type.String(),
+			OffsetBytes:        int64(f.Offset),
+			SizeBytes:          int64(f.Type.Size()),
+			AlignBytes:         int64(f.Type.Align()),
+			PaddingBeforeBytes: int64(f.Offset) - end,
+		}
+		if f.Type.Kind() == reflect.Slice {
+			field.ElementSizeBytes = int64(f.Type.Elem().Size())
+		}
+
+		layout.TotalPaddingBytes += field.PaddingBeforeBytes
+		layout.Fields = append(layout.Fields, field)
+		end = field.OffsetBytes + field.SizeBytes
+	}
+
+	layout.TrailingPaddingBytes = layout.SizeBytes - end
+	layout.TotalPaddingBytes += layout.TrailingPaddingBytes
+
+	return layout
+}
@@ -0,0 +1,155 @@
+package server
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"strings"
+
+	v1 "github.com/benjamin-rood/protogo-values-validation-demo/gen/api/validation/v1"
+	"google.golang.org/protobuf/proto"
+)
+
+// marshalCheckCase is a populated instance of a validated message type
+type marshalCheckCase struct {
+	messageType string
+	message     func() proto.Message
+}
+
+// marshalCheckCases lists every validated message type with populated value
+// slices, since marshaling only panics once a value-slice field has elements
+var marshalCheckCases = []marshalCheckCase{
+	{
+		messageType: "validation.v1.ValidationTestMessage",
+		message: func() proto.Message {
+			return &v1.ValidationTestMessage{
+				ValueSliceData:   []v1.DataPoint{{Id: "value", Value: 1}},
+				PointerSliceData: []*v1.DataPoint{{Id: "pointer", Value: 2}},
+				Metrics:          []v1.MetricPoint{{Name: "metric", Measurement: 3}},
+			}
+		},
+	},
+	{
+		messageType: "validation.v1.PerformanceTestMessage",
+		message: func() proto.Message {
+			return &v1.PerformanceTestMessage{
+				ValueSliceData:   []v1.DataPoint{{Id: "value", Value: 1}},
+				PointerSliceData: []*v1.Metadata{{Key: "key", Value: "value"}},
+				Results:          []v1.ProcessingResult{{OperationId: "op", Success: true}},
+			}
+		},
+	},
+	{
+		messageType: "validation.v1.DataPoint",
+		message: func() proto.Message {
+			return &v1.DataPoint{Id: "dp", Value: 1, Timestamp: 1, Tags: []string{"tag"}}
+		},
+	},
+	{
+		messageType: "validation.v1.MetricPoint",
+		message: func() proto.Message {
+			return &v1.MetricPoint{Name: "metric", Measurement: 1, Labels: map[string]string{"env": "test"}}
+		},
+	},
+	{
+		messageType: "validation.v1.Metadata",
+		message: func() proto.Message {
+			return &v1.Metadata{Key: "key", Value: "value", Attributes: map[string]string{"source": "check"}}
+		},
+	},
+	{
+		messageType: "validation.v1.ProcessingResult",
+		message: func() proto.Message {
+			return &v1.ProcessingResult{OperationId: "op", Success: false, ErrorMessages: []string{"failed"}}
+		},
+	},
+}
+
+// CheckMarshalCompatibility reports, per validated message type, whether it can
+// be marshaled without panicking
+func (s *ValidationServer) CheckMarshalCompatibility(ctx context.Context, req *v1.CheckMarshalCompatibilityRequest) (*v1.CheckMarshalCompatibilityResponse, error) {
+	results := MarshalCompatibilityResults()
+
+	allSafe := true
+	for _, result := range results {
+		if !result.MarshalSafe {
+			allSafe = false
+			break
+		}
+	}
+
+	return &v1.CheckMarshalCompatibilityResponse{
+		AllSafe: allSafe,
+		Results: results,
+	}, nil
+}
+
+// MarshalCompatibilityResults attempts a recovered marshal of every validated
+// message type. It never panics.
+func MarshalCompatibilityResults() []*v1.MarshalCompatibilityResult {
+	results := make([]*v1.MarshalCompatibilityResult, 0, len(marshalCheckCases))
+
+	for _, tc := range marshalCheckCases {
+		result := &v1.MarshalCompatibilityResult{
+			MessageType: tc.messageType,
+		}
+
+		panicMessage, err := tryMarshal(tc.message())
+		result.PanicMessage = panicMessage
+		if err != nil {
+			result.ErrorMessage = err.Error()
+		}
+		result.MarshalSafe = panicMessage == "" && err == nil
+
+		results = append(results, result)
+	}
+
+	return results
+}
+
+// ErrMarshalUnsafe is returned by CheckMarshalHazards, in fatal mode, when a
+// validated message type cannot be marshaled
+var ErrMarshalUnsafe = errors.New("validated message types are marshal-unsafe")
+
+// MarshalUnsafeTypes lists the validated message types whose recovered marshal
+// panicked or failed
+func MarshalUnsafeTypes() []string {
+	var unsafe []string
+	for _, result := range MarshalCompatibilityResults() {
+		if !result.MarshalSafe {
+			unsafe = append(unsafe, result.MessageType)
+		}
+	}
+	return unsafe
+}
+
+// CheckMarshalHazards is the startup self-check surfacing the value-slice
+// marshaling limitation at boot. It logs a warning listing the marshal-unsafe
+// types and, when fatal, also returns them as an error wrapping
+// ErrMarshalUnsafe. It never panics.
+func CheckMarshalHazards(fatal bool) error {
+	unsafe := MarshalUnsafeTypes()
+	if len(unsafe) == 0 {
+		return nil
+	}
+
+	slog.Warn("marshaling these message types panics; use convert.MarshalSafeCopy before proto.Marshal",
+		"types", unsafe)
+	if fatal {
+		return fmt.Errorf("%w: %s", ErrMarshalUnsafe, strings.Join(unsafe, ", "))
+	}
+	return nil
+}
+
+// tryMarshal marshals msg, converting a panic into its message
+func tryMarshal(msg proto.Message) (panicMessage string, err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			panicMessage = fmt.Sprint(r)
+		}
+	}()
+
+	_, err = proto.Marshal(msg)
+	return "", err
+}
@@ -0,0 +1,128 @@
+package server
+
+import (
+	"context"
+	"math"
+	"slices"
+	"strconv"
+	"strings"
+
+	v1 "github.com/benjamin-rood/protogo-values-validation-demo/gen/api/validation/v1"
+)
+
+// MatchesLabelSelector reports whether labels contain every key/value pair in
+// selector. An empty selector matches everything; a nil label map never
+// matches a non-empty selector.
+func MatchesLabelSelector(labels, selector map[string]string) bool {
+	for key, want := range selector {
+		got, ok := labels[key]
+		if !ok || got != want {
+			return false
+		}
+	}
+	return true
+}
+
+// FilterMetricPoints returns pointers to the points whose labels match
+// selector. Pointers index into points, so the value slice is never copied.
+func FilterMetricPoints(points []v1.MetricPoint, selector map[string]string) []*v1.MetricPoint {
+	matched := make([]*v1.MetricPoint, 0)
+	for i := range points {
+		if MatchesLabelSelector(points[i].Labels, selector) {
+			matched = append(matched, &points[i])
+		}
+	}
+	return matched
+}
+
+// AggregateMetrics groups the request's points by label set after applying
+// its label selector, enforcing the server's cardinality limit
+func (s *ValidationServer) AggregateMetrics(ctx context.Context, req *v1.AggregateMetricsRequest) (*v1.AggregateMetricsResponse, error) {
+	points := make([]*v1.MetricPoint, 0, len(req.Points))
+	for _, point := range req.Points {
+		if MatchesLabelSelector(point.GetLabels(), req.LabelSelector) {
+			points = append(points, point)
+		}
+	}
+
+	groups, err := AggregateMetricPoints(points, s.maxMetricGroups, s.metricOverflowBucket)
+	if err != nil {
+		return nil, err
+	}
+
+	return &v1.AggregateMetricsResponse{
+		Groups:        groups,
+		FilteredCount: int64(len(req.Points) - len(points)),
+	}, nil
+}
+
+// AggregateMetricPoints groups points by label set, in order of first
+// appearance. At most maxGroups label sets are tracked (zero or less means
+// unlimited); past that it returns ErrTooManyMetricGroups, or, when
+// overflowBucket is set, folds the remaining label sets into one trailing
+// group marked Overflow.
+func AggregateMetricPoints(points []*v1.MetricPoint, maxGroups int, overflowBucket bool) ([]*v1.MetricGroup, error) {
+	var groups []*v1.MetricGroup
+	var overflow *v1.MetricGroup
+	index := make(map[string]*v1.MetricGroup)
+
+	for _, point := range points {
+		key := labelSetKey(point.Labels)
+		group, ok := index[key]
+
+		if !ok && maxGroups > 0 && len(groups) >= maxGroups {
+			if !overflowBucket {
+				return nil, resourceExhausted(ErrTooManyMetricGroups, "limit is %d", maxGroups)
+			}
+			if overflow == nil {
+				overflow = &v1.MetricGroup{Overflow: true}
+			}
+			addToGroup(overflow, point.Measurement)
+			continue
+		}
+
+		if !ok {
+			group = &v1.MetricGroup{Labels: point.Labels}
+			index[key] = group
+			groups = append(groups, group)
+		}
+		addToGroup(group, point.Measurement)
+	}
+
+	if overflow != nil {
+		groups = append(groups, overflow)
+	}
+
+	return groups, nil
+}
+
+// addToGroup folds one measurement into a group's running statistics
+func addToGroup(group *v1.MetricGroup, measurement float64) {
+	if group.Count == 0 {
+		group.Min, group.Max = measurement, measurement
+	}
+	group.Count++
+	group.Sum += measurement
+	group.Min = math.Min(group.Min, measurement)
+	group.Max = math.Max(group.Max, measurement)
+	group.Mean = group.Sum / float64(group.Count)
+}
+
+// labelSetKey canonicalizes labels so equal label sets share a key
+// regardless of map iteration order
+func labelSetKey(labels map[string]string) string {
+	keys := make([]string, 0, len(labels))
+	for key := range labels {
+		keys = append(keys, key)
+	}
+	slices.Sort(keys)
+
+	var b strings.Builder
+	for _, key := range keys {
+		b.WriteString(strconv.Quote(key))
+		b.WriteByte('=')
+		b.WriteString(strconv.Quote(labels[key]))
+		b.WriteByte(',')
+	}
+	return b.String()
+}
@@ -0,0 +1,145 @@
+package server
+
+import (
+	"time"
+
+	v1 "github.com/benjamin-rood/protogo-values-validation-demo/gen/api/validation/v1"
+)
+
+// DefaultMaxDataSize is the largest data_size accepted unless WithMaxDataSize
+// overrides it
+const DefaultMaxDataSize = 100000
+
+// DefaultMaxMetricGroups is the most distinct label sets AggregateMetrics
+// tracks unless WithMetricCardinality overrides it
+const DefaultMaxMetricGroups = 1000
+
+// DefaultStreamIdleTimeout is how long a stream may go without a message
+// unless WithStreamIdleTimeout overrides it
+const DefaultStreamIdleTimeout = 5 * time.Minute
+
+// DefaultMaxStreamMessages is the most messages one StreamValidation stream
+// may send unless WithMaxStreamMessages overrides it
+const DefaultMaxStreamMessages = 1000000
+
+// Option configures a ValidationServer
+type Option func(*ValidationServer)
+
+// MetricsRecorder receives measurements produced by the server. Concurrent
+// RPCs record concurrently, so implementations must be safe for concurrent use.
+type MetricsRecorder interface {
+	RecordBenchmark(result *v1.BenchmarkResult)
+}
+
+// noopMetrics discards all measurements
+type noopMetrics struct{}
+
+func (noopMetrics) RecordBenchmark(*v1.BenchmarkResult) {}
+
+// WithClock sets the clock used for timing measurements
+func WithClock(c Clock) Option {
+	return func(s *ValidationServer) {
+		s.clock = c
+	}
+}
+
+// WithMetrics sets the recorder that receives every benchmark result
+func WithMetrics(m MetricsRecorder) Option {
+	return func(s *ValidationServer) {
+		s.metrics = m
+	}
+}
+
+// WithMaxDataSize sets the largest data_size a request may ask for
+func WithMaxDataSize(n int32) Option {
+	return func(s *ValidationServer) {
+		s.maxDataSize = n
+	}
+}
+
+// WithCache toggles the ValidateTypes result cache
+func WithCache(enabled bool) Option {
+	return func(s *ValidationServer) {
+		s.cacheEnabled = enabled
+	}
+}
+
+// WithMaxStreams caps concurrent StreamValidation streams; streams past the
+// cap are rejected with ResourceExhausted. Zero or less removes the cap.
+func WithMaxStreams(n int64) Option {
+	return func(s *ValidationServer) {
+		s.maxStreams = n
+	}
+}
+
+// WithStreamIdleTimeout closes StreamValidation streams with DeadlineExceeded
+// when no message arrives within d. Zero or less disables the timeout.
+func WithStreamIdleTimeout(d time.Duration) Option {
+	return func(s *ValidationServer) {
+		s.streamIdleTimeout = d
+	}
+}
+
+// WithMaxStreamMessages caps the messages processed per StreamValidation
+// stream. Past the cap the stream ends with a final response marked
+// cap_exceeded and ResourceExhausted. Zero or less removes the cap.
+func WithMaxStreamMessages(n int64) Option {
+	return func(s *ValidationServer) {
+		s.maxStreamMessages = n
+	}
+}
+
+// WithMetricCardinality limits AggregateMetrics to maxGroups distinct label
+// sets. Past the limit requests fail with ResourceExhausted, or, when
+// overflowBucket is set, further label sets share a single overflow group.
+func WithMetricCardinality(maxGroups int, overflowBucket bool) Option {
+	return func(s *ValidationServer) {
+		s.maxMetricGroups = maxGroups
+		s.metricOverflowBucket = overflowBucket
+	}
+}
+
+// WithBenchmarkSink reports every RunBenchmarks result set to sink in the
+// background. Sink failures are logged and never fail the RPC.
+func WithBenchmarkSink(sink BenchmarkSink) Option {
+	return func(s *ValidationServer) {
+		s.benchmarkSink = sink
+	}
+}
+
+// WithTypeExpectations overrides the expected types ValidateTypes checks
+// generated fields against. Entries for fields outside the defaults are
+// validated as well.
+func WithTypeExpectations(expectations TypeExpectations) Option {
+	return func(s *ValidationServer) {
+		for key, expected := range expectations {
+			s.expectations[key] = expected
+		}
+	}
+}
+
+// WithCountConstraints sets the entry counts ValidateSingleMessage requires of
+// repeated fields. Fields without a constraint are not checked.
+func WithCountConstraints(constraints CountConstraints) Option {
+	return func(s *ValidationServer) {
+		s.countConstraints = constraints
+	}
+}
+
+// WithGOMAXPROCSPinning lets RunBenchmarks requests pin GOMAXPROCS. It is off
+// by default: the setting is process-wide, so a pinned run slows every other
+// RPC for its duration.
+func WithGOMAXPROCSPinning(enabled bool) Option {
+	return func(s *ValidationServer) {
+		s.allowPinGOMAXPROCS = enabled
+	}
+}
+
+// WithScenarioFunc adds a scenario ValidateTypes runs after the built-in
+// ones, with name in its context. Its results are cached with the rest, so
+// fn must be deterministic for the lifetime of the process.
+func WithScenarioFunc(name string, fn ScenarioFunc) Option {
+	return func(s *ValidationServer) {
+		s.extraScenarios = append(s.extraScenarios, validationScenario{name, fn})
+	}
+}
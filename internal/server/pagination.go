@@ -0,0 +1,74 @@
+package server
+
+import (
+	"encoding/base64"
+	"fmt"
+	"hash/fnv"
+	"strconv"
+	"strings"
+
+	v1 "github.com/benjamin-rood/protogo-values-validation-demo/gen/api/validation/v1"
+)
+
+// paginateResults trims resp to the page of pageSize results starting at
+// offset. Results are produced in a fixed order, so pages of the same request
+// never overlap or skip. A pageSize of zero returns every remaining result.
+func paginateResults(resp *v1.ValidateTypesResponse, key string, offset, pageSize int) *v1.ValidateTypesResponse {
+	total := len(resp.Results)
+	resp.TotalResults = int32(total)
+
+	offset = min(offset, total)
+	end := total
+	if pageSize > 0 {
+		end = min(offset+pageSize, total)
+	}
+
+	resp.Results = resp.Results[offset:end]
+	if end < total {
+		resp.NextPageToken = encodePageToken(key, end)
+	}
+
+	return resp
+}
+
+// encodePageToken makes an opaque token for the page starting at offset. It
+// embeds a fingerprint of the request so tokens cannot be replayed against a
+// different scenario set.
+func encodePageToken(key string, offset int) string {
+	return base64.RawURLEncoding.EncodeToString([]byte(fmt.Sprintf("%d:%x", offset, requestFingerprint(key))))
+}
+
+// decodePageToken returns the offset a token points at, or 0 for an empty token
+func decodePageToken(token, key string) (int, error) {
+	if token == "" {
+		return 0, nil
+	}
+
+	raw, err := base64.RawURLEncoding.DecodeString(token)
+	if err != nil {
+		return 0, invalidArgument(ErrInvalidPageToken, "malformed")
+	}
+
+	offsetPart, fingerprintPart, ok := strings.Cut(string(raw), ":")
+	if !ok {
+		return 0, invalidArgument(ErrInvalidPageToken, "malformed")
+	}
+
+	offset, err := strconv.Atoi(offsetPart)
+	if err != nil || offset < 0 {
+		return 0, invalidArgument(ErrInvalidPageToken, "malformed")
+	}
+
+	if fingerprintPart != fmt.Sprintf("%x", requestFingerprint(key)) {
+		return 0, invalidArgument(ErrInvalidPageToken, "issued for a different request")
+	}
+
+	return offset, nil
+}
+
+// requestFingerprint hashes a validateTypesCacheKey
+func requestFingerprint(key string) uint64 {
+	h := fnv.New64a()
+	h.Write([]byte(key))
+	return h.Sum64()
+}
@@ -0,0 +1,171 @@
+package server
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/benjamin-rood/protogo-values-validation-demo/internal/convert"
+	"google.golang.org/protobuf/proto"
+)
+
+// payloadSizeBuckets are the histogram upper bounds in bytes, 64B to 16MiB
+var payloadSizeBuckets = []float64{64, 256, 1 << 10, 4 << 10, 16 << 10, 64 << 10, 256 << 10, 1 << 20, 4 << 20, 16 << 20}
+
+// payloadSizeMetric is the Prometheus histogram the sizes are exposed as
+const payloadSizeMetric = "grpc_server_payload_size_bytes"
+
+// PayloadSizeMetrics is a Prometheus histogram of gRPC message sizes, labelled
+// by method and direction ("request" or "response"). It is safe for
+// concurrent use.
+type PayloadSizeMetrics struct {
+	seriesMu sync.Mutex
+	series   map[payloadSeries]*sizeHistogram
+}
+
+type payloadSeries struct {
+	method, direction string
+}
+
+// sizeHistogram holds per-bucket counts, not yet cumulative
+type sizeHistogram struct {
+	buckets []uint64
+	count   uint64
+	sum     float64
+}
+
+// NewPayloadSizeMetrics creates an empty payload size histogram
+func NewPayloadSizeMetrics() *PayloadSizeMetrics {
+	return &PayloadSizeMetrics{series: make(map[payloadSeries]*sizeHistogram)}
+}
+
+// Observe records one message of size bytes
+func (m *PayloadSizeMetrics) Observe(method, direction string, size int) {
+	m.seriesMu.Lock()
+	defer m.seriesMu.Unlock()
+
+	key := payloadSeries{method: method, direction: direction}
+	h, ok := m.series[key]
+	if !ok {
+		h = &sizeHistogram{buckets: make([]uint64, len(payloadSizeBuckets))}
+		m.series[key] = h
+	}
+
+	// Sizes past the last bound only count towards +Inf
+	if i, _ := slices.BinarySearch(payloadSizeBuckets, float64(size)); i < len(h.buckets) {
+		h.buckets[i]++
+	}
+	h.count++
+	h.sum += float64(size)
+}
+
+// WritePrometheus writes the histogram in the Prometheus text exposition format
+func (m *PayloadSizeMetrics) WritePrometheus(w io.Writer) error {
+	m.seriesMu.Lock()
+	defer m.seriesMu.Unlock()
+
+	keys := make([]payloadSeries, 0, len(m.series))
+	for key := range m.series {
+		keys = append(keys, key)
+	}
+	slices.SortFunc(keys, func(a, b payloadSeries) int {
+		return strings.Compare(a.method+"\x00"+a.direction, b.method+"\x00"+b.direction)
+	})
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "# HELP %s Size of gRPC messages in bytes.\n", payloadSizeMetric)
+	fmt.Fprintf(&b, "# TYPE %s histogram\n", payloadSizeMetric)
+	for _, key := range keys {
+		h := m.series[key]
+		labels := fmt.Sprintf("method=%q,direction=%q", key.method, key.direction)
+
+		var cumulative uint64
+		for i, bound := range payloadSizeBuckets {
+			cumulative += h.buckets[i]
+			fmt.Fprintf(&b, "%s_bucket{%s,le=%q} %d\n", payloadSizeMetric, labels, strconv.FormatFloat(bound, 'f', -1, 64), cumulative)
+		}
+		fmt.Fprintf(&b, "%s_bucket{%s,le=\"+Inf\"} %d\n", payloadSizeMetric, labels, h.count)
+		fmt.Fprintf(&b, "%s_sum{%s} %s\n", payloadSizeMetric, labels, strconv.FormatFloat(h.sum, 'f', -1, 64))
+		fmt.Fprintf(&b, "%s_count{%s} %d\n", payloadSizeMetric, labels, h.count)
+	}
+
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+// Handler serves the histogram for Prometheus to scrape
+func (m *PayloadSizeMetrics) Handler() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+		w.WriteHeader(http.StatusOK)
+		m.WritePrometheus(w)
+	}
+}
+
+// MessageSize returns the wire size of msg. proto.Size cannot reflect over
+// populated value slices, so such messages are sized on a pointer-backed copy.
+// Values that are not protobuf messages have no size.
+func MessageSize(v any) (size int, ok bool) {
+	msg, ok := v.(proto.Message)
+	if !ok || msg == nil {
+		return 0, false
+	}
+
+	defer func() {
+		if r := recover(); r != nil {
+			safe, err := convert.MarshalSafeCopy(msg)
+			if err != nil {
+				size, ok = 0, false
+				return
+			}
+			size, ok = proto.Size(safe), true
+		}
+	}()
+	return proto.Size(msg), true
+}
+
+// InterceptorOption configures the request ID interceptors
+type InterceptorOption func(*interceptorConfig)
+
+type interceptorConfig struct {
+	payloadSizes *PayloadSizeMetrics
+	sampleSizes  bool
+}
+
+// WithPayloadSizes logs request and response sizes and records them in
+// metrics. Sizing walks every message, so it only happens on the hot path when
+// sample is set; otherwise it happens only while debug logging is enabled.
+func WithPayloadSizes(metrics *PayloadSizeMetrics, sample bool) InterceptorOption {
+	return func(c *interceptorConfig) {
+		c.payloadSizes = metrics
+		c.sampleSizes = sample
+	}
+}
+
+func newInterceptorConfig(opts []InterceptorOption) *interceptorConfig {
+	c := &interceptorConfig{}
+	for _, opt := range opts {
+		opt(c)
+	}
+	return c
+}
+
+// measureSizes reports whether this call's payloads should be sized
+func (c *interceptorConfig) measureSizes(ctx context.Context) bool {
+	return c.sampleSizes || slog.Default().Enabled(ctx, slog.LevelDebug)
+}
+
+// observeSize records one message's size, returning it for logging
+func (c *interceptorConfig) observeSize(method, direction string, msg any) (int, bool) {
+	size, ok := MessageSize(msg)
+	if ok && c.payloadSizes != nil {
+		c.payloadSizes.Observe(method, direction, size)
+	}
+	return size, ok
+}
@@ -0,0 +1,42 @@
+package server
+
+import (
+	"context"
+	"runtime"
+
+	v1 "github.com/benjamin-rood/protogo-values-validation-demo/gen/api/validation/v1"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// pinGOMAXPROCS sets GOMAXPROCS to n and returns a func restoring the previous
+// value, which callers defer so it runs on panics and early returns too.
+// GOMAXPROCS is process-wide, so pinning must be enabled with
+// WithGOMAXPROCSPinning and pinned runs are serialized; a run waiting for
+// another gives up when ctx is done. Unpinned calls running alongside still
+// see the pinned value.
+func (s *ValidationServer) pinGOMAXPROCS(ctx context.Context, n int) (restore func(), err error) {
+	if !s.allowPinGOMAXPROCS {
+		return nil, status.Errorf(codes.FailedPrecondition, "pinning GOMAXPROCS is disabled on this server")
+	}
+
+	select {
+	case s.procs <- struct{}{}:
+	case <-ctx.Done():
+		return nil, status.FromContextError(ctx.Err()).Err()
+	}
+
+	previous := runtime.GOMAXPROCS(n)
+	return func() {
+		runtime.GOMAXPROCS(previous)
+		<-s.procs
+	}, nil
+}
+
+// pinnedProcs is the GOMAXPROCS a pinning request asks for
+func pinnedProcs(req *v1.BenchmarkRequest) int {
+	if n := req.Gomaxprocs; n > 0 {
+		return int(n)
+	}
+	return 1
+}
@@ -0,0 +1,36 @@
+package server
+
+import (
+	"math"
+	"strconv"
+	"time"
+)
+
+// ratePerSecond returns count per second over d. Durations too short to
+// measure yield 0 rather than +Inf or NaN.
+func ratePerSecond(count float64, d time.Duration) float64 {
+	if d <= 0 {
+		return 0
+	}
+	return count / d.Seconds()
+}
+
+// rateSuffixes scale a rate by powers of 1000
+var rateSuffixes = []string{"", "K", "M", "G", "T"}
+
+// FormatRate renders a per-second rate with a metric suffix and one decimal
+// place, e.g. FormatRate(12345, "ops") is "12.3K ops/s". Non-finite rates
+// are rendered as 0.
+func FormatRate(rate float64, unit string) string {
+	if math.IsInf(rate, 0) || math.IsNaN(rate) {
+		rate = 0
+	}
+
+	i := 0
+	for math.Abs(rate) >= 1000 && i < len(rateSuffixes)-1 {
+		rate /= 1000
+		i++
+	}
+
+	return strconv.FormatFloat(rate, 'f', 1, 64) + rateSuffixes[i] + " " + unit + "/s"
+}
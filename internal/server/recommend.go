@@ -0,0 +1,297 @@
+package server
+
+import (
+	"context"
+	"fmt"
+	"runtime"
+	"strings"
+	"time"
+
+	v1 "github.com/benjamin-rood/protogo-values-validation-demo/gen/api/validation/v1"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
+)
+
+// defaultRecommendIterations repeats each benchmark when a request sets none
+const defaultRecommendIterations = 100
+
+// MaxRecommendIterations is the most repetitions a recommendation request may
+// ask for. Each repetition touches every element, so the work is bounded
+// together with the data size cap.
+const MaxRecommendIterations = 10000
+
+// recommendTolerance is the relative difference below which two measurements
+// are considered equal
+const recommendTolerance = 0.05
+
+// Representation names used in recommendations
+const (
+	representationValue   = "value"
+	representationPointer = "pointer"
+	representationTie     = "tie"
+)
+
+// RecommendRepresentation benchmarks iteration, allocation and serialization
+// for value and pointer slices of req.DataSize elements and recommends the
+// representation that performs better
+func (s *ValidationServer) RecommendRepresentation(ctx context.Context, req *v1.RecommendRepresentationRequest) (*v1.RecommendRepresentationResponse, error) {
+	if req.DataSize <= 0 {
+		return nil, invalidArgument(ErrInvalidDataSize, "got %d", req.DataSize)
+	}
+	if req.DataSize > s.maxDataSize {
+		return nil, invalidArgument(ErrDataSizeTooLarge, "got %d, max %d", req.DataSize, s.maxDataSize)
+	}
+	if req.Iterations < 0 {
+		return nil, invalidArgument(ErrInvalidIterations, "got %d", req.Iterations)
+	}
+	if req.Iterations > MaxRecommendIterations {
+		return nil, invalidArgument(ErrTooManyIterations, "got %d", req.Iterations)
+	}
+
+	iterations := int(req.Iterations)
+	if iterations == 0 {
+		iterations = defaultRecommendIterations
+	}
+
+	data, err := generateBenchmarkData(ctx, int(req.DataSize), payloadShape{})
+	if err != nil {
+		if _, ok := status.FromError(err); !ok {
+			err = status.Errorf(codes.Internal, "%v", err)
+		}
+		return nil, err
+	}
+
+	// sink keeps each run's result reachable so the work is not elided
+	var sink any
+	workloads := []struct {
+		category       string
+		value, pointer func()
+	}{
+		{"iteration",
+			func() { sink = sumValues(data.values) },
+			func() { sink = sumPointers(data.pointers) }},
+		{"allocation",
+			func() { sink = buildValueSlice(data.values) },
+			func() { sink = buildPointerSlice(data.values) }},
+		{"serialization",
+			func() { sink = marshalValueSlice(data.values) },
+			func() { sink, _ = proto.Marshal(&v1.ValidationTestMessage{PointerSliceData: data.pointers}) }},
+	}
+
+	deltas := make([]*v1.RepresentationDelta, 0, len(workloads))
+	for _, w := range workloads {
+		delta, err := s.compareRepresentations(ctx, w.category, iterations, w.value, w.pointer)
+		if err != nil {
+			return nil, err
+		}
+		deltas = append(deltas, delta)
+	}
+	runtime.KeepAlive(sink)
+
+	recommendation, confidence, reasoning := ChooseRepresentation(deltas)
+	return &v1.RecommendRepresentationResponse{
+		Recommendation: recommendation,
+		Confidence:     confidence,
+		Reasoning:      reasoning,
+		Deltas:         deltas,
+	}, nil
+}
+
+// compareRepresentations times iterations runs of each representation's
+// workload and counts the heap allocations they make
+func (s *ValidationServer) compareRepresentations(ctx context.Context, category string, iterations int, value, pointer func()) (*v1.RepresentationDelta, error) {
+	valueDuration, valueAllocs, err := s.measure(ctx, iterations, value)
+	if err != nil {
+		return nil, err
+	}
+	pointerDuration, pointerAllocs, err := s.measure(ctx, iterations, pointer)
+	if err != nil {
+		return nil, err
+	}
+
+	delta := &v1.RepresentationDelta{
+		Category:           category,
+		ValueDurationNs:    float64(valueDuration.Nanoseconds()),
+		PointerDurationNs:  float64(pointerDuration.Nanoseconds()),
+		ValueAllocations:   valueAllocs,
+		PointerAllocations: pointerAllocs,
+	}
+	if delta.ValueDurationNs > 0 {
+		delta.PointerToValueRatio = delta.PointerDurationNs / delta.ValueDurationNs
+	}
+	delta.Winner = deltaWinner(delta)
+	return delta, nil
+}
+
+// measure runs fn iterations times, returning the elapsed time and the heap
+// allocations made per run. It stops between runs once ctx is done.
+func (s *ValidationServer) measure(ctx context.Context, iterations int, fn func()) (time.Duration, int64, error) {
+	var before, after runtime.MemStats
+	runtime.ReadMemStats(&before)
+
+	start := s.clock.Now()
+	for i := 0; i < iterations; i++ {
+		if stageCanceled(ctx.Done()) {
+			return 0, 0, status.FromContextError(ctx.Err()).Err()
+		}
+		fn()
+	}
+	duration := s.clock.Since(start)
+
+	runtime.ReadMemStats(&after)
+	return duration, int64(after.Mallocs-before.Mallocs) / int64(iterations), nil
+}
+
+// deltaWinner picks the faster representation, falling back to the one that
+// allocates less when the durations are within recommendTolerance
+func deltaWinner(delta *v1.RepresentationDelta) string {
+	if winner := lower(delta.ValueDurationNs, delta.PointerDurationNs); winner != representationTie {
+		return winner
+	}
+	return lower(float64(delta.ValueAllocations), float64(delta.PointerAllocations))
+}
+
+// lower names the representation with the clearly lower measurement
+func lower(value, pointer float64) string {
+	switch {
+	case value < pointer && !withinTolerance(value, pointer):
+		return representationValue
+	case pointer < value && !withinTolerance(value, pointer):
+		return representationPointer
+	default:
+		return representationTie
+	}
+}
+
+func withinTolerance(a, b float64) bool {
+	return max(a, b)-min(a, b) <= recommendTolerance*max(a, b)
+}
+
+// ChooseRepresentation recommends "value" or "pointer" from per-category
+// deltas. The representation winning more categories is recommended. A tie
+// goes to the iteration winner, since iteration is the workload value slices
+// target, then to the representation making fewer allocations overall, then to
+// value slices. The result depends only on the deltas.
+func ChooseRepresentation(deltas []*v1.RepresentationDelta) (recommendation, confidence, reasoning string) {
+	var valueWins, pointerWins int
+	var valueAllocs, pointerAllocs int64
+	var iterationWinner string
+	reasons := make([]string, 0, len(deltas)+1)
+
+	for _, delta := range deltas {
+		switch delta.Winner {
+		case representationValue:
+			valueWins++
+		case representationPointer:
+			pointerWins++
+		}
+		if delta.Category == "iteration" {
+			iterationWinner = delta.Winner
+		}
+		valueAllocs += delta.ValueAllocations
+		pointerAllocs += delta.PointerAllocations
+		reasons = append(reasons, describeDelta(delta))
+	}
+
+	decided := ""
+	switch {
+	case valueWins != pointerWins:
+		recommendation = representationValue
+		if pointerWins > valueWins {
+			recommendation = representationPointer
+		}
+		decided = fmt.Sprintf("%s slices win %d of %d categories", recommendation, max(valueWins, pointerWins), len(deltas))
+	case iterationWinner == representationValue || iterationWinner == representationPointer:
+		recommendation = iterationWinner
+		decided = fmt.Sprintf("categories split %d-%d, so the iteration winner decides", valueWins, pointerWins)
+	case lower(float64(valueAllocs), float64(pointerAllocs)) == representationPointer:
+		recommendation = representationPointer
+		decided = fmt.Sprintf("categories split %d-%d, so fewer total allocations decide", valueWins, pointerWins)
+	default:
+		recommendation = representationValue
+		decided = fmt.Sprintf("categories split %d-%d with no clear difference, defaulting to value slices", valueWins, pointerWins)
+	}
+
+	losses := pointerWins
+	if recommendation == representationPointer {
+		losses = valueWins
+	}
+	switch {
+	case valueWins == pointerWins:
+		confidence = "low"
+	case losses == 0 && valueWins+pointerWins == len(deltas):
+		confidence = "high"
+	default:
+		confidence = "medium"
+	}
+
+	reasons = append(reasons, decided)
+	return recommendation, confidence, strings.Join(reasons, "; ")
+}
+
+// describeDelta summarizes one category for the reasoning
+func describeDelta(delta *v1.RepresentationDelta) string {
+	timing := fmt.Sprintf("value %.0fns vs pointer %.0fns, %d vs %d allocs",
+		delta.ValueDurationNs, delta.PointerDurationNs, delta.ValueAllocations, delta.PointerAllocations)
+
+	switch delta.Winner {
+	case representationTie:
+		return fmt.Sprintf("%s: no clear winner (%s)", delta.Category, timing)
+	case representationValue:
+		return fmt.Sprintf("%s: value slices %.2fx faster (%s)", delta.Category, delta.PointerToValueRatio, timing)
+	default:
+		ratio := 0.0
+		if delta.PointerToValueRatio > 0 {
+			ratio = 1 / delta.PointerToValueRatio
+		}
+		return fmt.Sprintf("%s: pointer slices %.2fx faster (%s)", delta.Category, ratio, timing)
+	}
+}
+
+func sumValues(data []v1.DataPoint) float64 {
+	var sum float64
+	for i := range data {
+		sum += data[i].Value
+	}
+	return sum
+}
+
+func sumPointers(data []*v1.DataPoint) float64 {
+	var sum float64
+	for _, dp := range data {
+		sum += dp.Value
+	}
+	return sum
+}
+
+// buildValueSlice allocates the backing array once and fills it in place
+func buildValueSlice(src []v1.DataPoint) []v1.DataPoint {
+	data := make([]v1.DataPoint, len(src))
+	for i := range src {
+		data[i].Id = src[i].Id
+		data[i].Value = src[i].Value
+		data[i].Timestamp = src[i].Timestamp
+	}
+	return data
+}
+
+// buildPointerSlice allocates every element separately
+func buildPointerSlice(src []v1.DataPoint) []*v1.DataPoint {
+	data := make([]*v1.DataPoint, len(src))
+	for i := range src {
+		data[i] = &v1.DataPoint{Id: src[i].Id, Value: src[i].Value, Timestamp: src[i].Timestamp}
+	}
+	return data
+}
+
+// marshalValueSlice marshals a value slice through a view of pointers into its
+// backing array, which protobuf can encode without copying the elements
+func marshalValueSlice(data []v1.DataPoint) []byte {
+	view := make([]*v1.DataPoint, len(data))
+	for i := range data {
+		view[i] = &data[i]
+	}
+	encoded, _ := proto.Marshal(&v1.ValidationTestMessage{PointerSliceData: view})
+	return encoded
+}
@@ -0,0 +1,12 @@
+package server
+
+import "google.golang.org/grpc/reflection"
+
+// RegisterReflection registers the gRPC reflection service on s when enabled.
+// Reflection exposes the full service schema to anyone who can reach the
+// port, so it is meant for local development with tools like grpcurl.
+func RegisterReflection(s reflection.GRPCServer, enabled bool) {
+	if enabled {
+		reflection.Register(s)
+	}
+}
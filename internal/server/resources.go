@@ -0,0 +1,64 @@
+package server
+
+import (
+	"runtime"
+	"time"
+
+	v1 "github.com/benjamin-rood/protogo-values-validation-demo/gen/api/validation/v1"
+	"google.golang.org/grpc/status"
+)
+
+// DefaultWatchInterval is the time between WatchResources snapshots when the
+// request leaves interval_ms unset
+const DefaultWatchInterval = time.Second
+
+// MinWatchInterval bounds how often WatchResources reads memory statistics,
+// which briefly stops the world
+const MinWatchInterval = 10 * time.Millisecond
+
+// WatchResources sends a snapshot of server resource usage immediately and
+// then once per interval, until the client cancels. The ticker is stopped on
+// return, so no goroutine outlives the stream.
+func (s *ValidationServer) WatchResources(req *v1.WatchResourcesRequest, stream v1.ValidationService_WatchResourcesServer) error {
+	// Validation interceptors only see unary calls
+	if err := ValidateRequest(req); err != nil {
+		return err
+	}
+
+	interval := DefaultWatchInterval
+	if req.IntervalMs > 0 {
+		interval = time.Duration(req.IntervalMs) * time.Millisecond
+	}
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	ctx := stream.Context()
+	for {
+		if err := stream.Send(s.resourceSnapshot()); err != nil {
+			return err
+		}
+
+		select {
+		case <-ctx.Done():
+			return status.FromContextError(ctx.Err()).Err()
+		case <-ticker.C:
+		}
+	}
+}
+
+// resourceSnapshot reads the current resource usage
+func (s *ValidationServer) resourceSnapshot() *v1.ResourceSnapshot {
+	var mem runtime.MemStats
+	runtime.ReadMemStats(&mem)
+
+	return &v1.ResourceSnapshot{
+		TimestampUnixNano: s.clock.Now().UnixNano(),
+		Goroutines:        int32(runtime.NumGoroutine()),
+		HeapAllocBytes:    mem.HeapAlloc,
+		HeapInuseBytes:    mem.HeapInuse,
+		ActiveStreams:     s.streams.Active(),
+		NumGc:             mem.NumGC,
+		GcPauseTotalNs:    mem.PauseTotalNs,
+		LastGcUnixNano:    mem.LastGC,
+	}
+}
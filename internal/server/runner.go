@@ -0,0 +1,84 @@
+package server
+
+import (
+	"context"
+	"strings"
+
+	v1 "github.com/benjamin-rood/protogo-values-validation-demo/gen/api/validation/v1"
+	"golang.org/x/sync/errgroup"
+	"google.golang.org/grpc/status"
+)
+
+// benchmarkStage is one named RunBenchmarks stage. run must return promptly
+// once ctx is done.
+type benchmarkStage struct {
+	name string
+	run  func(ctx context.Context) *v1.BenchmarkResult
+}
+
+// runBenchmarkStages runs stages one at a time, or all at once when parallel
+// is set, returning their results in stage order. A stage that panics yields a
+// result carrying the error and the others still run. The workers share a
+// context derived from ctx, so the RPC ending stops all of them, and every
+// worker has returned before runBenchmarkStages does: none outlives the RPC.
+func runBenchmarkStages(ctx context.Context, stages []benchmarkStage, parallel bool) ([]*v1.BenchmarkResult, error) {
+	g, gctx := errgroup.WithContext(ctx)
+	if !parallel {
+		g.SetLimit(1)
+	}
+
+	results := make([]*v1.BenchmarkResult, len(stages))
+	for i, stage := range stages {
+		g.Go(func() error {
+			if err := gctx.Err(); err != nil {
+				return err
+			}
+			results[i] = RunBenchmarkStage(stage.name, func() *v1.BenchmarkResult {
+				return stage.run(gctx)
+			})
+			// A stage cut short by cancellation has no meaningful result
+			return gctx.Err()
+		})
+	}
+
+	if err := g.Wait(); err != nil {
+		return nil, status.FromContextError(err).Err()
+	}
+	return results, nil
+}
+
+// stageCanceled reports, without blocking, whether done is closed. It is
+// cheap enough to call between benchmark iterations.
+func stageCanceled(done <-chan struct{}) bool {
+	select {
+	case <-done:
+		return true
+	default:
+		return false
+	}
+}
+
+// selectStages keeps the stages names selects, or all of them when names is
+// empty. A name selects every stage whose name starts with it, ignoring case
+// and underscores, so "value_slice" selects ValueSlice_Iteration and
+// "serialization" both serialization stages.
+func selectStages(stages []benchmarkStage, names []string) []benchmarkStage {
+	if len(names) == 0 {
+		return stages
+	}
+
+	normalize := func(name string) string {
+		return strings.ToLower(strings.ReplaceAll(name, "_", ""))
+	}
+
+	var selected []benchmarkStage
+	for _, stage := range stages {
+		for _, name := range names {
+			if prefix := normalize(name); prefix != "" && strings.HasPrefix(normalize(stage.name), prefix) {
+				selected = append(selected, stage)
+				break
+			}
+		}
+	}
+	return selected
+}
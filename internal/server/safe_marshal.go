@@ -0,0 +1,68 @@
+package server
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	v1 "github.com/benjamin-rood/protogo-values-validation-demo/gen/api/validation/v1"
+	"github.com/benjamin-rood/protogo-values-validation-demo/internal/convert"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/encoding/prototext"
+	"google.golang.org/protobuf/proto"
+)
+
+// SafeMarshal encodes msg in format from its marshal-safe copy, so messages
+// with populated value-slice fields encode instead of panicking.
+// MARSHAL_FORMAT_UNSPECIFIED encodes as binary.
+func SafeMarshal(msg proto.Message, format v1.MarshalFormat) ([]byte, error) {
+	safe, err := convert.MarshalSafeCopy(msg)
+	if err != nil {
+		return nil, err
+	}
+
+	switch format {
+	case v1.MarshalFormat_MARSHAL_FORMAT_UNSPECIFIED, v1.MarshalFormat_MARSHAL_FORMAT_BINARY:
+		return proto.Marshal(safe)
+	case v1.MarshalFormat_MARSHAL_FORMAT_JSON:
+		return protojson.Marshal(safe)
+	case v1.MarshalFormat_MARSHAL_FORMAT_TEXT:
+		return prototext.Marshal(safe)
+	default:
+		return nil, fmt.Errorf("unknown marshal format %v", format)
+	}
+}
+
+// SafeMarshal returns the example of the requested message type encoded in
+// the requested format
+func (s *ValidationServer) SafeMarshal(ctx context.Context, req *v1.SafeMarshalRequest) (*v1.SafeMarshalResponse, error) {
+	if req.MessageType == "" {
+		return nil, status.Errorf(codes.InvalidArgument, "message_type is required")
+	}
+	format := req.Format
+	if format == v1.MarshalFormat_MARSHAL_FORMAT_UNSPECIFIED {
+		format = v1.MarshalFormat_MARSHAL_FORMAT_BINARY
+	}
+	if _, ok := v1.MarshalFormat_name[int32(format)]; !ok {
+		return nil, status.Errorf(codes.InvalidArgument, "unknown format %d", format)
+	}
+
+	msg, ok := ExampleMessage(req.MessageType)
+	if !ok {
+		return nil, status.Errorf(codes.NotFound, "no example for %q, available: %s",
+			req.MessageType, strings.Join(ExampleTypes(), ", "))
+	}
+
+	data, err := SafeMarshal(msg, format)
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "failed to marshal example: %v", err)
+	}
+
+	return &v1.SafeMarshalResponse{
+		MessageType: string(msg.ProtoReflect().Descriptor().FullName()),
+		Format:      format,
+		Data:        data,
+	}, nil
+}
@@ -0,0 +1,95 @@
+package server
+
+import (
+	"context"
+	"math"
+	"time"
+
+	v1 "github.com/benjamin-rood/protogo-values-validation-demo/gen/api/validation/v1"
+)
+
+// MaxSamples bounds BenchmarkRequest.samples, since every sample reruns every
+// stage
+const MaxSamples = 100
+
+// sampledStage runs stage samples times and aggregates the runs into one
+// result. A failed run is returned as is, and sampling stops early once ctx
+// is done.
+func sampledStage(stage benchmarkStage, samples, iterations int) benchmarkStage {
+	return benchmarkStage{name: stage.name, run: func(ctx context.Context) *v1.BenchmarkResult {
+		runs := make([]*v1.BenchmarkResult, 0, samples)
+		for i := 0; i < samples; i++ {
+			if i > 0 && ctx.Err() != nil {
+				break
+			}
+			result := stage.run(ctx)
+			if result.Error != "" {
+				return result
+			}
+			runs = append(runs, result)
+		}
+		return aggregateSamples(runs, iterations)
+	}}
+}
+
+// aggregateSamples combines runs of one stage: durations, allocations and
+// bytes become means, with the sample standard deviation and minimum of the
+// duration alongside. The rate is recomputed from the mean duration.
+func aggregateSamples(runs []*v1.BenchmarkResult, iterations int) *v1.BenchmarkResult {
+	n := float64(len(runs))
+	durations := make([]float64, len(runs))
+	var allocations, bytesAllocated int64
+	for i, run := range runs {
+		durations[i] = run.DurationNs
+		allocations += run.Allocations
+		bytesAllocated += run.BytesAllocated
+	}
+
+	mean, stddev := meanStddev(durations)
+	minDuration := math.Inf(1)
+	for _, d := range durations {
+		minDuration = math.Min(minDuration, d)
+	}
+
+	return &v1.BenchmarkResult{
+		Name:                runs[0].Name,
+		DurationNs:          mean,
+		Allocations:         int64(math.Round(float64(allocations) / n)),
+		BytesAllocated:      int64(math.Round(float64(bytesAllocated) / n)),
+		OperationsPerSecond: ratePerSecond(float64(iterations), time.Duration(mean)),
+		Samples:             int32(len(runs)),
+		DurationNsStddev:    stddev,
+		DurationNsMin:       minDuration,
+	}
+}
+
+// meanStddev returns the mean and sample standard deviation of values, the
+// latter 0 for fewer than two values
+func meanStddev(values []float64) (mean, stddev float64) {
+	if len(values) == 0 {
+		return 0, 0
+	}
+	for _, v := range values {
+		mean += v
+	}
+	mean /= float64(len(values))
+	if len(values) < 2 {
+		return mean, 0
+	}
+
+	var squares float64
+	for _, v := range values {
+		squares += (v - mean) * (v - mean)
+	}
+	return mean, math.Sqrt(squares / float64(len(values)-1))
+}
+
+// ratioStddev propagates the standard deviations of numerator and denominator
+// to their ratio, treating them as independent
+func ratioStddev(numerator, numeratorStddev, denominator, denominatorStddev float64) float64 {
+	if numerator <= 0 || denominator <= 0 {
+		return 0
+	}
+	ratio := numerator / denominator
+	return ratio * math.Hypot(numeratorStddev/numerator, denominatorStddev/denominator)
+}
@@ -0,0 +1,107 @@
+package server
+
+import (
+	"context"
+	"slices"
+	"strings"
+
+	v1 "github.com/benjamin-rood/protogo-values-validation-demo/gen/api/validation/v1"
+)
+
+// Built-in ValidateTypes scenario names
+const (
+	ScenarioValidationTestMessage  = "validation_test_message"
+	ScenarioPerformanceTestMessage = "performance_test_message"
+	ScenarioScalarSlices           = "scalar_slices"
+	ScenarioServiceFields          = "service_fields"
+	ScenarioTypeExpectations       = "type_expectations"
+)
+
+// ScenarioFunc validates one ValidateTypes scenario. ctx carries the
+// scenario's name, see ScenarioFromContext.
+type ScenarioFunc func(ctx context.Context) []*v1.ValidationResult
+
+type scenarioKey struct{}
+
+// ContextWithScenario returns ctx tagged with the scenario being validated
+func ContextWithScenario(ctx context.Context, name string) context.Context {
+	return context.WithValue(ctx, scenarioKey{}, name)
+}
+
+// ScenarioFromContext returns the scenario ValidateTypes is running, so
+// interceptors, metrics and logs downstream of a scenario can tag by it
+func ScenarioFromContext(ctx context.Context) (string, bool) {
+	name, ok := ctx.Value(scenarioKey{}).(string)
+	return name, ok
+}
+
+// validationScenario is one named group of ValidateTypes results
+type validationScenario struct {
+	name string
+	run  ScenarioFunc
+}
+
+// scenarios returns the scenarios ValidateTypes runs, in result order
+func (s *ValidationServer) scenarios() []validationScenario {
+	builtin := []validationScenario{
+		// ValidationTestMessage types (MVP compatibility)
+		{ScenarioValidationTestMessage, func(context.Context) []*v1.ValidationResult {
+			return s.validateValidationTestMessageTypes()
+		}},
+		// PerformanceTestMessage types (Phase 1 spec-compliant)
+		{ScenarioPerformanceTestMessage, func(context.Context) []*v1.ValidationResult {
+			return s.validatePerformanceTestMessageTypes()
+		}},
+		// Scalar repeated fields were left untouched
+		{ScenarioScalarSlices, func(context.Context) []*v1.ValidationResult {
+			return s.validateScalarSliceTypes()
+		}},
+		// The service's own messages were left untouched
+		{ScenarioServiceFields, func(context.Context) []*v1.ValidationResult {
+			return s.validateServiceSliceTypes()
+		}},
+		// Configured fields outside this demo's schema
+		{ScenarioTypeExpectations, func(context.Context) []*v1.ValidationResult {
+			return s.validateAdditionalExpectations()
+		}},
+	}
+	return append(builtin, s.extraScenarios...)
+}
+
+// selectScenarios returns the scenarios named by a ValidateTypes request, in
+// result order. names are normalized by uniqueScenarios and matched case
+// insensitively; no names selects every scenario, and an unknown name is
+// rejected rather than silently running nothing.
+func (s *ValidationServer) selectScenarios(names []string) ([]validationScenario, error) {
+	all := s.scenarios()
+	if len(names) == 0 {
+		return all, nil
+	}
+
+	selected := make([]validationScenario, 0, len(names))
+	for _, scenario := range all {
+		if slices.Contains(names, strings.ToLower(scenario.name)) {
+			selected = append(selected, scenario)
+		}
+	}
+
+	if len(selected) < len(names) {
+		for _, name := range names {
+			if !slices.ContainsFunc(selected, func(scenario validationScenario) bool {
+				return strings.ToLower(scenario.name) == name
+			}) {
+				return nil, invalidArgument(ErrUnknownScenario, "%q, expected one of %s", name, strings.Join(scenarioNames(all), ", "))
+			}
+		}
+	}
+	return selected, nil
+}
+
+// scenarioNames returns the names of scenarios, in order
+func scenarioNames(scenarios []validationScenario) []string {
+	names := make([]string, len(scenarios))
+	for i, scenario := range scenarios {
+		names[i] = scenario.name
+	}
+	return names
+}
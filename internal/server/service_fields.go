@@ -0,0 +1,30 @@
+package server
+
+import v1 "github.com/benjamin-rood/protogo-values-validation-demo/gen/api/validation/v1"
+
+// UntransformedScenarioPrefix prefixes the scenarios asserting that a service
+// message field was not transformed, e.g.
+// "untransformed/BenchmarkResponse.Results"
+const UntransformedScenarioPrefix = "untransformed/"
+
+// serviceSliceFields are repeated message fields of the service's own request
+// and response messages. gRPC marshals them on every call, and marshaling
+// panics on value slices, so the plugin must leave them as pointer slices
+// whatever the expectations file says.
+var serviceSliceFields = []struct {
+	key, expectedType string
+}{
+	{"BenchmarkResponse.Results", "[]*v1.BenchmarkResult"},
+	{"StreamResponse.FieldErrors", "[]*v1.FieldError"},
+	{"ValidateTypesResponse.Results", "[]*v1.ValidationResult"},
+}
+
+// validateServiceSliceTypes checks the service message fields are still
+// pointer slices; a transformed field fails with ERROR severity
+func (s *ValidationServer) validateServiceSliceTypes() []*v1.ValidationResult {
+	results := make([]*v1.ValidationResult, 0, len(serviceSliceFields))
+	for _, f := range serviceSliceFields {
+		results = append(results, NewValidationResult(UntransformedScenarioPrefix+f.key, fieldTypeString(f.key), f.expectedType))
+	}
+	return results
+}
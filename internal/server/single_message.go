@@ -0,0 +1,186 @@
+package server
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"sort"
+
+	v1 "github.com/benjamin-rood/protogo-values-validation-demo/gen/api/validation/v1"
+	"github.com/benjamin-rood/protogo-values-validation-demo/internal/convert"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/types/dynamicpb"
+)
+
+// ValidateSingleMessage unmarshals a ValidationTestMessage produced by the
+// client and validates the field types of the resulting struct, the entry
+// counts of any constrained repeated fields and the UTF-8 encoding of its
+// strings, so instances from real clients are checked and not just the
+// server's synthetic ones
+func (s *ValidationServer) ValidateSingleMessage(ctx context.Context, req *v1.ValidateSingleMessageRequest) (*v1.ValidateSingleMessageResponse, error) {
+	if len(req.Message) == 0 {
+		return nil, status.Errorf(codes.InvalidArgument, "message must not be empty")
+	}
+
+	// Invalid UTF-8 is reported as a failure rather than rejected as malformed
+	data, invalidUTF8, ok := sanitizeUTF8("message", (&v1.ValidationTestMessage{}).ProtoReflect().Descriptor(), req.Message)
+	if !ok {
+		return nil, status.Errorf(codes.InvalidArgument, "malformed ValidationTestMessage: not a well-formed message")
+	}
+
+	msg, dynamic, err := decodeTestMessage(data)
+	if err != nil {
+		return nil, status.Errorf(codes.InvalidArgument, "malformed ValidationTestMessage: %v", err)
+	}
+
+	results := append(s.validateMessageInstance(msg), s.checkCountConstraints(msg)...)
+	results = append(results, invalidUTF8Results(invalidUTF8)...)
+	fieldErrors := unknownFieldErrors("message", msg)
+
+	success := len(fieldErrors) == 0
+	for _, result := range results {
+		if !result.Passed {
+			success = false
+		}
+	}
+
+	return &v1.ValidateSingleMessageResponse{
+		Success:     success,
+		Results:     results,
+		FieldErrors: fieldErrors,
+		Presence:    messagePresence(dynamic),
+	}, nil
+}
+
+// messagePresence reports the presence of each field of msg, then of its
+// first data point, mirroring validateMessageInstance. It reads the dynamic
+// form, as protoreflect cannot read populated value slices.
+func messagePresence(msg *dynamicpb.Message) []*v1.FieldPresence {
+	presence := fieldPresence(msg)
+
+	for _, name := range []protoreflect.Name{"value_slice_data", "pointer_slice_data"} {
+		list := msg.Get(msg.Descriptor().Fields().ByName(name)).List()
+		if list.Len() > 0 {
+			return append(presence, fieldPresence(list.Get(0).Message())...)
+		}
+	}
+	return presence
+}
+
+// fieldPresence reports Has for each field of m, in declaration order
+func fieldPresence(m protoreflect.Message) []*v1.FieldPresence {
+	fields := m.Descriptor().Fields()
+	presence := make([]*v1.FieldPresence, 0, fields.Len())
+	for i := range fields.Len() {
+		fd := fields.Get(i)
+		presence = append(presence, &v1.FieldPresence{
+			Field:            string(fd.FullName()),
+			Present:          m.Has(fd),
+			ExplicitPresence: fd.HasPresence(),
+		})
+	}
+	return presence
+}
+
+// validateMessageInstance checks the fields of a decoded message against the
+// configured expectations. DataPoint.Tags is checked on the first decoded data
+// point, when there is one.
+func (s *ValidationServer) validateMessageInstance(msg *v1.ValidationTestMessage) []*v1.ValidationResult {
+	results := []*v1.ValidationResult{
+		NewValidationResult("ValidationTestMessage.ValueSliceData", SafeTypeString(msg.ValueSliceData), s.expectations["ValidationTestMessage.ValueSliceData"]),
+		NewValidationResult("ValidationTestMessage.PointerSliceData", SafeTypeString(msg.PointerSliceData), s.expectations["ValidationTestMessage.PointerSliceData"]),
+		NewValidationResult("ValidationTestMessage.Metrics", SafeTypeString(msg.Metrics), s.expectations["ValidationTestMessage.Metrics"]),
+	}
+
+	var dataPoint *v1.DataPoint
+	switch {
+	case len(msg.ValueSliceData) > 0:
+		dataPoint = &msg.ValueSliceData[0]
+	case len(msg.PointerSliceData) > 0:
+		dataPoint = msg.PointerSliceData[0]
+	}
+	if dataPoint != nil {
+		results = append(results, NewValidationResult("DataPoint.Tags", SafeTypeString(dataPoint.Tags), s.expectations["DataPoint.Tags"]))
+	}
+
+	return results
+}
+
+// checkCountConstraints checks the constraints on fields of msg's message
+// type, in key order. Constraints on other messages do not apply.
+func (s *ValidationServer) checkCountConstraints(msg proto.Message) []*v1.ValidationResult {
+	descriptor := msg.ProtoReflect().Descriptor()
+
+	keys := make([]string, 0, len(s.countConstraints))
+	for key := range s.countConstraints {
+		message, _, _ := splitFieldKey(key)
+		if message == string(descriptor.Name()) || message == string(descriptor.FullName()) {
+			keys = append(keys, key)
+		}
+	}
+	sort.Strings(keys)
+
+	value := reflect.ValueOf(msg).Elem()
+	results := make([]*v1.ValidationResult, 0, len(keys))
+	for _, key := range keys {
+		results = append(results, checkCount(key, value, s.countConstraints[key]))
+	}
+	return results
+}
+
+// checkCount checks one constraint against the Go field its key names.
+// Reflection reads the field directly, as protoreflect cannot read populated
+// value slices.
+func checkCount(key string, msg reflect.Value, constraint CountConstraint) *v1.ValidationResult {
+	result := &v1.ValidationResult{
+		Scenario:      key,
+		ExpectedCount: constraint.String(),
+	}
+
+	_, field, _ := splitFieldKey(key)
+	sf, ok := msg.Type().FieldByName(field)
+	if !ok || !sf.IsExported() {
+		result.ErrorMessage = fmt.Sprintf("%s is not a field", key)
+		result.Severity = v1.Severity_SEVERITY_ERROR
+		return result
+	}
+	fieldValue := msg.FieldByIndex(sf.Index)
+	if kind := fieldValue.Kind(); kind != reflect.Slice && kind != reflect.Map {
+		result.ErrorMessage = fmt.Sprintf("%s is a %s, not a repeated field", key, fieldValue.Type())
+		result.Severity = v1.Severity_SEVERITY_ERROR
+		return result
+	}
+
+	result.ActualType = fieldValue.Type().String()
+	result.ActualCount = int64(fieldValue.Len())
+	if ok, violation := constraint.Check(fieldValue.Len()); !ok {
+		result.ErrorMessage = fmt.Sprintf("%s %s", key, violation)
+		result.Severity = v1.Severity_SEVERITY_ERROR
+		return result
+	}
+
+	result.Passed = true
+	result.Severity = v1.Severity_SEVERITY_INFO
+	return result
+}
+
+// decodeTestMessage unmarshals a ValidationTestMessage in binary wire format,
+// returning the dynamic message it was parsed into as well. Binary
+// unmarshaling panics on populated value slices, so the input is parsed into a
+// dynamic message first, which also rejects malformed input, and copied into
+// the generated type.
+func decodeTestMessage(data []byte) (*v1.ValidationTestMessage, *dynamicpb.Message, error) {
+	dynamic := dynamicpb.NewMessage((&v1.ValidationTestMessage{}).ProtoReflect().Descriptor())
+	if err := proto.Unmarshal(data, dynamic); err != nil {
+		return nil, nil, err
+	}
+
+	msg := &v1.ValidationTestMessage{}
+	if err := convert.FromDynamic(dynamic, msg); err != nil {
+		return nil, nil, err
+	}
+	return msg, dynamic, nil
+}
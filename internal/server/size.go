@@ -0,0 +1,235 @@
+package server
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"math"
+
+	v1 "github.com/benjamin-rood/protogo-values-validation-demo/gen/api/validation/v1"
+	"github.com/benjamin-rood/protogo-values-validation-demo/internal/convert"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/encoding/protowire"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protoreflect"
+)
+
+// EstimateSize returns the serialized size of a message and the extrapolated
+// size for req.Count messages
+func (s *ValidationServer) EstimateSize(ctx context.Context, req *v1.EstimateSizeRequest) (*v1.EstimateSizeResponse, error) {
+	if req.Count <= 0 {
+		return nil, status.Errorf(codes.InvalidArgument, "count must be > 0")
+	}
+
+	msg := req.Message
+	if msg == nil {
+		if req.DataSize <= 0 {
+			return nil, invalidArgument(ErrInvalidDataSize, "required when message is unset")
+		}
+		if req.DataSize > s.maxDataSize {
+			return nil, invalidArgument(ErrDataSizeTooLarge, "got %d, max %d", req.DataSize, s.maxDataSize)
+		}
+		msg = newSizingMessage(int(req.DataSize))
+	}
+
+	size, err := EstimateMessageSize(msg)
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "%v", err)
+	}
+
+	bytesPerMessage := int64(size)
+	if bytesPerMessage > 0 && req.Count > math.MaxInt64/bytesPerMessage {
+		return nil, status.Errorf(codes.InvalidArgument, "count %d overflows total size", req.Count)
+	}
+
+	return &v1.EstimateSizeResponse{
+		BytesPerMessage: bytesPerMessage,
+		Count:           req.Count,
+		TotalBytes:      bytesPerMessage * req.Count,
+	}, nil
+}
+
+// EstimateMessageSize returns the number of bytes msg would marshal to, without
+// marshaling it. Value-slice fields are sized element by element through
+// pointers, which proto.Size supports, and the remaining pointer-slice fields
+// are sized on a copy that leaves the value slices out.
+func EstimateMessageSize(msg *v1.ValidationTestMessage) (size int, err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			size, err = 0, fmt.Errorf("estimating size: %v", r)
+		}
+	}()
+
+	fields := msg.ProtoReflect().Descriptor().Fields()
+
+	valueSliceNumber, err := fieldNumber(fields, "value_slice_data")
+	if err != nil {
+		return 0, err
+	}
+	metricsNumber, err := fieldNumber(fields, "metrics")
+	if err != nil {
+		return 0, err
+	}
+
+	pointerOnly := &v1.ValidationTestMessage{
+		PointerSliceData: msg.PointerSliceData,
+	}
+	size = proto.Size(pointerOnly)
+
+	for i := range msg.ValueSliceData {
+		size += embeddedSize(valueSliceNumber, proto.Size(&msg.ValueSliceData[i]))
+	}
+	for i := range msg.Metrics {
+		size += embeddedSize(metricsNumber, proto.Size(&msg.Metrics[i]))
+	}
+
+	return size, nil
+}
+
+// fieldNumber looks up a field by name, failing if the schema no longer has it
+func fieldNumber(fields protoreflect.FieldDescriptors, name protoreflect.Name) (protowire.Number, error) {
+	fd := fields.ByName(name)
+	if fd == nil {
+		return 0, fmt.Errorf("estimating size: cannot convert field %q: not in descriptor", name)
+	}
+	if fd.Kind() != protoreflect.MessageKind || !fd.IsList() {
+		return 0, fmt.Errorf("estimating size: cannot convert field %q: not a repeated message", name)
+	}
+	return fd.Number(), nil
+}
+
+// embeddedSize is the wire size of one length-delimited repeated element
+func embeddedSize(num protowire.Number, n int) int {
+	return protowire.SizeTag(num) + protowire.SizeBytes(n)
+}
+
+// newSizingMessage builds a ValidationTestMessage with dataSize items in every
+// repeated field
+func newSizingMessage(dataSize int) *v1.ValidationTestMessage {
+	msg := &v1.ValidationTestMessage{
+		ValueSliceData:   make([]v1.DataPoint, dataSize),
+		PointerSliceData: make([]*v1.DataPoint, dataSize),
+		Metrics:          make([]v1.MetricPoint, dataSize),
+	}
+	for i := 0; i < dataSize; i++ {
+		msg.ValueSliceData[i] = v1.DataPoint{
+			Id:        fmt.Sprintf("value_%d", i),
+			Value:     float64(i),
+			Timestamp: int64(i),
+			Tags:      []string{"sizing"},
+		}
+		msg.PointerSliceData[i] = &v1.DataPoint{
+			Id:        fmt.Sprintf("pointer_%d", i),
+			Value:     float64(i),
+			Timestamp: int64(i),
+			Tags:      []string{"sizing"},
+		}
+		msg.Metrics[i] = v1.MetricPoint{
+			Name:        fmt.Sprintf("metric_%d", i),
+			Measurement: float64(i),
+			Labels:      map[string]string{"index": fmt.Sprintf("%d", i)},
+		}
+	}
+	return msg
+}
+
+// defaultCompareDataSizes are compared when a request lists no data sizes
+var defaultCompareDataSizes = []int32{1, 10, 100, 1000}
+
+// maxCompareDataSizes bounds the data sizes one CompareSerializedSize call
+// builds and marshals
+const maxCompareDataSizes = 16
+
+// CompareSerializedSize marshals identical data once as a value slice and once
+// as a pointer slice. Protobuf encoding does not depend on the Go
+// representation, so the encodings must match byte for byte. ValidationTestMessage
+// carries the data in value_slice_data (field 1) and pointer_slice_data
+// (field 2), whose tags both encode to one byte.
+func (s *ValidationServer) CompareSerializedSize(ctx context.Context, req *v1.CompareSerializedSizeRequest) (*v1.CompareSerializedSizeResponse, error) {
+	dataSizes := req.DataSizes
+	if len(dataSizes) == 0 {
+		dataSizes = defaultCompareDataSizes
+	}
+	if len(dataSizes) > maxCompareDataSizes {
+		return nil, invalidArgument(ErrTooManyDataSizes, "got %d", len(dataSizes))
+	}
+
+	for _, dataSize := range dataSizes {
+		if dataSize <= 0 {
+			return nil, invalidArgument(ErrInvalidDataSize, "got %d", dataSize)
+		}
+		if dataSize > s.maxDataSize {
+			return nil, invalidArgument(ErrDataSizeTooLarge, "got %d, max %d", dataSize, s.maxDataSize)
+		}
+	}
+
+	resp := &v1.CompareSerializedSizeResponse{AllIdentical: true}
+	for _, dataSize := range dataSizes {
+		comparison, err := compareSerializedSize(int(dataSize))
+		if err != nil {
+			return nil, status.Errorf(codes.Internal, "data_size %d: %v", dataSize, err)
+		}
+		resp.AllIdentical = resp.AllIdentical && comparison.Identical
+		resp.Comparisons = append(resp.Comparisons, comparison)
+	}
+
+	return resp, nil
+}
+
+// compareSerializedSize builds both representations of dataSize data points
+func compareSerializedSize(dataSize int) (*v1.SizeComparison, error) {
+	values := newSizingMessage(dataSize).ValueSliceData
+	pointers := make([]*v1.DataPoint, len(values))
+	for i := range values {
+		pointers[i] = proto.Clone(&values[i]).(*v1.DataPoint)
+	}
+
+	valueMsg := &v1.ValidationTestMessage{ValueSliceData: values}
+	pointerMsg := &v1.ValidationTestMessage{PointerSliceData: pointers}
+
+	// Value slices cannot be marshaled directly, so marshal a pointer-backed copy
+	valueCopy, err := convert.MarshalSafeCopy(valueMsg)
+	if err != nil {
+		return nil, fmt.Errorf("converting value slice: %w", err)
+	}
+	valueBytes, err := proto.Marshal(valueCopy)
+	if err != nil {
+		return nil, fmt.Errorf("marshaling value slice: %w", err)
+	}
+	// Deterministic, so the bytes compared below do not depend on map order
+	deterministic := proto.MarshalOptions{Deterministic: true}
+	pointerBytes, err := deterministic.Marshal(pointerMsg)
+	if err != nil {
+		return nil, fmt.Errorf("marshaling pointer slice: %w", err)
+	}
+	estimated, err := EstimateMessageSize(valueMsg)
+	if err != nil {
+		return nil, err
+	}
+
+	// Equal lengths could still hide different contents. The two encodings
+	// differ only in the field tag, so move the converted data to
+	// pointer_slice_data and compare the bytes.
+	moved := valueCopy.ProtoReflect()
+	fields := moved.Descriptor().Fields()
+	valueField, pointerField := fields.ByNumber(1), fields.ByNumber(2)
+	list := moved.Mutable(pointerField).List()
+	converted := moved.Get(valueField).List()
+	for i := 0; i < converted.Len(); i++ {
+		list.Append(converted.Get(i))
+	}
+	moved.Clear(valueField)
+	movedBytes, err := deterministic.Marshal(moved.Interface())
+	if err != nil {
+		return nil, fmt.Errorf("marshaling moved value slice: %w", err)
+	}
+
+	return &v1.SizeComparison{
+		DataSize:          int32(dataSize),
+		ValueSliceBytes:   int64(len(valueBytes)),
+		PointerSliceBytes: int64(len(pointerBytes)),
+		EstimatedBytes:    int64(estimated),
+		Identical:         len(valueBytes) == len(pointerBytes) && bytes.Equal(movedBytes, pointerBytes),
+	}, nil
+}
@@ -0,0 +1,32 @@
+package server
+
+import "sync/atomic"
+
+// StreamTracker counts the StreamValidation streams currently open
+type StreamTracker struct {
+	active atomic.Int64
+}
+
+// tryAcquire records a newly opened stream unless limit streams are already
+// open. A limit of 0 or less is unbounded.
+func (t *StreamTracker) tryAcquire(limit int64) bool {
+	for {
+		active := t.active.Load()
+		if limit > 0 && active >= limit {
+			return false
+		}
+		if t.active.CompareAndSwap(active, active+1) {
+			return true
+		}
+	}
+}
+
+// release records a closed stream
+func (t *StreamTracker) release() {
+	t.active.Add(-1)
+}
+
+// Active returns the number of streams currently open
+func (t *StreamTracker) Active() int64 {
+	return t.active.Load()
+}
@@ -0,0 +1,105 @@
+package server
+
+import (
+	"context"
+	"math"
+	"sort"
+
+	v1 "github.com/benjamin-rood/protogo-values-validation-demo/gen/api/validation/v1"
+)
+
+// defaultDurationBucketsMs are the histogram bounds used when a request sets none
+var defaultDurationBucketsMs = []float64{1, 5, 10, 25, 50, 100, 250, 500, 1000}
+
+// maxDurationBuckets bounds the histogram buckets a request may ask for
+const maxDurationBuckets = 100
+
+// SummarizeResults summarizes the processing results in the request
+func (s *ValidationServer) SummarizeResults(ctx context.Context, req *v1.SummarizeResultsRequest) (*v1.SummarizeResultsResponse, error) {
+	bounds := req.DurationBucketsMs
+	if len(bounds) == 0 {
+		bounds = defaultDurationBucketsMs
+	}
+	if err := validateDurationBuckets(bounds); err != nil {
+		return nil, err
+	}
+	return summarizeResults(req.Results, bounds), nil
+}
+
+func validateDurationBuckets(bounds []float64) error {
+	if len(bounds) > maxDurationBuckets {
+		return invalidArgument(ErrInvalidDurationBuckets, "got %d buckets, max %d", len(bounds), maxDurationBuckets)
+	}
+	for i, bound := range bounds {
+		if math.IsNaN(bound) || math.IsInf(bound, 0) {
+			return invalidArgument(ErrInvalidDurationBuckets, "bound %d is %v", i, bound)
+		}
+		if i > 0 && bound <= bounds[i-1] {
+			return invalidArgument(ErrInvalidDurationBuckets, "bound %d (%v) does not exceed %v", i, bound, bounds[i-1])
+		}
+	}
+	return nil
+}
+
+// SummarizeProcessingResults summarizes a PerformanceTestMessage.Results value
+// slice with the default duration buckets. Elements are read in place through
+// pointers rather than copied.
+func SummarizeProcessingResults(results []v1.ProcessingResult) *v1.SummarizeResultsResponse {
+	pointers := make([]*v1.ProcessingResult, len(results))
+	for i := range results {
+		pointers[i] = &results[i]
+	}
+	return summarizeResults(pointers, defaultDurationBucketsMs)
+}
+
+// summarizeResults summarizes results, bucketing their durations by bounds,
+// which must be ascending
+func summarizeResults(results []*v1.ProcessingResult, bounds []float64) *v1.SummarizeResultsResponse {
+	summary := &v1.SummarizeResultsResponse{
+		TotalCount:        int32(len(results)),
+		DistinctErrors:    []string{},
+		DurationHistogram: make([]*v1.DurationBucket, len(bounds)),
+	}
+	for i, bound := range bounds {
+		summary.DurationHistogram[i] = &v1.DurationBucket{UpperBoundMs: bound}
+	}
+	if len(results) == 0 {
+		return summary
+	}
+
+	var totalDuration float64
+	seen := make(map[string]struct{})
+
+	for _, result := range results {
+		if !result.Success {
+			summary.FailedCount++
+		}
+
+		totalDuration += result.DurationMs
+		if result.DurationMs > summary.MaxDurationMs {
+			summary.MaxDurationMs = result.DurationMs
+		}
+
+		// The first bound at or above the duration; NaN lands past the end
+		if i := sort.SearchFloat64s(bounds, result.DurationMs); i < len(bounds) {
+			summary.DurationHistogram[i].Count++
+		} else {
+			summary.DurationOverflowCount++
+		}
+
+		for _, msg := range result.ErrorMessages {
+			if _, ok := seen[msg]; !ok {
+				seen[msg] = struct{}{}
+				summary.DistinctErrors = append(summary.DistinctErrors, msg)
+			}
+		}
+	}
+
+	sort.Strings(summary.DistinctErrors)
+
+	total := float64(len(results))
+	summary.SuccessRate = float64(summary.TotalCount-summary.FailedCount) / total
+	summary.MeanDurationMs = totalDuration / total
+
+	return summary
+}
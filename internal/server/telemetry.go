@@ -0,0 +1,216 @@
+package server
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log/slog"
+	"maps"
+	"net/http"
+	"slices"
+	"strings"
+	"sync"
+	"sync/atomic"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/stats"
+	"google.golang.org/grpc/status"
+)
+
+// WireRecorder receives the connection and RPC telemetry gathered by the
+// stats handler from NewWireStatsHandler. Callbacks arrive concurrently from
+// every connection, so implementations must be safe for concurrent use.
+type WireRecorder interface {
+	ConnBegin()
+	// ConnEnd reports the wire bytes the connection carried in each direction
+	ConnEnd(bytesIn, bytesOut int64)
+	RPCBegin(method string)
+	RPCEnd(method string, code codes.Code)
+	// Payload reports the wire size of one message, after compression and
+	// including its frame header; direction is "request" or "response"
+	Payload(method, direction string, wireBytes int)
+}
+
+// wireStatsHandler forwards grpc stats events to a WireRecorder
+type wireStatsHandler struct {
+	recorder WireRecorder
+}
+
+// NewWireStatsHandler returns a stats.Handler, for grpc.StatsHandler, that
+// reports to recorder. Unlike interceptors it sees every connection and the
+// bytes each one carries.
+func NewWireStatsHandler(recorder WireRecorder) stats.Handler {
+	return &wireStatsHandler{recorder: recorder}
+}
+
+type (
+	connBytesKey struct{}
+	rpcMethodKey struct{}
+)
+
+// connBytes totals the payload bytes of all RPCs on one connection
+type connBytes struct {
+	in, out atomic.Int64
+}
+
+// TagConn attaches the connection's byte totals, which every RPC context on
+// the connection inherits
+func (h *wireStatsHandler) TagConn(ctx context.Context, _ *stats.ConnTagInfo) context.Context {
+	return context.WithValue(ctx, connBytesKey{}, &connBytes{})
+}
+
+func (h *wireStatsHandler) HandleConn(ctx context.Context, s stats.ConnStats) {
+	switch s.(type) {
+	case *stats.ConnBegin:
+		h.recorder.ConnBegin()
+	case *stats.ConnEnd:
+		var in, out int64
+		if totals, ok := ctx.Value(connBytesKey{}).(*connBytes); ok {
+			in, out = totals.in.Load(), totals.out.Load()
+		}
+		h.recorder.ConnEnd(in, out)
+	}
+}
+
+func (h *wireStatsHandler) TagRPC(ctx context.Context, info *stats.RPCTagInfo) context.Context {
+	return context.WithValue(ctx, rpcMethodKey{}, info.FullMethodName)
+}
+
+func (h *wireStatsHandler) HandleRPC(ctx context.Context, s stats.RPCStats) {
+	method, _ := ctx.Value(rpcMethodKey{}).(string)
+	totals, _ := ctx.Value(connBytesKey{}).(*connBytes)
+
+	switch s := s.(type) {
+	case *stats.Begin:
+		h.recorder.RPCBegin(method)
+	case *stats.InPayload:
+		if totals != nil {
+			totals.in.Add(int64(s.WireLength))
+		}
+		h.recorder.Payload(method, "request", s.WireLength)
+	case *stats.OutPayload:
+		if totals != nil {
+			totals.out.Add(int64(s.WireLength))
+		}
+		h.recorder.Payload(method, "response", s.WireLength)
+	case *stats.End:
+		h.recorder.RPCEnd(method, status.Code(s.Error))
+	}
+}
+
+// WireMetrics is a WireRecorder exposing Prometheus counters of connections,
+// RPCs by method and status code, and wire bytes by method and direction
+type WireMetrics struct {
+	connsOpened atomic.Uint64
+	connsOpen   atomic.Int64
+
+	countsMu sync.Mutex
+	started  map[string]uint64
+	handled  map[wireHandledSeries]uint64
+	bytes    map[payloadSeries]uint64
+}
+
+type wireHandledSeries struct {
+	method string
+	code   codes.Code
+}
+
+// NewWireMetrics creates wire metrics with every count at zero
+func NewWireMetrics() *WireMetrics {
+	return &WireMetrics{
+		started: make(map[string]uint64),
+		handled: make(map[wireHandledSeries]uint64),
+		bytes:   make(map[payloadSeries]uint64),
+	}
+}
+
+func (m *WireMetrics) ConnBegin() {
+	m.connsOpened.Add(1)
+	m.connsOpen.Add(1)
+}
+
+// ConnEnd logs the connection's byte totals at debug level; the totals are
+// already counted per RPC
+func (m *WireMetrics) ConnEnd(bytesIn, bytesOut int64) {
+	m.connsOpen.Add(-1)
+	slog.Debug("connection closed", "bytes_in", bytesIn, "bytes_out", bytesOut)
+}
+
+func (m *WireMetrics) RPCBegin(method string) {
+	m.countsMu.Lock()
+	defer m.countsMu.Unlock()
+	m.started[method]++
+}
+
+func (m *WireMetrics) RPCEnd(method string, code codes.Code) {
+	m.countsMu.Lock()
+	defer m.countsMu.Unlock()
+	m.handled[wireHandledSeries{method: method, code: code}]++
+}
+
+func (m *WireMetrics) Payload(method, direction string, wireBytes int) {
+	m.countsMu.Lock()
+	defer m.countsMu.Unlock()
+	m.bytes[payloadSeries{method: method, direction: direction}] += uint64(wireBytes)
+}
+
+// WritePrometheus writes the metrics in the Prometheus text exposition format
+func (m *WireMetrics) WritePrometheus(w io.Writer) error {
+	m.countsMu.Lock()
+	defer m.countsMu.Unlock()
+
+	var b strings.Builder
+	b.WriteString("# HELP grpc_server_connections_opened_total Connections accepted.\n")
+	b.WriteString("# TYPE grpc_server_connections_opened_total counter\n")
+	fmt.Fprintf(&b, "grpc_server_connections_opened_total %d\n", m.connsOpened.Load())
+	b.WriteString("# HELP grpc_server_connections_open Connections currently open.\n")
+	b.WriteString("# TYPE grpc_server_connections_open gauge\n")
+	fmt.Fprintf(&b, "grpc_server_connections_open %d\n", m.connsOpen.Load())
+
+	b.WriteString("# HELP grpc_server_started_total RPCs started.\n")
+	b.WriteString("# TYPE grpc_server_started_total counter\n")
+	for _, method := range slices.Sorted(maps.Keys(m.started)) {
+		fmt.Fprintf(&b, "grpc_server_started_total{method=%q} %d\n", method, m.started[method])
+	}
+
+	b.WriteString("# HELP grpc_server_handled_total RPCs completed, by status code.\n")
+	b.WriteString("# TYPE grpc_server_handled_total counter\n")
+	handled := slices.SortedFunc(maps.Keys(m.handled), func(a, b wireHandledSeries) int {
+		if c := strings.Compare(a.method, b.method); c != 0 {
+			return c
+		}
+		return int(a.code) - int(b.code)
+	})
+	for _, key := range handled {
+		fmt.Fprintf(&b, "grpc_server_handled_total{method=%q,code=%q} %d\n", key.method, key.code.String(), m.handled[key])
+	}
+
+	b.WriteString("# HELP grpc_server_wire_bytes_total Message bytes on the wire, after compression.\n")
+	b.WriteString("# TYPE grpc_server_wire_bytes_total counter\n")
+	byteSeries := slices.SortedFunc(maps.Keys(m.bytes), func(a, b payloadSeries) int {
+		return strings.Compare(a.method+"\x00"+a.direction, b.method+"\x00"+b.direction)
+	})
+	for _, key := range byteSeries {
+		fmt.Fprintf(&b, "grpc_server_wire_bytes_total{method=%q,direction=%q} %d\n", key.method, key.direction, m.bytes[key])
+	}
+
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+// PrometheusWriter is implemented by the metrics served at /metrics
+type PrometheusWriter interface {
+	WritePrometheus(w io.Writer) error
+}
+
+// MetricsHandler serves the concatenated expositions of writers for
+// Prometheus to scrape
+func MetricsHandler(writers ...PrometheusWriter) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+		w.WriteHeader(http.StatusOK)
+		for _, writer := range writers {
+			writer.WritePrometheus(w)
+		}
+	}
+}
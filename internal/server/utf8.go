@@ -0,0 +1,82 @@
+package server
+
+import (
+	"fmt"
+	"strings"
+	"unicode/utf8"
+
+	v1 "github.com/benjamin-rood/protogo-values-validation-demo/gen/api/validation/v1"
+	"google.golang.org/protobuf/encoding/protowire"
+	"google.golang.org/protobuf/reflect/protoreflect"
+)
+
+// sanitizeUTF8 walks the wire encoding of a message of type md and returns the
+// paths of all string fields, at any depth, whose bytes are not valid UTF-8.
+// proto3 rejects such messages outright, so it also returns a copy of data
+// with the invalid bytes replaced by U+FFFD, which the rest of the message can
+// still be decoded from. ok is false, and data is returned unchanged, when
+// data is not a well-formed message.
+func sanitizeUTF8(path string, md protoreflect.MessageDescriptor, data []byte) (sanitized []byte, invalid []string, ok bool) {
+	counts := make(map[protowire.Number]int)
+	out := make([]byte, 0, len(data))
+
+	for b := data; len(b) > 0; {
+		num, typ, n := protowire.ConsumeTag(b)
+		if n < 0 {
+			return data, nil, false
+		}
+		m := protowire.ConsumeFieldValue(num, typ, b[n:])
+		if m < 0 {
+			return data, nil, false
+		}
+		field, value := b[:n+m], b[n:n+m]
+		b = b[n+m:]
+
+		fd := md.Fields().ByNumber(num)
+		if fd == nil || typ != protowire.BytesType {
+			out = append(out, field...)
+			continue
+		}
+
+		fieldPath := path + "." + string(fd.Name())
+		if fd.IsList() || fd.IsMap() {
+			fieldPath = fmt.Sprintf("%s[%d]", fieldPath, counts[num])
+			counts[num]++
+		}
+
+		content, _ := protowire.ConsumeBytes(value)
+		switch {
+		case fd.Kind() == protoreflect.StringKind && !utf8.Valid(content):
+			invalid = append(invalid, fieldPath)
+			out = protowire.AppendTag(out, num, typ)
+			out = protowire.AppendString(out, strings.ToValidUTF8(string(content), "\uFFFD"))
+		case fd.Message() != nil:
+			nested, nestedInvalid, nestedOK := sanitizeUTF8(fieldPath, fd.Message(), content)
+			if !nestedOK {
+				return data, nil, false
+			}
+			invalid = append(invalid, nestedInvalid...)
+			out = protowire.AppendTag(out, num, typ)
+			out = protowire.AppendBytes(out, nested)
+		default:
+			out = append(out, field...)
+		}
+	}
+
+	return out, invalid, true
+}
+
+// invalidUTF8Results reports one failing result per invalid string field
+func invalidUTF8Results(paths []string) []*v1.ValidationResult {
+	results := make([]*v1.ValidationResult, len(paths))
+	for i, path := range paths {
+		results[i] = &v1.ValidationResult{
+			Scenario:     path,
+			ExpectedType: "string",
+			ActualType:   "invalid UTF-8",
+			ErrorMessage: fmt.Sprintf("%s is not valid UTF-8", path),
+			Severity:     v1.Severity_SEVERITY_ERROR,
+		}
+	}
+	return results
+}
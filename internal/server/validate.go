@@ -0,0 +1,125 @@
+package server
+
+import (
+	"context"
+	"fmt"
+	"runtime"
+	"time"
+
+	v1 "github.com/benjamin-rood/protogo-values-validation-demo/gen/api/validation/v1"
+	"google.golang.org/grpc"
+)
+
+// Validator is implemented by request messages with constraints the proto
+// schema cannot express
+type Validator interface {
+	Validate() error
+}
+
+// benchmarkRequest, validateTypesRequest, watchResourcesRequest and
+// generateDatasetRequest add Validate to generated request types, which cannot
+// carry hand-written methods since gen/ is regenerated
+type (
+	benchmarkRequest       struct{ *v1.BenchmarkRequest }
+	validateTypesRequest   struct{ *v1.ValidateTypesRequest }
+	watchResourcesRequest  struct{ *v1.WatchResourcesRequest }
+	generateDatasetRequest struct{ *v1.GenerateDatasetRequest }
+)
+
+// Validate checks the parameters that do not depend on server configuration;
+// RunBenchmarks still enforces the server's maximum data size
+func (r benchmarkRequest) Validate() error {
+	if r.Iterations <= 0 {
+		return fmt.Errorf("%w: got %d", ErrInvalidIterations, r.Iterations)
+	}
+	if r.DataSize <= 0 {
+		return fmt.Errorf("%w: got %d", ErrInvalidDataSize, r.DataSize)
+	}
+	for _, n := range []int32{r.TagsPerItem, r.AttributesPerItem} {
+		if n < 0 || n > MaxEntriesPerItem {
+			return fmt.Errorf("%w: got tags_per_item=%d, attributes_per_item=%d", ErrInvalidItemEntries, r.TagsPerItem, r.AttributesPerItem)
+		}
+	}
+	if r.Gomaxprocs < 0 || int(r.Gomaxprocs) > runtime.NumCPU() {
+		return fmt.Errorf("%w: got %d, have %d", ErrInvalidGOMAXPROCS, r.Gomaxprocs, runtime.NumCPU())
+	}
+	if r.Samples < 0 || r.Samples > MaxSamples {
+		return fmt.Errorf("%w: got %d", ErrInvalidSamples, r.Samples)
+	}
+	return nil
+}
+
+// Validate checks the page size; page tokens are checked by ValidateTypes, as
+// they are bound to the request's scenarios
+func (r validateTypesRequest) Validate() error {
+	if r.PageSize < 0 {
+		return fmt.Errorf("%w: got %d", ErrInvalidPageSize, r.PageSize)
+	}
+	return nil
+}
+
+// Validate checks the snapshot interval is unset or not below MinWatchInterval
+func (r watchResourcesRequest) Validate() error {
+	if r.IntervalMs != 0 && time.Duration(r.IntervalMs)*time.Millisecond < MinWatchInterval {
+		return fmt.Errorf("%w: got %d", ErrInvalidWatchInterval, r.IntervalMs)
+	}
+	return nil
+}
+
+// Validate checks the parameters that do not depend on server configuration;
+// GenerateDataset still enforces the server's maximum data size
+func (r generateDatasetRequest) Validate() error {
+	if r.DataSize <= 0 {
+		return fmt.Errorf("%w: got %d", ErrInvalidDataSize, r.DataSize)
+	}
+	for _, n := range []int32{r.TagsPerItem, r.AttributesPerItem} {
+		if n < 0 || n > MaxEntriesPerItem {
+			return fmt.Errorf("%w: got tags_per_item=%d, attributes_per_item=%d", ErrInvalidItemEntries, r.TagsPerItem, r.AttributesPerItem)
+		}
+	}
+	return nil
+}
+
+// requestValidator returns the Validator for req, if its type has one
+func requestValidator(req any) (Validator, bool) {
+	switch r := req.(type) {
+	case Validator:
+		return r, true
+	case *v1.BenchmarkRequest:
+		return benchmarkRequest{r}, true
+	case *v1.ValidateTypesRequest:
+		return validateTypesRequest{r}, true
+	case *v1.WatchResourcesRequest:
+		return watchResourcesRequest{r}, true
+	case *v1.GenerateDatasetRequest:
+		return generateDatasetRequest{r}, true
+	default:
+		return nil, false
+	}
+}
+
+// ValidateRequest validates req, returning an InvalidArgument error when it
+// fails. Requests without a Validator are accepted. Handlers rely on
+// UnaryValidationInterceptor having done this, so in-process callers must call
+// it themselves.
+func ValidateRequest(req any) error {
+	v, ok := requestValidator(req)
+	if !ok {
+		return nil
+	}
+	if err := v.Validate(); err != nil {
+		return invalidArgument(err, "")
+	}
+	return nil
+}
+
+// UnaryValidationInterceptor rejects requests failing ValidateRequest with
+// InvalidArgument before the handler runs
+func UnaryValidationInterceptor() grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+		if err := ValidateRequest(req); err != nil {
+			return nil, err
+		}
+		return handler(ctx, req)
+	}
+}
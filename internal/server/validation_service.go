@@ -2,38 +2,266 @@ package server
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"log/slog"
 	"reflect"
+	"runtime"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
 	"time"
 
 	v1 "github.com/benjamin-rood/protogo-values-validation-demo/gen/api/validation/v1"
 	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
 	"google.golang.org/grpc/status"
 	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/reflect/protoregistry"
 )
 
-// ValidationServer implements the ValidationService gRPC service
+const (
+	// maxConcurrentWorkers bounds the goroutines a single ValidateConcurrent call may spawn
+	maxConcurrentWorkers = 256
+
+	// streamQueueSize bounds the messages buffered per stream awaiting validation
+	streamQueueSize = 16
+
+	// streamWorkers is the number of goroutines validating messages per stream
+	streamWorkers = 4
+
+	// maxBatchSize bounds the sub-requests in a single BatchValidateTypes call
+	maxBatchSize = 100
+)
+
+// FailFastHeader is the metadata key that enables fail-fast streaming
+const FailFastHeader = "x-fail-fast"
+
+// errStreamIdle is the cancellation cause of a stream whose client went silent
+var errStreamIdle = errors.New("stream idle timeout")
+
+// ValidationServer implements the ValidationService gRPC service. Options are
+// applied at construction and read-only afterwards; the only state shared
+// between requests is atomic or guarded by the mutex whose name prefixes it
+// (cacheMu guards cache). Everything else an RPC needs is local to the call.
 type ValidationServer struct {
 	v1.UnimplementedValidationServiceServer
+
+	clock         Clock
+	metrics       MetricsRecorder
+	benchmarkSink BenchmarkSink
+	maxDataSize   int32
+
+	// Open StreamValidation streams, optionally capped at maxStreams
+	streams    StreamTracker
+	maxStreams int64
+	// Streams receiving no message within streamIdleTimeout are closed
+	streamIdleTimeout time.Duration
+	// Streams sending more than maxStreamMessages are cut off
+	maxStreamMessages int64
+
+	// Expected generated Go types, keyed "Message.Field"
+	expectations TypeExpectations
+	// Required entry counts of repeated fields, keyed "Message.Field"
+	countConstraints CountConstraints
+
+	// AggregateMetrics cardinality limit and behaviour past it
+	maxMetricGroups      int
+	metricOverflowBucket bool
+
+	// ValidateTypes result cache, keyed by validateTypesCacheKey
+	cacheEnabled bool
+	cacheMu      sync.Mutex
+	cache        map[string]*v1.ValidateTypesResponse
+	cacheHits    atomic.Uint64
+	cacheMisses  atomic.Uint64
+
+	unavailableMu    sync.RWMutex
+	unavailableUntil time.Time
+
+	// Whether RunBenchmarks requests may pin the process-wide GOMAXPROCS,
+	// and a one-slot semaphore serializing the runs that do
+	allowPinGOMAXPROCS bool
+	procs              chan struct{}
+
+	// Scenarios ValidateTypes runs after the built-in ones
+	extraScenarios []validationScenario
 }
 
-// NewValidationServer creates a new validation service server
-func NewValidationServer() *ValidationServer {
-	return &ValidationServer{}
+// NewValidationServer creates a new validation service server. Without options
+// it uses the wall clock, discards metrics, caches ValidateTypes results,
+// limits data_size to DefaultMaxDataSize, does not cap streams, closes
+// streams idle for DefaultStreamIdleTimeout, cuts streams off after
+// DefaultMaxStreamMessages messages, rejects AggregateMetrics
+// requests with more than DefaultMaxMetricGroups label sets, rejects
+// requests to pin GOMAXPROCS and validates against DefaultTypeExpectations.
+func NewValidationServer(opts ...Option) *ValidationServer {
+	s := &ValidationServer{
+		clock:             realClock{},
+		metrics:           noopMetrics{},
+		maxDataSize:       DefaultMaxDataSize,
+		streamIdleTimeout: DefaultStreamIdleTimeout,
+		maxStreamMessages: DefaultMaxStreamMessages,
+		maxMetricGroups:   DefaultMaxMetricGroups,
+		expectations:      DefaultTypeExpectations(),
+		cacheEnabled:      true,
+		cache:             make(map[string]*v1.ValidateTypesResponse),
+		procs:             make(chan struct{}, 1),
+	}
+	for _, opt := range opts {
+		opt(s)
+	}
+	return s
 }
 
-// ValidateTypes validates that the plugin correctly transforms field types
+// ValidateTypes validates that the plugin correctly transforms field types.
+// Field types are fixed for a given binary, so results are cached per
+// scenario set and deep-validation flag for the lifetime of the process.
 func (s *ValidationServer) ValidateTypes(ctx context.Context, req *v1.ValidateTypesRequest) (*v1.ValidateTypesResponse, error) {
+	if s.isUnavailable() {
+		return nil, status.Errorf(codes.Unavailable, "validation service is temporarily unavailable")
+	}
+
+	scenarios, err := s.selectScenarios(uniqueScenarios(req.TestScenarios))
+	if err != nil {
+		return nil, err
+	}
+	key := validateTypesCacheKey(scenarioNames(scenarios), req.DeepValidation)
+	offset, err := decodePageToken(req.PageToken, key)
+	if err != nil {
+		return nil, err
+	}
+
+	resp := s.cachedValidateTypes(ctx, key, scenarios)
+	resp.ScenariosRun = scenarioNames(scenarios)
+	if req.IncludeKinds {
+		setResultKinds(resp.Results)
+	}
+	if req.ErrorOnFailure && !resp.Success {
+		return nil, NewValidationError(resp.Results)
+	}
+
+	return paginateResults(resp, key, offset, int(req.PageSize)), nil
+}
+
+// cachedValidateTypes returns a private copy of the full result set for scenarios
+func (s *ValidationServer) cachedValidateTypes(ctx context.Context, key string, scenarios []validationScenario) *v1.ValidateTypesResponse {
+	if !s.cacheEnabled {
+		return s.validateTypes(ctx, scenarios)
+	}
+
+	s.cacheMu.Lock()
+	cached, ok := s.cache[key]
+	s.cacheMu.Unlock()
+	if ok {
+		s.cacheHits.Add(1)
+		return proto.Clone(cached).(*v1.ValidateTypesResponse)
+	}
+
+	s.cacheMisses.Add(1)
+	resp := s.validateTypes(ctx, scenarios)
+
+	s.cacheMu.Lock()
+	s.cache[key] = proto.Clone(resp).(*v1.ValidateTypesResponse)
+	s.cacheMu.Unlock()
+
+	return resp
+}
+
+// BatchValidateTypes runs ValidateTypes for each sub-request in order. Sub-requests
+// are independent: an error in one is reported in its slot without aborting the rest.
+func (s *ValidationServer) BatchValidateTypes(ctx context.Context, req *v1.BatchValidateTypesRequest) (*v1.BatchValidateTypesResponse, error) {
+	if len(req.Requests) > maxBatchSize {
+		return nil, status.Errorf(codes.InvalidArgument, "batch size must be <= %d, got %d", maxBatchSize, len(req.Requests))
+	}
+
+	responses := make([]*v1.ValidateTypesResponse, len(req.Requests))
+	for i, subReq := range req.Requests {
+		// Sub-requests bypass the validation interceptor
+		err := ValidateRequest(subReq)
+		var resp *v1.ValidateTypesResponse
+		if err == nil {
+			resp, err = s.ValidateTypes(ctx, subReq)
+		}
+		if err != nil {
+			resp = &v1.ValidateTypesResponse{
+				Success: false,
+				Results: []*v1.ValidationResult{{
+					Scenario:     fmt.Sprintf("batch[%d]", i),
+					Passed:       false,
+					ErrorMessage: status.Convert(err).Message(),
+					Severity:     v1.Severity_SEVERITY_ERROR,
+				}},
+			}
+		}
+		responses[i] = resp
+	}
+
+	return &v1.BatchValidateTypesResponse{Responses: responses}, nil
+}
+
+// ActiveStreams returns the number of StreamValidation streams currently open
+func (s *ValidationServer) ActiveStreams() int64 {
+	return s.streams.Active()
+}
+
+// CacheHits returns the number of ValidateTypes calls served from the cache
+func (s *ValidationServer) CacheHits() uint64 {
+	return s.cacheHits.Load()
+}
+
+// CacheMisses returns the number of ValidateTypes calls computed and then cached
+func (s *ValidationServer) CacheMisses() uint64 {
+	return s.cacheMisses.Load()
+}
+
+// ClearCache empties the ValidateTypes result cache, returning how many
+// results it held
+func (s *ValidationServer) ClearCache() int {
+	s.cacheMu.Lock()
+	defer s.cacheMu.Unlock()
+
+	evicted := len(s.cache)
+	s.cache = make(map[string]*v1.ValidateTypesResponse)
+	return evicted
+}
+
+// validateTypesCacheKey identifies a request by the set of scenarios it runs,
+// ignoring order, and its deep-validation flag
+func validateTypesCacheKey(scenarios []string, deepValidation bool) string {
+	sorted := slices.Sorted(slices.Values(scenarios))
+	return fmt.Sprintf("deep=%t|%s", deepValidation, strings.Join(sorted, "\x00"))
+}
+
+// uniqueScenarios normalizes scenario names to trimmed lower case and drops
+// empty names and repeats, keeping the first-seen order, so each scenario
+// runs at most once
+func uniqueScenarios(names []string) []string {
+	seen := make(map[string]bool, len(names))
+	scenarios := make([]string, 0, len(names))
+	for _, name := range names {
+		name = strings.ToLower(strings.TrimSpace(name))
+		if name == "" || seen[name] {
+			continue
+		}
+		seen[name] = true
+		scenarios = append(scenarios, name)
+	}
+	return scenarios
+}
+
+// validateTypes computes the type validation results for the selected scenarios
+func (s *ValidationServer) validateTypes(ctx context.Context, scenarios []validationScenario) *v1.ValidateTypesResponse {
 	results := make([]*v1.ValidationResult, 0)
 	var valueSliceCount, pointerSliceCount int32
 
-	// Validate ValidationTestMessage types (MVP compatibility)
-	validationResults := s.validateValidationTestMessageTypes()
-	results = append(results, validationResults...)
-
-	// Validate PerformanceTestMessage types (Phase 1 spec-compliant)
-	performanceResults := s.validatePerformanceTestMessageTypes()
-	results = append(results, performanceResults...)
+	// Each scenario runs with its name in the context
+	for _, scenario := range scenarios {
+		results = append(results, scenario.run(ContextWithScenario(ctx, scenario.name))...)
+	}
 
 	// Count value slices and pointer slices
 	for _, result := range results {
@@ -44,138 +272,549 @@ func (s *ValidationServer) ValidateTypes(ctx context.Context, req *v1.ValidateTy
 		}
 	}
 
-	// Check if all validations passed
+	// Check if all validations passed; only ERROR-level results fail the request
 	allPassed := true
 	for _, result := range results {
-		if !result.Passed {
+		if result.Severity == v1.Severity_SEVERITY_ERROR {
 			allPassed = false
 			break
 		}
 	}
 
 	return &v1.ValidateTypesResponse{
-		Success:             allPassed,
-		Results:             results,
-		ValueSliceCount:     valueSliceCount,
-		PointerSliceCount:   pointerSliceCount,
-	}, nil
+		Success:           allPassed,
+		Results:           results,
+		ValueSliceCount:   valueSliceCount,
+		PointerSliceCount: pointerSliceCount,
+		TransformedFields: transformedFields(),
+	}
+}
+
+// SetUnavailableFor makes ValidateTypes return Unavailable for the given duration
+func (s *ValidationServer) SetUnavailableFor(d time.Duration) {
+	s.unavailableMu.Lock()
+	defer s.unavailableMu.Unlock()
+	s.unavailableUntil = s.clock.Now().Add(d)
+}
+
+func (s *ValidationServer) isUnavailable() bool {
+	s.unavailableMu.RLock()
+	defer s.unavailableMu.RUnlock()
+	return s.clock.Now().Before(s.unavailableUntil)
 }
 
-// RunBenchmarks performs performance benchmarking
+// RunBenchmarks performs performance benchmarking. req must have passed
+// ValidateRequest.
 func (s *ValidationServer) RunBenchmarks(ctx context.Context, req *v1.BenchmarkRequest) (*v1.BenchmarkResponse, error) {
-	if req.Iterations <= 0 {
-		return nil, status.Errorf(codes.InvalidArgument, "iterations must be > 0")
+	if req.PinGomaxprocs {
+		restore, err := s.pinGOMAXPROCS(ctx, pinnedProcs(req))
+		if err != nil {
+			return nil, err
+		}
+		defer restore()
+	}
+
+	run, err := s.prepareBenchmarks(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+
+	results, err := runBenchmarkStages(ctx, run.stages, req.Parallel)
+	if err != nil {
+		return nil, err
+	}
+
+	return s.benchmarkResponse(req, run, results), nil
+}
+
+// benchmarkRun is the generated input and stages for one benchmark request
+type benchmarkRun struct {
+	stages          []benchmarkStage
+	setupDuration   time.Duration
+	serializedBytes int
+}
+
+// prepareBenchmarks generates the benchmark input for req and builds the
+// stages that measure it
+func (s *ValidationServer) prepareBenchmarks(ctx context.Context, req *v1.BenchmarkRequest) (*benchmarkRun, error) {
+	if req.DataSize > s.maxDataSize {
+		return nil, invalidArgument(ErrDataSizeTooLarge, "got %d, max %d", req.DataSize, s.maxDataSize)
+	}
+
+	shape := newPayloadShape(req.TagsPerItem, req.AttributesPerItem)
+	iterations := int(req.Iterations)
+
+	// Build the inputs up front, so stages time only the work they measure
+	setupStart := s.clock.Now()
+	data, err := generateBenchmarkData(ctx, int(req.DataSize), shape)
+	if err != nil {
+		if _, ok := status.FromError(err); !ok {
+			err = status.Errorf(codes.Internal, "%v", err)
+		}
+		return nil, err
+	}
+	setupDuration := s.clock.Since(setupStart)
+
+	// Each stage is isolated so one failure doesn't abort the others
+	stages := []benchmarkStage{
+		{"ValueSlice_Iteration", func(ctx context.Context) *v1.BenchmarkResult {
+			return s.benchmarkValueSliceIteration(ctx, iterations, data.values)
+		}},
+		{"PointerSlice_Iteration", func(ctx context.Context) *v1.BenchmarkResult {
+			return s.benchmarkPointerSliceIteration(ctx, iterations, data.pointers)
+		}},
+		{"Memory_Allocation", func(ctx context.Context) *v1.BenchmarkResult {
+			return s.benchmarkMemoryAllocation(ctx, iterations, len(data.values))
+		}},
+		{"Serialization", func(ctx context.Context) *v1.BenchmarkResult {
+			return s.benchmarkSerialization(ctx, iterations, data.serialization, proto.MarshalOptions{})
+		}},
 	}
 
-	if req.DataSize <= 0 {
-		return nil, status.Errorf(codes.InvalidArgument, "data_size must be > 0")
+	// Run deterministic serialization benchmark (stable map ordering)
+	if req.Deterministic {
+		stages = append(stages, benchmarkStage{"Serialization_Deterministic", func(ctx context.Context) *v1.BenchmarkResult {
+			return s.benchmarkSerialization(ctx, iterations, data.serialization, proto.MarshalOptions{Deterministic: true})
+		}})
 	}
 
-	results := make([]*v1.BenchmarkResult, 0)
+	stages = append(stages, benchmarkStage{"Deserialization", func(ctx context.Context) *v1.BenchmarkResult {
+		return s.benchmarkDeserialization(ctx, iterations, data.encoded)
+	}})
 
-	// Run value slice iteration benchmark
-	valueSliceResult := s.benchmarkValueSliceIteration(int(req.Iterations), int(req.DataSize))
-	results = append(results, valueSliceResult)
+	stages = selectStages(stages, req.BenchmarkNames)
 
-	// Run pointer slice iteration benchmark
-	pointerSliceResult := s.benchmarkPointerSliceIteration(int(req.Iterations), int(req.DataSize))
-	results = append(results, pointerSliceResult)
+	if req.Samples > 1 {
+		for i, stage := range stages {
+			stages[i] = sampledStage(stage, int(req.Samples), iterations)
+		}
+	}
 
-	// Run memory allocation benchmark
-	memoryResult := s.benchmarkMemoryAllocation(int(req.Iterations), int(req.DataSize))
-	results = append(results, memoryResult)
+	return &benchmarkRun{
+		stages:          stages,
+		setupDuration:   setupDuration,
+		serializedBytes: data.serializedBytes,
+	}, nil
+}
 
-	// Run serialization benchmark
-	serializationResult := s.benchmarkSerialization(int(req.Iterations), int(req.DataSize))
-	results = append(results, serializationResult)
+// benchmarkResponse records results and builds the response reporting them
+func (s *ValidationServer) benchmarkResponse(req *v1.BenchmarkRequest, run *benchmarkRun, results []*v1.BenchmarkResult) *v1.BenchmarkResponse {
+	success := true
+	for _, result := range results {
+		if result.Error != "" {
+			success = false
+		}
+		result.OperationsPerSecondHuman = FormatRate(result.OperationsPerSecond, "ops")
+		s.metrics.RecordBenchmark(result)
+	}
 
 	// Calculate summary statistics
 	summary := s.calculateBenchmarkSummary(results)
 
-	return &v1.BenchmarkResponse{
-		Success: true,
-		Results: results,
-		Summary: summary,
-	}, nil
+	resp := &v1.BenchmarkResponse{
+		Success:         success,
+		Results:         results,
+		Summary:         summary,
+		SetupDurationNs: run.setupDuration.Nanoseconds(),
+		SerializedBytes: int64(run.serializedBytes),
+		Gomaxprocs:      int32(runtime.GOMAXPROCS(0)),
+	}
+
+	if req.BenchstatOutput {
+		resp.Benchstat = FormatBenchstat(results, req.Iterations)
+	}
+
+	finished := s.clock.Now()
+	if req.OpenmetricsOutput {
+		resp.Openmetrics = FormatOpenMetrics(results, finished)
+	}
+
+	s.reportBenchmarks(results, finished)
+
+	return resp
+}
+
+// RunBenchmarkStage runs a single benchmark stage, converting a panic into a
+// result carrying the error so the remaining stages can still run
+func RunBenchmarkStage(name string, stage func() *v1.BenchmarkResult) (result *v1.BenchmarkResult) {
+	defer func() {
+		if r := recover(); r != nil {
+			result = &v1.BenchmarkResult{
+				Name:  name,
+				Error: fmt.Sprintf("stage panicked: %v", r),
+			}
+		}
+	}()
+
+	return stage()
 }
 
-// StreamValidation handles streaming validation requests
+// StreamValidation handles streaming validation requests. Messages are read into
+// a bounded queue and validated by a small worker pool, so responses may arrive
+// out of order; clients reorder them using SequenceNumber. When the client sets
+// the FailFastHeader, the first failing response ends the stream with
+// FailedPrecondition.
 func (s *ValidationServer) StreamValidation(stream v1.ValidationService_StreamValidationServer) error {
+	limit := s.maxStreams
+	if !s.streams.tryAcquire(limit) {
+		return status.Errorf(codes.ResourceExhausted, "too many concurrent streams (limit %d)", limit)
+	}
+	defer s.streams.release()
+
+	failFast, err := failFastFromContext(stream.Context())
+	if err != nil {
+		return err
+	}
+
+	ctx, cancel := context.WithCancelCause(stream.Context())
+	defer cancel(nil)
+
+	// Idle timer: cancels the stream when no message arrives in time, which
+	// also unblocks the reader's pending Recv
+	idle := s.newIdleTimer(func() { cancel(errStreamIdle) })
+
+	requests := make(chan *v1.StreamRequest, streamQueueSize)
+	responses := make(chan *v1.StreamResponse, streamQueueSize)
+
+	// Set by the reader before it closes requests, once the client sends
+	// past the message cap
+	var capExceeded atomic.Bool
+
+	// Reader: blocks on a full queue, applying backpressure to fast producers
+	go func() {
+		defer close(requests)
+		defer idle.stop()
+		for received := int64(1); ; received++ {
+			req, err := stream.Recv()
+			if err != nil {
+				// End of stream
+				return
+			}
+			// Past the cap the stream ends as if the client had closed it,
+			// so messages already queued are still answered
+			if s.maxStreamMessages > 0 && received > s.maxStreamMessages {
+				capExceeded.Store(true)
+				return
+			}
+
+			// Time spent blocked on a full queue is not client idleness
+			idle.stop()
+			select {
+			case requests <- req:
+			case <-ctx.Done():
+				return
+			}
+			idle.reset()
+		}
+	}()
+
+	// Workers: validate queued messages concurrently. They also watch ctx
+	// while idle, since the reader only closes requests once Recv returns.
+	var wg sync.WaitGroup
+	for i := 0; i < streamWorkers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for {
+				select {
+				case req, ok := <-requests:
+					if !ok {
+						return
+					}
+					select {
+					case responses <- s.processStreamRequest(req):
+					case <-ctx.Done():
+						return
+					}
+				case <-ctx.Done():
+					return
+				}
+			}
+		}()
+	}
+
+	go func() {
+		wg.Wait()
+		close(responses)
+	}()
+
+	// Stop the workers on every return path and wait for them, so none
+	// outlives the stream. The reader returns once gRPC ends the stream
+	// context after the handler returns.
+	defer func() {
+		cancel(nil)
+		idle.stop()
+		wg.Wait()
+	}()
+
+	// Sender: gRPC streams do not support concurrent Send calls
 	for {
-		req, err := stream.Recv()
-		if err != nil {
-			// End of stream
-			return nil
+		select {
+		case resp, ok := <-responses:
+			if !ok && capExceeded.Load() {
+				return s.streamCapExceeded(stream)
+			}
+			if !ok {
+				return nil
+			}
+			if err := stream.Send(resp); err != nil {
+				return streamSendError(stream.Context(), resp, err)
+			}
+			if failFast && !resp.Success {
+				return status.Errorf(codes.FailedPrecondition,
+					"validation failed for request %s (sequence %d)", resp.RequestId, resp.SequenceNumber)
+			}
+		case <-ctx.Done():
+			if errors.Is(context.Cause(ctx), errStreamIdle) {
+				return status.Errorf(codes.DeadlineExceeded,
+					"no stream message received within %s", s.streamIdleTimeout)
+			}
+			return status.FromContextError(ctx.Err()).Err()
 		}
+	}
+}
 
-		// Process the request
-		startTime := time.Now()
-		
-		// Validate the test data
-		isValid := s.validateTestMessage(req.TestData)
-		
-		processingTime := time.Since(startTime)
+// streamCapExceeded sends the final response of a stream cut off at the
+// message cap and returns the ResourceExhausted status ending it
+func (s *ValidationServer) streamCapExceeded(stream v1.ValidationService_StreamValidationServer) error {
+	message := fmt.Sprintf("stream exceeded the limit of %d messages", s.maxStreamMessages)
+	final := &v1.StreamResponse{Message: message, CapExceeded: true}
+	if err := stream.Send(final); err != nil {
+		return streamSendError(stream.Context(), final, err)
+	}
+	return status.Error(codes.ResourceExhausted, message)
+}
+
+// idleTimer fires once its window elapses without a reset. A nil timer,
+// used when the idle timeout is disabled, never fires.
+type idleTimer struct {
+	timer   *time.Timer
+	timeout time.Duration
+}
 
-		// Send response
-		resp := &v1.StreamResponse{
+// newIdleTimer starts a timer calling onIdle after s.streamIdleTimeout
+func (s *ValidationServer) newIdleTimer(onIdle func()) *idleTimer {
+	if s.streamIdleTimeout <= 0 {
+		return nil
+	}
+	return &idleTimer{
+		timer:   time.AfterFunc(s.streamIdleTimeout, onIdle),
+		timeout: s.streamIdleTimeout,
+	}
+}
+
+func (t *idleTimer) stop() {
+	if t != nil {
+		t.timer.Stop()
+	}
+}
+
+func (t *idleTimer) reset() {
+	if t != nil {
+		t.timer.Reset(t.timeout)
+	}
+}
+
+// streamSendError classifies a failed Send. A client that cancelled the
+// stream, or whose deadline passed, ends it normally, so that is logged at
+// debug level with the context's status. Anything else is a transport failure
+// and is logged as an error.
+func streamSendError(ctx context.Context, resp *v1.StreamResponse, err error) error {
+	if ctx.Err() != nil || status.Code(err) == codes.Canceled {
+		slog.DebugContext(ctx, "stream client went away during send",
+			"request_id", resp.RequestId, "sequence_number", resp.SequenceNumber, "error", err)
+		if ctx.Err() != nil {
+			return status.FromContextError(ctx.Err()).Err()
+		}
+		return err
+	}
+
+	slog.ErrorContext(ctx, "stream send failed",
+		"request_id", resp.RequestId, "sequence_number", resp.SequenceNumber, "error", err)
+	if _, ok := status.FromError(err); ok {
+		return err
+	}
+	return status.Errorf(codes.Unavailable, "sending stream response: %v", err)
+}
+
+// validateStreamRequest checks the envelope of a streamed message. Empty
+// slices in TestData are valid.
+func validateStreamRequest(req *v1.StreamRequest) error {
+	if req.RequestId == "" {
+		return errors.New("request_id is required")
+	}
+	if req.SequenceNumber < 0 {
+		return fmt.Errorf("sequence_number must be >= 0, got %d", req.SequenceNumber)
+	}
+	if req.TestData == nil {
+		return errors.New("test_data is required")
+	}
+	return nil
+}
+
+// failFastFromContext reads the FailFastHeader from incoming metadata
+func failFastFromContext(ctx context.Context) (bool, error) {
+	md, ok := metadata.FromIncomingContext(ctx)
+	if !ok {
+		return false, nil
+	}
+
+	values := md.Get(FailFastHeader)
+	if len(values) == 0 {
+		return false, nil
+	}
+
+	failFast, err := strconv.ParseBool(values[0])
+	if err != nil {
+		return false, status.Errorf(codes.InvalidArgument, "invalid %s header %q", FailFastHeader, values[0])
+	}
+	return failFast, nil
+}
+
+// processStreamRequest validates a single streamed message
+func (s *ValidationServer) processStreamRequest(req *v1.StreamRequest) *v1.StreamResponse {
+	// Malformed requests fail individually without ending the stream
+	if err := validateStreamRequest(req); err != nil {
+		return &v1.StreamResponse{
 			RequestId:      req.RequestId,
-			Success:        isValid,
-			Message:        fmt.Sprintf("Processed request %s", req.RequestId),
+			Success:        false,
+			Message:        fmt.Sprintf("Invalid request: %v", err),
 			SequenceNumber: req.SequenceNumber,
-			Stats: &v1.ProcessingStats{
-				ProcessingTimeNs: processingTime.Nanoseconds(),
-				ItemsProcessed:   int32(len(req.TestData.ValueSliceData) + len(req.TestData.PointerSliceData)),
-				Throughput:       float64(len(req.TestData.ValueSliceData)+len(req.TestData.PointerSliceData)) / processingTime.Seconds(),
-			},
 		}
+	}
 
-		if err := stream.Send(resp); err != nil {
-			return err
-		}
+	// Process the request
+	startTime := s.clock.Now()
+
+	// Validate the test data
+	fieldErrors := s.validateTestMessage(req.TestData)
+
+	processingTime := s.clock.Since(startTime)
+
+	itemsProcessed := len(req.GetTestData().GetValueSliceData()) + len(req.GetTestData().GetPointerSliceData())
+	throughput := ratePerSecond(float64(itemsProcessed), processingTime)
+
+	message := fmt.Sprintf("Processed request %s", req.RequestId)
+	if len(fieldErrors) > 0 {
+		message = fmt.Sprintf("%s: %d field errors", message, len(fieldErrors))
+	}
+
+	return &v1.StreamResponse{
+		RequestId:      req.RequestId,
+		Success:        len(fieldErrors) == 0,
+		Message:        message,
+		SequenceNumber: req.SequenceNumber,
+		Stats: &v1.ProcessingStats{
+			ProcessingTimeNs: processingTime.Nanoseconds(),
+			ItemsProcessed:   int32(itemsProcessed),
+			Throughput:       throughput,
+			ThroughputHuman:  FormatRate(throughput, "items"),
+		},
+		FieldErrors: fieldErrors,
+	}
+}
+
+// ValidateConcurrent runs the validation scenarios across many goroutines and
+// checks every run against a single-threaded reference result
+func (s *ValidationServer) ValidateConcurrent(ctx context.Context, req *v1.ValidateConcurrentRequest) (*v1.ValidateConcurrentResponse, error) {
+	if req.Workers <= 0 || req.Workers > maxConcurrentWorkers {
+		return nil, status.Errorf(codes.InvalidArgument, "workers must be between 1 and %d", maxConcurrentWorkers)
+	}
+
+	iterations := req.IterationsPerWorker
+	if iterations <= 0 {
+		iterations = 1
+	}
+
+	validateReq := &v1.ValidateTypesRequest{TestScenarios: req.TestScenarios}
+
+	// Reference result computed before any concurrency is introduced
+	reference, err := s.ValidateTypes(ctx, validateReq)
+	if err != nil {
+		return nil, err
+	}
+
+	var passed, failed, inconsistent atomic.Int32
+	var wg sync.WaitGroup
+
+	for w := int32(0); w < req.Workers; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := int32(0); i < iterations; i++ {
+				resp, err := s.ValidateTypes(ctx, validateReq)
+				if err != nil || !resp.Success {
+					failed.Add(1)
+				} else {
+					passed.Add(1)
+				}
+
+				// Any divergence from the reference indicates shared mutable state
+				if err == nil && !proto.Equal(resp, reference) {
+					inconsistent.Add(1)
+				}
+			}
+		}()
 	}
+
+	wg.Wait()
+
+	return &v1.ValidateConcurrentResponse{
+		Success:          failed.Load() == 0 && inconsistent.Load() == 0,
+		TotalRuns:        req.Workers * iterations,
+		PassedRuns:       passed.Load(),
+		FailedRuns:       failed.Load(),
+		InconsistentRuns: inconsistent.Load(),
+	}, nil
 }
 
 // Helper methods for type validation
 
+// transformedMessages are the messages whose fields may carry the value_slice option
+var transformedMessages = []reflect.Type{
+	reflect.TypeFor[v1.ValidationTestMessage](),
+	reflect.TypeFor[v1.PerformanceTestMessage](),
+}
+
+// transformedFields lists, in declaration order, the protobuf fields generated
+// as slices of structs rather than slices of pointers
+func transformedFields() []string {
+	var fields []string
+	for _, t := range transformedMessages {
+		for i := 0; i < t.NumField(); i++ {
+			field := t.Field(i)
+			if _, ok := field.Tag.Lookup("protobuf"); !ok {
+				continue
+			}
+			if field.Type.Kind() == reflect.Slice && field.Type.Elem().Kind() == reflect.Struct {
+				fields = append(fields, t.Name()+"."+field.Name)
+			}
+		}
+	}
+	return fields
+}
+
 func (s *ValidationServer) validateValidationTestMessageTypes() []*v1.ValidationResult {
 	var results []*v1.ValidationResult
 
 	// Test ValueSliceData field
 	msg := v1.ValidationTestMessage{}
-	actualType := reflect.TypeOf(msg.ValueSliceData).String()
-	expectedType := "[]v1.DataPoint"
+	actualType := SafeTypeString(msg.ValueSliceData)
+	expectedType := s.expectations["ValidationTestMessage.ValueSliceData"]
 	
-	results = append(results, &v1.ValidationResult{
-		Scenario:     "ValidationTestMessage.ValueSliceData",
-		Passed:       actualType == expectedType,
-		ErrorMessage: getErrorMessage(actualType, expectedType),
-		ExpectedType: expectedType,
-		ActualType:   actualType,
-	})
+	results = append(results, NewValidationResult("ValidationTestMessage.ValueSliceData", actualType, expectedType))
 
 	// Test PointerSliceData field
-	actualType = reflect.TypeOf(msg.PointerSliceData).String()
-	expectedType = "[]*v1.DataPoint"
+	actualType = SafeTypeString(msg.PointerSliceData)
+	expectedType = s.expectations["ValidationTestMessage.PointerSliceData"]
 	
-	results = append(results, &v1.ValidationResult{
-		Scenario:     "ValidationTestMessage.PointerSliceData",
-		Passed:       actualType == expectedType,
-		ErrorMessage: getErrorMessage(actualType, expectedType),
-		ExpectedType: expectedType,
-		ActualType:   actualType,
-	})
+	results = append(results, NewValidationResult("ValidationTestMessage.PointerSliceData", actualType, expectedType))
 
 	// Test Metrics field (structured field option)
-	actualType = reflect.TypeOf(msg.Metrics).String()
-	expectedType = "[]v1.MetricPoint"
+	actualType = SafeTypeString(msg.Metrics)
+	expectedType = s.expectations["ValidationTestMessage.Metrics"]
 	
-	results = append(results, &v1.ValidationResult{
-		Scenario:     "ValidationTestMessage.Metrics",
-		Passed:       actualType == expectedType,
-		ErrorMessage: getErrorMessage(actualType, expectedType),
-		ExpectedType: expectedType,
-		ActualType:   actualType,
-	})
+	results = append(results, NewValidationResult("ValidationTestMessage.Metrics", actualType, expectedType))
 
 	return results
 }
@@ -187,200 +826,314 @@ func (s *ValidationServer) validatePerformanceTestMessageTypes() []*v1.Validatio
 	msg := v1.PerformanceTestMessage{}
 	
 	// Test ValueSliceData field
-	actualType := reflect.TypeOf(msg.ValueSliceData).String()
-	expectedType := "[]v1.DataPoint"
+	actualType := SafeTypeString(msg.ValueSliceData)
+	expectedType := s.expectations["PerformanceTestMessage.ValueSliceData"]
 	
-	results = append(results, &v1.ValidationResult{
-		Scenario:     "PerformanceTestMessage.ValueSliceData",
-		Passed:       actualType == expectedType,
-		ErrorMessage: getErrorMessage(actualType, expectedType),
-		ExpectedType: expectedType,
-		ActualType:   actualType,
-	})
+	results = append(results, NewValidationResult("PerformanceTestMessage.ValueSliceData", actualType, expectedType))
 
 	// Test PointerSliceData field
-	actualType = reflect.TypeOf(msg.PointerSliceData).String()
-	expectedType = "[]*v1.Metadata"
+	actualType = SafeTypeString(msg.PointerSliceData)
+	expectedType = s.expectations["PerformanceTestMessage.PointerSliceData"]
 	
-	results = append(results, &v1.ValidationResult{
-		Scenario:     "PerformanceTestMessage.PointerSliceData",
-		Passed:       actualType == expectedType,
-		ErrorMessage: getErrorMessage(actualType, expectedType),
-		ExpectedType: expectedType,
-		ActualType:   actualType,
-	})
+	results = append(results, NewValidationResult("PerformanceTestMessage.PointerSliceData", actualType, expectedType))
 
 	// Test Results field
-	actualType = reflect.TypeOf(msg.Results).String()
-	expectedType = "[]v1.ProcessingResult"
+	actualType = SafeTypeString(msg.Results)
+	expectedType = s.expectations["PerformanceTestMessage.Results"]
 	
-	results = append(results, &v1.ValidationResult{
-		Scenario:     "PerformanceTestMessage.Results",
-		Passed:       actualType == expectedType,
-		ErrorMessage: getErrorMessage(actualType, expectedType),
-		ExpectedType: expectedType,
-		ActualType:   actualType,
-	})
+	results = append(results, NewValidationResult("PerformanceTestMessage.Results", actualType, expectedType))
 
 	return results
 }
 
-// Benchmark helper methods
+func (s *ValidationServer) validateScalarSliceTypes() []*v1.ValidationResult {
+	var results []*v1.ValidationResult
 
-func (s *ValidationServer) benchmarkValueSliceIteration(iterations, dataSize int) *v1.BenchmarkResult {
-	// Create test data
-	data := make([]v1.DataPoint, dataSize)
-	for i := 0; i < dataSize; i++ {
-		data[i] = v1.DataPoint{
-			Id:        fmt.Sprintf("dp_%d", i),
-			Value:     float64(i) * 1.5,
-			Timestamp: int64(1000000 + i),
-		}
-	}
+	// Scalar repeated fields are never transformed by the plugin, since
+	// only message-typed fields carry the value_slice option
+	dataPoint := v1.DataPoint{}
+	actualType := SafeTypeString(dataPoint.Tags)
+	expectedType := s.expectations["DataPoint.Tags"]
+
+	results = append(results, NewValidationResult("DataPoint.Tags", actualType, expectedType))
 
-	start := time.Now()
-	for i := 0; i < iterations; i++ {
+	// Test ErrorMessages field
+	processingResult := v1.ProcessingResult{}
+	actualType = SafeTypeString(processingResult.ErrorMessages)
+	expectedType = s.expectations["ProcessingResult.ErrorMessages"]
+
+	results = append(results, NewValidationResult("ProcessingResult.ErrorMessages", actualType, expectedType))
+
+	return results
+}
+
+// Benchmark helper methods
+
+func (s *ValidationServer) benchmarkValueSliceIteration(ctx context.Context, iterations int, data []v1.DataPoint) *v1.BenchmarkResult {
+	done := ctx.Done()
+	start := s.clock.Now()
+	for i := 0; i < iterations && !stageCanceled(done); i++ {
 		sum := float64(0)
 		for _, dp := range data {
 			sum += dp.Value
 		}
 		_ = sum
 	}
-	duration := time.Since(start)
+	duration := s.clock.Since(start)
 
 	return &v1.BenchmarkResult{
 		Name:                "ValueSlice_Iteration",
 		DurationNs:          float64(duration.Nanoseconds()),
 		Allocations:         0, // Value slice iteration should have minimal allocations
 		BytesAllocated:      0,
-		OperationsPerSecond: float64(iterations) / duration.Seconds(),
+		OperationsPerSecond: ratePerSecond(float64(iterations), duration),
 	}
 }
 
-func (s *ValidationServer) benchmarkPointerSliceIteration(iterations, dataSize int) *v1.BenchmarkResult {
-	// Create test data
-	data := make([]*v1.DataPoint, dataSize)
-	for i := 0; i < dataSize; i++ {
-		data[i] = &v1.DataPoint{
-			Id:        fmt.Sprintf("dp_%d", i),
-			Value:     float64(i) * 1.5,
-			Timestamp: int64(1000000 + i),
-		}
-	}
-
-	start := time.Now()
-	for i := 0; i < iterations; i++ {
+func (s *ValidationServer) benchmarkPointerSliceIteration(ctx context.Context, iterations int, data []*v1.DataPoint) *v1.BenchmarkResult {
+	done := ctx.Done()
+	start := s.clock.Now()
+	for i := 0; i < iterations && !stageCanceled(done); i++ {
 		sum := float64(0)
 		for _, dp := range data {
 			sum += dp.Value
 		}
 		_ = sum
 	}
-	duration := time.Since(start)
+	duration := s.clock.Since(start)
 
 	return &v1.BenchmarkResult{
 		Name:                "PointerSlice_Iteration",
 		DurationNs:          float64(duration.Nanoseconds()),
 		Allocations:         0, // Baseline comparison
 		BytesAllocated:      0,
-		OperationsPerSecond: float64(iterations) / duration.Seconds(),
+		OperationsPerSecond: ratePerSecond(float64(iterations), duration),
 	}
 }
 
-func (s *ValidationServer) benchmarkMemoryAllocation(iterations, dataSize int) *v1.BenchmarkResult {
-	start := time.Now()
-	for i := 0; i < iterations; i++ {
+func (s *ValidationServer) benchmarkMemoryAllocation(ctx context.Context, iterations, dataSize int) *v1.BenchmarkResult {
+	done := ctx.Done()
+	start := s.clock.Now()
+	for i := 0; i < iterations && !stageCanceled(done); i++ {
 		// Simulate memory allocation patterns
 		msg := &v1.PerformanceTestMessage{
 			ValueSliceData: make([]v1.DataPoint, dataSize),
 		}
 		_ = msg
 	}
-	duration := time.Since(start)
+	duration := s.clock.Since(start)
 
 	return &v1.BenchmarkResult{
 		Name:                "Memory_Allocation",
 		DurationNs:          float64(duration.Nanoseconds()),
 		Allocations:         int64(iterations), // One allocation per iteration
 		BytesAllocated:      int64(iterations * dataSize * 64), // Estimate
-		OperationsPerSecond: float64(iterations) / duration.Seconds(),
+		OperationsPerSecond: ratePerSecond(float64(iterations), duration),
 	}
 }
 
-func (s *ValidationServer) benchmarkSerialization(iterations, dataSize int) *v1.BenchmarkResult {
-	// Create test message
-	msg := &v1.PerformanceTestMessage{
-		ValueSliceData: make([]v1.DataPoint, dataSize),
-	}
-	for i := 0; i < dataSize; i++ {
-		msg.ValueSliceData[i] = v1.DataPoint{
-			Id:        fmt.Sprintf("dp_%d", i),
-			Value:     float64(i),
-			Timestamp: int64(i),
-		}
+func (s *ValidationServer) benchmarkSerialization(ctx context.Context, iterations int, msg *v1.PerformanceTestMessage, opts proto.MarshalOptions) *v1.BenchmarkResult {
+	name := "Serialization"
+	if opts.Deterministic {
+		name = "Serialization_Deterministic"
 	}
 
-	start := time.Now()
+	done := ctx.Done()
+	start := s.clock.Now()
 	var totalBytes int64
-	for i := 0; i < iterations; i++ {
-		data, err := proto.Marshal(msg)
-		if err == nil {
-			totalBytes += int64(len(data))
+	for i := 0; i < iterations && !stageCanceled(done); i++ {
+		data, err := opts.Marshal(msg)
+		if err != nil {
+			return &v1.BenchmarkResult{
+				Name:  name,
+				Error: fmt.Sprintf("marshal failed: %v", err),
+			}
 		}
-		_ = data
+		totalBytes += int64(len(data))
 	}
-	duration := time.Since(start)
+	duration := s.clock.Since(start)
 
 	return &v1.BenchmarkResult{
-		Name:                "Serialization",
+		Name:                name,
 		DurationNs:          float64(duration.Nanoseconds()),
 		Allocations:         int64(iterations),
 		BytesAllocated:      totalBytes,
-		OperationsPerSecond: float64(iterations) / duration.Seconds(),
+		OperationsPerSecond: ratePerSecond(float64(iterations), duration),
+	}
+}
+
+// benchmarkDeserialization times unmarshaling data, reporting the rate of the
+// runs that completed. Allocations are read from the process-wide
+// runtime.MemStats, so they include whatever else allocates meanwhile: other
+// stages in parallel mode and concurrent RPCs inflate them.
+func (s *ValidationServer) benchmarkDeserialization(ctx context.Context, iterations int, data []byte) *v1.BenchmarkResult {
+	var before, after runtime.MemStats
+	runtime.ReadMemStats(&before)
+
+	done := ctx.Done()
+	start := s.clock.Now()
+	var completed int
+	var unmarshalErr error
+	for ; completed < iterations && !stageCanceled(done); completed++ {
+		decoded := &v1.ValidationTestMessage{}
+		if unmarshalErr = proto.Unmarshal(data, decoded); unmarshalErr != nil {
+			break
+		}
+	}
+	duration := s.clock.Since(start)
+
+	runtime.ReadMemStats(&after)
+
+	result := &v1.BenchmarkResult{
+		Name:                "Deserialization",
+		DurationNs:          float64(duration.Nanoseconds()),
+		Allocations:         int64(after.Mallocs - before.Mallocs),
+		BytesAllocated:      int64(after.TotalAlloc - before.TotalAlloc),
+		OperationsPerSecond: ratePerSecond(float64(completed), duration),
+	}
+	if unmarshalErr != nil {
+		result.Error = fmt.Sprintf("unmarshal failed after %d of %d runs: %v", completed, iterations, unmarshalErr)
 	}
+	return result
 }
 
+// calculateBenchmarkSummary compares the iteration stages. Unless both ran
+// successfully, as when benchmark_names selects only one or neither, there is
+// no comparison: computed is false and the ratio fields are left unset.
 func (s *ValidationServer) calculateBenchmarkSummary(results []*v1.BenchmarkResult) *v1.BenchmarkSummary {
 	var valueSliceDuration, pointerSliceDuration float64
+	var valueSliceStddev, pointerSliceStddev float64
+	var valueSliceRan, pointerSliceRan bool
 	var memoryUsage int64
 
 	for _, result := range results {
 		switch result.Name {
 		case "ValueSlice_Iteration":
 			valueSliceDuration = result.DurationNs
+			valueSliceStddev = result.DurationNsStddev
+			valueSliceRan = result.Error == ""
 		case "PointerSlice_Iteration":
 			pointerSliceDuration = result.DurationNs
+			pointerSliceStddev = result.DurationNsStddev
+			pointerSliceRan = result.Error == ""
 		case "Memory_Allocation", "Serialization":
 			memoryUsage += result.BytesAllocated
 		}
 	}
 
-	// Calculate performance improvement ratio
+	summary := &v1.BenchmarkSummary{
+		ValueSliceAvgDuration:   valueSliceDuration,
+		PointerSliceAvgDuration: pointerSliceDuration,
+		MemorySavingsBytes:      memoryUsage,
+	}
+	if !valueSliceRan || !pointerSliceRan {
+		return summary
+	}
+
+	// Calculate performance improvement ratio. Without two positive durations
+	// there is nothing to compare, so the ratio is reported as 1 (no change).
 	improvementRatio := float64(1.0)
 	if pointerSliceDuration > 0 && valueSliceDuration > 0 {
 		improvementRatio = pointerSliceDuration / valueSliceDuration
 	}
 
-	return &v1.BenchmarkSummary{
-		ValueSliceAvgDuration:        valueSliceDuration,
-		PointerSliceAvgDuration:      pointerSliceDuration,
-		PerformanceImprovementRatio:  improvementRatio,
-		MemorySavingsBytes:           memoryUsage,
-	}
+	summary.Computed = true
+	summary.PerformanceImprovementRatio = improvementRatio
+	summary.PerformanceImprovementRatioStddev = ratioStddev(pointerSliceDuration, pointerSliceStddev, valueSliceDuration, valueSliceStddev)
+	return summary
 }
 
 // Utility functions
 
-func (s *ValidationServer) validateTestMessage(msg *v1.ValidationTestMessage) bool {
+// validateTestMessage returns the fields of msg that failed validation, or
+// nil when it is valid
+func (s *ValidationServer) validateTestMessage(msg *v1.ValidationTestMessage) []*v1.FieldError {
 	if msg == nil {
-		return false
+		return []*v1.FieldError{{
+			FieldPath:    "test_data",
+			ExpectedType: "validation.v1.ValidationTestMessage",
+			ActualType:   "<nil>",
+			Description:  "test_data is required",
+		}}
 	}
-	
+
+	var fieldErrors []*v1.FieldError
+
 	// Basic validation - check that fields have expected types
-	valueSliceType := reflect.TypeOf(msg.ValueSliceData).String()
-	pointerSliceType := reflect.TypeOf(msg.PointerSliceData).String()
-	
-	return valueSliceType == "[]v1.DataPoint" && pointerSliceType == "[]*v1.DataPoint"
+	for _, check := range []struct {
+		path, actualType, expectedType string
+	}{
+		{"test_data.value_slice_data", SafeTypeString(msg.ValueSliceData), "[]v1.DataPoint"},
+		{"test_data.pointer_slice_data", SafeTypeString(msg.PointerSliceData), "[]*v1.DataPoint"},
+	} {
+		if check.actualType != check.expectedType {
+			fieldErrors = append(fieldErrors, &v1.FieldError{
+				FieldPath:    check.path,
+				ExpectedType: check.expectedType,
+				ActualType:   check.actualType,
+				Description:  getErrorMessage(check.actualType, check.expectedType),
+			})
+		}
+	}
+
+	// Fields sent with the wrong wire type are kept as unknown fields
+	return append(fieldErrors, unknownFieldErrors("test_data", msg)...)
+}
+
+// InvalidTypeString is reported by SafeTypeString when a type cannot be described
+const InvalidTypeString = "<invalid type>"
+
+// SafeTypeString returns the reflected type name of v, or InvalidTypeString if
+// v is a nil interface (a field that no longer exists), so a schema change
+// degrades to a failed validation result instead of panicking the RPC
+func SafeTypeString(v any) string {
+	t := reflect.TypeOf(v)
+	if t == nil {
+		return InvalidTypeString
+	}
+	return t.String()
+}
+
+// NewValidationResult builds a ValidationResult whose severity is classified from
+// the actual and expected type strings. Passed is derived from the severity.
+func NewValidationResult(scenario, actualType, expectedType string) *v1.ValidationResult {
+	severity := classifySeverity(actualType, expectedType)
+
+	return &v1.ValidationResult{
+		Scenario:     scenario,
+		Passed:       severity != v1.Severity_SEVERITY_ERROR,
+		ErrorMessage: getErrorMessage(actualType, expectedType),
+		ExpectedType: expectedType,
+		ActualType:   actualType,
+		Severity:     severity,
+	}
+}
+
+// classifySeverity grades a type mismatch. A match is INFO; the same type under
+// another package qualifier (an expectation written with a different import
+// alias) is WARNING since it is compatible; anything else, such as a wrong
+// slice element type or representation, is ERROR.
+func classifySeverity(actual, expected string) v1.Severity {
+	if actual == expected {
+		return v1.Severity_SEVERITY_INFO
+	}
+
+	if unqualifiedType(actual) == unqualifiedType(expected) {
+		return v1.Severity_SEVERITY_WARNING
+	}
+
+	return v1.Severity_SEVERITY_ERROR
+}
+
+// unqualifiedType drops the package qualifier from a type string, keeping
+// any slice and pointer prefix, so "[]*v1.Metadata" becomes "[]*Metadata"
+func unqualifiedType(typeStr string) string {
+	name := strings.TrimLeft(typeStr, "[]*")
+	prefix := typeStr[:len(typeStr)-len(name)]
+	if i := strings.LastIndex(name, "."); i >= 0 {
+		name = name[i+1:]
+	}
+	return prefix + name
 }
 
 func getErrorMessage(actual, expected string) string {
@@ -390,10 +1143,25 @@ func getErrorMessage(actual, expected string) string {
 	return ""
 }
 
+// containsValueSlice reports whether typeStr is a slice of generated message
+// structs. Repeated scalars such as []string are slices of values too, but
+// are not what the plugin transforms.
 func containsValueSlice(typeStr string) bool {
-	return typeStr[:2] == "[]" && typeStr[2] != '*'
+	elem, ok := strings.CutPrefix(typeStr, "[]")
+	return ok && messageTypeNames()[elem]
 }
 
+// messageTypeNames holds the Go type name, as reflect prints it, of every
+// linked-in generated message
+var messageTypeNames = sync.OnceValue(func() map[string]bool {
+	names := make(map[string]bool)
+	protoregistry.GlobalTypes.RangeMessages(func(mt protoreflect.MessageType) bool {
+		names[reflect.TypeOf(mt.Zero().Interface()).Elem().String()] = true
+		return true
+	})
+	return names
+})
+
 func containsPointerSlice(typeStr string) bool {
-	return typeStr[:3] == "[]*"
+	return len(typeStr) > 3 && typeStr[:3] == "[]*"
 }
\ No newline at end of file
@@ -0,0 +1,198 @@
+package validation
+
+import (
+	"context"
+	"math"
+	"testing"
+	"time"
+
+	v1 "github.com/benjamin-rood/protogo-values-validation-demo/gen/api/validation/v1"
+	"github.com/benjamin-rood/protogo-values-validation-demo/internal/server"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/health"
+	"google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+const testAdminSecret = "chaos-secret"
+
+// setupAdminTestServer creates an in-memory gRPC server with health and admin services
+func setupAdminTestServer(t *testing.T) (*grpc.ClientConn, func()) {
+	return setupCustomTestServer(t, func(s *grpc.Server) {
+		validationServer := server.NewValidationServer()
+		v1.RegisterValidationServiceServer(s, validationServer)
+
+		healthServer := health.NewServer()
+		grpc_health_v1.RegisterHealthServer(s, healthServer)
+		healthServer.SetServingStatus("validation.v1.ValidationService", grpc_health_v1.HealthCheckResponse_SERVING)
+
+		v1.RegisterAdminServiceServer(s, server.NewAdminServer(testAdminSecret, healthServer, validationServer))
+	})
+}
+
+// TestAdminServingStatusToggle tests flipping the health status via the admin service
+func TestAdminServingStatusToggle(t *testing.T) {
+	conn, cleanup := setupAdminTestServer(t)
+	defer cleanup()
+
+	admin := v1.NewAdminServiceClient(conn)
+	healthClient := grpc_health_v1.NewHealthClient(conn)
+	validation := v1.NewValidationServiceClient(conn)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	authCtx := metadata.AppendToOutgoingContext(ctx, server.AdminSecretHeader, testAdminSecret)
+	healthReq := &grpc_health_v1.HealthCheckRequest{Service: "validation.v1.ValidationService"}
+
+	t.Run("RejectsMissingSecret", func(t *testing.T) {
+		_, err := admin.SetServingStatus(ctx, &v1.SetServingStatusRequest{Serving: false})
+		if status.Code(err) != codes.Unauthenticated {
+			t.Errorf("Expected Unauthenticated, got %v", err)
+		}
+	})
+
+	t.Run("RejectsWrongSecret", func(t *testing.T) {
+		badCtx := metadata.AppendToOutgoingContext(ctx, server.AdminSecretHeader, "wrong")
+		_, err := admin.SetServingStatus(badCtx, &v1.SetServingStatusRequest{Serving: false})
+		if status.Code(err) != codes.PermissionDenied {
+			t.Errorf("Expected PermissionDenied, got %v", err)
+		}
+	})
+
+	t.Run("ToggleNotServingAndBack", func(t *testing.T) {
+		if _, err := admin.SetServingStatus(authCtx, &v1.SetServingStatusRequest{Serving: false}); err != nil {
+			t.Fatalf("SetServingStatus failed: %v", err)
+		}
+
+		resp, err := healthClient.Check(ctx, healthReq)
+		if err != nil {
+			t.Fatalf("Health check failed: %v", err)
+		}
+		if resp.Status != grpc_health_v1.HealthCheckResponse_NOT_SERVING {
+			t.Errorf("Expected NOT_SERVING, got %v", resp.Status)
+		}
+
+		if _, err := admin.SetServingStatus(authCtx, &v1.SetServingStatusRequest{Serving: true}); err != nil {
+			t.Fatalf("SetServingStatus failed: %v", err)
+		}
+
+		resp, err = healthClient.Check(ctx, healthReq)
+		if err != nil {
+			t.Fatalf("Health check failed: %v", err)
+		}
+		if resp.Status != grpc_health_v1.HealthCheckResponse_SERVING {
+			t.Errorf("Expected SERVING, got %v", resp.Status)
+		}
+	})
+
+	t.Run("ValidateTypesUnavailableForDuration", func(t *testing.T) {
+		_, err := admin.SetServingStatus(authCtx, &v1.SetServingStatusRequest{
+			Serving:               true,
+			UnavailableDurationMs: 200,
+		})
+		if err != nil {
+			t.Fatalf("SetServingStatus failed: %v", err)
+		}
+
+		_, err = validation.ValidateTypes(ctx, &v1.ValidateTypesRequest{TestScenarios: []string{server.ScenarioValidationTestMessage}})
+		if status.Code(err) != codes.Unavailable {
+			t.Errorf("Expected Unavailable, got %v", err)
+		}
+
+		time.Sleep(250 * time.Millisecond)
+
+		if _, err := validation.ValidateTypes(ctx, &v1.ValidateTypesRequest{TestScenarios: []string{server.ScenarioValidationTestMessage}}); err != nil {
+			t.Errorf("Expected ValidateTypes to recover after the window, got %v", err)
+		}
+	})
+}
+
+// TestAdminUnavailableDurationBounds tests the limits on the unavailability window
+func TestAdminUnavailableDurationBounds(t *testing.T) {
+	conn, cleanup := setupAdminTestServer(t)
+	defer cleanup()
+
+	admin := v1.NewAdminServiceClient(conn)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	authCtx := metadata.AppendToOutgoingContext(ctx, server.AdminSecretHeader, testAdminSecret)
+
+	maxMs := server.MaxUnavailableDuration.Milliseconds()
+	tests := []struct {
+		durationMs int64
+		code       codes.Code
+	}{
+		{-1, codes.InvalidArgument},
+		{maxMs, codes.OK},
+		{maxMs + 1, codes.InvalidArgument},
+		// Would overflow time.Duration if converted
+		{math.MaxInt64 / 1000, codes.InvalidArgument},
+	}
+	for _, tt := range tests {
+		_, err := admin.SetServingStatus(authCtx, &v1.SetServingStatusRequest{Serving: true, UnavailableDurationMs: tt.durationMs})
+		if status.Code(err) != tt.code {
+			t.Errorf("Expected %v for %dms, got %v", tt.code, tt.durationMs, err)
+		}
+	}
+}
+
+// TestAdminClearCache tests flushing the ValidateTypes result cache
+func TestAdminClearCache(t *testing.T) {
+	validationServer := server.NewValidationServer()
+	conn, cleanup := setupCustomTestServer(t, func(s *grpc.Server) {
+		v1.RegisterValidationServiceServer(s, validationServer)
+		v1.RegisterAdminServiceServer(s, server.NewAdminServer(testAdminSecret, health.NewServer(), validationServer))
+	})
+	defer cleanup()
+
+	admin := v1.NewAdminServiceClient(conn)
+	validation := v1.NewValidationServiceClient(conn)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	authCtx := metadata.AppendToOutgoingContext(ctx, server.AdminSecretHeader, testAdminSecret)
+
+	// Populate two entries and hit one of them
+	for _, scenarios := range [][]string{{server.ScenarioValidationTestMessage}, {server.ScenarioPerformanceTestMessage}, {server.ScenarioValidationTestMessage}} {
+		if _, err := validation.ValidateTypes(ctx, &v1.ValidateTypesRequest{TestScenarios: scenarios}); err != nil {
+			t.Fatalf("ValidateTypes failed: %v", err)
+		}
+	}
+	if misses, hits := validationServer.CacheMisses(), validationServer.CacheHits(); misses != 2 || hits != 1 {
+		t.Fatalf("Expected 2 misses and 1 hit, got %d and %d", misses, hits)
+	}
+
+	if _, err := admin.ClearCache(ctx, &v1.ClearCacheRequest{}); status.Code(err) != codes.Unauthenticated {
+		t.Errorf("Expected Unauthenticated without the secret, got %v", err)
+	}
+
+	resp, err := admin.ClearCache(authCtx, &v1.ClearCacheRequest{})
+	if err != nil {
+		t.Fatalf("ClearCache failed: %v", err)
+	}
+	if resp.Evicted != 2 {
+		t.Errorf("Expected 2 evicted entries, got %d", resp.Evicted)
+	}
+
+	// The next call recomputes rather than hitting the cache
+	if _, err := validation.ValidateTypes(ctx, &v1.ValidateTypesRequest{TestScenarios: []string{server.ScenarioValidationTestMessage}}); err != nil {
+		t.Fatalf("ValidateTypes failed: %v", err)
+	}
+	if misses, hits := validationServer.CacheMisses(), validationServer.CacheHits(); misses != 3 || hits != 1 {
+		t.Errorf("Expected the cleared entry to be recomputed (3 misses, 1 hit), got %d misses and %d hits", misses, hits)
+	}
+
+	resp, err = admin.ClearCache(authCtx, &v1.ClearCacheRequest{})
+	if err != nil {
+		t.Fatalf("ClearCache failed: %v", err)
+	}
+	if resp.Evicted != 1 {
+		t.Errorf("Expected 1 evicted entry, got %d", resp.Evicted)
+	}
+}
@@ -0,0 +1,139 @@
+package validation
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	v1 "github.com/benjamin-rood/protogo-values-validation-demo/gen/api/validation/v1"
+	"github.com/benjamin-rood/protogo-values-validation-demo/internal/server"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
+)
+
+func TestBatchValidateTypes(t *testing.T) {
+	validationServer := server.NewValidationServer()
+
+	conn, cleanup := setupCustomTestServer(t, func(s *grpc.Server) {
+		v1.RegisterValidationServiceServer(s, validationServer)
+	})
+	defer cleanup()
+	client := v1.NewValidationServiceClient(conn)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	requests := []*v1.ValidateTypesRequest{
+		{TestScenarios: []string{server.ScenarioValidationTestMessage}},
+		{TestScenarios: []string{server.ScenarioScalarSlices}, DeepValidation: true},
+		{TestScenarios: []string{server.ScenarioValidationTestMessage, server.ScenarioPerformanceTestMessage}, DeepValidation: true},
+	}
+
+	t.Run("OrderedIndependentResponses", func(t *testing.T) {
+		resp, err := client.BatchValidateTypes(ctx, &v1.BatchValidateTypesRequest{Requests: requests})
+		if err != nil {
+			t.Fatalf("BatchValidateTypes failed: %v", err)
+		}
+
+		if len(resp.Responses) != len(requests) {
+			t.Fatalf("Expected %d responses, got %d", len(requests), len(resp.Responses))
+		}
+
+		// Each slot must match what the sub-request returns on its own
+		for i, req := range requests {
+			single, err := client.ValidateTypes(ctx, req)
+			if err != nil {
+				t.Fatalf("ValidateTypes failed: %v", err)
+			}
+			if !proto.Equal(resp.Responses[i], single) {
+				t.Errorf("Response %d does not match its individual ValidateTypes result", i)
+			}
+			if !resp.Responses[i].Success {
+				t.Errorf("Expected response %d (%v) to succeed", i, req.TestScenarios)
+			}
+		}
+	})
+
+	t.Run("SubRequestErrorsDoNotAbort", func(t *testing.T) {
+		// The unknown scenario and the bad page size fail; the slots
+		// between and after them still run
+		mixed := []*v1.ValidateTypesRequest{
+			{TestScenarios: []string{server.ScenarioValidationTestMessage}},
+			{TestScenarios: []string{"unknown_scenario"}},
+			{TestScenarios: []string{server.ScenarioScalarSlices}, DeepValidation: true},
+			{TestScenarios: []string{server.ScenarioValidationTestMessage}, PageSize: -1},
+			{TestScenarios: []string{server.ScenarioPerformanceTestMessage}},
+		}
+		failing := map[int]string{
+			1: server.ErrUnknownScenario.Error(),
+			3: server.ErrInvalidPageSize.Error(),
+		}
+
+		resp, err := client.BatchValidateTypes(ctx, &v1.BatchValidateTypesRequest{Requests: mixed})
+		if err != nil {
+			t.Fatalf("Expected batch to complete, got %v", err)
+		}
+
+		if len(resp.Responses) != len(mixed) {
+			t.Fatalf("Expected %d responses, got %d", len(mixed), len(resp.Responses))
+		}
+
+		for i, r := range resp.Responses {
+			wantErr, fails := failing[i]
+			if !fails {
+				single, err := client.ValidateTypes(ctx, mixed[i])
+				if err != nil {
+					t.Fatalf("ValidateTypes failed: %v", err)
+				}
+				if !r.Success || !proto.Equal(r, single) {
+					t.Errorf("Expected response %d to match its own successful result, got %v", i, r)
+				}
+				continue
+			}
+
+			if r.Success || len(r.Results) != 1 {
+				t.Errorf("Expected response %d to carry only its sub-request error, got %v", i, r)
+				continue
+			}
+			result := r.Results[0]
+			if result.Scenario != fmt.Sprintf("batch[%d]", i) || result.Severity != v1.Severity_SEVERITY_ERROR {
+				t.Errorf("Expected an ERROR result for batch[%d], got %v", i, result)
+			}
+			if !strings.Contains(result.ErrorMessage, wantErr) {
+				t.Errorf("Expected response %d error to contain %q, got %q", i, wantErr, result.ErrorMessage)
+			}
+		}
+	})
+
+	t.Run("UnavailableFailsEverySlot", func(t *testing.T) {
+		validationServer.SetUnavailableFor(time.Minute)
+		defer validationServer.SetUnavailableFor(0)
+
+		resp, err := client.BatchValidateTypes(ctx, &v1.BatchValidateTypesRequest{Requests: requests})
+		if err != nil {
+			t.Fatalf("Expected batch to complete, got %v", err)
+		}
+
+		for i, r := range resp.Responses {
+			if r.Success || len(r.Results) != 1 || r.Results[0].Severity != v1.Severity_SEVERITY_ERROR {
+				t.Errorf("Expected response %d to carry the unavailable error, got %v", i, r)
+			}
+		}
+	})
+
+	t.Run("TooLarge", func(t *testing.T) {
+		oversized := make([]*v1.ValidateTypesRequest, 101)
+		for i := range oversized {
+			oversized[i] = &v1.ValidateTypesRequest{}
+		}
+
+		_, err := client.BatchValidateTypes(ctx, &v1.BatchValidateTypesRequest{Requests: oversized})
+		if status.Code(err) != codes.InvalidArgument {
+			t.Errorf("Expected InvalidArgument, got %v", err)
+		}
+	})
+}
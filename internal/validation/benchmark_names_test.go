@@ -0,0 +1,72 @@
+package validation
+
+import (
+	"context"
+	"testing"
+
+	v1 "github.com/benjamin-rood/protogo-values-validation-demo/gen/api/validation/v1"
+	"github.com/benjamin-rood/protogo-values-validation-demo/internal/server"
+)
+
+func TestRunBenchmarksNamesSelectStages(t *testing.T) {
+	resp, err := server.NewValidationServer().RunBenchmarks(context.Background(), &v1.BenchmarkRequest{
+		Iterations:     10,
+		DataSize:       10,
+		BenchmarkNames: []string{"value_slice", "PointerSlice_Iteration"},
+	})
+	if err != nil {
+		t.Fatalf("RunBenchmarks failed: %v", err)
+	}
+
+	var names []string
+	for _, result := range resp.Results {
+		names = append(names, result.Name)
+	}
+	if len(names) != 2 || names[0] != "ValueSlice_Iteration" || names[1] != "PointerSlice_Iteration" {
+		t.Errorf("Expected only the iteration stages, got %v", names)
+	}
+	if !resp.Summary.Computed {
+		t.Error("Expected the summary to be computed when both iteration stages ran")
+	}
+	if resp.Summary.PerformanceImprovementRatio <= 0 {
+		t.Errorf("Expected a positive ratio, got %v", resp.Summary.PerformanceImprovementRatio)
+	}
+}
+
+func TestRunBenchmarksNamesMatchNothing(t *testing.T) {
+	resp, err := server.NewValidationServer().RunBenchmarks(context.Background(), &v1.BenchmarkRequest{
+		Iterations:     10,
+		DataSize:       10,
+		BenchmarkNames: []string{"no_such_benchmark"},
+	})
+	if err != nil {
+		t.Fatalf("RunBenchmarks failed: %v", err)
+	}
+
+	if len(resp.Results) != 0 {
+		t.Errorf("Expected no results, got %d", len(resp.Results))
+	}
+	if resp.Summary.Computed {
+		t.Error("Expected the summary not to be computed without benchmarks to compare")
+	}
+	if resp.Summary.PerformanceImprovementRatio != 0 || resp.Summary.PerformanceImprovementRatioStddev != 0 {
+		t.Errorf("Expected the ratio fields to be unset, got %v ± %v",
+			resp.Summary.PerformanceImprovementRatio, resp.Summary.PerformanceImprovementRatioStddev)
+	}
+}
+
+func TestRunBenchmarksNamesOneSide(t *testing.T) {
+	resp, err := server.NewValidationServer().RunBenchmarks(context.Background(), &v1.BenchmarkRequest{
+		Iterations:     10,
+		DataSize:       10,
+		BenchmarkNames: []string{"value_slice"},
+	})
+	if err != nil {
+		t.Fatalf("RunBenchmarks failed: %v", err)
+	}
+
+	if resp.Summary.Computed || resp.Summary.PerformanceImprovementRatio != 0 {
+		t.Errorf("Expected no ratio with only the value-slice stage, got computed=%v ratio=%v",
+			resp.Summary.Computed, resp.Summary.PerformanceImprovementRatio)
+	}
+}
@@ -0,0 +1,56 @@
+package validation
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	v1 "github.com/benjamin-rood/protogo-values-validation-demo/gen/api/validation/v1"
+	"github.com/benjamin-rood/protogo-values-validation-demo/internal/server"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func TestRunBenchmarksReportsSetupDuration(t *testing.T) {
+	validationServer := server.NewValidationServer()
+
+	resp, err := validationServer.RunBenchmarks(context.Background(), &v1.BenchmarkRequest{Iterations: 1, DataSize: 1000})
+	if err != nil {
+		t.Fatalf("RunBenchmarks failed: %v", err)
+	}
+	if resp.SetupDurationNs <= 0 {
+		t.Errorf("Expected a positive setup duration, got %d", resp.SetupDurationNs)
+	}
+}
+
+func TestRunBenchmarksSetupCancellation(t *testing.T) {
+	// Generating this many elements takes seconds, far past the deadline
+	const dataSize = 5_000_000
+	validationServer := server.NewValidationServer(server.WithMaxDataSize(dataSize))
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+
+	start := time.Now()
+	_, err := validationServer.RunBenchmarks(ctx, &v1.BenchmarkRequest{Iterations: 1, DataSize: dataSize})
+	elapsed := time.Since(start)
+
+	if status.Code(err) != codes.DeadlineExceeded {
+		t.Errorf("Expected DeadlineExceeded, got %v", err)
+	}
+	if elapsed > 500*time.Millisecond {
+		t.Errorf("Expected setup to stop promptly after the deadline, took %v", elapsed)
+	}
+}
+
+func TestRunBenchmarksCanceledBeforeSetup(t *testing.T) {
+	validationServer := server.NewValidationServer()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	_, err := validationServer.RunBenchmarks(ctx, &v1.BenchmarkRequest{Iterations: 1, DataSize: 10})
+	if status.Code(err) != codes.Canceled {
+		t.Errorf("Expected Canceled, got %v", err)
+	}
+}
@@ -0,0 +1,166 @@
+package validation
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	v1 "github.com/benjamin-rood/protogo-values-validation-demo/gen/api/validation/v1"
+	"github.com/benjamin-rood/protogo-values-validation-demo/internal/server"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// gatedClock measures free durations until its first free measurements are
+// used, then blocks each measurement until open is closed
+type gatedClock struct {
+	mu   sync.Mutex
+	free int
+	open chan struct{}
+}
+
+func (c *gatedClock) Now() time.Time { return time.Unix(0, 0) }
+
+func (c *gatedClock) Since(time.Time) time.Duration {
+	c.mu.Lock()
+	gated := c.free == 0
+	if !gated {
+		c.free--
+	}
+	c.mu.Unlock()
+
+	if gated {
+		<-c.open
+	}
+	return time.Microsecond
+}
+
+// startBenchmarkStream opens StreamBenchmarks against validationServer and
+// sends the start message
+func startBenchmarkStream(t *testing.T, validationServer *server.ValidationServer, req *v1.BenchmarkRequest) v1.ValidationService_StreamBenchmarksClient {
+	t.Helper()
+
+	conn, cleanup := setupCustomTestServer(t, func(s *grpc.Server) {
+		v1.RegisterValidationServiceServer(s, validationServer)
+	})
+	t.Cleanup(cleanup)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	t.Cleanup(cancel)
+
+	stream, err := v1.NewValidationServiceClient(conn).StreamBenchmarks(ctx)
+	if err != nil {
+		t.Fatalf("StreamBenchmarks failed: %v", err)
+	}
+	if err := stream.Send(&v1.BenchmarkControl{Control: &v1.BenchmarkControl_Start{Start: req}}); err != nil {
+		t.Fatalf("Send start failed: %v", err)
+	}
+	return stream
+}
+
+// receiveBenchmarkEvents reads results until the final response
+func receiveBenchmarkEvents(t *testing.T, stream v1.ValidationService_StreamBenchmarksClient) ([]*v1.BenchmarkResult, *v1.BenchmarkResponse) {
+	t.Helper()
+
+	var results []*v1.BenchmarkResult
+	for {
+		event, err := stream.Recv()
+		if err != nil {
+			t.Fatalf("Recv failed: %v", err)
+		}
+		if final := event.GetFinal(); final != nil {
+			return results, final
+		}
+		results = append(results, event.GetResult())
+	}
+}
+
+func TestStreamBenchmarksRunsEveryStage(t *testing.T) {
+	stream := startBenchmarkStream(t, server.NewValidationServer(), &v1.BenchmarkRequest{Iterations: 10, DataSize: 10})
+
+	results, final := receiveBenchmarkEvents(t, stream)
+	if final.Truncated {
+		t.Error("Expected a run without a stop not to be truncated")
+	}
+	if len(results) != 5 || len(final.Results) != len(results) {
+		t.Fatalf("Expected 5 streamed results matching the final response, got %d and %d", len(results), len(final.Results))
+	}
+	for i, result := range results {
+		if result.Name != final.Results[i].Name {
+			t.Errorf("Expected result %d to be %s, got %s", i, final.Results[i].Name, result.Name)
+		}
+		if result.OperationsPerSecondHuman == "" {
+			t.Errorf("Expected %s to carry a human-readable rate", result.Name)
+		}
+	}
+}
+
+func TestStreamBenchmarksStop(t *testing.T) {
+	// The setup and first stage measure freely; the second stage holds until
+	// the stop has been sent
+	clock := &gatedClock{free: 2, open: make(chan struct{})}
+	stream := startBenchmarkStream(t, server.NewValidationServer(server.WithClock(clock)),
+		&v1.BenchmarkRequest{Iterations: 10, DataSize: 10})
+
+	first, err := stream.Recv()
+	if err != nil {
+		t.Fatalf("Recv failed: %v", err)
+	}
+	if first.GetResult().GetName() != "ValueSlice_Iteration" {
+		t.Fatalf("Expected the first stage's result, got %v", first)
+	}
+
+	if err := stream.Send(&v1.BenchmarkControl{Control: &v1.BenchmarkControl_Stop{Stop: &v1.StopBenchmarks{}}}); err != nil {
+		t.Fatalf("Send stop failed: %v", err)
+	}
+	// Give the server time to read the stop before the second stage finishes
+	time.Sleep(50 * time.Millisecond)
+	close(clock.open)
+
+	results, final := receiveBenchmarkEvents(t, stream)
+	if !final.Success || !final.Truncated {
+		t.Errorf("Expected a successful truncated run, got success=%v truncated=%v", final.Success, final.Truncated)
+	}
+	// The stage in progress when the stop arrived still completes
+	if len(results) != 1 || results[0].Name != "PointerSlice_Iteration" {
+		t.Fatalf("Expected only the in-progress stage after the stop, got %v", results)
+	}
+	if len(final.Results) != 2 {
+		t.Errorf("Expected the final response to cover 2 stages, got %d", len(final.Results))
+	}
+	if final.Summary == nil {
+		t.Error("Expected a partial summary")
+	}
+}
+
+func TestStreamBenchmarksRequiresStart(t *testing.T) {
+	conn, cleanup := setupCustomTestServer(t, func(s *grpc.Server) {
+		v1.RegisterValidationServiceServer(s, server.NewValidationServer())
+	})
+	defer cleanup()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	tests := []struct {
+		name  string
+		first *v1.BenchmarkControl
+	}{
+		{"stop first", &v1.BenchmarkControl{Control: &v1.BenchmarkControl_Stop{Stop: &v1.StopBenchmarks{}}}},
+		{"invalid start", &v1.BenchmarkControl{Control: &v1.BenchmarkControl_Start{Start: &v1.BenchmarkRequest{}}}},
+	}
+	for _, tt := range tests {
+		stream, err := v1.NewValidationServiceClient(conn).StreamBenchmarks(ctx)
+		if err != nil {
+			t.Fatalf("StreamBenchmarks failed: %v", err)
+		}
+		if err := stream.Send(tt.first); err != nil {
+			t.Fatalf("%s: Send failed: %v", tt.name, err)
+		}
+		if _, err := stream.Recv(); status.Code(err) != codes.InvalidArgument {
+			t.Errorf("%s: expected InvalidArgument, got %v", tt.name, err)
+		}
+	}
+}
@@ -1,10 +1,20 @@
 package validation
 
 import (
+	"bytes"
+	"encoding/csv"
+	"flag"
 	"fmt"
+	"io"
+	"os"
+	"reflect"
+	"strconv"
+	"sync"
 	"testing"
+	"time"
 
 	v1 "github.com/benjamin-rood/protogo-values-validation-demo/gen/api/validation/v1"
+	"github.com/benjamin-rood/protogo-values-validation-demo/internal/convert"
 	"google.golang.org/protobuf/proto"
 )
 
@@ -217,6 +227,1206 @@ func BenchmarkSerializationPerformance(b *testing.B) {
 	})
 }
 
+// BenchmarkDeterministicSerialization compares deterministic and default marshal cost
+func BenchmarkDeterministicSerialization(b *testing.B) {
+	// Metadata attribute maps are where ordering differs between runs
+	msg := &v1.PerformanceTestMessage{
+		PointerSliceData: createMetadataPointers(mediumDataSize),
+	}
+
+	b.Run("Default_Marshal", func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			data, err := proto.Marshal(msg)
+			if err != nil {
+				b.Fatal(err)
+			}
+			_ = data
+		}
+	})
+
+	b.Run("Deterministic_Marshal", func(b *testing.B) {
+		opts := proto.MarshalOptions{Deterministic: true}
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			data, err := opts.Marshal(msg)
+			if err != nil {
+				b.Fatal(err)
+			}
+			_ = data
+		}
+	})
+}
+
+// TestDeterministicMarshalStable verifies deterministic marshaling is byte-identical across calls
+func TestDeterministicMarshalStable(t *testing.T) {
+	msg := &v1.PerformanceTestMessage{
+		PointerSliceData: createMetadataPointers(smallDataSize),
+	}
+	opts := proto.MarshalOptions{Deterministic: true}
+
+	first, err := opts.Marshal(msg)
+	if err != nil {
+		t.Fatalf("Deterministic marshal failed: %v", err)
+	}
+
+	for i := 0; i < 20; i++ {
+		data, err := opts.Marshal(msg)
+		if err != nil {
+			t.Fatalf("Deterministic marshal failed: %v", err)
+		}
+		if !bytes.Equal(first, data) {
+			t.Fatalf("Deterministic marshal output differed on call %d", i+1)
+		}
+	}
+}
+
+// BenchmarkDeserializationPerformance tests protobuf deserialization into pointer-slice messages
+func BenchmarkDeserializationPerformance(b *testing.B) {
+	// Value-slice messages cannot be marshaled, so both payloads use pointer slices
+	dataPointMsg := &v1.ValidationTestMessage{
+		PointerSliceData: createDataPointPointers(mediumDataSize),
+	}
+	metadataMsg := &v1.PerformanceTestMessage{
+		PointerSliceData: createMetadataPointers(mediumDataSize),
+	}
+
+	dataPointBytes, err := proto.Marshal(dataPointMsg)
+	if err != nil {
+		b.Fatal(err)
+	}
+	metadataBytes, err := proto.Marshal(metadataMsg)
+	if err != nil {
+		b.Fatal(err)
+	}
+
+	b.Run("DataPointPointers_Unmarshal", func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			decoded := &v1.ValidationTestMessage{}
+			if err := proto.Unmarshal(dataPointBytes, decoded); err != nil {
+				b.Fatal(err)
+			}
+		}
+	})
+
+	b.Run("MetadataPointers_Unmarshal", func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			decoded := &v1.PerformanceTestMessage{}
+			if err := proto.Unmarshal(metadataBytes, decoded); err != nil {
+				b.Fatal(err)
+			}
+		}
+	})
+}
+
+// TestDeserializationRoundTrip verifies unmarshaled messages equal the originals
+func TestDeserializationRoundTrip(t *testing.T) {
+	tests := []struct {
+		name     string
+		original proto.Message
+		decoded  proto.Message
+	}{
+		{
+			name: "ValidationTestMessage pointer slices",
+			original: &v1.ValidationTestMessage{
+				PointerSliceData: createDataPointPointers(smallDataSize),
+			},
+			decoded: &v1.ValidationTestMessage{},
+		},
+		{
+			name: "PerformanceTestMessage metadata pointers",
+			original: &v1.PerformanceTestMessage{
+				PointerSliceData: createMetadataPointers(smallDataSize),
+			},
+			decoded: &v1.PerformanceTestMessage{},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			data, err := proto.Marshal(tt.original)
+			if err != nil {
+				t.Fatalf("Marshal failed: %v", err)
+			}
+
+			if err := proto.Unmarshal(data, tt.decoded); err != nil {
+				t.Fatalf("Unmarshal failed: %v", err)
+			}
+
+			if !proto.Equal(tt.original, tt.decoded) {
+				t.Error("Unmarshaled message does not equal the original")
+			}
+		})
+	}
+}
+
+// BenchmarkAppend compares growing value slices and pointer slices via append.
+// Growing a []DataPoint copies whole structs on every reallocation, whereas a
+// []*DataPoint only copies pointers, so this is a case where value slices lose.
+func BenchmarkAppend(b *testing.B) {
+	dataSizes := []struct {
+		name string
+		size int
+	}{
+		{"Small", smallDataSize},
+		{"Medium", mediumDataSize},
+		{"Large", largeDataSize},
+	}
+
+	valueElemSize := int(reflect.TypeOf(v1.DataPoint{}).Size())
+	pointerElemSize := int(reflect.TypeOf(&v1.DataPoint{}).Size())
+
+	for _, ds := range dataSizes {
+		b.Run(fmt.Sprintf("DataSize_%s", ds.name), func(b *testing.B) {
+			b.Run("ValueSlice_Append", func(b *testing.B) {
+				source := createPerformanceTestMessage(ds.size).ValueSliceData
+				var copiedBytes int
+				b.ResetTimer()
+				b.ReportAllocs()
+
+				for i := 0; i < b.N; i++ {
+					var data []v1.DataPoint
+					for j := range source {
+						if len(data) == cap(data) {
+							copiedBytes += len(data) * valueElemSize
+						}
+						data = append(data, source[j])
+					}
+					_ = data
+				}
+
+				b.ReportMetric(float64(copiedBytes)/float64(b.N), "copied-B/op")
+			})
+
+			b.Run("PointerSlice_Append", func(b *testing.B) {
+				source := createDataPointPointers(ds.size)
+				var copiedBytes int
+				b.ResetTimer()
+				b.ReportAllocs()
+
+				for i := 0; i < b.N; i++ {
+					var data []*v1.DataPoint
+					for j := range source {
+						if len(data) == cap(data) {
+							copiedBytes += len(data) * pointerElemSize
+						}
+						data = append(data, source[j])
+					}
+					_ = data
+				}
+
+				b.ReportMetric(float64(copiedBytes)/float64(b.N), "copied-B/op")
+			})
+		})
+	}
+}
+
+// BenchmarkClone compares deep-copying value-slice and pointer-slice messages.
+// proto.Clone cannot reflect over value-slice fields, so the value-slice path
+// converts to a pointer-backed message first and that conversion is included in
+// the measurement; the pointer-slice path is a native proto.Clone.
+func BenchmarkClone(b *testing.B) {
+	dataSizes := []struct {
+		name string
+		size int
+	}{
+		{"Small", smallDataSize},
+		{"Medium", mediumDataSize},
+		{"Large", largeDataSize},
+	}
+
+	for _, ds := range dataSizes {
+		b.Run(fmt.Sprintf("DataSize_%s", ds.name), func(b *testing.B) {
+			b.Run("ValueSlice_ConvertThenClone", func(b *testing.B) {
+				msg := &v1.ValidationTestMessage{
+					ValueSliceData: createPerformanceTestMessage(ds.size).ValueSliceData,
+				}
+				b.ResetTimer()
+				b.ReportAllocs()
+
+				for i := 0; i < b.N; i++ {
+					converted, err := toPointerMessage(msg)
+					if err != nil {
+						b.Fatal(err)
+					}
+					_ = proto.Clone(converted)
+				}
+			})
+
+			b.Run("PointerSlice_NativeClone", func(b *testing.B) {
+				msg := &v1.ValidationTestMessage{
+					PointerSliceData: createDataPointPointers(ds.size),
+				}
+				b.ResetTimer()
+				b.ReportAllocs()
+
+				for i := 0; i < b.N; i++ {
+					_ = proto.Clone(msg)
+				}
+			})
+		})
+	}
+}
+
+// TestCloneEqualsOriginal verifies both clone paths used by BenchmarkClone
+func TestCloneEqualsOriginal(t *testing.T) {
+	t.Run("ValueSlice_ConvertThenClone", func(t *testing.T) {
+		msg := &v1.ValidationTestMessage{
+			ValueSliceData: createPerformanceTestMessage(smallDataSize).ValueSliceData,
+		}
+		converted, err := toPointerMessage(msg)
+		if err != nil {
+			t.Fatalf("Conversion failed: %v", err)
+		}
+		assertEquivalent(t, msg, proto.Clone(converted))
+	})
+
+	t.Run("PointerSlice_NativeClone", func(t *testing.T) {
+		msg := &v1.ValidationTestMessage{
+			PointerSliceData: createDataPointPointers(smallDataSize),
+		}
+		clone := proto.Clone(msg)
+		if !proto.Equal(msg, clone) {
+			t.Error("Expected clone to equal the original")
+		}
+	})
+}
+
+// boxSink keeps boxed values reachable so the compiler cannot elide boxing
+var boxSink any
+
+//go:noinline
+func consumeAny(v any) {
+	boxSink = v
+}
+
+// BenchmarkInterfaceBoxing passes each element through an any parameter.
+// Boxing a DataPoint copies the struct to the heap on every call, while a
+// *DataPoint fits in the interface word, so this is a case where value slices
+// pay an allocation per element.
+func BenchmarkInterfaceBoxing(b *testing.B) {
+	dataSizes := []struct {
+		name string
+		size int
+	}{
+		{"Small", smallDataSize},
+		{"Medium", mediumDataSize},
+		{"Large", largeDataSize},
+	}
+
+	for _, ds := range dataSizes {
+		b.Run(fmt.Sprintf("DataSize_%s", ds.name), func(b *testing.B) {
+			b.Run("ValueSlice_BoxElement", func(b *testing.B) {
+				data := createPerformanceTestMessage(ds.size).ValueSliceData
+				b.ResetTimer()
+				b.ReportAllocs()
+
+				for i := 0; i < b.N; i++ {
+					for j := range data {
+						consumeAny(data[j])
+					}
+				}
+			})
+
+			b.Run("PointerSlice_BoxElement", func(b *testing.B) {
+				data := createDataPointPointers(ds.size)
+				b.ResetTimer()
+				b.ReportAllocs()
+
+				for i := 0; i < b.N; i++ {
+					for j := range data {
+						consumeAny(data[j])
+					}
+				}
+			})
+
+			b.Run("ValueSlice_BoxAddress", func(b *testing.B) {
+				data := createPerformanceTestMessage(ds.size).ValueSliceData
+				b.ResetTimer()
+				b.ReportAllocs()
+
+				for i := 0; i < b.N; i++ {
+					for j := range data {
+						consumeAny(&data[j])
+					}
+				}
+			})
+		})
+	}
+}
+
+// dataPointPool recycles DataPoint objects for pointer-slice batches
+var dataPointPool = sync.Pool{
+	New: func() any { return new(v1.DataPoint) },
+}
+
+// acquireDataPoints appends n pooled DataPoints to dst
+func acquireDataPoints(dst []*v1.DataPoint, n int) []*v1.DataPoint {
+	for i := 0; i < n; i++ {
+		dst = append(dst, dataPointPool.Get().(*v1.DataPoint))
+	}
+	return dst
+}
+
+// releaseDataPoints resets and returns every element of data to the pool,
+// returning the emptied slice for reuse
+func releaseDataPoints(data []*v1.DataPoint) []*v1.DataPoint {
+	for i, p := range data {
+		resetDataPoint(p)
+		dataPointPool.Put(p)
+		data[i] = nil
+	}
+	return data[:0]
+}
+
+// resetDataPoint clears p for reuse, keeping the Tags backing array so a
+// pooled object stops allocating once warm
+func resetDataPoint(p *v1.DataPoint) {
+	clear(p.Tags)
+	tags := p.Tags[:0]
+	p.Reset()
+	p.Tags = tags
+}
+
+// fillDataPoint populates p the same way on every benchmark path, so only
+// where the element and its Tags come from differs
+func fillDataPoint(p *v1.DataPoint, id string, i int) {
+	p.Id = id
+	p.Value = float64(i) * 1.5
+	p.Timestamp = int64(1000000 + i)
+	p.Tags = append(p.Tags, "performance", "benchmark", id)
+}
+
+// BenchmarkPooledPointerSlice builds and consumes a batch of DataPoints per
+// iteration. Value slices reallocate their backing array each time; pointer
+// slices either allocate every element or recycle them through a sync.Pool,
+// the optimization teams typically apply to pointer-slice hot paths.
+func BenchmarkPooledPointerSlice(b *testing.B) {
+	dataSizes := []struct {
+		name string
+		size int
+	}{
+		{"Small", smallDataSize},
+		{"Medium", mediumDataSize},
+		{"Large", largeDataSize},
+	}
+
+	for _, ds := range dataSizes {
+		ids := make([]string, ds.size)
+		for i := range ids {
+			ids[i] = fmt.Sprintf("dp_%d", i)
+		}
+
+		b.Run(fmt.Sprintf("DataSize_%s", ds.name), func(b *testing.B) {
+			b.Run("ValueSlice_Realloc", func(b *testing.B) {
+				b.ReportAllocs()
+				var sum float64
+
+				for i := 0; i < b.N; i++ {
+					data := make([]v1.DataPoint, ds.size)
+					for j := range data {
+						fillDataPoint(&data[j], ids[j], j)
+					}
+					sum += benchmarkValueSliceIteration(data)
+				}
+				_ = sum
+			})
+
+			b.Run("PointerSlice_Realloc", func(b *testing.B) {
+				b.ReportAllocs()
+				var sum float64
+
+				for i := 0; i < b.N; i++ {
+					data := make([]*v1.DataPoint, ds.size)
+					for j := range data {
+						data[j] = new(v1.DataPoint)
+						fillDataPoint(data[j], ids[j], j)
+					}
+					for _, p := range data {
+						sum += p.Value
+					}
+				}
+				_ = sum
+			})
+
+			b.Run("PointerSlice_Pooled", func(b *testing.B) {
+				data := make([]*v1.DataPoint, 0, ds.size)
+				b.ReportAllocs()
+				var sum float64
+
+				for i := 0; i < b.N; i++ {
+					data = acquireDataPoints(data, ds.size)
+					for j, p := range data {
+						fillDataPoint(p, ids[j], j)
+					}
+					for _, p := range data {
+						sum += p.Value
+					}
+					data = releaseDataPoints(data)
+				}
+				_ = sum
+			})
+		})
+	}
+}
+
+// TestPooledDataPointsReset verifies pooled DataPoints carry no state from
+// their previous use
+func TestPooledDataPointsReset(t *testing.T) {
+	t.Run("reset clears fields", func(t *testing.T) {
+		p := new(v1.DataPoint)
+		fillDataPoint(p, "dp_1", 1)
+		tagsCap := cap(p.Tags)
+
+		resetDataPoint(p)
+
+		if p.Id != "" || p.Value != 0 || p.Timestamp != 0 || len(p.Tags) != 0 {
+			t.Errorf("Expected a zero DataPoint after reset, got %v", p)
+		}
+		if cap(p.Tags) != tagsCap {
+			t.Errorf("Expected Tags capacity %d to be kept, got %d", tagsCap, cap(p.Tags))
+		}
+		for _, tag := range p.Tags[:tagsCap] {
+			if tag != "" {
+				t.Errorf("Expected retained Tags backing array to be cleared, got %q", tag)
+			}
+		}
+	})
+
+	t.Run("reused batches match fresh batches", func(t *testing.T) {
+		var data []*v1.DataPoint
+
+		for round := 0; round < 3; round++ {
+			data = acquireDataPoints(data, smallDataSize)
+			for j, p := range data {
+				// Later rounds fill fewer fields, exposing anything left over
+				if round == 0 {
+					fillDataPoint(p, fmt.Sprintf("dp_%d", j), j)
+				} else {
+					p.Id = fmt.Sprintf("round_%d_%d", round, j)
+				}
+			}
+
+			for j, p := range data {
+				expected := &v1.DataPoint{Id: fmt.Sprintf("dp_%d", j)}
+				if round == 0 {
+					fillDataPoint(expected, expected.Id, j)
+				} else {
+					expected.Id = fmt.Sprintf("round_%d_%d", round, j)
+				}
+				if !proto.Equal(p, expected) {
+					t.Fatalf("Round %d element %d: expected %v, got %v", round, j, expected, p)
+				}
+			}
+
+			data = releaseDataPoints(data)
+			if len(data) != 0 {
+				t.Fatalf("Expected released batch to be empty, got %d elements", len(data))
+			}
+		}
+	})
+}
+
+// paddedElement is a synthetic element whose size is controlled by the padding
+// type, used to sweep element sizes past a cache line
+type paddedElement[P any] struct {
+	pad   P // Leading so a zero-size pad adds no trailing padding
+	Value float64
+}
+
+// elementSizeCase runs the value/pointer comparison for one element size
+type elementSizeCase struct {
+	name  string
+	size  uintptr
+	value func(count int) func() float64
+	ptr   func(count int) func() float64
+}
+
+func newElementSizeCase[P any](name string) elementSizeCase {
+	return elementSizeCase{
+		name: name,
+		size: reflect.TypeOf(paddedElement[P]{}).Size(),
+		value: func(count int) func() float64 {
+			data := make([]paddedElement[P], count)
+			for i := range data {
+				data[i].Value = float64(i)
+			}
+			return func() float64 {
+				var sum float64
+				for i := range data {
+					sum += data[i].Value
+				}
+				return sum
+			}
+		},
+		ptr: func(count int) func() float64 {
+			data := make([]*paddedElement[P], count)
+			for i := range data {
+				data[i] = &paddedElement[P]{Value: float64(i)}
+			}
+			return func() float64 {
+				var sum float64
+				for _, element := range data {
+					sum += element.Value
+				}
+				return sum
+			}
+		},
+	}
+}
+
+// elementSizeCases sweeps element sizes from a single word to several cache lines
+var elementSizeCases = []elementSizeCase{
+	newElementSizeCase[[0]byte]("Size_8B"),
+	newElementSizeCase[[24]byte]("Size_32B"),
+	newElementSizeCase[[56]byte]("Size_64B"),
+	newElementSizeCase[[120]byte]("Size_128B"),
+	newElementSizeCase[[248]byte]("Size_256B"),
+	newElementSizeCase[[504]byte]("Size_512B"),
+}
+
+// BenchmarkElementSize compares value and pointer slice iteration as element size grows
+func BenchmarkElementSize(b *testing.B) {
+	for _, tc := range elementSizeCases {
+		b.Run(tc.name, func(b *testing.B) {
+			b.Run("ValueSlice_Sequential", func(b *testing.B) {
+				iterate := tc.value(largeDataSize)
+				b.ResetTimer()
+				b.ReportAllocs()
+
+				for i := 0; i < b.N; i++ {
+					sum := iterate()
+					_ = sum
+				}
+			})
+
+			b.Run("PointerSlice_Sequential", func(b *testing.B) {
+				iterate := tc.ptr(largeDataSize)
+				b.ResetTimer()
+				b.ReportAllocs()
+
+				for i := 0; i < b.N; i++ {
+					sum := iterate()
+					_ = sum
+				}
+			})
+		})
+	}
+}
+
+// TestElementSizeSweep prints the pointer/value duration ratio for each element size
+func TestElementSizeSweep(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping element size sweep in short mode")
+	}
+
+	const passes = 50
+
+	for _, tc := range elementSizeCases {
+		valueIterate := tc.value(largeDataSize)
+		pointerIterate := tc.ptr(largeDataSize)
+
+		start := time.Now()
+		for i := 0; i < passes; i++ {
+			_ = valueIterate()
+		}
+		valueDuration := time.Since(start)
+
+		start = time.Now()
+		for i := 0; i < passes; i++ {
+			_ = pointerIterate()
+		}
+		pointerDuration := time.Since(start)
+
+		if valueIterate() != pointerIterate() {
+			t.Fatalf("%s: value and pointer sums differ", tc.name)
+		}
+
+		ratio := float64(pointerDuration) / float64(valueDuration)
+		t.Logf("%-10s element=%4dB value=%v pointer=%v pointer/value=%.2fx",
+			tc.name, tc.size, valueDuration, pointerDuration, ratio)
+	}
+}
+
+// rangeByValueSum ranges with a value loop variable, copying each whole
+// DataPoint into point before reading one field
+func rangeByValueSum(data []v1.DataPoint) float64 {
+	var sum float64
+	for _, point := range data {
+		sum += point.Value
+	}
+	return sum
+}
+
+// rangeByIndexSum reads each DataPoint in place through its index
+func rangeByIndexSum(data []v1.DataPoint) float64 {
+	var sum float64
+	for i := range data {
+		sum += data[i].Value
+	}
+	return sum
+}
+
+// rangePointerSum dereferences each element of a pointer slice
+func rangePointerSum(data []*v1.DataPoint) float64 {
+	var sum float64
+	for _, point := range data {
+		sum += point.Value
+	}
+	return sum
+}
+
+// newIterationPatternData builds the same data points as a value slice and as
+// a pointer slice
+func newIterationPatternData(size int) ([]v1.DataPoint, []*v1.DataPoint) {
+	values := make([]v1.DataPoint, size)
+	pointers := make([]*v1.DataPoint, size)
+	for i := 0; i < size; i++ {
+		values[i] = v1.DataPoint{
+			Id:        fmt.Sprintf("dp_%d", i),
+			Value:     float64(i) * 1.5,
+			Timestamp: int64(i),
+			Tags:      []string{"iteration"},
+		}
+		pointers[i] = &v1.DataPoint{
+			Id:        values[i].Id,
+			Value:     values[i].Value,
+			Timestamp: values[i].Timestamp,
+			Tags:      []string{"iteration"},
+		}
+	}
+	return values, pointers
+}
+
+// BenchmarkIterationPattern separates the cost of the loop shape from the
+// cost of the representation. Ranging over a value slice by value copies every
+// element into the loop variable, which the other benchmarks in this file do;
+// ranging by index reads elements in place. The copied-B/elem metric reports
+// how many bytes each pattern nominally copies per element; the compiler may
+// elide part of a copy whose fields go unused.
+func BenchmarkIterationPattern(b *testing.B) {
+	values, pointers := newIterationPatternData(largeDataSize)
+	elementSize := float64(reflect.TypeFor[v1.DataPoint]().Size())
+	pointerSize := float64(reflect.TypeFor[*v1.DataPoint]().Size())
+
+	b.Run("ValueSlice_RangeByValue", func(b *testing.B) {
+		b.ReportAllocs()
+		b.ReportMetric(elementSize, "copied-B/elem")
+		for i := 0; i < b.N; i++ {
+			_ = rangeByValueSum(values)
+		}
+	})
+
+	b.Run("ValueSlice_RangeByIndex", func(b *testing.B) {
+		b.ReportAllocs()
+		b.ReportMetric(0, "copied-B/elem")
+		for i := 0; i < b.N; i++ {
+			_ = rangeByIndexSum(values)
+		}
+	})
+
+	b.Run("PointerSlice_Range", func(b *testing.B) {
+		b.ReportAllocs()
+		b.ReportMetric(pointerSize, "copied-B/elem")
+		for i := 0; i < b.N; i++ {
+			_ = rangePointerSum(pointers)
+		}
+	})
+}
+
+// TestIterationPatternSums verifies every BenchmarkIterationPattern loop
+// computes the same sum
+func TestIterationPatternSums(t *testing.T) {
+	values, pointers := newIterationPatternData(1000)
+
+	byValue := rangeByValueSum(values)
+	byIndex := rangeByIndexSum(values)
+	byPointer := rangePointerSum(pointers)
+
+	if byValue != byIndex || byValue != byPointer {
+		t.Errorf("Expected equal sums, got by value %v, by index %v, pointer %v", byValue, byIndex, byPointer)
+	}
+
+	// 1.5 * (0 + 1 + ... + 999)
+	if expected := 1.5 * 999 * 1000 / 2; byValue != expected {
+		t.Errorf("Expected sum %v, got %v", expected, byValue)
+	}
+}
+
+// scalingMaxSize is the largest data size BenchmarkScaling sweeps to, e.g.
+// go test -bench=Scaling ./internal/validation -scaling.max=1048576
+var scalingMaxSize = flag.Int("scaling.max", 1<<16, "largest data size swept by BenchmarkScaling")
+
+// scalingCSV, when set, is where BenchmarkScaling writes its sweep as CSV
+var scalingCSV = flag.String("scaling.csv", "", "write the BenchmarkScaling sweep as CSV to this file")
+
+// scalingMinSize is the first data size of the sweep
+const scalingMinSize = 16
+
+// scalingSizes returns the powers of two from scalingMinSize up to maxSize
+func scalingSizes(maxSize int) []int {
+	var sizes []int
+	for size := scalingMinSize; size <= maxSize; size *= 2 {
+		sizes = append(sizes, size)
+	}
+	return sizes
+}
+
+// scalingPoint is the value/pointer iteration comparison at one data size
+type scalingPoint struct {
+	size                int
+	valueNsPerElement   float64
+	pointerNsPerElement float64
+}
+
+// pointerToValue is how many times slower pointer iteration is than value
+// iteration; above 1 means value slices are ahead
+func (p scalingPoint) pointerToValue() float64 {
+	if p.valueNsPerElement == 0 {
+		return 0
+	}
+	return p.pointerNsPerElement / p.valueNsPerElement
+}
+
+// measureScalingPoint times passes iterations over each representation
+func measureScalingPoint(values []v1.DataPoint, pointers []*v1.DataPoint, passes int) scalingPoint {
+	start := time.Now()
+	for i := 0; i < passes; i++ {
+		_ = rangeByIndexSum(values)
+	}
+	valueDuration := time.Since(start)
+
+	start = time.Now()
+	for i := 0; i < passes; i++ {
+		_ = rangePointerSum(pointers)
+	}
+	pointerDuration := time.Since(start)
+
+	elements := float64(passes * len(values))
+	return scalingPoint{
+		size:                len(values),
+		valueNsPerElement:   float64(valueDuration.Nanoseconds()) / elements,
+		pointerNsPerElement: float64(pointerDuration.Nanoseconds()) / elements,
+	}
+}
+
+// measureScaling measures one scalingPoint per size
+func measureScaling(sizes []int, passes int) []scalingPoint {
+	points := make([]scalingPoint, 0, len(sizes))
+	for _, size := range sizes {
+		values, pointers := newIterationPatternData(size)
+		points = append(points, measureScalingPoint(values, pointers, passes))
+	}
+	return points
+}
+
+// writeScalingCSV writes one row per point, ready for plotting
+func writeScalingCSV(w io.Writer, points []scalingPoint) error {
+	cw := csv.NewWriter(w)
+	cw.Write([]string{"size", "value_ns_per_elem", "pointer_ns_per_elem", "pointer_to_value"})
+	for _, p := range points {
+		cw.Write([]string{
+			strconv.Itoa(p.size),
+			strconv.FormatFloat(p.valueNsPerElement, 'f', 4, 64),
+			strconv.FormatFloat(p.pointerNsPerElement, 'f', 4, 64),
+			strconv.FormatFloat(p.pointerToValue(), 'f', 4, 64),
+		})
+	}
+	cw.Flush()
+	return cw.Error()
+}
+
+// BenchmarkScaling sweeps data sizes by powers of two up to -scaling.max,
+// reporting per-element iteration cost and the pointer/value ratio at each
+// size, to show whether the value-slice advantage grows, plateaus or reverses
+// as the data outgrows each cache level. Use -scaling.csv to also write the
+// sweep to a file for plotting.
+func BenchmarkScaling(b *testing.B) {
+	var points []scalingPoint
+
+	for _, size := range scalingSizes(*scalingMaxSize) {
+		values, pointers := newIterationPatternData(size)
+
+		b.Run(fmt.Sprintf("Size_%d", size), func(b *testing.B) {
+			point := measureScalingPoint(values, pointers, b.N)
+			b.ReportMetric(point.valueNsPerElement, "value-ns/elem")
+			b.ReportMetric(point.pointerNsPerElement, "pointer-ns/elem")
+			b.ReportMetric(point.pointerToValue(), "pointer/value")
+
+			// The final run of each size has the largest b.N
+			if len(points) > 0 && points[len(points)-1].size == size {
+				points[len(points)-1] = point
+			} else {
+				points = append(points, point)
+			}
+		})
+	}
+
+	if *scalingCSV == "" {
+		return
+	}
+	f, err := os.Create(*scalingCSV)
+	if err != nil {
+		b.Fatalf("Failed to create %s: %v", *scalingCSV, err)
+	}
+	defer f.Close()
+	if err := writeScalingCSV(f, points); err != nil {
+		b.Fatalf("Failed to write %s: %v", *scalingCSV, err)
+	}
+}
+
+// TestScalingSweep checks the sweep yields one ordered point per size step
+func TestScalingSweep(t *testing.T) {
+	sizes := scalingSizes(1024)
+	if expected := []int{16, 32, 64, 128, 256, 512, 1024}; !reflect.DeepEqual(sizes, expected) {
+		t.Fatalf("Expected sizes %v, got %v", expected, sizes)
+	}
+	if got := scalingSizes(1000); got[len(got)-1] != 512 {
+		t.Errorf("Expected the sweep to stop below a non-power-of-two max, got %v", got)
+	}
+
+	points := measureScaling(sizes, 3)
+	if len(points) != len(sizes) {
+		t.Fatalf("Expected %d points, got %d", len(sizes), len(points))
+	}
+	for i, p := range points {
+		if p.size != sizes[i] {
+			t.Errorf("Expected point %d at size %d, got %d", i, sizes[i], p.size)
+		}
+		if p.valueNsPerElement <= 0 || p.pointerNsPerElement <= 0 || p.pointerToValue() <= 0 {
+			t.Errorf("Expected positive measurements at size %d, got %+v", p.size, p)
+		}
+	}
+
+	var buf bytes.Buffer
+	if err := writeScalingCSV(&buf, points); err != nil {
+		t.Fatalf("writeScalingCSV failed: %v", err)
+	}
+	rows, err := csv.NewReader(&buf).ReadAll()
+	if err != nil {
+		t.Fatalf("Failed to read CSV: %v", err)
+	}
+	if len(rows) != len(sizes)+1 {
+		t.Errorf("Expected a header and %d rows, got %d rows", len(sizes), len(rows))
+	}
+}
+
+// sliceGrowth is what appending elements one at a time cost a slice
+type sliceGrowth struct {
+	reallocations int
+	copiedBytes   int
+}
+
+// appendGrowth appends each element of source to dst one at a time. Every
+// capacity change of a non-empty slice is a reallocation that copied the
+// elements appended before it.
+func appendGrowth[T any](dst, source []T) ([]T, sliceGrowth) {
+	elemSize := int(reflect.TypeFor[T]().Size())
+	var growth sliceGrowth
+	for i := range source {
+		before := cap(dst)
+		dst = append(dst, source[i])
+		if before > 0 && cap(dst) != before {
+			growth.reallocations++
+			growth.copiedBytes += (len(dst) - 1) * elemSize
+		}
+	}
+	return dst, growth
+}
+
+// BenchmarkSliceGrowth appends DataPoints one at a time without presizing,
+// reporting the reallocations Go's growth algorithm makes and the bytes they
+// copy. Each value-slice reallocation copies whole structs where a pointer
+// slice copies pointers; a presized slice never reallocates.
+func BenchmarkSliceGrowth(b *testing.B) {
+	dataSizes := []struct {
+		name string
+		size int
+	}{
+		{"Small", smallDataSize},
+		{"Medium", mediumDataSize},
+		{"Large", largeDataSize},
+	}
+
+	report := func(b *testing.B, growth sliceGrowth) {
+		b.ReportMetric(float64(growth.reallocations), "reallocs/op")
+		b.ReportMetric(float64(growth.copiedBytes), "copied-B/op")
+	}
+
+	for _, ds := range dataSizes {
+		values := createPerformanceTestMessage(ds.size).ValueSliceData
+		pointers := createDataPointPointers(ds.size)
+
+		b.Run(fmt.Sprintf("DataSize_%s", ds.name), func(b *testing.B) {
+			b.Run("ValueSlice", func(b *testing.B) {
+				var growth sliceGrowth
+				b.ReportAllocs()
+				for i := 0; i < b.N; i++ {
+					_, growth = appendGrowth(nil, values)
+				}
+				report(b, growth)
+			})
+
+			b.Run("PointerSlice", func(b *testing.B) {
+				var growth sliceGrowth
+				b.ReportAllocs()
+				for i := 0; i < b.N; i++ {
+					_, growth = appendGrowth(nil, pointers)
+				}
+				report(b, growth)
+			})
+
+			b.Run("ValueSlice_Presized", func(b *testing.B) {
+				var growth sliceGrowth
+				b.ReportAllocs()
+				for i := 0; i < b.N; i++ {
+					_, growth = appendGrowth(make([]v1.DataPoint, 0, len(values)), values)
+				}
+				report(b, growth)
+			})
+		})
+	}
+}
+
+// TestSliceGrowthCopies checks unsized value slices copy more bytes while
+// growing than pointer slices, and that presizing avoids reallocation
+func TestSliceGrowthCopies(t *testing.T) {
+	values := createPerformanceTestMessage(largeDataSize).ValueSliceData
+	pointers := createDataPointPointers(largeDataSize)
+
+	grownValues, valueGrowth := appendGrowth(nil, values)
+	grownPointers, pointerGrowth := appendGrowth(nil, pointers)
+	if len(grownValues) != largeDataSize || len(grownPointers) != largeDataSize {
+		t.Fatalf("Expected %d elements, got %d values and %d pointers", largeDataSize, len(grownValues), len(grownPointers))
+	}
+
+	if valueGrowth.reallocations == 0 || pointerGrowth.reallocations == 0 {
+		t.Errorf("Expected unsized slices to reallocate, got %d value and %d pointer reallocations",
+			valueGrowth.reallocations, pointerGrowth.reallocations)
+	}
+	if valueGrowth.copiedBytes <= pointerGrowth.copiedBytes {
+		t.Errorf("Expected the value slice to copy more bytes, got %d value vs %d pointer",
+			valueGrowth.copiedBytes, pointerGrowth.copiedBytes)
+	}
+	t.Logf("Appending %d elements: value slice %d reallocations, %d bytes copied; pointer slice %d reallocations, %d bytes copied",
+		largeDataSize, valueGrowth.reallocations, valueGrowth.copiedBytes, pointerGrowth.reallocations, pointerGrowth.copiedBytes)
+
+	if _, presized := appendGrowth(make([]v1.DataPoint, 0, len(values)), values); presized.reallocations != 0 {
+		t.Errorf("Expected a presized slice not to reallocate, got %d reallocations", presized.reallocations)
+	}
+}
+
+// metadataAttributesPerItem is how many attributes createMetadataPointers
+// gives each item
+const metadataAttributesPerItem = 4
+
+// iterateAttributes visits every attribute of every Metadata, returning the
+// number of key-value pairs visited and their combined length so the loop
+// cannot be elided
+func iterateAttributes(data []*v1.Metadata) (pairs, length int) {
+	for _, meta := range data {
+		for k, v := range meta.Attributes {
+			pairs++
+			length += len(k) + len(v)
+		}
+	}
+	return pairs, length
+}
+
+// BenchmarkMapIteration measures iterating the Attributes maps across a slice
+// of Metadata. Map access is orthogonal to the value/pointer slice question but
+// dominates some workloads, so it puts the slice differences in proportion.
+func BenchmarkMapIteration(b *testing.B) {
+	dataSizes := []struct {
+		name string
+		size int
+	}{
+		{"Small", smallDataSize},
+		{"Medium", mediumDataSize},
+		{"Large", largeDataSize},
+	}
+
+	for _, ds := range dataSizes {
+		b.Run(fmt.Sprintf("DataSize_%s", ds.name), func(b *testing.B) {
+			data := createMetadataPointers(ds.size)
+			var pairs int
+			b.ResetTimer()
+			b.ReportAllocs()
+
+			for i := 0; i < b.N; i++ {
+				pairs, _ = iterateAttributes(data)
+			}
+			b.ReportMetric(float64(pairs), "pairs/op")
+		})
+	}
+}
+
+// TestMapIterationCount verifies BenchmarkMapIteration visits every attribute
+func TestMapIterationCount(t *testing.T) {
+	data := createMetadataPointers(smallDataSize)
+
+	pairs, length := iterateAttributes(data)
+	if want := smallDataSize * metadataAttributesPerItem; pairs != want {
+		t.Errorf("Expected %d key-value pairs, got %d", want, pairs)
+	}
+	if length == 0 {
+		t.Error("Expected a non-zero combined attribute length")
+	}
+}
+
+// marshalAppendMessages returns the messages BenchmarkMarshalAppend encodes:
+// the value-slice message as its marshal-safe copy, since the generated type
+// cannot be marshaled, and the pointer-slice message natively
+func marshalAppendMessages(tb testing.TB, size int) (value, pointer proto.Message) {
+	tb.Helper()
+
+	value, err := convert.MarshalSafeCopy(createPerformanceTestMessage(size))
+	if err != nil {
+		tb.Fatalf("MarshalSafeCopy failed: %v", err)
+	}
+	pointer = &v1.ValidationTestMessage{PointerSliceData: createDataPointPointers(size)}
+	return value, pointer
+}
+
+// marshalReused encodes msg into buf reset to zero length, reusing its
+// capacity, and returns the encoding
+func marshalReused(buf []byte, msg proto.Message) ([]byte, error) {
+	return proto.MarshalOptions{}.MarshalAppend(buf[:0], msg)
+}
+
+// BenchmarkMarshalAppend compares proto.Marshal, which allocates a fresh
+// output slice every call, with MarshalAppend into a reused buffer. Buffer
+// reuse is orthogonal to the slice representation, so both are measured.
+func BenchmarkMarshalAppend(b *testing.B) {
+	value, pointer := marshalAppendMessages(b, mediumDataSize)
+
+	for _, tc := range []struct {
+		name string
+		msg  proto.Message
+	}{
+		{"ValueSlice", value},
+		{"PointerSlice", pointer},
+	} {
+		b.Run(tc.name+"_Marshal", func(b *testing.B) {
+			b.ReportAllocs()
+			for i := 0; i < b.N; i++ {
+				data, err := proto.Marshal(tc.msg)
+				if err != nil {
+					b.Fatal(err)
+				}
+				_ = data
+			}
+		})
+
+		b.Run(tc.name+"_MarshalAppend", func(b *testing.B) {
+			var buf []byte
+			b.ReportAllocs()
+			for i := 0; i < b.N; i++ {
+				var err error
+				buf, err = marshalReused(buf, tc.msg)
+				if err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
+	}
+}
+
+// TestMarshalAppendAllocatesLess verifies the reused buffer saves the output
+// allocation for both representations, and still encodes the message
+func TestMarshalAppendAllocatesLess(t *testing.T) {
+	value, pointer := marshalAppendMessages(t, smallDataSize)
+
+	for name, msg := range map[string]proto.Message{"ValueSlice": value, "PointerSlice": pointer} {
+		buf, err := marshalReused(nil, msg)
+		if err != nil {
+			t.Fatalf("%s: MarshalAppend failed: %v", name, err)
+		}
+		// Attribute map order varies between encodings, so compare decoded
+		decoded := msg.ProtoReflect().New().Interface()
+		if err := proto.Unmarshal(buf, decoded); err != nil {
+			t.Fatalf("%s: Unmarshal failed: %v", name, err)
+		}
+		if !proto.Equal(decoded, msg) {
+			t.Errorf("%s: Expected MarshalAppend to round-trip the message", name)
+		}
+
+		marshalAllocs := testing.AllocsPerRun(100, func() {
+			_, _ = proto.Marshal(msg)
+		})
+		appendAllocs := testing.AllocsPerRun(100, func() {
+			buf, _ = marshalReused(buf, msg)
+		})
+		if appendAllocs >= marshalAllocs {
+			t.Errorf("%s: Expected MarshalAppend to allocate less than Marshal (%.0f), got %.0f", name, marshalAllocs, appendAllocs)
+		}
+	}
+}
+
+// concurrentReaders is how many goroutines TestConcurrentReadSums starts
+const concurrentReaders = 8
+
+// BenchmarkConcurrentRead has every goroutine iterate one shared value slice
+// and one shared pointer slice. Reads need no synchronization, and each
+// goroutine accumulates into its own local sum, so there are no shared writes
+// to contend on or falsely share a cache line. Run with -race to confirm.
+func BenchmarkConcurrentRead(b *testing.B) {
+	values, pointers := newIterationPatternData(mediumDataSize)
+	expected := rangeByIndexSum(values)
+
+	b.Run("ValueSlice", func(b *testing.B) {
+		b.ReportAllocs()
+		b.RunParallel(func(pb *testing.PB) {
+			for pb.Next() {
+				if sum := rangeByIndexSum(values); sum != expected {
+					b.Errorf("Expected sum %v, got %v", expected, sum)
+					return
+				}
+			}
+		})
+	})
+
+	b.Run("PointerSlice", func(b *testing.B) {
+		b.ReportAllocs()
+		b.RunParallel(func(pb *testing.PB) {
+			for pb.Next() {
+				if sum := rangePointerSum(pointers); sum != expected {
+					b.Errorf("Expected sum %v, got %v", expected, sum)
+					return
+				}
+			}
+		})
+	})
+}
+
+// TestConcurrentReadSums verifies concurrent readers of the shared slices
+// each compute the expected total; under -race it also checks the reads are
+// race-free
+func TestConcurrentReadSums(t *testing.T) {
+	values, pointers := newIterationPatternData(1000)
+	// 1.5 * (0 + 1 + ... + 999)
+	expected := 1.5 * 999 * 1000 / 2
+
+	sums := make([][2]float64, concurrentReaders)
+	var wg sync.WaitGroup
+	for i := range sums {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			sums[i] = [2]float64{rangeByIndexSum(values), rangePointerSum(pointers)}
+		}()
+	}
+	wg.Wait()
+
+	for i, sum := range sums {
+		if sum[0] != expected || sum[1] != expected {
+			t.Errorf("Reader %d: expected sums %v, got value %v and pointer %v", i, expected, sum[0], sum[1])
+		}
+	}
+}
+
 // Helper functions for benchmark data creation
 
 func createPerformanceTestMessage(size int) *v1.PerformanceTestMessage {
@@ -0,0 +1,90 @@
+package validation
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	v1 "github.com/benjamin-rood/protogo-values-validation-demo/gen/api/validation/v1"
+	"github.com/benjamin-rood/protogo-values-validation-demo/internal/server"
+	"google.golang.org/grpc"
+	"google.golang.org/protobuf/proto"
+)
+
+func TestValidateTypesCache(t *testing.T) {
+	validationServer := server.NewValidationServer()
+
+	conn, cleanup := setupCustomTestServer(t, func(s *grpc.Server) {
+		v1.RegisterValidationServiceServer(s, validationServer)
+	})
+	defer cleanup()
+	client := v1.NewValidationServiceClient(conn)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	req := &v1.ValidateTypesRequest{
+		TestScenarios:  []string{server.ScenarioValidationTestMessage, server.ScenarioPerformanceTestMessage},
+		DeepValidation: true,
+	}
+
+	first, err := client.ValidateTypes(ctx, req)
+	if err != nil {
+		t.Fatalf("ValidateTypes failed: %v", err)
+	}
+	if hits := validationServer.CacheHits(); hits != 0 {
+		t.Fatalf("Expected no cache hits after first call, got %d", hits)
+	}
+
+	second, err := client.ValidateTypes(ctx, req)
+	if err != nil {
+		t.Fatalf("ValidateTypes failed: %v", err)
+	}
+	if hits := validationServer.CacheHits(); hits != 1 {
+		t.Errorf("Expected second call served from cache, got %d hits", hits)
+	}
+	if !proto.Equal(first, second) {
+		t.Error("Expected cached response to equal the computed response")
+	}
+
+	// Scenario order does not change the key
+	_, err = client.ValidateTypes(ctx, &v1.ValidateTypesRequest{
+		TestScenarios:  []string{server.ScenarioPerformanceTestMessage, server.ScenarioValidationTestMessage},
+		DeepValidation: true,
+	})
+	if err != nil {
+		t.Fatalf("ValidateTypes failed: %v", err)
+	}
+	if hits := validationServer.CacheHits(); hits != 2 {
+		t.Errorf("Expected reordered scenarios to hit the cache, got %d hits", hits)
+	}
+
+	// DeepValidation is part of the key
+	_, err = client.ValidateTypes(ctx, &v1.ValidateTypesRequest{
+		TestScenarios:  req.TestScenarios,
+		DeepValidation: false,
+	})
+	if err != nil {
+		t.Fatalf("ValidateTypes failed: %v", err)
+	}
+	if hits := validationServer.CacheHits(); hits != 2 {
+		t.Errorf("Expected DeepValidation=false to miss the cache, got %d hits", hits)
+	}
+}
+
+func TestValidateTypesCacheDisabled(t *testing.T) {
+	validationServer := server.NewValidationServer(server.WithCache(false))
+
+	ctx := context.Background()
+	req := &v1.ValidateTypesRequest{TestScenarios: []string{server.ScenarioValidationTestMessage}}
+
+	for i := 0; i < 3; i++ {
+		if _, err := validationServer.ValidateTypes(ctx, req); err != nil {
+			t.Fatalf("ValidateTypes failed: %v", err)
+		}
+	}
+
+	if hits := validationServer.CacheHits(); hits != 0 {
+		t.Errorf("Expected no cache hits when disabled, got %d", hits)
+	}
+}
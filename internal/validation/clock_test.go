@@ -0,0 +1,122 @@
+package validation
+
+import (
+	"context"
+	"io"
+	"sync"
+	"testing"
+	"time"
+
+	v1 "github.com/benjamin-rood/protogo-values-validation-demo/gen/api/validation/v1"
+	"github.com/benjamin-rood/protogo-values-validation-demo/internal/server"
+	"google.golang.org/grpc"
+)
+
+// stepClock advances by a fixed step on every Now call, so any measured
+// interval of one Now/Since pair is exactly one step
+type stepClock struct {
+	mu   sync.Mutex
+	now  time.Time
+	step time.Duration
+}
+
+func (c *stepClock) Now() time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	now := c.now
+	c.now = c.now.Add(c.step)
+	return now
+}
+
+func (c *stepClock) Since(t time.Time) time.Duration {
+	return c.Now().Sub(t)
+}
+
+func setupClockTestServer(t *testing.T, clock server.Clock) (v1.ValidationServiceClient, func()) {
+	validationServer := server.NewValidationServer(server.WithClock(clock))
+
+	conn, cleanup := setupCustomTestServer(t, func(s *grpc.Server) {
+		v1.RegisterValidationServiceServer(s, validationServer)
+	})
+	return v1.NewValidationServiceClient(conn), cleanup
+}
+
+func TestClockProcessingTime(t *testing.T) {
+	const step = 250 * time.Microsecond
+
+	client, cleanup := setupClockTestServer(t, &stepClock{now: time.Unix(0, 0), step: step})
+	defer cleanup()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	stream, err := client.StreamValidation(ctx)
+	if err != nil {
+		t.Fatalf("Failed to create stream: %v", err)
+	}
+
+	// The stream validates messages on several workers sharing the clock, so
+	// send one message at a time: a second message in flight would take a
+	// step between the first one's Now and Since
+	const messages = 10
+	for i := 0; i < messages; i++ {
+		err := stream.Send(&v1.StreamRequest{
+			RequestId:      "clock",
+			SequenceNumber: int32(i),
+			TestData: &v1.ValidationTestMessage{
+				PointerSliceData: createDataPointPointers(4),
+			},
+		})
+		if err != nil {
+			t.Fatalf("Failed to send: %v", err)
+		}
+
+		resp, err := stream.Recv()
+		if err != nil {
+			t.Fatalf("Failed to receive: %v", err)
+		}
+
+		if resp.Stats.ProcessingTimeNs != step.Nanoseconds() {
+			t.Errorf("Expected ProcessingTimeNs %d, got %d", step.Nanoseconds(), resp.Stats.ProcessingTimeNs)
+		}
+
+		expectedThroughput := 4 / step.Seconds()
+		if resp.Stats.Throughput != expectedThroughput {
+			t.Errorf("Expected throughput %v, got %v", expectedThroughput, resp.Stats.Throughput)
+		}
+	}
+	if err := stream.CloseSend(); err != nil {
+		t.Fatalf("Failed to close send: %v", err)
+	}
+
+	if _, err := stream.Recv(); err != io.EOF {
+		t.Errorf("Expected the stream to end after %d responses, got %v", messages, err)
+	}
+}
+
+func TestClockBenchmarkDuration(t *testing.T) {
+	const step = time.Millisecond
+
+	client, cleanup := setupClockTestServer(t, &stepClock{now: time.Unix(0, 0), step: step})
+	defer cleanup()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	resp, err := client.RunBenchmarks(ctx, &v1.BenchmarkRequest{
+		Iterations: 10,
+		DataSize:   10,
+	})
+	if err != nil {
+		t.Fatalf("RunBenchmarks failed: %v", err)
+	}
+
+	for _, result := range resp.Results {
+		if result.Error != "" {
+			continue
+		}
+		if result.DurationNs != float64(step.Nanoseconds()) {
+			t.Errorf("%s: expected duration %d ns, got %v", result.Name, step.Nanoseconds(), result.DurationNs)
+		}
+	}
+}
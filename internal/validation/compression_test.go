@@ -0,0 +1,155 @@
+package validation
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	v1 "github.com/benjamin-rood/protogo-values-validation-demo/gen/api/validation/v1"
+	"github.com/benjamin-rood/protogo-values-validation-demo/internal/server"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/encoding/gzip"
+	"google.golang.org/grpc/stats"
+	"google.golang.org/protobuf/proto"
+)
+
+// payloadRecorder records the wire and decoded sizes of received and sent
+// messages
+type payloadRecorder struct {
+	mu       sync.Mutex
+	payloads []*stats.InPayload
+	sent     []*stats.OutPayload
+}
+
+func (r *payloadRecorder) TagRPC(ctx context.Context, _ *stats.RPCTagInfo) context.Context {
+	return ctx
+}
+
+func (r *payloadRecorder) HandleRPC(_ context.Context, s stats.RPCStats) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	switch p := s.(type) {
+	case *stats.InPayload:
+		r.payloads = append(r.payloads, p)
+	case *stats.OutPayload:
+		r.sent = append(r.sent, p)
+	}
+}
+
+func (r *payloadRecorder) TagConn(ctx context.Context, _ *stats.ConnTagInfo) context.Context {
+	return ctx
+}
+
+func (r *payloadRecorder) HandleConn(context.Context, stats.ConnStats) {}
+
+func TestGzipCompressedResponse(t *testing.T) {
+	cleanup := setupTestServer()
+	defer cleanup()
+
+	recorder := &payloadRecorder{}
+	conn, err := grpc.DialContext(context.Background(), "bufnet",
+		grpc.WithContextDialer(bufDialer),
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+		grpc.WithStatsHandler(recorder),
+		grpc.WithDefaultCallOptions(grpc.UseCompressor(gzip.Name)))
+	if err != nil {
+		t.Fatalf("Failed to dial bufnet: %v", err)
+	}
+	defer conn.Close()
+	client := v1.NewValidationServiceClient(conn)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	// A full batch of deep validations produces a large, repetitive response
+	requests := make([]*v1.ValidateTypesRequest, 100)
+	for i := range requests {
+		requests[i] = &v1.ValidateTypesRequest{TestScenarios: []string{server.ScenarioValidationTestMessage}, DeepValidation: true}
+	}
+
+	resp, err := client.BatchValidateTypes(ctx, &v1.BatchValidateTypesRequest{Requests: requests})
+	if err != nil {
+		t.Fatalf("BatchValidateTypes failed: %v", err)
+	}
+
+	if len(resp.Responses) != len(requests) {
+		t.Fatalf("Expected %d responses, got %d", len(requests), len(resp.Responses))
+	}
+
+	single, err := client.ValidateTypes(ctx, requests[0])
+	if err != nil {
+		t.Fatalf("ValidateTypes failed: %v", err)
+	}
+	for i, r := range resp.Responses {
+		if !proto.Equal(r, single) {
+			t.Fatalf("Response %d was not decoded correctly", i)
+		}
+	}
+
+	recorder.mu.Lock()
+	defer recorder.mu.Unlock()
+
+	if len(recorder.payloads) == 0 {
+		t.Fatal("Expected to observe the response payload")
+	}
+	batch := recorder.payloads[0]
+	if batch.CompressedLength >= batch.Length {
+		t.Errorf("Expected a gzip-compressed reply, got %d wire bytes for %d decoded bytes",
+			batch.CompressedLength, batch.Length)
+	}
+	t.Logf("Batch response: %d bytes decoded, %d bytes on the wire", batch.Length, batch.CompressedLength)
+}
+
+func TestCompressionThreshold(t *testing.T) {
+	const minBytes = 1024
+
+	// The server records what it sends, so both calls share the client's settings
+	recorder := &payloadRecorder{}
+	conn, cleanup := setupCustomTestServer(t, func(s *grpc.Server) {
+		v1.RegisterValidationServiceServer(s, server.NewValidationServer())
+	}, grpc.StatsHandler(recorder), grpc.ChainUnaryInterceptor(server.UnaryCompressionThresholdInterceptor(minBytes)))
+	defer cleanup()
+	client := v1.NewValidationServiceClient(conn)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	gzipCall := grpc.UseCompressor(gzip.Name)
+
+	if _, err := client.ValidateTypes(ctx, &v1.ValidateTypesRequest{TestScenarios: []string{server.ScenarioValidationTestMessage}}, gzipCall); err != nil {
+		t.Fatalf("ValidateTypes failed: %v", err)
+	}
+
+	requests := make([]*v1.ValidateTypesRequest, 100)
+	for i := range requests {
+		requests[i] = &v1.ValidateTypesRequest{TestScenarios: []string{server.ScenarioValidationTestMessage}, DeepValidation: true}
+	}
+	if _, err := client.BatchValidateTypes(ctx, &v1.BatchValidateTypesRequest{Requests: requests}, gzipCall); err != nil {
+		t.Fatalf("BatchValidateTypes failed: %v", err)
+	}
+
+	recorder.mu.Lock()
+	defer recorder.mu.Unlock()
+
+	if len(recorder.sent) != 2 {
+		t.Fatalf("Expected 2 responses sent, got %d", len(recorder.sent))
+	}
+	small, large := recorder.sent[0], recorder.sent[1]
+
+	if small.Length >= minBytes {
+		t.Fatalf("Expected the ValidateTypes response under %d bytes, got %d", minBytes, small.Length)
+	}
+	if small.CompressedLength != small.Length {
+		t.Errorf("Expected the small response uncompressed, got %d wire bytes for %d decoded bytes",
+			small.CompressedLength, small.Length)
+	}
+
+	if large.Length < minBytes {
+		t.Fatalf("Expected the BatchValidateTypes response of at least %d bytes, got %d", minBytes, large.Length)
+	}
+	if large.CompressedLength >= large.Length {
+		t.Errorf("Expected the large response compressed, got %d wire bytes for %d decoded bytes",
+			large.CompressedLength, large.Length)
+	}
+}
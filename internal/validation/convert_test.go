@@ -0,0 +1,223 @@
+package validation
+
+import (
+	"errors"
+	"testing"
+
+	v1 "github.com/benjamin-rood/protogo-values-validation-demo/gen/api/validation/v1"
+	"github.com/benjamin-rood/protogo-values-validation-demo/internal/convert"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/types/dynamicpb"
+)
+
+// roundTripSafeCopy marshals a MarshalSafeCopy of msg and decodes the bytes
+// into a dynamic message of the same type
+func roundTripSafeCopy(t *testing.T, msg proto.Message) protoreflect.Message {
+	t.Helper()
+
+	safe, err := convert.MarshalSafeCopy(msg)
+	if err != nil {
+		t.Fatalf("MarshalSafeCopy failed: %v", err)
+	}
+	data, err := proto.Marshal(safe)
+	if err != nil {
+		t.Fatalf("Marshaling the copy failed: %v", err)
+	}
+
+	decoded := dynamicpb.NewMessage(msg.ProtoReflect().Descriptor())
+	if err := proto.Unmarshal(data, decoded); err != nil {
+		t.Fatalf("Unmarshaling the copy failed: %v", err)
+	}
+	return decoded
+}
+
+func TestMarshalSafeCopyValidationTestMessage(t *testing.T) {
+	msg := &v1.ValidationTestMessage{
+		ValueSliceData: []v1.DataPoint{
+			{Id: "v1", Value: 1.5, Timestamp: 100, Tags: []string{"a", "b"}},
+			{Id: "v2", Value: -2, Timestamp: 200},
+		},
+		PointerSliceData: []*v1.DataPoint{
+			{Id: "p1", Value: 3, Timestamp: 300, Tags: []string{"c"}},
+		},
+		Metrics: []v1.MetricPoint{
+			{Name: "cpu", Measurement: 0.5, Labels: map[string]string{"host": "a", "region": "eu"}},
+			{Name: "mem", Measurement: 0.25},
+		},
+	}
+
+	decoded := roundTripSafeCopy(t, msg)
+	if diff := equivalenceDiff(msg, decoded.Interface()); diff != "" {
+		t.Errorf("Expected the copy to carry identical data, differs at %s", diff)
+	}
+}
+
+func TestMarshalSafeCopyPerformanceTestMessage(t *testing.T) {
+	msg := &v1.PerformanceTestMessage{
+		ValueSliceData: []v1.DataPoint{{Id: "v1", Value: 1, Timestamp: 10, Tags: []string{"x"}}},
+		PointerSliceData: []*v1.Metadata{
+			{Key: "k1", Value: "v1", Attributes: map[string]string{"owner": "team-a"}},
+			{Key: "k2"},
+		},
+		Results: []v1.ProcessingResult{
+			{OperationId: "op-1", Success: true, DurationMs: 1.25},
+			{OperationId: "op-2", ErrorMessages: []string{"timeout", "retry"}},
+		},
+	}
+
+	decoded := roundTripSafeCopy(t, msg)
+	if diff := equivalenceDiff(msg, decoded.Interface()); diff != "" {
+		t.Errorf("Expected the copy to carry identical data, differs at %s", diff)
+	}
+}
+
+func TestMarshalSafeCopyNestedMessage(t *testing.T) {
+	// StreamRequest holds a value-slice message one level down
+	req := &v1.StreamRequest{
+		RequestId:      "req-1",
+		SequenceNumber: 7,
+		TestData: &v1.ValidationTestMessage{
+			ValueSliceData: []v1.DataPoint{{Id: "v1", Tags: []string{"nested"}}},
+			Metrics:        []v1.MetricPoint{{Name: "m", Labels: map[string]string{"depth": "2"}}},
+		},
+	}
+
+	decoded := roundTripSafeCopy(t, req)
+	fields := decoded.Descriptor().Fields()
+
+	if got := decoded.Get(fields.ByName("request_id")).String(); got != "req-1" {
+		t.Errorf("Expected request_id req-1, got %q", got)
+	}
+	if got := decoded.Get(fields.ByName("sequence_number")).Int(); got != 7 {
+		t.Errorf("Expected sequence_number 7, got %d", got)
+	}
+	testData := decoded.Get(fields.ByName("test_data")).Message().Interface()
+	if diff := equivalenceDiff(req.TestData, testData); diff != "" {
+		t.Errorf("Expected the nested message to carry identical data, differs at %s", diff)
+	}
+}
+
+func TestMarshalSafeCopyIsDeep(t *testing.T) {
+	msg := &v1.ValidationTestMessage{
+		ValueSliceData:   []v1.DataPoint{{Id: "v1", Tags: []string{"before"}}},
+		PointerSliceData: []*v1.DataPoint{{Id: "p1"}},
+		Metrics:          []v1.MetricPoint{{Name: "m", Labels: map[string]string{"k": "before"}}},
+	}
+
+	safe, err := convert.MarshalSafeCopy(msg)
+	if err != nil {
+		t.Fatalf("MarshalSafeCopy failed: %v", err)
+	}
+	// Compared with proto.Equal, as dynamic messages marshal fields in no fixed order
+	snapshot := proto.Clone(safe)
+
+	// Mutating the original, including its slices and maps, must not reach the copy
+	msg.ValueSliceData[0].Id = "changed"
+	msg.ValueSliceData[0].Tags[0] = "after"
+	msg.PointerSliceData[0].Id = "changed"
+	msg.Metrics[0].Labels["k"] = "after"
+	msg.Metrics[0].Labels["added"] = "after"
+
+	if !proto.Equal(safe, snapshot) {
+		t.Error("Expected the copy to be unaffected by changes to the original")
+	}
+}
+
+func TestMarshalSafeCopyNilFields(t *testing.T) {
+	tests := []struct {
+		name string
+		msg  proto.Message
+	}{
+		{"empty validation message", &v1.ValidationTestMessage{}},
+		{"empty performance message", &v1.PerformanceTestMessage{}},
+		{"empty slices", &v1.ValidationTestMessage{ValueSliceData: []v1.DataPoint{}, Metrics: []v1.MetricPoint{}}},
+		{"nil map", &v1.ValidationTestMessage{Metrics: []v1.MetricPoint{{Name: "m"}}}},
+		{"nil tags", &v1.PerformanceTestMessage{Results: []v1.ProcessingResult{{OperationId: "op-1"}}}},
+		{"zero value element", &v1.ValidationTestMessage{ValueSliceData: []v1.DataPoint{{}}}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			decoded := roundTripSafeCopy(t, tt.msg)
+			if diff := equivalenceDiff(tt.msg, decoded.Interface()); diff != "" {
+				t.Errorf("Expected the copy to carry identical data, differs at %s", diff)
+			}
+		})
+	}
+
+	t.Run("nil nested message", func(t *testing.T) {
+		decoded := roundTripSafeCopy(t, &v1.StreamRequest{RequestId: "req-1"})
+		if decoded.Has(decoded.Descriptor().Fields().ByName("test_data")) {
+			t.Error("Expected test_data to stay unset")
+		}
+	})
+}
+
+func TestMarshalSafeCopyRejectsNil(t *testing.T) {
+	if _, err := convert.MarshalSafeCopy(nil); !errors.Is(err, convert.ErrNilMessage) {
+		t.Errorf("Expected ErrNilMessage for nil, got %v", err)
+	}
+
+	var typedNil *v1.ValidationTestMessage
+	if _, err := convert.MarshalSafeCopy(typedNil); !errors.Is(err, convert.ErrNilMessage) {
+		t.Errorf("Expected ErrNilMessage for a typed nil, got %v", err)
+	}
+
+	// proto.Marshal cannot encode a nil element, so neither can the copy
+	msg := &v1.ValidationTestMessage{PointerSliceData: []*v1.DataPoint{{Id: "p1"}, nil}}
+	if _, err := convert.MarshalSafeCopy(msg); err == nil {
+		t.Error("Expected an error for a nil pointer-slice element")
+	}
+}
+
+func TestFromDynamic(t *testing.T) {
+	for _, msg := range []proto.Message{
+		&v1.ValidationTestMessage{
+			ValueSliceData:   []v1.DataPoint{{Id: "v1", Value: 1.5, Timestamp: 100, Tags: []string{"a", "b"}}, {Id: "v2"}},
+			PointerSliceData: []*v1.DataPoint{{Id: "p1", Tags: []string{"c"}}},
+			Metrics:          []v1.MetricPoint{{Name: "cpu", Measurement: 0.5, Labels: map[string]string{"host": "a"}}},
+		},
+		&v1.PerformanceTestMessage{
+			ValueSliceData:   []v1.DataPoint{{Id: "v1", Tags: []string{"x"}}},
+			PointerSliceData: []*v1.Metadata{{Key: "k1", Attributes: map[string]string{"owner": "team-a"}}},
+			Results:          []v1.ProcessingResult{{OperationId: "op-1", Success: true, ErrorMessages: []string{"retry"}}},
+		},
+		// A value-slice message one level down
+		&v1.StreamRequest{
+			RequestId: "req-1",
+			TestData:  &v1.ValidationTestMessage{ValueSliceData: []v1.DataPoint{{Id: "v1", Tags: []string{"nested"}}}},
+		},
+	} {
+		decoded := roundTripSafeCopy(t, msg).Interface().(*dynamicpb.Message)
+
+		filled := msg.ProtoReflect().Type().New().Interface()
+		if err := convert.FromDynamic(decoded, filled); err != nil {
+			t.Fatalf("FromDynamic failed for %T: %v", msg, err)
+		}
+		// Compare the marshal-safe forms, as protoreflect cannot read value slices
+		want, err := convert.MarshalSafeCopy(msg)
+		if err != nil {
+			t.Fatalf("MarshalSafeCopy failed: %v", err)
+		}
+		got, err := convert.MarshalSafeCopy(filled)
+		if err != nil {
+			t.Fatalf("MarshalSafeCopy of the filled %T failed: %v", msg, err)
+		}
+		if !proto.Equal(want, got) {
+			t.Errorf("Expected %v, got %v", want, got)
+		}
+	}
+}
+
+func TestFromDynamicRejectsOtherTypes(t *testing.T) {
+	dynamic := dynamicpb.NewMessage((&v1.DataPoint{}).ProtoReflect().Descriptor())
+	if err := convert.FromDynamic(dynamic, &v1.ValidationTestMessage{}); err == nil {
+		t.Error("Expected an error copying a DataPoint into a ValidationTestMessage")
+	}
+
+	var typedNil *v1.ValidationTestMessage
+	if err := convert.FromDynamic(dynamic, typedNil); !errors.Is(err, convert.ErrNilMessage) {
+		t.Errorf("Expected ErrNilMessage for a typed nil, got %v", err)
+	}
+}
@@ -0,0 +1,70 @@
+package validation
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	v1 "github.com/benjamin-rood/protogo-values-validation-demo/gen/api/validation/v1"
+	"github.com/benjamin-rood/protogo-values-validation-demo/internal/server"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/types/dynamicpb"
+)
+
+func TestGenerateDataset(t *testing.T) {
+	const dataSize = 250
+
+	resp, err := server.NewValidationServer().GenerateDataset(context.Background(),
+		&v1.GenerateDatasetRequest{DataSize: dataSize, TagsPerItem: 2})
+	if err != nil {
+		t.Fatalf("GenerateDataset failed: %v", err)
+	}
+
+	// The generated type cannot unmarshal value slices, so decode dynamically
+	desc := (&v1.PerformanceTestMessage{}).ProtoReflect().Descriptor()
+	dataset := dynamicpb.NewMessage(desc)
+	if err := proto.Unmarshal(resp.Dataset, dataset); err != nil {
+		t.Fatalf("Unmarshal failed: %v", err)
+	}
+
+	for _, name := range []string{"value_slice_data", "pointer_slice_data"} {
+		fd := desc.Fields().ByName(protoreflect.Name(name))
+		if got := dataset.Get(fd).List().Len(); got != dataSize {
+			t.Errorf("Expected %d %s elements, got %d", dataSize, name, got)
+		}
+	}
+
+	first := dataset.Get(desc.Fields().ByName("value_slice_data")).List().Get(0).Message()
+	if tags := first.Get(first.Descriptor().Fields().ByName("tags")).List().Len(); tags != 2 {
+		t.Errorf("Expected 2 tags per data point, got %d", tags)
+	}
+}
+
+func TestGenerateDatasetLimits(t *testing.T) {
+	validationServer := server.NewValidationServer(server.WithMaxDataSize(100))
+
+	_, err := validationServer.GenerateDataset(context.Background(), &v1.GenerateDatasetRequest{DataSize: 101})
+	if !errors.Is(err, server.ErrDataSizeTooLarge) || status.Code(err) != codes.InvalidArgument {
+		t.Errorf("Expected ErrDataSizeTooLarge with InvalidArgument, got %v", err)
+	}
+
+	if err := server.ValidateRequest(&v1.GenerateDatasetRequest{}); !errors.Is(err, server.ErrInvalidDataSize) {
+		t.Errorf("Expected ErrInvalidDataSize, got %v", err)
+	}
+	if err := server.ValidateRequest(&v1.GenerateDatasetRequest{DataSize: 1, TagsPerItem: -1}); !errors.Is(err, server.ErrInvalidItemEntries) {
+		t.Errorf("Expected ErrInvalidItemEntries, got %v", err)
+	}
+}
+
+func TestGenerateDatasetCanceled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	_, err := server.NewValidationServer().GenerateDataset(ctx, &v1.GenerateDatasetRequest{DataSize: 100})
+	if status.Code(err) != codes.Canceled {
+		t.Errorf("Expected Canceled, got %v", err)
+	}
+}
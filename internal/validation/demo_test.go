@@ -0,0 +1,58 @@
+package validation
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/benjamin-rood/protogo-values-validation-demo/internal/server"
+)
+
+func TestDemoHandler(t *testing.T) {
+	rec := httptest.NewRecorder()
+	server.DemoHandler().ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/demo", nil))
+
+	if rec.Code != http.StatusOK {
+		t.Fatalf("Expected status 200, got %d: %s", rec.Code, rec.Body)
+	}
+	if ct := rec.Header().Get("Content-Type"); ct != "application/json" {
+		t.Errorf("Expected application/json, got %q", ct)
+	}
+
+	var report server.DemoReport
+	if err := json.NewDecoder(rec.Body).Decode(&report); err != nil {
+		t.Fatalf("Failed to decode report: %v", err)
+	}
+
+	expected := map[string]bool{
+		"ValidationTestMessage.ValueSliceData":   true,
+		"ValidationTestMessage.PointerSliceData": false,
+		"ValidationTestMessage.Metrics":          true,
+		"ValidateTypesResponse.Results":          false,
+	}
+	if len(report.Fields) != len(expected) {
+		t.Errorf("Expected %d fields, got %v", len(expected), report.Fields)
+	}
+	for _, field := range report.Fields {
+		transformed, ok := expected[field.Field]
+		if !ok {
+			t.Errorf("Unexpected field %s", field.Field)
+			continue
+		}
+		if field.Transformed != transformed {
+			t.Errorf("Expected %s transformed=%v, got %v (%s)", field.Field, transformed, field.Transformed, field.GoType)
+		}
+		if !field.Correct {
+			t.Errorf("Expected %s to be %s, got %s", field.Field, field.ExpectedType, field.GoType)
+		}
+	}
+
+	// Marshaling populated value slices panics, and the server survived it
+	if report.MarshalSafe {
+		t.Error("Expected marshaling to be reported unsafe")
+	}
+	if report.PanicMessage == "" {
+		t.Error("Expected the recovered panic message")
+	}
+}
@@ -0,0 +1,318 @@
+package validation
+
+import (
+	"context"
+	"sort"
+	"testing"
+	"time"
+
+	v1 "github.com/benjamin-rood/protogo-values-validation-demo/gen/api/validation/v1"
+	"github.com/benjamin-rood/protogo-values-validation-demo/internal/server"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/encoding/protowire"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protodesc"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/reflect/protoregistry"
+	"google.golang.org/protobuf/types/descriptorpb"
+)
+
+// descriptorSet serializes fd and its transitive imports in dependency order,
+// as `protoc --include_imports --descriptor_set_out` would
+func descriptorSet(t *testing.T, fd protoreflect.FileDescriptor) []byte {
+	t.Helper()
+
+	set := &descriptorpb.FileDescriptorSet{}
+	seen := map[string]bool{}
+
+	var add func(protoreflect.FileDescriptor)
+	add = func(fd protoreflect.FileDescriptor) {
+		if seen[fd.Path()] {
+			return
+		}
+		seen[fd.Path()] = true
+
+		imports := fd.Imports()
+		for i := 0; i < imports.Len(); i++ {
+			add(imports.Get(i).FileDescriptor)
+		}
+		set.File = append(set.File, protodesc.ToFileDescriptorProto(fd))
+	}
+	add(fd)
+
+	b, err := proto.Marshal(set)
+	if err != nil {
+		t.Fatalf("Failed to marshal descriptor set: %v", err)
+	}
+	return b
+}
+
+func annotatedFields(resp *v1.AnalyzeDescriptorSetResponse) []string {
+	var fields []string
+	for _, f := range resp.Fields {
+		if f.ValueSlice {
+			fields = append(fields, f.Message+"."+f.Field+" "+f.Option)
+		}
+	}
+	sort.Strings(fields)
+	return fields
+}
+
+func TestAnalyzeDescriptorSet(t *testing.T) {
+	cleanup := setupTestServer()
+	defer cleanup()
+
+	client, closeConn := createTestClient(t)
+	defer closeConn()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	resp, err := client.AnalyzeDescriptorSet(ctx, &v1.AnalyzeDescriptorSetRequest{
+		DescriptorSet: descriptorSet(t, v1.File_api_validation_v1_types_proto),
+	})
+	if err != nil {
+		t.Fatalf("AnalyzeDescriptorSet failed: %v", err)
+	}
+
+	expected := []string{
+		"validation.v1.PerformanceTestMessage.results value_slice",
+		"validation.v1.PerformanceTestMessage.value_slice_data value_slice",
+		"validation.v1.ValidationTestMessage.metrics field_opts.value_slice",
+		"validation.v1.ValidationTestMessage.value_slice_data value_slice",
+	}
+
+	got := annotatedFields(resp)
+	if len(got) != len(expected) {
+		t.Fatalf("Expected annotated fields %v, got %v", expected, got)
+	}
+	for i := range expected {
+		if got[i] != expected[i] {
+			t.Errorf("Expected annotated field %q, got %q", expected[i], got[i])
+		}
+	}
+
+	if resp.AnnotatedCount != int32(len(expected)) {
+		t.Errorf("Expected annotated count %d, got %d", len(expected), resp.AnnotatedCount)
+	}
+	if resp.IssueCount != 0 {
+		t.Errorf("Expected no issues, got %d", resp.IssueCount)
+	}
+
+	// Unannotated fields are still reported
+	found := false
+	for _, f := range resp.Fields {
+		if f.Message == "validation.v1.ValidationTestMessage" && f.Field == "pointer_slice_data" {
+			found = true
+			if f.ValueSlice {
+				t.Errorf("Expected pointer_slice_data to be unannotated")
+			}
+		}
+	}
+	if !found {
+		t.Errorf("Expected pointer_slice_data in the analyzed fields")
+	}
+}
+
+func TestAnalyzeDescriptorSetIssues(t *testing.T) {
+	cleanup := setupTestServer()
+	defer cleanup()
+
+	client, closeConn := createTestClient(t)
+	defer closeConn()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	xt, err := protoregistry.GlobalTypes.FindExtensionByName("protogo_values.value_slice")
+	if err != nil {
+		t.Fatalf("Failed to resolve value_slice extension: %v", err)
+	}
+
+	// The option is applied to a scalar field, which the plugin cannot transform
+	options := &descriptorpb.FieldOptions{}
+	raw := protowire.AppendTag(nil, xt.TypeDescriptor().Number(), protowire.VarintType)
+	raw = protowire.AppendVarint(raw, 1)
+	options.ProtoReflect().SetUnknown(raw)
+
+	set := &descriptorpb.FileDescriptorSet{
+		File: []*descriptorpb.FileDescriptorProto{{
+			Name:    proto.String("custom.proto"),
+			Package: proto.String("custom"),
+			Syntax:  proto.String("proto3"),
+			MessageType: []*descriptorpb.DescriptorProto{{
+				Name: proto.String("Sample"),
+				Field: []*descriptorpb.FieldDescriptorProto{{
+					Name:     proto.String("count"),
+					Number:   proto.Int32(1),
+					Label:    descriptorpb.FieldDescriptorProto_LABEL_OPTIONAL.Enum(),
+					Type:     descriptorpb.FieldDescriptorProto_TYPE_INT32.Enum(),
+					JsonName: proto.String("count"),
+					Options:  options,
+				}},
+			}},
+		}},
+	}
+
+	b, err := proto.Marshal(set)
+	if err != nil {
+		t.Fatalf("Failed to marshal descriptor set: %v", err)
+	}
+
+	resp, err := client.AnalyzeDescriptorSet(ctx, &v1.AnalyzeDescriptorSetRequest{DescriptorSet: b})
+	if err != nil {
+		t.Fatalf("AnalyzeDescriptorSet failed: %v", err)
+	}
+
+	if len(resp.Fields) != 1 {
+		t.Fatalf("Expected 1 field, got %d", len(resp.Fields))
+	}
+	if !resp.Fields[0].ValueSlice {
+		t.Errorf("Expected custom.Sample.count to be annotated")
+	}
+	if resp.Fields[0].Issue == "" || resp.IssueCount != 1 {
+		t.Errorf("Expected an issue for a scalar field, got %q (count %d)", resp.Fields[0].Issue, resp.IssueCount)
+	}
+}
+
+func TestAnalyzeDescriptorSetInvalid(t *testing.T) {
+	cleanup := setupTestServer()
+	defer cleanup()
+
+	client, closeConn := createTestClient(t)
+	defer closeConn()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	// Field number 0 is reserved and fails descriptor validation
+	invalidNumber, err := proto.Marshal(&descriptorpb.FileDescriptorSet{
+		File: []*descriptorpb.FileDescriptorProto{{
+			Name:    proto.String("broken.proto"),
+			Package: proto.String("broken"),
+			Syntax:  proto.String("proto3"),
+			MessageType: []*descriptorpb.DescriptorProto{{
+				Name: proto.String("Sample"),
+				Field: []*descriptorpb.FieldDescriptorProto{{
+					Name:   proto.String("count"),
+					Number: proto.Int32(0),
+					Label:  descriptorpb.FieldDescriptorProto_LABEL_OPTIONAL.Enum(),
+					Type:   descriptorpb.FieldDescriptorProto_TYPE_INT32.Enum(),
+				}},
+			}},
+		}},
+	})
+	if err != nil {
+		t.Fatalf("Failed to marshal descriptor set: %v", err)
+	}
+
+	tests := []struct {
+		name string
+		set  []byte
+	}{
+		{name: "empty", set: nil},
+		{name: "not a descriptor set", set: []byte{0xff, 0xff, 0xff}},
+		{name: "invalid field number", set: invalidNumber},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := client.AnalyzeDescriptorSet(ctx, &v1.AnalyzeDescriptorSetRequest{DescriptorSet: tt.set})
+			if status.Code(err) != codes.InvalidArgument {
+				t.Errorf("Expected InvalidArgument, got %v", err)
+			}
+		})
+	}
+}
+
+func TestAnalyzeDescriptorSetDecisions(t *testing.T) {
+	cleanup := setupTestServer()
+	defer cleanup()
+
+	client, closeConn := createTestClient(t)
+	defer closeConn()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	xt, err := protoregistry.GlobalTypes.FindExtensionByName("protogo_values.value_slice")
+	if err != nil {
+		t.Fatalf("Failed to resolve value_slice extension: %v", err)
+	}
+
+	// valueSlice sets the value_slice option explicitly
+	valueSlice := func(v uint64) *descriptorpb.FieldOptions {
+		options := &descriptorpb.FieldOptions{}
+		raw := protowire.AppendTag(nil, xt.TypeDescriptor().Number(), protowire.VarintType)
+		options.ProtoReflect().SetUnknown(protowire.AppendVarint(raw, v))
+		return options
+	}
+	repeatedItem := func(name string, number int32, options *descriptorpb.FieldOptions) *descriptorpb.FieldDescriptorProto {
+		return &descriptorpb.FieldDescriptorProto{
+			Name:     proto.String(name),
+			Number:   proto.Int32(number),
+			Label:    descriptorpb.FieldDescriptorProto_LABEL_REPEATED.Enum(),
+			Type:     descriptorpb.FieldDescriptorProto_TYPE_MESSAGE.Enum(),
+			TypeName: proto.String(".custom.Item"),
+			JsonName: proto.String(name),
+			Options:  options,
+		}
+	}
+
+	b, err := proto.Marshal(&descriptorpb.FileDescriptorSet{
+		File: []*descriptorpb.FileDescriptorProto{{
+			Name:    proto.String("custom.proto"),
+			Package: proto.String("custom"),
+			Syntax:  proto.String("proto3"),
+			MessageType: []*descriptorpb.DescriptorProto{
+				{Name: proto.String("Item")},
+				{
+					Name: proto.String("Sample"),
+					Field: []*descriptorpb.FieldDescriptorProto{
+						repeatedItem("transformed", 1, valueSlice(1)),
+						repeatedItem("kept", 2, valueSlice(0)),
+						repeatedItem("undecided", 3, nil),
+						{
+							Name:     proto.String("count"),
+							Number:   proto.Int32(4),
+							Label:    descriptorpb.FieldDescriptorProto_LABEL_OPTIONAL.Enum(),
+							Type:     descriptorpb.FieldDescriptorProto_TYPE_INT32.Enum(),
+							JsonName: proto.String("count"),
+						},
+					},
+				},
+			},
+		}},
+	})
+	if err != nil {
+		t.Fatalf("Failed to marshal descriptor set: %v", err)
+	}
+
+	resp, err := client.AnalyzeDescriptorSet(ctx, &v1.AnalyzeDescriptorSetRequest{DescriptorSet: b})
+	if err != nil {
+		t.Fatalf("AnalyzeDescriptorSet failed: %v", err)
+	}
+
+	expected := map[string]string{
+		"transformed": server.DecisionTransformed,
+		"kept":        server.DecisionExplicitPointer,
+		"undecided":   server.DecisionUndecided,
+		"count":       "",
+	}
+	if len(resp.Fields) != len(expected) {
+		t.Fatalf("Expected %d fields, got %d", len(expected), len(resp.Fields))
+	}
+	for _, f := range resp.Fields {
+		if want := expected[f.Field]; f.Decision != want {
+			t.Errorf("%s: expected decision %q, got %q", f.Field, want, f.Decision)
+		}
+		if f.ValueSlice != (f.Field == "transformed") {
+			t.Errorf("%s: expected value_slice=%v, got %v", f.Field, f.Field == "transformed", f.ValueSlice)
+		}
+	}
+
+	if resp.UndecidedCount != 1 || resp.AnnotatedCount != 1 {
+		t.Errorf("Expected 1 undecided and 1 annotated field, got %d and %d", resp.UndecidedCount, resp.AnnotatedCount)
+	}
+}
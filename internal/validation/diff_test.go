@@ -0,0 +1,122 @@
+package validation
+
+import (
+	"context"
+	"testing"
+
+	v1 "github.com/benjamin-rood/protogo-values-validation-demo/gen/api/validation/v1"
+	"github.com/benjamin-rood/protogo-values-validation-demo/internal/server"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
+)
+
+// diffInstances marshals a and b and diffs them as messageType
+func diffInstances(t *testing.T, messageType string, a, b proto.Message) *v1.DiffMessagesResponse {
+	t.Helper()
+
+	aBytes, err := proto.Marshal(a)
+	if err != nil {
+		t.Fatalf("Marshal a failed: %v", err)
+	}
+	bBytes, err := proto.Marshal(b)
+	if err != nil {
+		t.Fatalf("Marshal b failed: %v", err)
+	}
+
+	resp, err := server.NewValidationServer().DiffMessages(context.Background(),
+		&v1.DiffMessagesRequest{MessageType: messageType, A: aBytes, B: bBytes})
+	if err != nil {
+		t.Fatalf("DiffMessages failed: %v", err)
+	}
+	return resp
+}
+
+// assertDiffs checks the reported diffs against the expected path, kind and
+// rendered values, in order
+func assertDiffs(t *testing.T, resp *v1.DiffMessagesResponse, want ...*v1.FieldDiff) {
+	t.Helper()
+
+	if resp.Equal != (len(want) == 0) {
+		t.Errorf("Expected equal=%v, got %v", len(want) == 0, resp.Equal)
+	}
+	if len(resp.Diffs) != len(want) {
+		t.Fatalf("Expected %d diffs, got %v", len(want), resp.Diffs)
+	}
+	for i, got := range resp.Diffs {
+		if !proto.Equal(got, want[i]) {
+			t.Errorf("Expected diff %d to be %v, got %v", i, want[i], got)
+		}
+	}
+}
+
+func TestDiffMessagesScalar(t *testing.T) {
+	a := &v1.DataPoint{Id: "p1", Value: 1.5, Timestamp: 100}
+	b := &v1.DataPoint{Id: "p2", Value: 2.5, Timestamp: 100}
+
+	assertDiffs(t, diffInstances(t, "DataPoint", a, b),
+		&v1.FieldDiff{Path: "id", Kind: server.DiffChanged, A: `"p1"`, B: `"p2"`},
+		&v1.FieldDiff{Path: "value", Kind: server.DiffChanged, A: "1.5", B: "2.5"},
+	)
+}
+
+func TestDiffMessagesRepeatedLength(t *testing.T) {
+	a := &v1.DataPoint{Id: "p", Tags: []string{"x", "y", "z"}}
+	b := &v1.DataPoint{Id: "p", Tags: []string{"x", "w"}}
+
+	assertDiffs(t, diffInstances(t, "DataPoint", a, b),
+		&v1.FieldDiff{Path: "tags[1]", Kind: server.DiffChanged, A: `"y"`, B: `"w"`},
+		&v1.FieldDiff{Path: "tags[2]", Kind: server.DiffRemoved, A: `"z"`},
+	)
+}
+
+func TestDiffMessagesMapValue(t *testing.T) {
+	a := &v1.MetricPoint{Name: "cpu", Labels: map[string]string{"env": "prod", "host": "a"}}
+	b := &v1.MetricPoint{Name: "cpu", Labels: map[string]string{"env": "dev", "host": "a", "zone": "z1"}}
+
+	assertDiffs(t, diffInstances(t, "MetricPoint", a, b),
+		&v1.FieldDiff{Path: `labels["env"]`, Kind: server.DiffChanged, A: `"prod"`, B: `"dev"`},
+		&v1.FieldDiff{Path: `labels["zone"]`, Kind: server.DiffAdded, B: `"z1"`},
+	)
+}
+
+func TestDiffMessagesNested(t *testing.T) {
+	a := &v1.AggregateMetricsRequest{Points: []*v1.MetricPoint{
+		{Name: "cpu", Labels: map[string]string{"env": "prod"}},
+	}}
+	b := &v1.AggregateMetricsRequest{Points: []*v1.MetricPoint{
+		{Name: "cpu", Labels: map[string]string{"env": "dev"}},
+		{Name: "mem"},
+	}}
+
+	assertDiffs(t, diffInstances(t, "validation.v1.AggregateMetricsRequest", a, b),
+		&v1.FieldDiff{Path: `points[0].labels["env"]`, Kind: server.DiffChanged, A: `"prod"`, B: `"dev"`},
+		&v1.FieldDiff{Path: "points[1]", Kind: server.DiffAdded, B: `{"name":"mem"}`},
+	)
+}
+
+func TestDiffMessagesEqual(t *testing.T) {
+	point := &v1.MetricPoint{Name: "cpu", Measurement: 0.5, Labels: map[string]string{"env": "prod"}}
+
+	assertDiffs(t, diffInstances(t, "MetricPoint", point, proto.Clone(point)))
+}
+
+func TestDiffMessagesErrors(t *testing.T) {
+	validationServer := server.NewValidationServer()
+
+	tests := []struct {
+		name string
+		req  *v1.DiffMessagesRequest
+		code codes.Code
+	}{
+		{"missing type", &v1.DiffMessagesRequest{}, codes.InvalidArgument},
+		{"unknown type", &v1.DiffMessagesRequest{MessageType: "NoSuchMessage"}, codes.NotFound},
+		{"malformed instance", &v1.DiffMessagesRequest{MessageType: "DataPoint", B: []byte{0xff}}, codes.InvalidArgument},
+	}
+	for _, tt := range tests {
+		_, err := validationServer.DiffMessages(context.Background(), tt.req)
+		if status.Code(err) != tt.code {
+			t.Errorf("%s: expected %v, got %v", tt.name, tt.code, err)
+		}
+	}
+}
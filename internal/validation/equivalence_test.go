@@ -0,0 +1,262 @@
+package validation
+
+import (
+	"bytes"
+	"fmt"
+	"reflect"
+	"strings"
+	"testing"
+
+	v1 "github.com/benjamin-rood/protogo-values-validation-demo/gen/api/validation/v1"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/types/dynamicpb"
+)
+
+// Value-slice fields cannot be read through protoreflect, so top-level
+// repeated message fields are accessed through the Go struct and every element
+// is reflected individually through its address.
+
+// repeatedMessageElements returns the elements of a repeated message field,
+// addressing value-slice elements in place rather than copying them
+func repeatedMessageElements(msg proto.Message, fd protoreflect.FieldDescriptor) ([]protoreflect.Message, error) {
+	if !fd.IsList() || fd.Kind() != protoreflect.MessageKind {
+		return nil, fmt.Errorf("%s: only repeated message fields are supported", fd.Name())
+	}
+
+	rv := reflect.ValueOf(msg).Elem()
+	tag := fmt.Sprintf("name=%s,", fd.Name())
+
+	for i := 0; i < rv.NumField(); i++ {
+		if !strings.Contains(rv.Type().Field(i).Tag.Get("protobuf"), tag) {
+			continue
+		}
+
+		slice := rv.Field(i)
+		elems := make([]protoreflect.Message, slice.Len())
+		for j := range elems {
+			elem := slice.Index(j)
+			if elem.Kind() != reflect.Pointer {
+				elem = elem.Addr()
+			}
+			elems[j] = elem.Interface().(proto.Message).ProtoReflect()
+		}
+		return elems, nil
+	}
+
+	return nil, fmt.Errorf("%s: no Go field found", fd.Name())
+}
+
+// toPointerMessage converts a message with value slices into a dynamic message
+// of the same type whose repeated fields hold independent pointer elements
+func toPointerMessage(msg proto.Message) (*dynamicpb.Message, error) {
+	desc := msg.ProtoReflect().Descriptor()
+	out := dynamicpb.NewMessage(desc)
+
+	fields := desc.Fields()
+	for i := 0; i < fields.Len(); i++ {
+		fd := fields.Get(i)
+		elems, err := repeatedMessageElements(msg, fd)
+		if err != nil {
+			return nil, err
+		}
+
+		list := out.Mutable(fd).List()
+		for _, elem := range elems {
+			list.Append(protoreflect.ValueOfMessage(proto.Clone(elem.Interface()).ProtoReflect()))
+		}
+	}
+
+	return out, nil
+}
+
+// equivalenceDiff returns the path of the first field at which valueMsg and
+// pointerMsg differ, or "" when they carry identical data
+func equivalenceDiff(valueMsg, pointerMsg proto.Message) string {
+	desc := valueMsg.ProtoReflect().Descriptor()
+	if got := pointerMsg.ProtoReflect().Descriptor().FullName(); got != desc.FullName() {
+		return fmt.Sprintf("<root>: message type %s != %s", desc.FullName(), got)
+	}
+
+	fields := desc.Fields()
+	for i := 0; i < fields.Len(); i++ {
+		fd := fields.Get(i)
+		path := string(fd.Name())
+
+		elems, err := repeatedMessageElements(valueMsg, fd)
+		if err != nil {
+			return path + ": " + err.Error()
+		}
+
+		list := pointerMsg.ProtoReflect().Get(fd).List()
+		if len(elems) != list.Len() {
+			return fmt.Sprintf("%s: length %d != %d", path, len(elems), list.Len())
+		}
+		for j, elem := range elems {
+			if diff := diffMessages(fmt.Sprintf("%s[%d]", path, j), elem, list.Get(j).Message()); diff != "" {
+				return diff
+			}
+		}
+	}
+
+	return ""
+}
+
+// diffMessages compares two reflectable messages field by field
+func diffMessages(path string, a, b protoreflect.Message) string {
+	fields := a.Descriptor().Fields()
+	for i := 0; i < fields.Len(); i++ {
+		fd := fields.Get(i)
+		fieldPath := path + "." + string(fd.Name())
+
+		av, bv := a.Get(fd), b.Get(fd)
+		switch {
+		case fd.IsList():
+			al, bl := av.List(), bv.List()
+			if al.Len() != bl.Len() {
+				return fmt.Sprintf("%s: length %d != %d", fieldPath, al.Len(), bl.Len())
+			}
+			for j := 0; j < al.Len(); j++ {
+				if diff := diffValues(fmt.Sprintf("%s[%d]", fieldPath, j), fd, al.Get(j), bl.Get(j)); diff != "" {
+					return diff
+				}
+			}
+		case fd.IsMap():
+			am, bm := av.Map(), bv.Map()
+			if am.Len() != bm.Len() {
+				return fmt.Sprintf("%s: length %d != %d", fieldPath, am.Len(), bm.Len())
+			}
+			var diff string
+			am.Range(func(k protoreflect.MapKey, v protoreflect.Value) bool {
+				keyPath := fmt.Sprintf("%s[%q]", fieldPath, k.String())
+				if !bm.Has(k) {
+					diff = keyPath + ": missing"
+					return false
+				}
+				diff = diffValues(keyPath, fd.MapValue(), v, bm.Get(k))
+				return diff == ""
+			})
+			if diff != "" {
+				return diff
+			}
+		default:
+			if diff := diffValues(fieldPath, fd, av, bv); diff != "" {
+				return diff
+			}
+		}
+	}
+	return ""
+}
+
+// diffValues compares a single scalar or message value
+func diffValues(path string, fd protoreflect.FieldDescriptor, a, b protoreflect.Value) string {
+	switch fd.Kind() {
+	case protoreflect.MessageKind, protoreflect.GroupKind:
+		return diffMessages(path, a.Message(), b.Message())
+	case protoreflect.BytesKind:
+		if !bytes.Equal(a.Bytes(), b.Bytes()) {
+			return fmt.Sprintf("%s: %x != %x", path, a.Bytes(), b.Bytes())
+		}
+	default:
+		if a.Interface() != b.Interface() {
+			return fmt.Sprintf("%s: %v != %v", path, a.Interface(), b.Interface())
+		}
+	}
+	return ""
+}
+
+// assertEquivalent fails the test if the value-slice message and its
+// pointer-slice conversion differ in any field
+func assertEquivalent(t *testing.T, valueMsg, pointerMsg proto.Message) {
+	t.Helper()
+	if diff := equivalenceDiff(valueMsg, pointerMsg); diff != "" {
+		t.Errorf("Messages are not equivalent at %s", diff)
+	}
+}
+
+func newEquivalenceTestMessage() *v1.ValidationTestMessage {
+	return &v1.ValidationTestMessage{
+		ValueSliceData: []v1.DataPoint{
+			{Id: "v0", Value: 1.5, Timestamp: 100, Tags: []string{"a", "b"}},
+			{Id: "v1", Value: 2.5, Timestamp: 101, Tags: []string{"c"}},
+		},
+		PointerSliceData: createDataPointPointers(3),
+		Metrics: []v1.MetricPoint{
+			{Name: "cpu", Measurement: 0.5, Labels: map[string]string{"env": "test", "host": "h1"}},
+		},
+	}
+}
+
+func TestAssertEquivalentConversion(t *testing.T) {
+	t.Run("ValidationTestMessage", func(t *testing.T) {
+		msg := newEquivalenceTestMessage()
+		converted, err := toPointerMessage(msg)
+		if err != nil {
+			t.Fatalf("Conversion failed: %v", err)
+		}
+		assertEquivalent(t, msg, converted)
+	})
+
+	t.Run("PerformanceTestMessage", func(t *testing.T) {
+		msg := createPerformanceTestMessage(5)
+		converted, err := toPointerMessage(msg)
+		if err != nil {
+			t.Fatalf("Conversion failed: %v", err)
+		}
+		assertEquivalent(t, msg, converted)
+	})
+}
+
+func TestEquivalenceDiffReportsFieldPath(t *testing.T) {
+	tests := []struct {
+		name         string
+		corrupt      func(msg *v1.ValidationTestMessage)
+		expectedPath string
+	}{
+		{
+			name:         "scalar in value slice",
+			corrupt:      func(msg *v1.ValidationTestMessage) { msg.ValueSliceData[1].Value = -1 },
+			expectedPath: "value_slice_data[1].value",
+		},
+		{
+			name:         "repeated scalar",
+			corrupt:      func(msg *v1.ValidationTestMessage) { msg.ValueSliceData[0].Tags[1] = "corrupt" },
+			expectedPath: "value_slice_data[0].tags[1]",
+		},
+		{
+			name:         "nested map value",
+			corrupt:      func(msg *v1.ValidationTestMessage) { msg.Metrics[0].Labels["env"] = "prod" },
+			expectedPath: `metrics[0].labels["env"]`,
+		},
+		{
+			name:         "pointer slice element",
+			corrupt:      func(msg *v1.ValidationTestMessage) { msg.PointerSliceData[2].Id = "corrupt" },
+			expectedPath: "pointer_slice_data[2].id",
+		},
+		{
+			name:         "dropped element",
+			corrupt:      func(msg *v1.ValidationTestMessage) { msg.ValueSliceData = msg.ValueSliceData[:1] },
+			expectedPath: "value_slice_data: length 1 != 2",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			msg := newEquivalenceTestMessage()
+			converted, err := toPointerMessage(msg)
+			if err != nil {
+				t.Fatalf("Conversion failed: %v", err)
+			}
+
+			tt.corrupt(msg)
+
+			diff := equivalenceDiff(msg, converted)
+			if diff == "" {
+				t.Fatal("Expected corrupted message to be reported as not equivalent")
+			}
+			if !strings.HasPrefix(diff, tt.expectedPath) {
+				t.Errorf("Expected diff at %s, got %s", tt.expectedPath, diff)
+			}
+		})
+	}
+}
@@ -0,0 +1,172 @@
+package validation
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"testing"
+	"time"
+
+	v1 "github.com/benjamin-rood/protogo-values-validation-demo/gen/api/validation/v1"
+	"github.com/benjamin-rood/protogo-values-validation-demo/internal/server"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func TestBenchmarkParameterErrors(t *testing.T) {
+	tests := []struct {
+		name     string
+		req      *v1.BenchmarkRequest
+		expected error
+	}{
+		{
+			name:     "zero iterations",
+			req:      &v1.BenchmarkRequest{Iterations: 0, DataSize: 10},
+			expected: server.ErrInvalidIterations,
+		},
+		{
+			name:     "negative data size",
+			req:      &v1.BenchmarkRequest{Iterations: 10, DataSize: -1},
+			expected: server.ErrInvalidDataSize,
+		},
+	}
+
+	cleanup := setupTestServer()
+	defer cleanup()
+
+	client, closeConn := createTestClient(t)
+	defer closeConn()
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// In-process callers validate first, and can match the sentinel and the code
+			inProcessErr := server.ValidateRequest(tt.req)
+			if !errors.Is(inProcessErr, tt.expected) {
+				t.Fatalf("Expected errors.Is(%v, %v)", inProcessErr, tt.expected)
+			}
+			if status.Code(inProcessErr) != codes.InvalidArgument {
+				t.Errorf("Expected InvalidArgument in-process, got %v", status.Code(inProcessErr))
+			}
+
+			// Remote callers still receive the status code and message
+			ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+			defer cancel()
+
+			_, err := client.RunBenchmarks(ctx, tt.req)
+			st, ok := status.FromError(err)
+			if !ok || st.Code() != codes.InvalidArgument {
+				t.Fatalf("Expected InvalidArgument remotely, got %v", err)
+			}
+			if st.Message() != inProcessErr.Error() {
+				t.Errorf("Expected remote message to match in-process error, got %q", st.Message())
+			}
+		})
+	}
+}
+
+func TestValidateTypesErrorOnFailure(t *testing.T) {
+	// Expecting pointer elements for a scalar slice fails validation
+	validationServer := server.NewValidationServer(
+		server.WithTypeExpectations(server.TypeExpectations{"DataPoint.Tags": "[]*string"}),
+	)
+	conn, cleanup := setupCustomTestServer(t, func(s *grpc.Server) {
+		v1.RegisterValidationServiceServer(s, validationServer)
+	})
+	defer cleanup()
+	client := v1.NewValidationServiceClient(conn)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	// Default behaviour is unchanged: OK with success=false
+	resp, err := client.ValidateTypes(ctx, &v1.ValidateTypesRequest{})
+	if err != nil {
+		t.Fatalf("Expected OK status by default, got %v", err)
+	}
+	if resp.Success {
+		t.Fatal("Expected validation to fail")
+	}
+
+	_, err = client.ValidateTypes(ctx, &v1.ValidateTypesRequest{ErrorOnFailure: true})
+	st, ok := status.FromError(err)
+	if !ok {
+		t.Fatalf("Expected a gRPC status, got %v", err)
+	}
+	if st.Code() != codes.FailedPrecondition {
+		t.Errorf("Expected FailedPrecondition, got %v", st.Code())
+	}
+
+	var badRequest *errdetails.BadRequest
+	for _, detail := range st.Details() {
+		if br, ok := detail.(*errdetails.BadRequest); ok {
+			badRequest = br
+		}
+	}
+	if badRequest == nil {
+		t.Fatalf("Expected BadRequest details, got %v", st.Details())
+	}
+
+	if len(badRequest.FieldViolations) != 1 {
+		t.Fatalf("Expected 1 field violation, got %d", len(badRequest.FieldViolations))
+	}
+	violation := badRequest.FieldViolations[0]
+	if violation.Field != "DataPoint.Tags" {
+		t.Errorf("Expected violation for DataPoint.Tags, got %q", violation.Field)
+	}
+	if violation.Description != "expected []*string, got []string" {
+		t.Errorf("Expected type mismatch description, got %q", violation.Description)
+	}
+}
+
+func TestValidateTypesErrorOnFailurePassing(t *testing.T) {
+	conn, cleanup := setupCustomTestServer(t, func(s *grpc.Server) {
+		v1.RegisterValidationServiceServer(s, server.NewValidationServer())
+	})
+	defer cleanup()
+	client := v1.NewValidationServiceClient(conn)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	resp, err := client.ValidateTypes(ctx, &v1.ValidateTypesRequest{ErrorOnFailure: true})
+	if err != nil {
+		t.Fatalf("Expected a passing validation to return OK, got %v", err)
+	}
+	if !resp.Success {
+		t.Error("Expected validation to succeed")
+	}
+}
+
+func TestValidationErrorAs(t *testing.T) {
+	// Expecting pointer elements for scalar slices fails validation
+	validationServer := server.NewValidationServer(
+		server.WithTypeExpectations(server.TypeExpectations{
+			"DataPoint.Tags":                 "[]*string",
+			"ProcessingResult.ErrorMessages": "[]*string",
+		}),
+	)
+
+	_, err := validationServer.ValidateTypes(context.Background(), &v1.ValidateTypesRequest{ErrorOnFailure: true})
+
+	var validationErr *server.ValidationError
+	if !errors.As(fmt.Errorf("readiness: %w", err), &validationErr) {
+		t.Fatalf("Expected a *ValidationError, got %v", err)
+	}
+	expected := []string{"DataPoint.Tags", "ProcessingResult.ErrorMessages"}
+	if !slices.Equal(validationErr.Scenarios(), expected) {
+		t.Errorf("Expected failing scenarios %v, got %v", expected, validationErr.Scenarios())
+	}
+	if status.Code(err) != codes.FailedPrecondition {
+		t.Errorf("Expected FailedPrecondition, got %v", status.Code(err))
+	}
+}
+
+func TestNewValidationErrorAllPassed(t *testing.T) {
+	results := []*v1.ValidationResult{server.NewValidationResult("DataPoint.Tags", "[]string", "[]string")}
+	if err := server.NewValidationError(results); err != nil {
+		t.Errorf("Expected nil when every result passes, got %v", err)
+	}
+}
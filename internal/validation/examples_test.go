@@ -0,0 +1,140 @@
+package validation
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	v1 "github.com/benjamin-rood/protogo-values-validation-demo/gen/api/validation/v1"
+	"github.com/benjamin-rood/protogo-values-validation-demo/internal/server"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/reflect/protoregistry"
+)
+
+func TestGetExampleRoundTrip(t *testing.T) {
+	cleanup := setupTestServer()
+	defer cleanup()
+
+	client, closeConn := createTestClient(t)
+	defer closeConn()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	for _, messageType := range server.ExampleTypes() {
+		t.Run(messageType, func(t *testing.T) {
+			resp, err := client.GetExample(ctx, &v1.GetExampleRequest{MessageType: messageType})
+			if err != nil {
+				t.Fatalf("GetExample failed: %v", err)
+			}
+			if resp.MessageType != messageType {
+				t.Errorf("Expected message type %s, got %s", messageType, resp.MessageType)
+			}
+
+			mt, err := protoregistry.GlobalTypes.FindMessageByName(protoreflect.FullName(messageType))
+			if err != nil {
+				t.Fatalf("Unknown message type: %v", err)
+			}
+
+			msg := mt.New().Interface()
+			if err := protojson.Unmarshal([]byte(resp.Json), msg); err != nil {
+				t.Fatalf("Example does not unmarshal into %s: %v", messageType, err)
+			}
+
+			// Re-encoding the decoded message must reproduce the example exactly
+			again, err := server.MarshalExampleJSON(msg)
+			if err != nil {
+				t.Fatalf("Failed to re-marshal example: %v", err)
+			}
+			if again != resp.Json {
+				t.Errorf("Expected round trip to preserve the example\nwant: %s\ngot:  %s", resp.Json, again)
+			}
+
+			second, err := client.GetExample(ctx, &v1.GetExampleRequest{MessageType: messageType})
+			if err != nil {
+				t.Fatalf("GetExample failed: %v", err)
+			}
+			if second.Json != resp.Json {
+				t.Errorf("Expected deterministic examples, got\n%s\nthen\n%s", resp.Json, second.Json)
+			}
+		})
+	}
+}
+
+func TestGetExampleValueSlices(t *testing.T) {
+	resp, err := server.NewValidationServer().GetExample(context.Background(), &v1.GetExampleRequest{MessageType: "ValidationTestMessage"})
+	if err != nil {
+		t.Fatalf("GetExample failed: %v", err)
+	}
+
+	var msg v1.ValidationTestMessage
+	if err := protojson.Unmarshal([]byte(resp.Json), &msg); err != nil {
+		t.Fatalf("Failed to unmarshal example: %v", err)
+	}
+
+	if len(msg.ValueSliceData) != 2 || len(msg.PointerSliceData) != 1 || len(msg.Metrics) != 1 {
+		t.Errorf("Expected 2 value-slice, 1 pointer-slice and 1 metric element, got %d, %d and %d",
+			len(msg.ValueSliceData), len(msg.PointerSliceData), len(msg.Metrics))
+	}
+	if msg.ValueSliceData[0].Id != "dp_0" {
+		t.Errorf("Expected first data point dp_0, got %q", msg.ValueSliceData[0].Id)
+	}
+}
+
+func TestGetExampleErrors(t *testing.T) {
+	s := server.NewValidationServer()
+
+	tests := []struct {
+		name         string
+		messageType  string
+		expectedCode codes.Code
+	}{
+		{"empty", "", codes.InvalidArgument},
+		{"unknown", "validation.v1.Unknown", codes.NotFound},
+		{"wrong package", "other.v1.DataPoint", codes.NotFound},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := s.GetExample(context.Background(), &v1.GetExampleRequest{MessageType: tt.messageType})
+			if status.Code(err) != tt.expectedCode {
+				t.Errorf("Expected %v, got %v", tt.expectedCode, err)
+			}
+		})
+	}
+}
+
+func TestExampleHandler(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /examples/{type}", server.ExampleHandler(server.NewValidationServer()))
+
+	tests := []struct {
+		path         string
+		expectedCode int
+	}{
+		{"/examples/DataPoint", http.StatusOK},
+		{"/examples/validation.v1.StreamRequest", http.StatusOK},
+		{"/examples/Unknown", http.StatusNotFound},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.path, func(t *testing.T) {
+			rec := httptest.NewRecorder()
+			mux.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, tt.path, nil))
+
+			if rec.Code != tt.expectedCode {
+				t.Fatalf("Expected status %d, got %d: %s", tt.expectedCode, rec.Code, rec.Body)
+			}
+			if tt.expectedCode == http.StatusOK {
+				if ct := rec.Header().Get("Content-Type"); ct != "application/json" {
+					t.Errorf("Expected application/json, got %q", ct)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,124 @@
+package validation
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	v1 "github.com/benjamin-rood/protogo-values-validation-demo/gen/api/validation/v1"
+	"github.com/benjamin-rood/protogo-values-validation-demo/internal/server"
+	"google.golang.org/grpc"
+)
+
+// writeExpectations writes an expectations file into a temp dir
+func writeExpectations(t *testing.T, name, content string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), name)
+	if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+		t.Fatalf("Failed to write expectations: %v", err)
+	}
+	return path
+}
+
+func TestValidateTypesWithExpectationsFile(t *testing.T) {
+	path := writeExpectations(t, "expected-types.yaml", `
+# DataPoint.Tags deliberately expects pointer elements
+DataPoint.Tags: "[]*string"
+Metadata.Attributes: "map[string]string"
+validation.v1.MetricPoint.Labels: "map[string]string"
+DataPoint.Missing: "string"
+`)
+
+	expectations, err := server.LoadTypeExpectations(path)
+	if err != nil {
+		t.Fatalf("LoadTypeExpectations failed: %v", err)
+	}
+
+	validationServer := server.NewValidationServer(server.WithTypeExpectations(expectations))
+	conn, cleanup := setupCustomTestServer(t, func(s *grpc.Server) {
+		v1.RegisterValidationServiceServer(s, validationServer)
+	})
+	defer cleanup()
+	client := v1.NewValidationServiceClient(conn)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	resp, err := client.ValidateTypes(ctx, &v1.ValidateTypesRequest{})
+	if err != nil {
+		t.Fatalf("ValidateTypes failed: %v", err)
+	}
+
+	results := make(map[string]*v1.ValidationResult)
+	for _, result := range resp.Results {
+		results[result.Scenario] = result
+	}
+
+	expected := map[string]struct {
+		actualType string
+		passed     bool
+	}{
+		// Defaults still apply to fields the file does not mention
+		"ValidationTestMessage.ValueSliceData": {"[]v1.DataPoint", true},
+		"DataPoint.Tags":                       {"[]string", false},
+		"Metadata.Attributes":                  {"map[string]string", true},
+		"validation.v1.MetricPoint.Labels":     {"map[string]string", true},
+		"DataPoint.Missing":                    {server.InvalidTypeString, false},
+	}
+	for scenario, want := range expected {
+		result, ok := results[scenario]
+		if !ok {
+			t.Errorf("Expected a result for %s", scenario)
+			continue
+		}
+		if result.ActualType != want.actualType {
+			t.Errorf("Expected %s actual type %q, got %q", scenario, want.actualType, result.ActualType)
+		}
+		if result.Passed != want.passed {
+			t.Errorf("Expected %s passed=%v, got %v", scenario, want.passed, result.Passed)
+		}
+	}
+
+	if resp.Success {
+		t.Error("Expected validation to fail against the custom expectations")
+	}
+}
+
+func TestLoadTypeExpectationsJSON(t *testing.T) {
+	path := writeExpectations(t, "expected-types.json", `{"ValidationTestMessage.Metrics": "[]*v1.MetricPoint"}`)
+
+	expectations, err := server.LoadTypeExpectations(path)
+	if err != nil {
+		t.Fatalf("LoadTypeExpectations failed: %v", err)
+	}
+	if got := expectations["ValidationTestMessage.Metrics"]; got != "[]*v1.MetricPoint" {
+		t.Errorf("Expected []*v1.MetricPoint, got %q", got)
+	}
+	if len(expectations) != 1 {
+		t.Errorf("Expected only the file's entries, got %d", len(expectations))
+	}
+}
+
+func TestLoadTypeExpectationsInvalid(t *testing.T) {
+	cases := map[string]string{
+		"empty":       ``,
+		"not a map":   `["DataPoint.Tags"]`,
+		"no field":    `{"DataPoint": "[]string"}`,
+		"empty field": `{"DataPoint.": "[]string"}`,
+		"empty type":  `{"DataPoint.Tags": ""}`,
+	}
+	for name, content := range cases {
+		t.Run(name, func(t *testing.T) {
+			path := writeExpectations(t, "expected-types.yaml", content)
+			if _, err := server.LoadTypeExpectations(path); err == nil {
+				t.Error("Expected an error")
+			}
+		})
+	}
+
+	if _, err := server.LoadTypeExpectations(filepath.Join(t.TempDir(), "missing.yaml")); err == nil {
+		t.Error("Expected an error for a missing file")
+	}
+}
@@ -0,0 +1,317 @@
+package validation
+
+import (
+	"context"
+	"encoding/csv"
+	"math"
+	"net/http"
+	"net/http/httptest"
+	"regexp"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	v1 "github.com/benjamin-rood/protogo-values-validation-demo/gen/api/validation/v1"
+	"github.com/benjamin-rood/protogo-values-validation-demo/internal/server"
+)
+
+func sampleBenchmarkResults() []*v1.BenchmarkResult {
+	return []*v1.BenchmarkResult{
+		{Name: "ValueSlice_Iteration", DurationNs: 123456, Allocations: 0, BytesAllocated: 0, OperationsPerSecond: 8100.5},
+		{Name: "Serialization", DurationNs: 987654.5, Allocations: 1000, BytesAllocated: 64000, OperationsPerSecond: 1012.5},
+	}
+}
+
+func TestFormatBenchstatRoundTrip(t *testing.T) {
+	const iterations = 1000
+	results := sampleBenchmarkResults()
+
+	output := server.FormatBenchstat(results, iterations)
+
+	var lines []string
+	for _, line := range strings.Split(output, "\n") {
+		if strings.HasPrefix(line, "Benchmark") {
+			lines = append(lines, line)
+		}
+	}
+
+	if len(lines) != len(results) {
+		t.Fatalf("Expected %d benchmark lines, got %d:\n%s", len(results), len(lines), output)
+	}
+
+	for i, line := range lines {
+		// BenchmarkName-P  N  X ns/op  Y B/op  Z allocs/op
+		fields := strings.Fields(line)
+		if len(fields) != 8 {
+			t.Fatalf("Unexpected line format: %q", line)
+		}
+
+		name := strings.TrimPrefix(fields[0], "Benchmark")
+		name = name[:strings.LastIndex(name, "-")]
+		if name != results[i].Name {
+			t.Errorf("Expected name %s, got %s", results[i].Name, name)
+		}
+
+		n, err := strconv.Atoi(fields[1])
+		if err != nil || n != iterations {
+			t.Errorf("Expected N=%d, got %s", iterations, fields[1])
+		}
+
+		units := map[string]float64{}
+		for j := 2; j < len(fields); j += 2 {
+			value, err := strconv.ParseFloat(fields[j], 64)
+			if err != nil {
+				t.Fatalf("Failed to parse value %q: %v", fields[j], err)
+			}
+			units[fields[j+1]] = value
+		}
+
+		assertClose(t, "ns/op", units["ns/op"]*iterations, results[i].DurationNs)
+		assertClose(t, "B/op", units["B/op"]*iterations, float64(results[i].BytesAllocated))
+		assertClose(t, "allocs/op", units["allocs/op"]*iterations, float64(results[i].Allocations))
+	}
+}
+
+func assertClose(t *testing.T, unit string, got, want float64) {
+	t.Helper()
+	if math.Abs(got-want) > 1e-6*math.Max(1, math.Abs(want)) {
+		t.Errorf("%s: expected %v, got %v", unit, want, got)
+	}
+}
+
+func TestFormatCSVRoundTrip(t *testing.T) {
+	resp := &v1.BenchmarkResponse{
+		Results: sampleBenchmarkResults(),
+		Summary: &v1.BenchmarkSummary{
+			ValueSliceAvgDuration:       123456,
+			PointerSliceAvgDuration:     234567.25,
+			PerformanceImprovementRatio: 1.9,
+			MemorySavingsBytes:          4096,
+		},
+	}
+
+	output, err := server.FormatCSV(resp)
+	if err != nil {
+		t.Fatalf("FormatCSV failed: %v", err)
+	}
+
+	reader := csv.NewReader(strings.NewReader(output))
+	reader.FieldsPerRecord = -1
+	records, err := reader.ReadAll()
+	if err != nil {
+		t.Fatalf("Failed to parse CSV: %v", err)
+	}
+
+	// header + results + summary header + 4 summary rows (blank line is skipped)
+	if len(records) != 1+len(resp.Results)+5 {
+		t.Fatalf("Expected %d records, got %d:\n%s", 1+len(resp.Results)+5, len(records), output)
+	}
+
+	if strings.Join(records[0], ",") != "name,duration_ns,allocations,bytes_allocated,ops_per_sec,error" {
+		t.Errorf("Unexpected header: %v", records[0])
+	}
+
+	for i, result := range resp.Results {
+		row := records[i+1]
+		if row[0] != result.Name {
+			t.Errorf("Expected name %s, got %s", result.Name, row[0])
+		}
+
+		duration, _ := strconv.ParseFloat(row[1], 64)
+		allocs, _ := strconv.ParseInt(row[2], 10, 64)
+		bytesAllocated, _ := strconv.ParseInt(row[3], 10, 64)
+		ops, _ := strconv.ParseFloat(row[4], 64)
+
+		if duration != result.DurationNs || allocs != result.Allocations ||
+			bytesAllocated != result.BytesAllocated || ops != result.OperationsPerSecond {
+			t.Errorf("Row %v does not match result %v", row, result)
+		}
+	}
+
+	summary := map[string]string{}
+	for _, row := range records[len(resp.Results)+2:] {
+		summary[row[0]] = row[1]
+	}
+
+	ratio, _ := strconv.ParseFloat(summary["performance_improvement_ratio"], 64)
+	if ratio != resp.Summary.PerformanceImprovementRatio {
+		t.Errorf("Expected ratio %v, got %v", resp.Summary.PerformanceImprovementRatio, ratio)
+	}
+	if summary["memory_savings_bytes"] != "4096" {
+		t.Errorf("Expected memory savings 4096, got %s", summary["memory_savings_bytes"])
+	}
+}
+
+func TestBenchmarksCSVHandler(t *testing.T) {
+	handler := server.BenchmarksCSVHandler(server.NewValidationServer())
+
+	rec := httptest.NewRecorder()
+	handler(rec, httptest.NewRequest(http.MethodGet, "/benchmarks.csv?iterations=10&data_size=10", nil))
+
+	if rec.Code != http.StatusOK {
+		t.Fatalf("Expected status 200, got %d: %s", rec.Code, rec.Body.String())
+	}
+	if ct := rec.Header().Get("Content-Type"); ct != "text/csv" {
+		t.Errorf("Expected text/csv, got %q", ct)
+	}
+	if !strings.HasPrefix(rec.Body.String(), "name,duration_ns,") {
+		t.Errorf("Expected CSV header, got %q", rec.Body.String())
+	}
+
+	// Values the gRPC API accepts can still be too costly for this
+	// unauthenticated endpoint
+	for _, query := range []string{"iterations=0", "iterations=10001", "data_size=1001", "data_size=100000"} {
+		rec = httptest.NewRecorder()
+		handler(rec, httptest.NewRequest(http.MethodGet, "/benchmarks.csv?"+query, nil))
+		if rec.Code != http.StatusBadRequest {
+			t.Errorf("Expected status 400 for %s, got %d", query, rec.Code)
+		}
+	}
+}
+
+var (
+	openMetricsName   = regexp.MustCompile(`^[a-zA-Z_:][a-zA-Z0-9_:]*$`)
+	openMetricsSample = regexp.MustCompile(`^([a-zA-Z_:][a-zA-Z0-9_:]*)(\{(.*)\})? (\S+)( (\S+))?$`)
+	openMetricsLabel  = regexp.MustCompile(`^([a-zA-Z_][a-zA-Z0-9_]*)="((?:[^"\\\n]|\\[\\"n])*)"(,|$)`)
+)
+
+// parseOpenMetrics checks text against the basics of the OpenMetrics grammar:
+// every family declares its type before its samples, families are not
+// interleaved, label sets and numbers are well formed, and the exposition
+// ends with a single "# EOF". It returns each sample's value keyed by metric
+// name and name label.
+func parseOpenMetrics(t *testing.T, text string) map[string]float64 {
+	t.Helper()
+
+	if !strings.HasSuffix(text, "# EOF\n") {
+		t.Fatalf("Expected the exposition to end with # EOF, got:\n%s", text)
+	}
+	lines := strings.Split(strings.TrimSuffix(text, "# EOF\n"), "\n")
+	lines = lines[:len(lines)-1] // trailing newline before # EOF
+
+	samples := make(map[string]float64)
+	declared := make(map[string]bool)
+	current := ""
+
+	for i, line := range lines {
+		if line == "" {
+			t.Fatalf("Line %d: blank lines are not allowed", i+1)
+		}
+
+		if strings.HasPrefix(line, "#") {
+			fields := strings.SplitN(line, " ", 4)
+			if len(fields) < 4 || (fields[1] != "TYPE" && fields[1] != "HELP" && fields[1] != "UNIT") {
+				t.Fatalf("Line %d: malformed descriptor %q", i+1, line)
+			}
+			name := fields[2]
+			if !openMetricsName.MatchString(name) {
+				t.Fatalf("Line %d: invalid metric name %q", i+1, name)
+			}
+			if fields[1] == "TYPE" {
+				if declared[name] {
+					t.Fatalf("Line %d: family %s declared twice", i+1, name)
+				}
+				declared[name] = true
+				current = name
+			} else if name != current {
+				t.Fatalf("Line %d: %s descriptor outside its family", i+1, fields[1])
+			}
+			continue
+		}
+
+		match := openMetricsSample.FindStringSubmatch(line)
+		if match == nil {
+			t.Fatalf("Line %d: malformed sample %q", i+1, line)
+		}
+		if match[1] != current {
+			t.Fatalf("Line %d: sample of %s outside its family %s", i+1, match[1], current)
+		}
+
+		var nameLabel string
+		for labels := match[3]; labels != ""; {
+			label := openMetricsLabel.FindStringSubmatch(labels)
+			if label == nil {
+				t.Fatalf("Line %d: malformed labels %q", i+1, match[3])
+			}
+			if label[1] == "name" {
+				nameLabel = label[2]
+			}
+			labels = labels[len(label[0]):]
+		}
+
+		value, err := strconv.ParseFloat(match[4], 64)
+		if err != nil {
+			t.Fatalf("Line %d: invalid value %q", i+1, match[4])
+		}
+		if match[6] != "" {
+			if _, err := strconv.ParseFloat(match[6], 64); err != nil {
+				t.Fatalf("Line %d: invalid timestamp %q", i+1, match[6])
+			}
+		}
+		samples[match[1]+"/"+nameLabel] = value
+	}
+	return samples
+}
+
+func TestFormatOpenMetrics(t *testing.T) {
+	results := append(sampleBenchmarkResults(),
+		&v1.BenchmarkResult{Name: `Odd "name"\with` + "\nnewline", Error: "stage panicked"})
+	at := time.Unix(1700000000, 250*int64(time.Millisecond))
+
+	text := server.FormatOpenMetrics(results, at)
+	samples := parseOpenMetrics(t, text)
+
+	expected := map[string]float64{
+		"benchmark_duration_ns/ValueSlice_Iteration":           123456,
+		"benchmark_operations_per_second/ValueSlice_Iteration": 8100.5,
+		"benchmark_duration_ns/Serialization":                  987654.5,
+		"benchmark_allocations/Serialization":                  1000,
+		"benchmark_allocated_bytes/Serialization":              64000,
+		"benchmark_failed/Serialization":                       0,
+		`benchmark_failed/Odd \"name\"\\with\nnewline`:         1,
+	}
+	for key, want := range expected {
+		got, ok := samples[key]
+		if !ok {
+			t.Errorf("Expected a sample for %s in:\n%s", key, text)
+			continue
+		}
+		if got != want {
+			t.Errorf("Expected %s to be %v, got %v", key, want, got)
+		}
+	}
+
+	// Failed stages have no measurements
+	if _, ok := samples[`benchmark_duration_ns/Odd \"name\"\\with\nnewline`]; ok {
+		t.Error("Expected no duration for a failed stage")
+	}
+
+	if !strings.Contains(text, `benchmark_duration_ns{name="ValueSlice_Iteration"} 123456 1700000000.250`) {
+		t.Errorf("Expected samples stamped with the run time, got:\n%s", text)
+	}
+}
+
+func TestRunBenchmarksOpenMetricsOutput(t *testing.T) {
+	validationServer := server.NewValidationServer()
+
+	resp, err := validationServer.RunBenchmarks(context.Background(), &v1.BenchmarkRequest{Iterations: 2, DataSize: 10, OpenmetricsOutput: true})
+	if err != nil {
+		t.Fatalf("RunBenchmarks failed: %v", err)
+	}
+
+	samples := parseOpenMetrics(t, resp.Openmetrics)
+	for _, result := range resp.Results {
+		if _, ok := samples["benchmark_failed/"+result.Name]; !ok {
+			t.Errorf("Expected a benchmark_failed sample for %s", result.Name)
+		}
+	}
+
+	plain, err := validationServer.RunBenchmarks(context.Background(), &v1.BenchmarkRequest{Iterations: 2, DataSize: 10})
+	if err != nil {
+		t.Fatalf("RunBenchmarks failed: %v", err)
+	}
+	if plain.Openmetrics != "" {
+		t.Error("Expected no OpenMetrics text unless requested")
+	}
+}
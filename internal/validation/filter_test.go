@@ -0,0 +1,166 @@
+package validation
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"testing"
+	"time"
+
+	v1 "github.com/benjamin-rood/protogo-values-validation-demo/gen/api/validation/v1"
+	"github.com/benjamin-rood/protogo-values-validation-demo/internal/server"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// timestampedPoints returns one data point per timestamp
+func timestampedPoints(timestamps ...int64) []*v1.DataPoint {
+	points := make([]*v1.DataPoint, len(timestamps))
+	for i, ts := range timestamps {
+		points[i] = &v1.DataPoint{Id: fmt.Sprintf("dp-%d", ts), Value: float64(ts), Timestamp: ts}
+	}
+	return points
+}
+
+func TestFilterDataPointsBoundaries(t *testing.T) {
+	cleanup := setupTestServer()
+	defer cleanup()
+
+	client, closeConn := createTestClient(t)
+	defer closeConn()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	points := timestampedPoints(5, 10, 15, 20, 25)
+
+	tests := []struct {
+		name         string
+		start, end   int64
+		endExclusive bool
+		want         []int64
+	}{
+		{name: "inclusive range", start: 10, end: 20, want: []int64{10, 15, 20}},
+		{name: "exclusive end", start: 10, end: 20, endExclusive: true, want: []int64{10, 15}},
+		{name: "single instant", start: 15, end: 15, want: []int64{15}},
+		{name: "empty exclusive instant", start: 15, end: 15, endExclusive: true},
+		{name: "between points", start: 11, end: 14},
+		{name: "covers everything", start: 0, end: 100, want: []int64{5, 10, 15, 20, 25}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resp, err := client.FilterDataPoints(ctx, &v1.FilterDataPointsRequest{
+				Points:         points,
+				StartTimestamp: tt.start,
+				EndTimestamp:   tt.end,
+				EndExclusive:   tt.endExclusive,
+			})
+			if err != nil {
+				t.Fatalf("FilterDataPoints failed: %v", err)
+			}
+
+			var got []int64
+			for _, point := range resp.Points {
+				got = append(got, point.Timestamp)
+			}
+			if !slices.Equal(got, tt.want) {
+				t.Errorf("Expected timestamps %v, got %v", tt.want, got)
+			}
+			if resp.IncludedCount != int64(len(tt.want)) || resp.ExcludedCount != int64(len(points)-len(tt.want)) {
+				t.Errorf("Expected %d included and %d excluded, got %d and %d",
+					len(tt.want), len(points)-len(tt.want), resp.IncludedCount, resp.ExcludedCount)
+			}
+		})
+	}
+}
+
+func TestFilterDataPointsPreservesFields(t *testing.T) {
+	cleanup := setupTestServer()
+	defer cleanup()
+
+	client, closeConn := createTestClient(t)
+	defer closeConn()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	point := &v1.DataPoint{Id: "dp-1", Value: 2.5, Timestamp: 42, Tags: []string{"a", "b"}}
+	resp, err := client.FilterDataPoints(ctx, &v1.FilterDataPointsRequest{
+		Points:         []*v1.DataPoint{point},
+		StartTimestamp: 42,
+		EndTimestamp:   42,
+	})
+	if err != nil {
+		t.Fatalf("FilterDataPoints failed: %v", err)
+	}
+
+	if len(resp.Points) != 1 {
+		t.Fatalf("Expected 1 point, got %d", len(resp.Points))
+	}
+	got := resp.Points[0]
+	if got.Id != point.Id || got.Value != point.Value || !slices.Equal(got.Tags, point.Tags) {
+		t.Errorf("Expected %v, got %v", point, got)
+	}
+}
+
+func TestFilterDataPointsEmptyInput(t *testing.T) {
+	cleanup := setupTestServer()
+	defer cleanup()
+
+	client, closeConn := createTestClient(t)
+	defer closeConn()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	resp, err := client.FilterDataPoints(ctx, &v1.FilterDataPointsRequest{StartTimestamp: 0, EndTimestamp: 100})
+	if err != nil {
+		t.Fatalf("FilterDataPoints failed: %v", err)
+	}
+
+	if len(resp.Points) != 0 || resp.IncludedCount != 0 || resp.ExcludedCount != 0 {
+		t.Errorf("Expected an empty result, got %d points, %d included, %d excluded",
+			len(resp.Points), resp.IncludedCount, resp.ExcludedCount)
+	}
+}
+
+func TestFilterDataPointsInvertedRange(t *testing.T) {
+	cleanup := setupTestServer()
+	defer cleanup()
+
+	client, closeConn := createTestClient(t)
+	defer closeConn()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	_, err := client.FilterDataPoints(ctx, &v1.FilterDataPointsRequest{
+		Points:         timestampedPoints(10),
+		StartTimestamp: 20,
+		EndTimestamp:   10,
+	})
+	if status.Code(err) != codes.InvalidArgument {
+		t.Fatalf("Expected InvalidArgument, got %v", err)
+	}
+
+	// In-process callers can match the sentinel
+	_, err = server.NewValidationServer().FilterDataPoints(ctx, &v1.FilterDataPointsRequest{StartTimestamp: 20, EndTimestamp: 10})
+	if !errors.Is(err, server.ErrInvalidTimeRange) {
+		t.Errorf("Expected ErrInvalidTimeRange, got %v", err)
+	}
+}
+
+func TestFilterByTimestamp(t *testing.T) {
+	values := []v1.DataPoint{{Timestamp: 30}, {Timestamp: 10}, {Timestamp: 20}}
+
+	indices := server.FilterByTimestamp(values, server.TimestampRange{Start: 10, End: 20})
+	if !slices.Equal(indices, []int{1, 2}) {
+		t.Errorf("Expected indices [1 2], got %v", indices)
+	}
+
+	if indices := server.FilterByTimestamp(nil, server.TimestampRange{End: 100}); len(indices) != 0 {
+		t.Errorf("Expected no indices for empty input, got %v", indices)
+	}
+}
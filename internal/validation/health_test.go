@@ -0,0 +1,246 @@
+package validation
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	v1 "github.com/benjamin-rood/protogo-values-validation-demo/gen/api/validation/v1"
+	"github.com/benjamin-rood/protogo-values-validation-demo/internal/server"
+	"google.golang.org/grpc"
+)
+
+func TestHealthHandlerReportsRuntimeStats(t *testing.T) {
+	handler := server.HealthHandler(time.Now().Add(-time.Minute), server.NewValidationServer())
+
+	rec := httptest.NewRecorder()
+	handler(rec, httptest.NewRequest(http.MethodGet, "/health", nil))
+
+	if rec.Code != http.StatusOK {
+		t.Fatalf("Expected status 200, got %d", rec.Code)
+	}
+
+	if ct := rec.Header().Get("Content-Type"); ct != "application/json" {
+		t.Errorf("Expected application/json, got %q", ct)
+	}
+
+	var body map[string]any
+	if err := json.Unmarshal(rec.Body.Bytes(), &body); err != nil {
+		t.Fatalf("Expected valid JSON: %v", err)
+	}
+
+	for _, field := range []string{"uptime_seconds", "goroutines", "heap_inuse_bytes", "active_streams"} {
+		if _, ok := body[field].(float64); !ok {
+			t.Errorf("Expected numeric %s, got %v", field, body[field])
+		}
+	}
+
+	if uptime := body["uptime_seconds"].(float64); uptime < 60 {
+		t.Errorf("Expected uptime of at least 60s, got %v", uptime)
+	}
+
+	if goroutines := body["goroutines"].(float64); goroutines < 1 {
+		t.Errorf("Expected a positive goroutine count, got %v", goroutines)
+	}
+}
+
+func TestHealthHandlerReportsServerStreams(t *testing.T) {
+	validationServer := server.NewValidationServer()
+	handler := server.HealthHandler(time.Now(), validationServer)
+
+	activeStreams := func() float64 {
+		rec := httptest.NewRecorder()
+		handler(rec, httptest.NewRequest(http.MethodGet, "/health", nil))
+		var body struct {
+			ActiveStreams float64 `json:"active_streams"`
+		}
+		if err := json.Unmarshal(rec.Body.Bytes(), &body); err != nil {
+			t.Fatalf("Expected valid JSON: %v", err)
+		}
+		return body.ActiveStreams
+	}
+
+	conn, cleanup := setupCustomTestServer(t, func(s *grpc.Server) {
+		v1.RegisterValidationServiceServer(s, validationServer)
+	})
+	defer cleanup()
+	client := v1.NewValidationServiceClient(conn)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	stream, err := client.StreamValidation(ctx)
+	if err != nil {
+		t.Fatalf("Failed to create stream: %v", err)
+	}
+
+	// The handler starts asynchronously; a round trip proves it is running
+	if err := stream.Send(&v1.StreamRequest{RequestId: "health", TestData: &v1.ValidationTestMessage{}}); err != nil {
+		t.Fatalf("Failed to send: %v", err)
+	}
+	if _, err := stream.Recv(); err != nil {
+		t.Fatalf("Failed to receive: %v", err)
+	}
+
+	if active := activeStreams(); active != 1 {
+		t.Errorf("Expected 1 active stream, got %v", active)
+	}
+
+	stream.CloseSend()
+	for {
+		if _, err := stream.Recv(); err != nil {
+			break
+		}
+	}
+
+	deadline := time.Now().Add(time.Second)
+	for validationServer.ActiveStreams() != 0 && time.Now().Before(deadline) {
+		time.Sleep(5 * time.Millisecond)
+	}
+	if active := activeStreams(); active != 0 {
+		t.Errorf("Expected 0 active streams after close, got %v", active)
+	}
+}
+
+// flakyValidator fails its first failures calls, then succeeds
+type flakyValidator struct {
+	failures int
+	calls    int
+}
+
+func (v *flakyValidator) ValidateTypes(ctx context.Context, req *v1.ValidateTypesRequest) (*v1.ValidateTypesResponse, error) {
+	v.calls++
+	if v.calls <= v.failures {
+		return nil, errors.New("transient startup failure")
+	}
+	return &v1.ValidateTypesResponse{Success: true}, nil
+}
+
+func TestReadinessHandlerRetries(t *testing.T) {
+	tests := []struct {
+		name           string
+		failures       int
+		attempts       int
+		expectedStatus int
+		expectedCalls  int
+	}{
+		{"recovers after one failure", 1, 3, http.StatusOK, 2},
+		{"succeeds first time", 0, 3, http.StatusOK, 1},
+		{"exhausts attempts", 5, 3, http.StatusServiceUnavailable, 3},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			validator := &flakyValidator{failures: tt.failures}
+			handler := server.ReadinessHandler(validator, tt.attempts, time.Millisecond)
+
+			rec := httptest.NewRecorder()
+			handler(rec, httptest.NewRequest(http.MethodGet, "/ready", nil))
+
+			if rec.Code != tt.expectedStatus {
+				t.Errorf("Expected status %d, got %d: %s", tt.expectedStatus, rec.Code, rec.Body.String())
+			}
+			if validator.calls != tt.expectedCalls {
+				t.Errorf("Expected %d calls, got %d", tt.expectedCalls, validator.calls)
+			}
+
+			var body map[string]string
+			if err := json.Unmarshal(rec.Body.Bytes(), &body); err != nil {
+				t.Fatalf("Expected valid JSON: %v", err)
+			}
+			expected := "ready"
+			if tt.expectedStatus != http.StatusOK {
+				expected = "not ready"
+			}
+			if body["status"] != expected {
+				t.Errorf("Expected status %q, got %q", expected, body["status"])
+			}
+		})
+	}
+}
+
+func TestReadinessHandlerFailOnMarshalUnsafe(t *testing.T) {
+	tests := []struct {
+		name           string
+		enabled        bool
+		expectedStatus int
+		expectedCalls  int
+	}{
+		{"disabled", false, http.StatusOK, 1},
+		{"enabled", true, http.StatusServiceUnavailable, 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			validator := &flakyValidator{}
+			handler := server.ReadinessHandler(validator, 1, time.Millisecond, server.WithFailOnMarshalUnsafe(tt.enabled))
+
+			rec := httptest.NewRecorder()
+			handler(rec, httptest.NewRequest(http.MethodGet, "/ready", nil))
+
+			if rec.Code != tt.expectedStatus {
+				t.Errorf("Expected status %d, got %d: %s", tt.expectedStatus, rec.Code, rec.Body.String())
+			}
+			if validator.calls != tt.expectedCalls {
+				t.Errorf("Expected %d calls, got %d", tt.expectedCalls, validator.calls)
+			}
+
+			var body map[string]string
+			if err := json.Unmarshal(rec.Body.Bytes(), &body); err != nil {
+				t.Fatalf("Expected valid JSON: %v", err)
+			}
+			if !tt.enabled {
+				return
+			}
+			if body["status"] != "not ready" {
+				t.Errorf("Expected status %q, got %q", "not ready", body["status"])
+			}
+			for _, messageType := range knownUnsafeTypes {
+				if !strings.Contains(body["error"], messageType) {
+					t.Errorf("Expected the error to name %s, got %q", messageType, body["error"])
+				}
+			}
+		})
+	}
+}
+
+// failingValidator returns a failing result without an error
+type failingValidator struct {
+	calls int
+}
+
+func (v *failingValidator) ValidateTypes(ctx context.Context, req *v1.ValidateTypesRequest) (*v1.ValidateTypesResponse, error) {
+	v.calls++
+	return &v1.ValidateTypesResponse{
+		Results: []*v1.ValidationResult{server.NewValidationResult("DataPoint.Tags", "[]string", "[]*string")},
+	}, nil
+}
+
+func TestReadinessHandlerFailingResults(t *testing.T) {
+	validator := &failingValidator{}
+	handler := server.ReadinessHandler(validator, 3, time.Millisecond)
+
+	rec := httptest.NewRecorder()
+	handler(rec, httptest.NewRequest(http.MethodGet, "/ready", nil))
+
+	if rec.Code != http.StatusServiceUnavailable {
+		t.Errorf("Expected status %d, got %d: %s", http.StatusServiceUnavailable, rec.Code, rec.Body.String())
+	}
+	// Field types cannot change between attempts, so failures are not retried
+	if validator.calls != 1 {
+		t.Errorf("Expected 1 call, got %d", validator.calls)
+	}
+
+	var body map[string]string
+	if err := json.Unmarshal(rec.Body.Bytes(), &body); err != nil {
+		t.Fatalf("Expected valid JSON: %v", err)
+	}
+	if body["failing_scenarios"] != "DataPoint.Tags" {
+		t.Errorf("Expected failing scenario DataPoint.Tags, got %q", body["failing_scenarios"])
+	}
+}
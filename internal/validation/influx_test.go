@@ -0,0 +1,130 @@
+package validation
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"regexp"
+	"strings"
+	"testing"
+	"time"
+
+	v1 "github.com/benjamin-rood/protogo-values-validation-demo/gen/api/validation/v1"
+	"github.com/benjamin-rood/protogo-values-validation-demo/internal/buildinfo"
+	"github.com/benjamin-rood/protogo-values-validation-demo/internal/server"
+)
+
+func TestInfluxSinkLineProtocol(t *testing.T) {
+	info := buildinfo.Get()
+	tags := ",version=" + info.Version + ",commit=" + info.Commit
+	at := time.Unix(1700000000, 123)
+
+	results := []*v1.BenchmarkResult{
+		{Name: "ValueSlice_Iteration", DurationNs: 1500.5, Allocations: 0, BytesAllocated: 0, OperationsPerSecond: 666444.5},
+		{Name: "Needs escaping, a=b", DurationNs: 10, Allocations: 3, BytesAllocated: 96, OperationsPerSecond: 100},
+		{Name: "Serialization", Error: `marshal failed: "reflect" \ panic`},
+	}
+
+	expected := "benchmark,name=ValueSlice_Iteration" + tags + " duration_ns=1500.5,allocations=0i,bytes_allocated=0i,operations_per_second=666444.5 1700000000000000123\n" +
+		`benchmark,name=Needs\ escaping\,\ a\=b` + tags + " duration_ns=10,allocations=3i,bytes_allocated=96i,operations_per_second=100 1700000000000000123\n" +
+		"benchmark,name=Serialization" + tags + ` error="marshal failed: \"reflect\" \\ panic" 1700000000000000123` + "\n"
+
+	if got := server.NewInfluxSink("http://unused").FormatLines(results, at); got != expected {
+		t.Errorf("Expected line protocol\n%s\ngot\n%s", expected, got)
+	}
+}
+
+func TestInfluxSinkReportsBenchmarks(t *testing.T) {
+	type write struct {
+		contentType string
+		body        string
+	}
+	writes := make(chan write, 1)
+
+	sink := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		writes <- write{contentType: r.Header.Get("Content-Type"), body: string(body)}
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer sink.Close()
+
+	s := server.NewValidationServer(server.WithBenchmarkSink(server.NewInfluxSink(sink.URL)))
+
+	resp, err := s.RunBenchmarks(context.Background(), &v1.BenchmarkRequest{Iterations: 10, DataSize: 10})
+	if err != nil {
+		t.Fatalf("RunBenchmarks failed: %v", err)
+	}
+
+	var got write
+	select {
+	case got = <-writes:
+	case <-time.After(5 * time.Second):
+		t.Fatal("Expected benchmark results to be written to the sink")
+	}
+
+	if !strings.HasPrefix(got.contentType, "text/plain") {
+		t.Errorf("Expected text/plain, got %q", got.contentType)
+	}
+
+	lines := strings.Split(strings.TrimSuffix(got.body, "\n"), "\n")
+	if len(lines) != len(resp.Results) {
+		t.Fatalf("Expected %d lines, got %d:\n%s", len(resp.Results), len(lines), got.body)
+	}
+
+	line := regexp.MustCompile(`^benchmark,name=\S+,version=\S+,commit=\S+ (duration_ns=[0-9.e+-]+,allocations=\d+i,bytes_allocated=\d+i,operations_per_second=[0-9.e+-]+|error=".*") \d+$`)
+	for i, l := range lines {
+		if !line.MatchString(l) {
+			t.Errorf("Line %d is not valid line protocol: %q", i, l)
+		}
+		if !strings.HasPrefix(l, "benchmark,name="+resp.Results[i].Name+",") {
+			t.Errorf("Expected line %d for %s, got %q", i, resp.Results[i].Name, l)
+		}
+	}
+}
+
+func TestInfluxSinkBestEffort(t *testing.T) {
+	failing := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "unavailable", http.StatusServiceUnavailable)
+	}))
+	defer failing.Close()
+
+	err := server.NewInfluxSink(failing.URL).ReportBenchmarks(context.Background(), []*v1.BenchmarkResult{{Name: "x"}}, time.Now())
+	if err == nil || !strings.Contains(err.Error(), "503") {
+		t.Errorf("Expected the sink status to be reported, got %v", err)
+	}
+
+	// An unreachable sink must not fail the RPC
+	unreachable := httptest.NewServer(http.NotFoundHandler())
+	unreachable.Close()
+
+	s := server.NewValidationServer(server.WithBenchmarkSink(server.NewInfluxSink(unreachable.URL)))
+	if _, err := s.RunBenchmarks(context.Background(), &v1.BenchmarkRequest{Iterations: 10, DataSize: 10}); err != nil {
+		t.Errorf("Expected RunBenchmarks to ignore sink failures, got %v", err)
+	}
+}
+
+func TestInfluxSinkToken(t *testing.T) {
+	authorization := make(chan string, 2)
+	sink := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		authorization <- r.Header.Get("Authorization")
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer sink.Close()
+
+	results := []*v1.BenchmarkResult{{Name: "x"}}
+
+	if err := server.NewInfluxSink(sink.URL, server.WithInfluxToken("s3cret")).ReportBenchmarks(context.Background(), results, time.Now()); err != nil {
+		t.Fatalf("ReportBenchmarks failed: %v", err)
+	}
+	if got := <-authorization; got != "Token s3cret" {
+		t.Errorf("Expected Authorization Token s3cret, got %q", got)
+	}
+
+	if err := server.NewInfluxSink(sink.URL).ReportBenchmarks(context.Background(), results, time.Now()); err != nil {
+		t.Fatalf("ReportBenchmarks failed: %v", err)
+	}
+	if got := <-authorization; got != "" {
+		t.Errorf("Expected no Authorization header without a token, got %q", got)
+	}
+}
@@ -3,8 +3,10 @@ package validation
 import (
 	"context"
 	"fmt"
+	"io"
 	"log"
 	"net"
+	"strings"
 	"testing"
 	"time"
 
@@ -12,8 +14,12 @@ import (
 	v1 "github.com/benjamin-rood/protogo-values-validation-demo/gen/api/validation/v1"
 	
 	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
 	"google.golang.org/grpc/test/bufconn"
+	"google.golang.org/protobuf/proto"
 )
 
 // Phase 2: Integration Testing Framework
@@ -30,7 +36,7 @@ var lis *bufconn.Listener
 // setupTestServer creates an in-memory gRPC server for testing
 func setupTestServer() func() {
 	lis = bufconn.Listen(bufSize)
-	s := grpc.NewServer()
+	s := grpc.NewServer(grpc.UnaryInterceptor(server.UnaryValidationInterceptor()))
 	
 	validationServer := server.NewValidationServer()
 	v1.RegisterValidationServiceServer(s, validationServer)
@@ -47,6 +53,31 @@ func setupTestServer() func() {
 	}
 }
 
+// setupCustomTestServer creates an in-memory gRPC server with the given server
+// options and services, returning a connected client connection
+func setupCustomTestServer(t testing.TB, register func(*grpc.Server), opts ...grpc.ServerOption) (*grpc.ClientConn, func()) {
+	customLis := bufconn.Listen(bufSize)
+	s := grpc.NewServer(opts...)
+	register(s)
+
+	go s.Serve(customLis)
+
+	conn, err := grpc.DialContext(context.Background(), "bufnet",
+		grpc.WithContextDialer(func(context.Context, string) (net.Conn, error) {
+			return customLis.Dial()
+		}),
+		grpc.WithTransportCredentials(insecure.NewCredentials()))
+	if err != nil {
+		t.Fatalf("Failed to dial bufnet: %v", err)
+	}
+
+	return conn, func() {
+		conn.Close()
+		s.Stop()
+		customLis.Close()
+	}
+}
+
 // bufDialer creates a dialer for the in-memory test server
 func bufDialer(context.Context, string) (net.Conn, error) {
 	return lis.Dial()
@@ -80,7 +111,7 @@ func TestValidationServiceIntegration(t *testing.T) {
 	
 	t.Run("ValidateTypes_Success", func(t *testing.T) {
 		req := &v1.ValidateTypesRequest{
-			TestScenarios:  []string{"basic", "performance"},
+			TestScenarios:  []string{server.ScenarioValidationTestMessage, server.ScenarioPerformanceTestMessage},
 			DeepValidation: true,
 		}
 		
@@ -120,6 +151,57 @@ func TestValidationServiceIntegration(t *testing.T) {
 		}
 	})
 	
+	t.Run("ValidateTypes_TransformedFields", func(t *testing.T) {
+		resp, err := client.ValidateTypes(ctx, &v1.ValidateTypesRequest{TestScenarios: []string{server.ScenarioValidationTestMessage}})
+		if err != nil {
+			t.Fatalf("ValidateTypes failed: %v", err)
+		}
+
+		expected := []string{
+			"ValidationTestMessage.ValueSliceData",
+			"ValidationTestMessage.Metrics",
+			"PerformanceTestMessage.ValueSliceData",
+			"PerformanceTestMessage.Results",
+		}
+
+		if strings.Join(resp.TransformedFields, ",") != strings.Join(expected, ",") {
+			t.Errorf("Expected transformed fields %v, got %v", expected, resp.TransformedFields)
+		}
+	})
+
+	t.Run("ValidateTypes_ScalarSlicesUntouched", func(t *testing.T) {
+		req := &v1.ValidateTypesRequest{
+			TestScenarios: []string{server.ScenarioScalarSlices},
+		}
+
+		resp, err := client.ValidateTypes(ctx, req)
+		if err != nil {
+			t.Fatalf("ValidateTypes failed: %v", err)
+		}
+
+		expected := map[string]bool{
+			"DataPoint.Tags":                 false,
+			"ProcessingResult.ErrorMessages": false,
+		}
+
+		for _, result := range resp.Results {
+			if _, ok := expected[result.Scenario]; !ok {
+				continue
+			}
+			expected[result.Scenario] = true
+
+			if !result.Passed || result.ActualType != "[]string" {
+				t.Errorf("Scalar field %s was transformed: got %s", result.Scenario, result.ActualType)
+			}
+		}
+
+		for scenario, found := range expected {
+			if !found {
+				t.Errorf("Expected validation result for %s", scenario)
+			}
+		}
+	})
+
 	t.Run("RunBenchmarks_Success", func(t *testing.T) {
 		req := &v1.BenchmarkRequest{
 			Iterations:     1000,
@@ -132,8 +214,12 @@ func TestValidationServiceIntegration(t *testing.T) {
 			t.Fatalf("RunBenchmarks failed: %v", err)
 		}
 		
-		if !resp.Success {
-			t.Error("Expected benchmarks to succeed")
+		// Marshaling a populated value slice panics, so only the
+		// serialization stages may fail
+		for _, result := range resp.Results {
+			if result.Error != "" && !strings.HasPrefix(result.Name, "Serialization") {
+				t.Errorf("Expected %s to succeed, got %q", result.Name, result.Error)
+			}
 		}
 		
 		if len(resp.Results) == 0 {
@@ -168,6 +254,71 @@ func TestValidationServiceIntegration(t *testing.T) {
 	})
 }
 
+// TestBenchmarkStageIsolation tests a failing stage is captured without aborting the run
+func TestBenchmarkStageIsolation(t *testing.T) {
+	t.Run("PanickingStage", func(t *testing.T) {
+		result := server.RunBenchmarkStage("Injected_Failure", func() *v1.BenchmarkResult {
+			panic("injected failure")
+		})
+
+		if result.Name != "Injected_Failure" {
+			t.Errorf("Expected name Injected_Failure, got %s", result.Name)
+		}
+		if !strings.Contains(result.Error, "injected failure") {
+			t.Errorf("Expected error to contain panic value, got %q", result.Error)
+		}
+	})
+
+	t.Run("HealthyStage", func(t *testing.T) {
+		result := server.RunBenchmarkStage("Healthy", func() *v1.BenchmarkResult {
+			return &v1.BenchmarkResult{Name: "Healthy", DurationNs: 1}
+		})
+
+		if result.Error != "" {
+			t.Errorf("Expected no error, got %q", result.Error)
+		}
+	})
+
+	t.Run("RunBenchmarks_ContinuesAfterFailure", func(t *testing.T) {
+		cleanup := setupTestServer()
+		defer cleanup()
+
+		client, closeConn := createTestClient(t)
+		defer closeConn()
+
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		defer cancel()
+
+		// Serialization marshals value slices, which panics
+		resp, err := client.RunBenchmarks(ctx, &v1.BenchmarkRequest{
+			Iterations: 10,
+			DataSize:   10,
+		})
+		if err != nil {
+			t.Fatalf("RunBenchmarks failed: %v", err)
+		}
+
+		failed := make(map[string]string)
+		for _, result := range resp.Results {
+			if result.Error != "" {
+				failed[result.Name] = result.Error
+			}
+		}
+
+		if _, ok := failed["Serialization"]; !ok {
+			t.Error("Expected Serialization stage to report an error")
+		}
+		if resp.Success {
+			t.Error("Expected overall success=false when a stage fails")
+		}
+
+		last := resp.Results[len(resp.Results)-1]
+		if last.Name != "Deserialization" || last.Error != "" {
+			t.Errorf("Expected Deserialization to run after the failure, got %s (%q)", last.Name, last.Error)
+		}
+	})
+}
+
 // TestStreamingValidation tests the streaming validation functionality
 func TestStreamingValidation(t *testing.T) {
 	cleanup := setupTestServer()
@@ -239,6 +390,76 @@ func TestStreamingValidation(t *testing.T) {
 	}
 }
 
+// TestStreamingValidationBackpressure tests many pipelined messages all receive responses
+func TestStreamingValidationBackpressure(t *testing.T) {
+	cleanup := setupTestServer()
+	defer cleanup()
+
+	client, closeConn := createTestClient(t)
+	defer closeConn()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	stream, err := client.StreamValidation(ctx)
+	if err != nil {
+		t.Fatalf("Failed to create stream: %v", err)
+	}
+
+	// Well beyond the server's bounded queue size
+	numRequests := 500
+
+	go func() {
+		for i := 0; i < numRequests; i++ {
+			req := &v1.StreamRequest{
+				RequestId:      fmt.Sprintf("req_%d", i),
+				SequenceNumber: int32(i),
+				TestData: &v1.ValidationTestMessage{
+					PointerSliceData: []*v1.DataPoint{
+						{Id: fmt.Sprintf("ptr_%d", i), Value: float64(i), Timestamp: int64(i)},
+					},
+				},
+			}
+			if err := stream.Send(req); err != nil {
+				t.Errorf("Failed to send request %d: %v", i, err)
+				return
+			}
+		}
+		stream.CloseSend()
+	}()
+
+	// Responses may arrive out of order; reassemble by sequence number
+	seen := make(map[int32]bool, numRequests)
+	for {
+		resp, err := stream.Recv()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			t.Fatalf("Failed to receive response: %v", err)
+		}
+
+		if seen[resp.SequenceNumber] {
+			t.Errorf("Duplicate response for sequence number %d", resp.SequenceNumber)
+		}
+		seen[resp.SequenceNumber] = true
+
+		if resp.RequestId != fmt.Sprintf("req_%d", resp.SequenceNumber) {
+			t.Errorf("Response %s does not match sequence number %d", resp.RequestId, resp.SequenceNumber)
+		}
+	}
+
+	if len(seen) != numRequests {
+		t.Fatalf("Expected %d responses, got %d", numRequests, len(seen))
+	}
+
+	for i := 0; i < numRequests; i++ {
+		if !seen[int32(i)] {
+			t.Errorf("Missing response for sequence number %d", i)
+		}
+	}
+}
+
 // TestProtobufCompatibility tests protobuf serialization/deserialization
 func TestProtobufCompatibility(t *testing.T) {
 	cleanup := setupTestServer()
@@ -253,7 +474,7 @@ func TestProtobufCompatibility(t *testing.T) {
 	t.Run("MessageSerialization", func(t *testing.T) {
 		// Create a complex test message
 		original := &v1.ValidateTypesRequest{
-			TestScenarios:  []string{"test1", "test2", "test3"},
+			TestScenarios:  []string{server.ScenarioValidationTestMessage, server.ScenarioPerformanceTestMessage, server.ScenarioScalarSlices},
 			DeepValidation: true,
 		}
 		
@@ -352,6 +573,7 @@ func TestConcurrentAccess(t *testing.T) {
 	// Run multiple concurrent requests
 	numWorkers := 10
 	results := make(chan error, numWorkers)
+	workerScenarios := []string{server.ScenarioValidationTestMessage, server.ScenarioPerformanceTestMessage, server.ScenarioScalarSlices}
 	
 	for i := 0; i < numWorkers; i++ {
 		go func(workerID int) {
@@ -359,7 +581,7 @@ func TestConcurrentAccess(t *testing.T) {
 			defer cancel()
 			
 			req := &v1.ValidateTypesRequest{
-				TestScenarios:  []string{fmt.Sprintf("worker_%d", workerID)},
+				TestScenarios:  []string{workerScenarios[workerID%len(workerScenarios)]},
 				DeepValidation: false,
 			}
 			
@@ -388,6 +610,359 @@ func TestConcurrentAccess(t *testing.T) {
 	t.Logf("Concurrent access test completed with %d workers", numWorkers)
 }
 
+// TestValidateConcurrent tests server-side concurrent validation; run with -race
+func TestValidateConcurrent(t *testing.T) {
+	// Without the cache every run validates afresh instead of reading a clone
+	// of the first run's response
+	validationServer := server.NewValidationServer(server.WithCache(false))
+	conn, cleanup := setupCustomTestServer(t, func(s *grpc.Server) {
+		v1.RegisterValidationServiceServer(s, validationServer)
+	})
+	defer cleanup()
+	client := v1.NewValidationServiceClient(conn)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	t.Run("AllRunsConsistent", func(t *testing.T) {
+		req := &v1.ValidateConcurrentRequest{
+			Workers:             16,
+			IterationsPerWorker: 25,
+			TestScenarios:       []string{server.ScenarioValidationTestMessage, server.ScenarioPerformanceTestMessage},
+		}
+
+		resp, err := client.ValidateConcurrent(ctx, req)
+		if err != nil {
+			t.Fatalf("ValidateConcurrent failed: %v", err)
+		}
+
+		if resp.TotalRuns != 16*25 {
+			t.Errorf("Expected %d total runs, got %d", 16*25, resp.TotalRuns)
+		}
+
+		if !resp.Success || resp.PassedRuns != resp.TotalRuns {
+			t.Errorf("Expected all runs to pass, got passed=%d failed=%d inconsistent=%d",
+				resp.PassedRuns, resp.FailedRuns, resp.InconsistentRuns)
+		}
+
+		if resp.InconsistentRuns != 0 {
+			t.Errorf("Expected no inconsistent runs, got %d", resp.InconsistentRuns)
+		}
+	})
+
+	t.Run("InvalidWorkerCount", func(t *testing.T) {
+		_, err := client.ValidateConcurrent(ctx, &v1.ValidateConcurrentRequest{Workers: 0})
+		if status.Code(err) != codes.InvalidArgument {
+			t.Errorf("Expected InvalidArgument, got %v", err)
+		}
+	})
+}
+
+// TestStreamingValidationFailFast tests the stream aborts on the first failure only when requested
+func TestStreamingValidationFailFast(t *testing.T) {
+	cleanup := setupTestServer()
+	defer cleanup()
+
+	client, closeConn := createTestClient(t)
+	defer closeConn()
+
+	const total = 200
+	const badSeq = 5
+
+	run := func(t *testing.T, ctx context.Context) (received, failed int, err error) {
+		stream, err := client.StreamValidation(ctx)
+		if err != nil {
+			t.Fatalf("Failed to create stream: %v", err)
+		}
+
+		go func() {
+			for i := 0; i < total; i++ {
+				req := &v1.StreamRequest{
+					RequestId:      fmt.Sprintf("ff_%d", i),
+					SequenceNumber: int32(i),
+					TestData: &v1.ValidationTestMessage{
+						PointerSliceData: []*v1.DataPoint{{Id: "p", Value: float64(i)}},
+					},
+				}
+				if i == badSeq {
+					req.TestData = nil // fails validation
+				}
+				if stream.Send(req) != nil {
+					return
+				}
+			}
+			stream.CloseSend()
+		}()
+
+		for {
+			resp, recvErr := stream.Recv()
+			if recvErr == io.EOF {
+				return received, failed, nil
+			}
+			if recvErr != nil {
+				return received, failed, recvErr
+			}
+			received++
+			if !resp.Success {
+				failed++
+			}
+		}
+	}
+
+	t.Run("FailFast", func(t *testing.T) {
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		defer cancel()
+		ctx = metadata.AppendToOutgoingContext(ctx, server.FailFastHeader, "true")
+
+		received, failed, err := run(t, ctx)
+		if status.Code(err) != codes.FailedPrecondition {
+			t.Fatalf("Expected FailedPrecondition, got %v", err)
+		}
+		if failed != 1 {
+			t.Errorf("Expected the failing response before termination, got %d failures", failed)
+		}
+		if received >= total {
+			t.Errorf("Expected early termination, got all %d responses", received)
+		}
+	})
+
+	t.Run("ProcessAll", func(t *testing.T) {
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		defer cancel()
+
+		received, failed, err := run(t, ctx)
+		if err != nil {
+			t.Fatalf("Expected clean end of stream, got %v", err)
+		}
+		if received != total {
+			t.Errorf("Expected %d responses, got %d", total, received)
+		}
+		if failed != 1 {
+			t.Errorf("Expected 1 failed response, got %d", failed)
+		}
+	})
+
+	t.Run("InvalidHeader", func(t *testing.T) {
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		defer cancel()
+		ctx = metadata.AppendToOutgoingContext(ctx, server.FailFastHeader, "sometimes")
+
+		_, _, err := run(t, ctx)
+		if status.Code(err) != codes.InvalidArgument {
+			t.Errorf("Expected InvalidArgument, got %v", err)
+		}
+	})
+}
+
+// TestStreamRequestValidation tests malformed stream messages are rejected individually
+func TestStreamRequestValidation(t *testing.T) {
+	cleanup := setupTestServer()
+	defer cleanup()
+
+	client, closeConn := createTestClient(t)
+	defer closeConn()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	tests := []struct {
+		name            string
+		req             *v1.StreamRequest
+		expectedSuccess bool
+		expectedMessage string
+	}{
+		{
+			name:            "empty request id",
+			req:             &v1.StreamRequest{SequenceNumber: 0, TestData: &v1.ValidationTestMessage{}},
+			expectedMessage: "request_id is required",
+		},
+		{
+			name:            "negative sequence number",
+			req:             &v1.StreamRequest{RequestId: "neg", SequenceNumber: -1, TestData: &v1.ValidationTestMessage{}},
+			expectedMessage: "sequence_number must be >= 0",
+		},
+		{
+			name:            "missing test data",
+			req:             &v1.StreamRequest{RequestId: "nil", SequenceNumber: 2},
+			expectedMessage: "test_data is required",
+		},
+		{
+			name:            "empty slices are valid",
+			req:             &v1.StreamRequest{RequestId: "empty", SequenceNumber: 3, TestData: &v1.ValidationTestMessage{}},
+			expectedSuccess: true,
+		},
+	}
+
+	// All cases share one stream to prove rejections don't tear it down
+	stream, err := client.StreamValidation(ctx)
+	if err != nil {
+		t.Fatalf("Failed to create stream: %v", err)
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := stream.Send(tt.req); err != nil {
+				t.Fatalf("Failed to send: %v", err)
+			}
+
+			resp, err := stream.Recv()
+			if err != nil {
+				t.Fatalf("Failed to receive: %v", err)
+			}
+
+			if resp.Success != tt.expectedSuccess {
+				t.Errorf("Expected success=%v, got %v (%s)", tt.expectedSuccess, resp.Success, resp.Message)
+			}
+			if tt.expectedMessage != "" && !strings.Contains(resp.Message, tt.expectedMessage) {
+				t.Errorf("Expected message containing %q, got %q", tt.expectedMessage, resp.Message)
+			}
+		})
+	}
+
+	stream.CloseSend()
+	if _, err := stream.Recv(); err != io.EOF {
+		t.Errorf("Expected clean end of stream, got %v", err)
+	}
+}
+
+// TestStreamFieldErrors sends a payload converted from another message type and
+// checks the wrongly-typed fields are reported by path
+func TestStreamFieldErrors(t *testing.T) {
+	cleanup := setupTestServer()
+	defer cleanup()
+
+	client, closeConn := createTestClient(t)
+	defer closeConn()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	// Field 2 holds Metadata here but DataPoint in ValidationTestMessage:
+	// key/id agree, value (string vs double) and attributes/timestamp (map vs int64) do not
+	wrong, err := proto.Marshal(&v1.PerformanceTestMessage{
+		PointerSliceData: []*v1.Metadata{
+			{Key: "ok"},
+			{Key: "k", Value: "not a double", Attributes: map[string]string{"a": "b"}},
+		},
+	})
+	if err != nil {
+		t.Fatalf("Failed to marshal source payload: %v", err)
+	}
+
+	testData := &v1.ValidationTestMessage{}
+	if err := proto.Unmarshal(wrong, testData); err != nil {
+		t.Fatalf("Failed to convert payload: %v", err)
+	}
+
+	stream, err := client.StreamValidation(ctx)
+	if err != nil {
+		t.Fatalf("Failed to create stream: %v", err)
+	}
+
+	if err := stream.Send(&v1.StreamRequest{RequestId: "converted", TestData: testData}); err != nil {
+		t.Fatalf("Failed to send: %v", err)
+	}
+	resp, err := stream.Recv()
+	if err != nil {
+		t.Fatalf("Failed to receive: %v", err)
+	}
+
+	if resp.Success {
+		t.Error("Expected converted payload to fail validation")
+	}
+	if !strings.Contains(resp.Message, "2 field errors") {
+		t.Errorf("Expected message to count the field errors, got %q", resp.Message)
+	}
+
+	expected := []*v1.FieldError{
+		{FieldPath: "test_data.pointer_slice_data[1].value", ExpectedType: "double", ActualType: "bytes"},
+		{FieldPath: "test_data.pointer_slice_data[1].timestamp", ExpectedType: "int64", ActualType: "bytes"},
+	}
+	if len(resp.FieldErrors) != len(expected) {
+		t.Fatalf("Expected %d field errors, got %v", len(expected), resp.FieldErrors)
+	}
+	for i, want := range expected {
+		got := resp.FieldErrors[i]
+		if got.FieldPath != want.FieldPath || got.ExpectedType != want.ExpectedType || got.ActualType != want.ActualType {
+			t.Errorf("Expected %s (%s, got %s), got %s (%s, got %s)",
+				want.FieldPath, want.ExpectedType, want.ActualType, got.FieldPath, got.ExpectedType, got.ActualType)
+		}
+		if got.Description == "" {
+			t.Errorf("Expected a description for %s", got.FieldPath)
+		}
+	}
+
+	// A well-typed payload on the same stream carries no field errors
+	if err := stream.Send(&v1.StreamRequest{RequestId: "valid", TestData: &v1.ValidationTestMessage{PointerSliceData: createDataPointPointers(3)}}); err != nil {
+		t.Fatalf("Failed to send: %v", err)
+	}
+	resp, err = stream.Recv()
+	if err != nil {
+		t.Fatalf("Failed to receive: %v", err)
+	}
+	if !resp.Success || len(resp.FieldErrors) != 0 {
+		t.Errorf("Expected valid payload without field errors, got %v", resp.FieldErrors)
+	}
+
+	stream.CloseSend()
+	if _, err := stream.Recv(); err != io.EOF {
+		t.Errorf("Expected clean end of stream, got %v", err)
+	}
+}
+
+// TestCheckMarshalCompatibility tests the marshaling limitation is reported as data
+func TestCheckMarshalCompatibility(t *testing.T) {
+	cleanup := setupTestServer()
+	defer cleanup()
+
+	client, closeConn := createTestClient(t)
+	defer closeConn()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	resp, err := client.CheckMarshalCompatibility(ctx, &v1.CheckMarshalCompatibilityRequest{})
+	if err != nil {
+		t.Fatalf("CheckMarshalCompatibility failed: %v", err)
+	}
+
+	if resp.AllSafe {
+		t.Error("Expected value-slice messages to make the overall result unsafe")
+	}
+
+	expectedSafe := map[string]bool{
+		"validation.v1.ValidationTestMessage":  false,
+		"validation.v1.PerformanceTestMessage": false,
+		"validation.v1.DataPoint":              true,
+		"validation.v1.MetricPoint":            true,
+		"validation.v1.Metadata":               true,
+		"validation.v1.ProcessingResult":       true,
+	}
+
+	if len(resp.Results) != len(expectedSafe) {
+		t.Errorf("Expected %d results, got %d", len(expectedSafe), len(resp.Results))
+	}
+
+	for _, result := range resp.Results {
+		safe, ok := expectedSafe[result.MessageType]
+		if !ok {
+			t.Errorf("Unexpected message type %s", result.MessageType)
+			continue
+		}
+
+		if result.MarshalSafe != safe {
+			t.Errorf("%s: expected marshal_safe=%v, got %v (panic: %s)",
+				result.MessageType, safe, result.MarshalSafe, result.PanicMessage)
+		}
+
+		if !safe && result.PanicMessage == "" {
+			t.Errorf("%s: expected captured panic message", result.MessageType)
+		}
+
+		t.Logf("%s: safe=%v panic=%q", result.MessageType, result.MarshalSafe, result.PanicMessage)
+	}
+}
+
 // BenchmarkServicePerformance benchmarks the service under load
 func BenchmarkServicePerformance(b *testing.B) {
 	cleanup := setupTestServer()
@@ -397,7 +972,7 @@ func BenchmarkServicePerformance(b *testing.B) {
 	defer closeConn()
 	
 	req := &v1.ValidateTypesRequest{
-		TestScenarios:  []string{"performance"},
+		TestScenarios:  []string{server.ScenarioPerformanceTestMessage},
 		DeepValidation: false,
 	}
 	
@@ -0,0 +1,90 @@
+package validation
+
+import (
+	"context"
+	"io"
+	"testing"
+	"time"
+
+	v1 "github.com/benjamin-rood/protogo-values-validation-demo/gen/api/validation/v1"
+	"github.com/benjamin-rood/protogo-values-validation-demo/internal/server"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+)
+
+// setupInterceptedTestServer creates an in-memory gRPC server using the request ID interceptors
+func setupInterceptedTestServer(t *testing.T) (v1.ValidationServiceClient, func()) {
+	conn, cleanup := setupCustomTestServer(t, func(s *grpc.Server) {
+		v1.RegisterValidationServiceServer(s, server.NewValidationServer())
+	},
+		grpc.ChainUnaryInterceptor(server.UnaryRequestIDInterceptor()),
+		grpc.ChainStreamInterceptor(server.StreamRequestIDInterceptor()),
+	)
+
+	return v1.NewValidationServiceClient(conn), cleanup
+}
+
+// TestRequestIDInterceptor tests the request ID round-trips through the trailer
+func TestRequestIDInterceptor(t *testing.T) {
+	client, cleanup := setupInterceptedTestServer(t)
+	defer cleanup()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	req := &v1.ValidateTypesRequest{TestScenarios: []string{server.ScenarioValidationTestMessage}}
+
+	t.Run("PropagatesIncomingID", func(t *testing.T) {
+		var header, trailer metadata.MD
+		outCtx := metadata.AppendToOutgoingContext(ctx, server.RequestIDHeader, "trace-123")
+
+		if _, err := client.ValidateTypes(outCtx, req, grpc.Header(&header), grpc.Trailer(&trailer)); err != nil {
+			t.Fatalf("ValidateTypes failed: %v", err)
+		}
+
+		if got := trailer.Get(server.RequestIDHeader); len(got) != 1 || got[0] != "trace-123" {
+			t.Errorf("Expected trailer request ID trace-123, got %v", got)
+		}
+
+		if got := header.Get(server.RequestIDHeader); len(got) != 1 || got[0] != "trace-123" {
+			t.Errorf("Expected header request ID trace-123, got %v", got)
+		}
+	})
+
+	t.Run("GeneratesMissingID", func(t *testing.T) {
+		var trailer metadata.MD
+
+		if _, err := client.ValidateTypes(ctx, req, grpc.Trailer(&trailer)); err != nil {
+			t.Fatalf("ValidateTypes failed: %v", err)
+		}
+
+		got := trailer.Get(server.RequestIDHeader)
+		if len(got) != 1 || got[0] == "" {
+			t.Fatalf("Expected generated request ID in trailer, got %v", got)
+		}
+
+		t.Logf("Generated request ID: %s", got[0])
+	})
+
+	t.Run("StreamPropagatesIncomingID", func(t *testing.T) {
+		outCtx := metadata.AppendToOutgoingContext(ctx, server.RequestIDHeader, "stream-456")
+
+		stream, err := client.StreamValidation(outCtx)
+		if err != nil {
+			t.Fatalf("Failed to create stream: %v", err)
+		}
+
+		if err := stream.CloseSend(); err != nil {
+			t.Fatalf("Failed to close send: %v", err)
+		}
+
+		if _, err := stream.Recv(); err != io.EOF {
+			t.Fatalf("Expected clean end of stream, got %v", err)
+		}
+
+		if got := stream.Trailer().Get(server.RequestIDHeader); len(got) != 1 || got[0] != "stream-456" {
+			t.Errorf("Expected trailer request ID stream-456, got %v", got)
+		}
+	})
+}
@@ -0,0 +1,44 @@
+package validation
+
+import (
+	"testing"
+
+	v1 "github.com/benjamin-rood/protogo-values-validation-demo/gen/api/validation/v1"
+	"github.com/benjamin-rood/protogo-values-validation-demo/pkg/iterate"
+)
+
+func TestSumValues(t *testing.T) {
+	data := []v1.DataPoint{{Value: 1.5}, {Value: 2}, {Value: -0.5}}
+	if got := iterate.SumValues(data); got != 3 {
+		t.Errorf("Expected sum 3, got %v", got)
+	}
+	if got := iterate.SumValues(nil); got != 0 {
+		t.Errorf("Expected sum 0 for no data, got %v", got)
+	}
+}
+
+func TestCountNonEmpty(t *testing.T) {
+	data := []*v1.Metadata{{Key: "a"}, nil, {Key: ""}, {Key: "b", Value: "v"}}
+	if got := iterate.CountNonEmpty(data); got != 2 {
+		t.Errorf("Expected 2 non-empty entries, got %d", got)
+	}
+}
+
+func TestIterateZeroAllocation(t *testing.T) {
+	values := createPerformanceTestMessage(mediumDataSize).ValueSliceData
+	metadata := createMetadataPointers(mediumDataSize)
+
+	var sum float64
+	if allocs := testing.AllocsPerRun(100, func() { sum = iterate.SumValues(values) }); allocs != 0 {
+		t.Errorf("Expected SumValues to be allocation-free, got %v allocs per run", allocs)
+	}
+
+	var count int
+	if allocs := testing.AllocsPerRun(100, func() { count = iterate.CountNonEmpty(metadata) }); allocs != 0 {
+		t.Errorf("Expected CountNonEmpty to be allocation-free, got %v allocs per run", allocs)
+	}
+	if count != mediumDataSize {
+		t.Errorf("Expected %d non-empty entries, got %d", mediumDataSize, count)
+	}
+	_ = sum
+}
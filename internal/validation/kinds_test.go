@@ -0,0 +1,61 @@
+package validation
+
+import (
+	"context"
+	"testing"
+
+	v1 "github.com/benjamin-rood/protogo-values-validation-demo/gen/api/validation/v1"
+	"github.com/benjamin-rood/protogo-values-validation-demo/internal/server"
+)
+
+func TestValidateTypesIncludeKinds(t *testing.T) {
+	validationServer := server.NewValidationServer()
+
+	resp, err := validationServer.ValidateTypes(context.Background(), &v1.ValidateTypesRequest{IncludeKinds: true})
+	if err != nil {
+		t.Fatalf("ValidateTypes failed: %v", err)
+	}
+
+	expected := map[string]struct{ kind, elementKind string }{
+		"ValidationTestMessage.ValueSliceData":    {"slice", "struct"},
+		"ValidationTestMessage.PointerSliceData":  {"slice", "ptr"},
+		"ValidationTestMessage.Metrics":           {"slice", "struct"},
+		"PerformanceTestMessage.PointerSliceData": {"slice", "ptr"},
+		"DataPoint.Tags":                          {"slice", "string"},
+		"untransformed/BenchmarkResponse.Results": {"slice", "ptr"},
+	}
+	seen := 0
+	for _, result := range resp.Results {
+		want, ok := expected[result.Scenario]
+		if !ok {
+			continue
+		}
+		seen++
+		if result.Kind != want.kind || result.ElementKind != want.elementKind {
+			t.Errorf("%s: expected %s of %s, got %s of %s", result.Scenario,
+				want.kind, want.elementKind, result.Kind, result.ElementKind)
+		}
+	}
+	if seen != len(expected) {
+		t.Errorf("Expected %d results with kinds, found %d", len(expected), seen)
+	}
+}
+
+func TestValidateTypesOmitsKindsByDefault(t *testing.T) {
+	validationServer := server.NewValidationServer()
+
+	// A cached response must not carry kinds set for an earlier request
+	if _, err := validationServer.ValidateTypes(context.Background(), &v1.ValidateTypesRequest{IncludeKinds: true}); err != nil {
+		t.Fatalf("ValidateTypes failed: %v", err)
+	}
+	resp, err := validationServer.ValidateTypes(context.Background(), &v1.ValidateTypesRequest{})
+	if err != nil {
+		t.Fatalf("ValidateTypes failed: %v", err)
+	}
+
+	for _, result := range resp.Results {
+		if result.Kind != "" || result.ElementKind != "" {
+			t.Errorf("%s: expected no kinds, got %s of %s", result.Scenario, result.Kind, result.ElementKind)
+		}
+	}
+}
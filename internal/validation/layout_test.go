@@ -0,0 +1,93 @@
+package validation
+
+import (
+	"context"
+	"testing"
+	"time"
+	"unsafe"
+
+	v1 "github.com/benjamin-rood/protogo-values-validation-demo/gen/api/validation/v1"
+	"github.com/benjamin-rood/protogo-values-validation-demo/internal/server"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func TestGetMemoryLayout(t *testing.T) {
+	conn, cleanup := setupCustomTestServer(t, func(s *grpc.Server) {
+		v1.RegisterValidationServiceServer(s, server.NewValidationServer())
+	})
+	defer cleanup()
+	client := v1.NewValidationServiceClient(conn)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	resp, err := client.GetMemoryLayout(ctx, &v1.GetMemoryLayoutRequest{})
+	if err != nil {
+		t.Fatalf("GetMemoryLayout failed: %v", err)
+	}
+
+	layouts := make(map[string]*v1.MessageLayout)
+	for _, layout := range resp.Layouts {
+		layouts[layout.MessageType] = layout
+	}
+	if len(layouts) != 6 {
+		t.Errorf("Expected 6 validated message types, got %d", len(layouts))
+	}
+
+	dataPoint, ok := layouts["validation.v1.DataPoint"]
+	if !ok {
+		t.Fatal("Expected a layout for validation.v1.DataPoint")
+	}
+
+	var dp v1.DataPoint
+	if dataPoint.SizeBytes != int64(unsafe.Sizeof(dp)) {
+		t.Errorf("Expected DataPoint size %d, got %d", unsafe.Sizeof(dp), dataPoint.SizeBytes)
+	}
+	if dataPoint.AlignBytes != int64(unsafe.Alignof(dp)) {
+		t.Errorf("Expected DataPoint alignment %d, got %d", unsafe.Alignof(dp), dataPoint.AlignBytes)
+	}
+
+	offsets := map[string]uintptr{
+		"Id":        unsafe.Offsetof(dp.Id),
+		"Value":     unsafe.Offsetof(dp.Value),
+		"Timestamp": unsafe.Offsetof(dp.Timestamp),
+		"Tags":      unsafe.Offsetof(dp.Tags),
+	}
+	for _, field := range dataPoint.Fields {
+		if want, ok := offsets[field.Name]; ok && field.OffsetBytes != int64(want) {
+			t.Errorf("Expected DataPoint.%s offset %d, got %d", field.Name, want, field.OffsetBytes)
+		}
+	}
+
+	// Fields, padding and trailing padding account for the whole struct
+	var total int64
+	for _, field := range dataPoint.Fields {
+		total += field.PaddingBeforeBytes + field.SizeBytes
+	}
+	if total+dataPoint.TrailingPaddingBytes != dataPoint.SizeBytes {
+		t.Errorf("Expected fields and padding to sum to %d, got %d", dataPoint.SizeBytes, total+dataPoint.TrailingPaddingBytes)
+	}
+
+	// A value slice stores whole structs, a pointer slice stores pointers
+	elementSizes := make(map[string]int64)
+	for _, field := range layouts["validation.v1.ValidationTestMessage"].Fields {
+		elementSizes[field.Name] = field.ElementSizeBytes
+	}
+	if elementSizes["ValueSliceData"] != int64(unsafe.Sizeof(dp)) {
+		t.Errorf("Expected value slice element size %d, got %d", unsafe.Sizeof(dp), elementSizes["ValueSliceData"])
+	}
+	if elementSizes["PointerSliceData"] != int64(unsafe.Sizeof(&dp)) {
+		t.Errorf("Expected pointer slice element size %d, got %d", unsafe.Sizeof(&dp), elementSizes["PointerSliceData"])
+	}
+}
+
+func TestGetMemoryLayoutUnknownType(t *testing.T) {
+	validationServer := server.NewValidationServer()
+
+	_, err := validationServer.GetMemoryLayout(context.Background(), &v1.GetMemoryLayoutRequest{MessageTypes: []string{"NoSuchMessage"}})
+	if status.Code(err) != codes.NotFound {
+		t.Errorf("Expected NotFound, got %v", err)
+	}
+}
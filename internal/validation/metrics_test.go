@@ -0,0 +1,178 @@
+package validation
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	v1 "github.com/benjamin-rood/protogo-values-validation-demo/gen/api/validation/v1"
+	"github.com/benjamin-rood/protogo-values-validation-demo/internal/server"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func TestFilterMetricPoints(t *testing.T) {
+	points := []v1.MetricPoint{
+		{Name: "exact", Measurement: 1, Labels: map[string]string{"env": "test"}},
+		{Name: "superset", Measurement: 2, Labels: map[string]string{"env": "test", "region": "eu"}},
+		{Name: "other_env", Measurement: 3, Labels: map[string]string{"env": "prod"}},
+		{Name: "nil_labels", Measurement: 4},
+	}
+
+	tests := []struct {
+		name     string
+		selector map[string]string
+		expected []string
+	}{
+		{
+			name:     "exact and superset match",
+			selector: map[string]string{"env": "test"},
+			expected: []string{"exact", "superset"},
+		},
+		{
+			name:     "superset only",
+			selector: map[string]string{"env": "test", "region": "eu"},
+			expected: []string{"superset"},
+		},
+		{
+			name:     "no match returns empty",
+			selector: map[string]string{"env": "staging"},
+			expected: []string{},
+		},
+		{
+			name:     "empty selector matches all",
+			selector: nil,
+			expected: []string{"exact", "superset", "other_env", "nil_labels"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			matched := server.FilterMetricPoints(points, tt.selector)
+
+			if len(matched) != len(tt.expected) {
+				t.Fatalf("Expected %d points, got %d", len(tt.expected), len(matched))
+			}
+			for i, point := range matched {
+				if point.Name != tt.expected[i] {
+					t.Errorf("Expected point %s at %d, got %s", tt.expected[i], i, point.Name)
+				}
+			}
+		})
+	}
+}
+
+func TestMatchesLabelSelectorNilLabels(t *testing.T) {
+	if server.MatchesLabelSelector(nil, map[string]string{"env": "test"}) {
+		t.Error("Expected nil labels not to match a non-empty selector")
+	}
+}
+
+// distinctLabelPoints returns n points, each with its own label set
+func distinctLabelPoints(n int) []*v1.MetricPoint {
+	points := make([]*v1.MetricPoint, n)
+	for i := range points {
+		points[i] = &v1.MetricPoint{
+			Name:        "requests",
+			Measurement: float64(i + 1),
+			Labels:      map[string]string{"user": fmt.Sprintf("u%d", i)},
+		}
+	}
+	return points
+}
+
+func TestAggregateMetricPoints(t *testing.T) {
+	points := []*v1.MetricPoint{
+		{Measurement: 1, Labels: map[string]string{"env": "test", "region": "eu"}},
+		{Measurement: 5, Labels: map[string]string{"env": "prod"}},
+		{Measurement: 3, Labels: map[string]string{"region": "eu", "env": "test"}},
+	}
+
+	groups, err := server.AggregateMetricPoints(points, 0, false)
+	if err != nil {
+		t.Fatalf("AggregateMetricPoints failed: %v", err)
+	}
+
+	if len(groups) != 2 {
+		t.Fatalf("Expected 2 groups, got %d", len(groups))
+	}
+
+	first := groups[0]
+	if first.Count != 2 || first.Sum != 4 || first.Min != 1 || first.Max != 3 || first.Mean != 2 {
+		t.Errorf("Expected count 2, sum 4, min 1, max 3, mean 2, got %v", first)
+	}
+	if groups[1].Labels["env"] != "prod" || groups[1].Count != 1 {
+		t.Errorf("Expected a single prod point in the second group, got %v", groups[1])
+	}
+}
+
+func TestAggregateMetricsCardinalityLimit(t *testing.T) {
+	const limit = 3
+
+	t.Run("reject", func(t *testing.T) {
+		_, err := server.AggregateMetricPoints(distinctLabelPoints(limit+2), limit, false)
+		if !errors.Is(err, server.ErrTooManyMetricGroups) {
+			t.Errorf("Expected ErrTooManyMetricGroups, got %v", err)
+		}
+		if status.Code(err) != codes.ResourceExhausted {
+			t.Errorf("Expected ResourceExhausted, got %v", status.Code(err))
+		}
+
+		// Exactly at the limit is accepted
+		if _, err := server.AggregateMetricPoints(distinctLabelPoints(limit), limit, false); err != nil {
+			t.Errorf("Expected %d label sets to be accepted, got %v", limit, err)
+		}
+	})
+
+	t.Run("overflow bucket", func(t *testing.T) {
+		groups, err := server.AggregateMetricPoints(distinctLabelPoints(limit+2), limit, true)
+		if err != nil {
+			t.Fatalf("AggregateMetricPoints failed: %v", err)
+		}
+
+		if len(groups) != limit+1 {
+			t.Fatalf("Expected %d groups plus overflow, got %d", limit, len(groups))
+		}
+
+		overflow := groups[limit]
+		if !overflow.Overflow || len(overflow.Labels) != 0 {
+			t.Errorf("Expected an unlabeled overflow group, got %v", overflow)
+		}
+		// Measurements 4 and 5 belong to the label sets past the limit
+		if overflow.Count != 2 || overflow.Sum != 9 {
+			t.Errorf("Expected overflow count 2 and sum 9, got %d and %v", overflow.Count, overflow.Sum)
+		}
+	})
+
+	t.Run("rpc", func(t *testing.T) {
+		validationServer := server.NewValidationServer(server.WithMetricCardinality(limit, false))
+		conn, cleanup := setupCustomTestServer(t, func(s *grpc.Server) {
+			v1.RegisterValidationServiceServer(s, validationServer)
+		})
+		defer cleanup()
+		client := v1.NewValidationServiceClient(conn)
+
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+
+		_, err := client.AggregateMetrics(ctx, &v1.AggregateMetricsRequest{Points: distinctLabelPoints(limit * 100)})
+		if status.Code(err) != codes.ResourceExhausted {
+			t.Errorf("Expected ResourceExhausted, got %v", err)
+		}
+
+		// A selector narrowing the input below the limit is accepted
+		resp, err := client.AggregateMetrics(ctx, &v1.AggregateMetricsRequest{
+			Points:        distinctLabelPoints(limit * 100),
+			LabelSelector: map[string]string{"user": "u7"},
+		})
+		if err != nil {
+			t.Fatalf("AggregateMetrics failed: %v", err)
+		}
+		if len(resp.Groups) != 1 || resp.FilteredCount != limit*100-1 {
+			t.Errorf("Expected 1 group and %d filtered points, got %d and %d", limit*100-1, len(resp.Groups), resp.FilteredCount)
+		}
+	})
+}
@@ -0,0 +1,98 @@
+package validation
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	v1 "github.com/benjamin-rood/protogo-values-validation-demo/gen/api/validation/v1"
+	"github.com/benjamin-rood/protogo-values-validation-demo/internal/openapi"
+)
+
+// TestOpenAPIDocument tests the served OpenAPI document describes the service
+func TestOpenAPIDocument(t *testing.T) {
+	req := httptest.NewRequest(http.MethodGet, "/openapi.json", nil)
+	rec := httptest.NewRecorder()
+
+	openapi.Handler()(rec, req)
+
+	if rec.Code != http.StatusOK {
+		t.Fatalf("Expected status 200, got %d", rec.Code)
+	}
+
+	if contentType := rec.Header().Get("Content-Type"); contentType != "application/json" {
+		t.Errorf("Expected application/json content type, got %s", contentType)
+	}
+
+	var doc struct {
+		OpenAPI    string                                `json:"openapi"`
+		Paths      map[string]map[string]json.RawMessage `json:"paths"`
+		Components struct {
+			Schemas map[string]any `json:"schemas"`
+		} `json:"components"`
+	}
+	if err := json.Unmarshal(rec.Body.Bytes(), &doc); err != nil {
+		t.Fatalf("OpenAPI document is not valid JSON: %v", err)
+	}
+
+	if !strings.HasPrefix(doc.OpenAPI, "3.") {
+		t.Errorf("Expected an OpenAPI 3 document, got version %q", doc.OpenAPI)
+	}
+	if strings.Contains(rec.Body.String(), "#/definitions/") {
+		t.Error("Expected every reference to point at components/schemas")
+	}
+
+	for _, path := range []string{
+		"/validation.v1.ValidationService/ValidateTypes",
+		"/validation.v1.ValidationService/RunBenchmarks",
+	} {
+		operations, ok := doc.Paths[path]
+		if !ok {
+			t.Errorf("Expected operation %s in OpenAPI document", path)
+			continue
+		}
+		post, ok := operations["post"]
+		if !ok {
+			t.Errorf("Expected POST operation for %s", path)
+			continue
+		}
+		var operation struct {
+			RequestBody struct {
+				Content map[string]any `json:"content"`
+			} `json:"requestBody"`
+		}
+		if err := json.Unmarshal(post, &operation); err != nil {
+			t.Fatalf("Operation %s is not valid JSON: %v", path, err)
+		}
+		if _, ok := operation.RequestBody.Content["application/json"]; !ok {
+			t.Errorf("Expected a JSON request body for %s", path)
+		}
+	}
+
+	for _, definition := range []string{
+		"v1ValidateTypesRequest",
+		"v1ValidateTypesResponse",
+		"v1BenchmarkRequest",
+		"v1BenchmarkResponse",
+	} {
+		if _, ok := doc.Components.Schemas[definition]; !ok {
+			t.Errorf("Expected schema %s in OpenAPI document", definition)
+		}
+	}
+
+	// The document is committed, so catch a service change that was not
+	// followed by make generate
+	services := v1.File_api_validation_v1_validation_proto.Services()
+	for i := 0; i < services.Len(); i++ {
+		methods := services.Get(i).Methods()
+		for j := 0; j < methods.Len(); j++ {
+			method := methods.Get(j)
+			path := "/" + string(method.Parent().FullName()) + "/" + string(method.Name())
+			if _, ok := doc.Paths[path]; !ok {
+				t.Errorf("Expected operation %s in OpenAPI document, regenerate it with make generate", path)
+			}
+		}
+	}
+}
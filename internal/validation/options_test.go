@@ -0,0 +1,102 @@
+package validation
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	v1 "github.com/benjamin-rood/protogo-values-validation-demo/gen/api/validation/v1"
+	"github.com/benjamin-rood/protogo-values-validation-demo/internal/server"
+)
+
+// recordingMetrics collects every benchmark result it is given
+type recordingMetrics struct {
+	mu      sync.Mutex
+	results []string
+}
+
+func (m *recordingMetrics) RecordBenchmark(result *v1.BenchmarkResult) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.results = append(m.results, result.Name)
+}
+
+func TestNewValidationServerDefaults(t *testing.T) {
+	s := server.NewValidationServer()
+	ctx := context.Background()
+
+	// Default max data size: the limit is accepted, one past it is rejected
+	if _, err := s.EstimateSize(ctx, &v1.EstimateSizeRequest{Count: 1, DataSize: server.DefaultMaxDataSize}); err != nil {
+		t.Errorf("Expected the default max data size to be accepted, got %v", err)
+	}
+	_, err := s.EstimateSize(ctx, &v1.EstimateSizeRequest{Count: 1, DataSize: server.DefaultMaxDataSize + 1})
+	if !errors.Is(err, server.ErrDataSizeTooLarge) {
+		t.Errorf("Expected ErrDataSizeTooLarge past the default max, got %v", err)
+	}
+
+	// Default clock is the wall clock
+	resp, err := s.RunBenchmarks(ctx, &v1.BenchmarkRequest{Iterations: 10, DataSize: 10})
+	if err != nil {
+		t.Fatalf("RunBenchmarks failed: %v", err)
+	}
+	if resp.Results[0].DurationNs <= 0 {
+		t.Errorf("Expected a positive wall-clock duration, got %v", resp.Results[0].DurationNs)
+	}
+
+	// Cache is enabled by default
+	req := &v1.ValidateTypesRequest{TestScenarios: []string{server.ScenarioValidationTestMessage}}
+	for i := 0; i < 2; i++ {
+		if _, err := s.ValidateTypes(ctx, req); err != nil {
+			t.Fatalf("ValidateTypes failed: %v", err)
+		}
+	}
+	if s.CacheHits() != 1 {
+		t.Errorf("Expected cache enabled by default, got %d hits", s.CacheHits())
+	}
+}
+
+func TestNewValidationServerOptions(t *testing.T) {
+	metrics := &recordingMetrics{}
+	clock := &stepClock{now: time.Unix(0, 0), step: time.Second}
+
+	s := server.NewValidationServer(
+		server.WithClock(clock),
+		server.WithMetrics(metrics),
+		server.WithMaxDataSize(50),
+		server.WithCache(false),
+	)
+	ctx := context.Background()
+
+	_, err := s.RunBenchmarks(ctx, &v1.BenchmarkRequest{Iterations: 10, DataSize: 51})
+	if !errors.Is(err, server.ErrDataSizeTooLarge) {
+		t.Errorf("Expected ErrDataSizeTooLarge with WithMaxDataSize(50), got %v", err)
+	}
+
+	resp, err := s.RunBenchmarks(ctx, &v1.BenchmarkRequest{Iterations: 10, DataSize: 50})
+	if err != nil {
+		t.Fatalf("RunBenchmarks failed: %v", err)
+	}
+
+	if resp.Results[0].DurationNs != float64(time.Second.Nanoseconds()) {
+		t.Errorf("Expected duration from injected clock, got %v", resp.Results[0].DurationNs)
+	}
+
+	metrics.mu.Lock()
+	recorded := len(metrics.results)
+	metrics.mu.Unlock()
+	if recorded != len(resp.Results) {
+		t.Errorf("Expected %d recorded results, got %d", len(resp.Results), recorded)
+	}
+
+	req := &v1.ValidateTypesRequest{TestScenarios: []string{server.ScenarioValidationTestMessage}}
+	for i := 0; i < 2; i++ {
+		if _, err := s.ValidateTypes(ctx, req); err != nil {
+			t.Fatalf("ValidateTypes failed: %v", err)
+		}
+	}
+	if s.CacheHits() != 0 {
+		t.Errorf("Expected cache disabled, got %d hits", s.CacheHits())
+	}
+}
@@ -0,0 +1,125 @@
+package validation
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	v1 "github.com/benjamin-rood/protogo-values-validation-demo/gen/api/validation/v1"
+	"github.com/benjamin-rood/protogo-values-validation-demo/internal/server"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
+)
+
+func TestValidateTypesPagination(t *testing.T) {
+	cleanup := setupTestServer()
+	defer cleanup()
+
+	client, closeConn := createTestClient(t)
+	defer closeConn()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	scenarios := []string{server.ScenarioValidationTestMessage, server.ScenarioPerformanceTestMessage}
+	full, err := client.ValidateTypes(ctx, &v1.ValidateTypesRequest{TestScenarios: scenarios, DeepValidation: true})
+	if err != nil {
+		t.Fatalf("ValidateTypes failed: %v", err)
+	}
+	if full.NextPageToken != "" {
+		t.Errorf("Expected no next page without page_size, got %q", full.NextPageToken)
+	}
+	if int(full.TotalResults) != len(full.Results) {
+		t.Errorf("Expected total_results %d, got %d", len(full.Results), full.TotalResults)
+	}
+
+	for _, pageSize := range []int32{1, 3, int32(len(full.Results)), int32(len(full.Results)) + 5} {
+		var (
+			reassembled []*v1.ValidationResult
+			token       string
+			pages       int
+		)
+		for {
+			page, err := client.ValidateTypes(ctx, &v1.ValidateTypesRequest{
+				TestScenarios:  scenarios,
+				DeepValidation: true,
+				PageSize:       pageSize,
+				PageToken:      token,
+			})
+			if err != nil {
+				t.Fatalf("Page size %d, page %d: ValidateTypes failed: %v", pageSize, pages, err)
+			}
+			pages++
+
+			if len(page.Results) > int(pageSize) {
+				t.Errorf("Page size %d: got %d results on one page", pageSize, len(page.Results))
+			}
+			if page.TotalResults != full.TotalResults || page.Success != full.Success {
+				t.Errorf("Page size %d: expected every page to carry the overall summary", pageSize)
+			}
+
+			reassembled = append(reassembled, page.Results...)
+			token = page.NextPageToken
+			if token == "" {
+				break
+			}
+			if pages > len(full.Results) {
+				t.Fatalf("Page size %d: pagination did not terminate", pageSize)
+			}
+		}
+
+		expectedPages := (len(full.Results) + int(pageSize) - 1) / int(pageSize)
+		if pages != expectedPages {
+			t.Errorf("Page size %d: expected %d pages, got %d", pageSize, expectedPages, pages)
+		}
+
+		if len(reassembled) != len(full.Results) {
+			t.Fatalf("Page size %d: expected %d results, got %d", pageSize, len(full.Results), len(reassembled))
+		}
+		for i := range reassembled {
+			if !proto.Equal(reassembled[i], full.Results[i]) {
+				t.Errorf("Page size %d: result %d differs: expected %v, got %v", pageSize, i, full.Results[i], reassembled[i])
+			}
+		}
+	}
+}
+
+func TestValidateTypesPaginationErrors(t *testing.T) {
+	s := server.NewValidationServer()
+	ctx := context.Background()
+
+	first, err := s.ValidateTypes(ctx, &v1.ValidateTypesRequest{TestScenarios: []string{server.ScenarioValidationTestMessage}, PageSize: 1})
+	if err != nil {
+		t.Fatalf("ValidateTypes failed: %v", err)
+	}
+	if first.NextPageToken == "" {
+		t.Fatal("Expected a next page token")
+	}
+
+	tests := []struct {
+		name string
+		req  *v1.ValidateTypesRequest
+	}{
+		{"negative page size", &v1.ValidateTypesRequest{TestScenarios: []string{server.ScenarioValidationTestMessage}, PageSize: -1}},
+		{"malformed token", &v1.ValidateTypesRequest{TestScenarios: []string{server.ScenarioValidationTestMessage}, PageSize: 1, PageToken: "not a token"}},
+		{"token from another request", &v1.ValidateTypesRequest{TestScenarios: []string{server.ScenarioPerformanceTestMessage}, PageSize: 1, PageToken: first.NextPageToken}},
+		{"token with another deep flag", &v1.ValidateTypesRequest{TestScenarios: []string{server.ScenarioValidationTestMessage}, DeepValidation: true, PageSize: 1, PageToken: first.NextPageToken}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := server.ValidateRequest(tt.req)
+			if err == nil {
+				_, err = s.ValidateTypes(ctx, tt.req)
+			}
+			if status.Code(err) != codes.InvalidArgument {
+				t.Errorf("Expected InvalidArgument, got %v", err)
+			}
+			if tt.req.PageToken != "" && !errors.Is(err, server.ErrInvalidPageToken) {
+				t.Errorf("Expected ErrInvalidPageToken, got %v", err)
+			}
+		})
+	}
+}
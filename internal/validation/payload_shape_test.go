@@ -0,0 +1,83 @@
+package validation
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	v1 "github.com/benjamin-rood/protogo-values-validation-demo/gen/api/validation/v1"
+	"github.com/benjamin-rood/protogo-values-validation-demo/internal/server"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func TestRunBenchmarksPayloadShapeScalesSerializedSize(t *testing.T) {
+	validationServer := server.NewValidationServer()
+
+	serializedBytes := func(tags, attributes int32) int64 {
+		t.Helper()
+		resp, err := validationServer.RunBenchmarks(context.Background(), &v1.BenchmarkRequest{
+			Iterations:        1,
+			DataSize:          100,
+			TagsPerItem:       tags,
+			AttributesPerItem: attributes,
+		})
+		if err != nil {
+			t.Fatalf("RunBenchmarks(tags=%d, attributes=%d) failed: %v", tags, attributes, err)
+		}
+		return resp.SerializedBytes
+	}
+
+	base := serializedBytes(0, 0)
+	if base <= 0 {
+		t.Fatalf("Expected a positive serialized size, got %d", base)
+	}
+
+	// The default shape has two attributes, so asking for two changes nothing
+	if got := serializedBytes(0, 2); got != base {
+		t.Errorf("Expected attributes_per_item=2 to match the default %d bytes, got %d", base, got)
+	}
+
+	// Each added tag or attribute adds roughly the same bytes per element
+	tags8, tags16 := serializedBytes(8, 0), serializedBytes(16, 0)
+	if tags8 <= base || tags16 <= tags8 {
+		t.Errorf("Expected size to grow with tags, got %d, %d, %d", base, tags8, tags16)
+	}
+	if growth, next := tags8-base, tags16-tags8; next < growth*9/10 || next > growth*11/10 {
+		t.Errorf("Expected linear growth with tags, got +%d then +%d bytes", growth, next)
+	}
+
+	attributes10, attributes18 := serializedBytes(0, 10), serializedBytes(0, 18)
+	if attributes10 <= base || attributes18 <= attributes10 {
+		t.Errorf("Expected size to grow with attributes, got %d, %d, %d", base, attributes10, attributes18)
+	}
+	if growth, next := attributes10-base, attributes18-attributes10; next < growth*9/10 || next > growth*11/10 {
+		t.Errorf("Expected linear growth with attributes, got +%d then +%d bytes", growth, next)
+	}
+}
+
+func TestRunBenchmarksPayloadShapeLimits(t *testing.T) {
+	validationServer := server.NewValidationServer()
+
+	for _, req := range []*v1.BenchmarkRequest{
+		{Iterations: 1, DataSize: 10, TagsPerItem: -1},
+		{Iterations: 1, DataSize: 10, AttributesPerItem: -1},
+		{Iterations: 1, DataSize: 10, TagsPerItem: server.MaxEntriesPerItem + 1},
+		{Iterations: 1, DataSize: 10, AttributesPerItem: 1 << 30},
+	} {
+		err := server.ValidateRequest(req)
+		if status.Code(err) != codes.InvalidArgument {
+			t.Errorf("Expected InvalidArgument for %v, got %v", req, err)
+		}
+		if !errors.Is(err, server.ErrInvalidItemEntries) {
+			t.Errorf("Expected ErrInvalidItemEntries for %v, got %v", req, err)
+		}
+	}
+
+	// The cap itself is allowed
+	if _, err := validationServer.RunBenchmarks(context.Background(), &v1.BenchmarkRequest{
+		Iterations: 1, DataSize: 10, TagsPerItem: server.MaxEntriesPerItem, AttributesPerItem: server.MaxEntriesPerItem,
+	}); err != nil {
+		t.Errorf("Expected the maximum entries per item to be accepted, got %v", err)
+	}
+}
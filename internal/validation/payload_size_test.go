@@ -0,0 +1,192 @@
+package validation
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"log/slog"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	v1 "github.com/benjamin-rood/protogo-values-validation-demo/gen/api/validation/v1"
+	"github.com/benjamin-rood/protogo-values-validation-demo/internal/server"
+	"google.golang.org/grpc"
+	"google.golang.org/protobuf/proto"
+)
+
+// captureLogs routes the default slog logger to a buffer at level for the test
+func captureLogs(t *testing.T, level slog.Level) *bytes.Buffer {
+	t.Helper()
+
+	var buf bytes.Buffer
+	previous := slog.Default()
+	slog.SetDefault(slog.New(slog.NewJSONHandler(&buf, &slog.HandlerOptions{Level: level})))
+	t.Cleanup(func() { slog.SetDefault(previous) })
+
+	return &buf
+}
+
+// completedCalls decodes the "call completed" log records in buf
+func completedCalls(t *testing.T, buf *bytes.Buffer) []map[string]any {
+	t.Helper()
+
+	var records []map[string]any
+	for _, line := range strings.Split(strings.TrimSpace(buf.String()), "\n") {
+		var record map[string]any
+		if err := json.Unmarshal([]byte(line), &record); err != nil {
+			t.Fatalf("Failed to decode log line %q: %v", line, err)
+		}
+		if msg, _ := record["msg"].(string); strings.HasSuffix(msg, "call completed") {
+			records = append(records, record)
+		}
+	}
+	return records
+}
+
+// setupSizedTestServer serves ValidationService behind interceptors configured with opts
+func setupSizedTestServer(t *testing.T, opts ...server.InterceptorOption) (v1.ValidationServiceClient, func()) {
+	conn, cleanup := setupCustomTestServer(t, func(s *grpc.Server) {
+		v1.RegisterValidationServiceServer(s, server.NewValidationServer())
+	},
+		grpc.ChainUnaryInterceptor(server.UnaryRequestIDInterceptor(opts...)),
+		grpc.ChainStreamInterceptor(server.StreamRequestIDInterceptor(opts...)),
+	)
+	return v1.NewValidationServiceClient(conn), cleanup
+}
+
+func TestPayloadSizesLoggedWhenSampled(t *testing.T) {
+	logs := captureLogs(t, slog.LevelInfo)
+	metrics := server.NewPayloadSizeMetrics()
+
+	client, cleanup := setupSizedTestServer(t, server.WithPayloadSizes(metrics, true))
+	defer cleanup()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	req := &v1.ValidateTypesRequest{TestScenarios: []string{server.ScenarioValidationTestMessage}}
+	resp, err := client.ValidateTypes(ctx, req)
+	if err != nil {
+		t.Fatalf("ValidateTypes failed: %v", err)
+	}
+
+	stream, err := client.StreamValidation(ctx)
+	if err != nil {
+		t.Fatalf("Failed to open stream: %v", err)
+	}
+	streamReq := &v1.StreamRequest{RequestId: "sized", TestData: &v1.ValidationTestMessage{}}
+	if err := stream.Send(streamReq); err != nil {
+		t.Fatalf("Send failed: %v", err)
+	}
+	stream.CloseSend()
+	for {
+		if _, err := stream.Recv(); err != nil {
+			if err != io.EOF {
+				t.Fatalf("Recv failed: %v", err)
+			}
+			break
+		}
+	}
+
+	// The server logs after the handler returns, so wait for both records
+	deadline := time.Now().Add(2 * time.Second)
+	for len(completedCalls(t, logs)) < 2 && time.Now().Before(deadline) {
+		time.Sleep(5 * time.Millisecond)
+	}
+	records := completedCalls(t, logs)
+	if len(records) != 2 {
+		t.Fatalf("Expected 2 completed calls logged, got %d", len(records))
+	}
+
+	unary := records[0]
+	if got := unary["request_bytes"]; got != float64(proto.Size(req)) {
+		t.Errorf("Expected request_bytes %d, got %v", proto.Size(req), got)
+	}
+	if got := unary["response_bytes"]; got != float64(proto.Size(resp)) {
+		t.Errorf("Expected response_bytes %d, got %v", proto.Size(resp), got)
+	}
+
+	if got := records[1]["request_bytes"]; got != float64(proto.Size(streamReq)) {
+		t.Errorf("Expected stream request_bytes %d, got %v", proto.Size(streamReq), got)
+	}
+	if got, ok := records[1]["response_bytes"].(float64); !ok || got <= 0 {
+		t.Errorf("Expected positive stream response_bytes, got %v", records[1]["response_bytes"])
+	}
+
+	rec := httptest.NewRecorder()
+	metrics.Handler()(rec, httptest.NewRequest("GET", "/metrics", nil))
+	body := rec.Body.String()
+	for _, want := range []string{
+		"# TYPE grpc_server_payload_size_bytes histogram",
+		`grpc_server_payload_size_bytes_count{method="/validation.v1.ValidationService/ValidateTypes",direction="request"} 1`,
+		`grpc_server_payload_size_bytes_count{method="/validation.v1.ValidationService/ValidateTypes",direction="response"} 1`,
+		`grpc_server_payload_size_bytes_count{method="/validation.v1.ValidationService/StreamValidation",direction="request"} 1`,
+	} {
+		if !strings.Contains(body, want) {
+			t.Errorf("Expected metrics to contain %q, got:\n%s", want, body)
+		}
+	}
+}
+
+func TestPayloadSizesOnlyAtDebugWhenNotSampled(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	req := &v1.ValidateTypesRequest{TestScenarios: []string{server.ScenarioValidationTestMessage}}
+
+	for _, tc := range []struct {
+		level    slog.Level
+		expected bool
+	}{
+		{slog.LevelInfo, false},
+		{slog.LevelDebug, true},
+	} {
+		t.Run(tc.level.String(), func(t *testing.T) {
+			logs := captureLogs(t, tc.level)
+			client, cleanup := setupSizedTestServer(t, server.WithPayloadSizes(server.NewPayloadSizeMetrics(), false))
+			defer cleanup()
+
+			if _, err := client.ValidateTypes(ctx, req); err != nil {
+				t.Fatalf("ValidateTypes failed: %v", err)
+			}
+
+			deadline := time.Now().Add(2 * time.Second)
+			for len(completedCalls(t, logs)) < 1 && time.Now().Before(deadline) {
+				time.Sleep(5 * time.Millisecond)
+			}
+			records := completedCalls(t, logs)
+			if len(records) != 1 {
+				t.Fatalf("Expected 1 completed call logged, got %d", len(records))
+			}
+
+			if _, ok := records[0]["request_bytes"]; ok != tc.expected {
+				t.Errorf("Expected request_bytes logged=%v at %s", tc.expected, tc.level)
+			}
+		})
+	}
+}
+
+func TestMessageSizeValueSlices(t *testing.T) {
+	msg := &v1.ValidationTestMessage{
+		ValueSliceData: []v1.DataPoint{{Id: "dp_0", Value: 1.5}, {Id: "dp_1", Value: 3}},
+	}
+
+	size, ok := server.MessageSize(msg)
+	if !ok {
+		t.Fatal("Expected a value-slice message to be sized")
+	}
+
+	estimated, err := server.EstimateMessageSize(msg)
+	if err != nil {
+		t.Fatalf("EstimateMessageSize failed: %v", err)
+	}
+	if size != estimated {
+		t.Errorf("Expected size %d, got %d", estimated, size)
+	}
+
+	if _, ok := server.MessageSize("not a message"); ok {
+		t.Error("Expected non-messages to have no size")
+	}
+}
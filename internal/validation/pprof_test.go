@@ -0,0 +1,65 @@
+package validation
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/benjamin-rood/protogo-values-validation-demo/internal/config"
+	"github.com/benjamin-rood/protogo-values-validation-demo/internal/server"
+)
+
+func TestPprofHandlerServesProfiles(t *testing.T) {
+	ts := httptest.NewServer(server.PprofHandler())
+	defer ts.Close()
+
+	for _, path := range []string{"/debug/pprof/", "/debug/pprof/heap", "/debug/pprof/goroutine?debug=1"} {
+		resp, err := http.Get(ts.URL + path)
+		if err != nil {
+			t.Fatalf("GET %s failed: %v", path, err)
+		}
+		resp.Body.Close()
+
+		if resp.StatusCode != http.StatusOK {
+			t.Errorf("Expected 200 for %s, got %d", path, resp.StatusCode)
+		}
+	}
+
+	// Only profiles are served on the private port
+	resp, err := http.Get(ts.URL + "/health")
+	if err != nil {
+		t.Fatalf("GET /health failed: %v", err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusNotFound {
+		t.Errorf("Expected 404 for /health on the pprof port, got %d", resp.StatusCode)
+	}
+}
+
+func TestConfigPprofPort(t *testing.T) {
+	t.Run("Enabled", func(t *testing.T) {
+		clearConfigEnv(t)
+		t.Setenv("PPROF_PORT", "6060")
+
+		cfg, err := config.Load()
+		if err != nil {
+			t.Fatalf("Load failed: %v", err)
+		}
+		if !cfg.PprofEnabled() || cfg.PprofPort != "6060" {
+			t.Errorf("Expected pprof enabled on 6060, got %q", cfg.PprofPort)
+		}
+	})
+
+	for _, port := range []string{"8080", "9090"} {
+		t.Run("CollidesWith_"+port, func(t *testing.T) {
+			clearConfigEnv(t)
+			t.Setenv("PPROF_PORT", port)
+
+			_, err := config.Load()
+			if err == nil || !strings.Contains(err.Error(), "PPROF_PORT must differ") {
+				t.Errorf("Expected PPROF_PORT collision error, got %v", err)
+			}
+		})
+	}
+}
@@ -0,0 +1,164 @@
+package validation
+
+import (
+	"context"
+	"errors"
+	"runtime"
+	"testing"
+	"time"
+
+	v1 "github.com/benjamin-rood/protogo-values-validation-demo/gen/api/validation/v1"
+	"github.com/benjamin-rood/protogo-values-validation-demo/internal/server"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// panickingClock fails the first timing measurement a run takes
+type panickingClock struct{}
+
+func (panickingClock) Now() time.Time                { panic("clock unavailable") }
+func (panickingClock) Since(time.Time) time.Duration { panic("clock unavailable") }
+
+// otherProcs is a GOMAXPROCS within the CPU count that differs from the
+// current one where the host allows it
+func otherProcs() int32 {
+	if runtime.GOMAXPROCS(0) > 1 {
+		return 1
+	}
+	return int32(runtime.NumCPU())
+}
+
+func TestRunBenchmarksPinGOMAXPROCS(t *testing.T) {
+	original := runtime.GOMAXPROCS(0)
+	validationServer := server.NewValidationServer(server.WithGOMAXPROCSPinning(true))
+
+	tests := []struct {
+		name       string
+		gomaxprocs int32
+		expected   int32
+	}{
+		{"default pins to 1", 0, 1},
+		{"requested value", int32(runtime.NumCPU()), int32(runtime.NumCPU())},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resp, err := validationServer.RunBenchmarks(context.Background(), &v1.BenchmarkRequest{
+				Iterations: 2, DataSize: 10, PinGomaxprocs: true, Gomaxprocs: tt.gomaxprocs,
+			})
+			if err != nil {
+				t.Fatalf("RunBenchmarks failed: %v", err)
+			}
+			if resp.Gomaxprocs != tt.expected {
+				t.Errorf("Expected benchmarks to run with GOMAXPROCS %d, got %d", tt.expected, resp.Gomaxprocs)
+			}
+			if got := runtime.GOMAXPROCS(0); got != original {
+				t.Errorf("Expected GOMAXPROCS restored to %d, got %d", original, got)
+			}
+		})
+	}
+
+	t.Run("unpinned", func(t *testing.T) {
+		resp, err := validationServer.RunBenchmarks(context.Background(), &v1.BenchmarkRequest{Iterations: 2, DataSize: 10, Gomaxprocs: 1})
+		if err != nil {
+			t.Fatalf("RunBenchmarks failed: %v", err)
+		}
+		if resp.Gomaxprocs != int32(original) {
+			t.Errorf("Expected GOMAXPROCS left at %d without pinning, got %d", original, resp.Gomaxprocs)
+		}
+	})
+}
+
+func TestRunBenchmarksRestoresGOMAXPROCSOnError(t *testing.T) {
+	original := runtime.GOMAXPROCS(0)
+	validationServer := server.NewValidationServer(server.WithGOMAXPROCSPinning(true))
+
+	// Data past the server's maximum fails the run after GOMAXPROCS is pinned
+	_, err := validationServer.RunBenchmarks(context.Background(), &v1.BenchmarkRequest{
+		Iterations: 2, DataSize: server.DefaultMaxDataSize + 1, PinGomaxprocs: true, Gomaxprocs: otherProcs(),
+	})
+	if !errors.Is(err, server.ErrDataSizeTooLarge) {
+		t.Fatalf("Expected RunBenchmarks to fail with ErrDataSizeTooLarge, got %v", err)
+	}
+	if got := runtime.GOMAXPROCS(0); got != original {
+		t.Errorf("Expected GOMAXPROCS restored to %d after an error, got %d", original, got)
+	}
+
+	// The pin was released, so later pinned runs are not blocked
+	if _, err := validationServer.RunBenchmarks(context.Background(), &v1.BenchmarkRequest{
+		Iterations: 2, DataSize: 10, PinGomaxprocs: true,
+	}); err != nil {
+		t.Fatalf("RunBenchmarks after the error failed: %v", err)
+	}
+}
+
+func TestRunBenchmarksRestoresGOMAXPROCSOnPanic(t *testing.T) {
+	original := runtime.GOMAXPROCS(0)
+	validationServer := server.NewValidationServer(server.WithClock(panickingClock{}), server.WithGOMAXPROCSPinning(true))
+
+	func() {
+		defer func() {
+			if recover() == nil {
+				t.Error("Expected RunBenchmarks to panic")
+			}
+		}()
+		validationServer.RunBenchmarks(context.Background(), &v1.BenchmarkRequest{
+			Iterations: 2, DataSize: 10, PinGomaxprocs: true, Gomaxprocs: otherProcs(),
+		})
+	}()
+
+	if got := runtime.GOMAXPROCS(0); got != original {
+		t.Errorf("Expected GOMAXPROCS restored to %d after a panic, got %d", original, got)
+	}
+}
+
+func TestRunBenchmarksPinGOMAXPROCSDisabled(t *testing.T) {
+	original := runtime.GOMAXPROCS(0)
+	validationServer := server.NewValidationServer()
+
+	_, err := validationServer.RunBenchmarks(context.Background(), &v1.BenchmarkRequest{
+		Iterations: 2, DataSize: 10, PinGomaxprocs: true, Gomaxprocs: otherProcs(),
+	})
+	if status.Code(err) != codes.FailedPrecondition {
+		t.Errorf("Expected FailedPrecondition without WithGOMAXPROCSPinning, got %v", err)
+	}
+	if got := runtime.GOMAXPROCS(0); got != original {
+		t.Errorf("Expected GOMAXPROCS left at %d, got %d", original, got)
+	}
+}
+
+func TestRunBenchmarksPinGOMAXPROCSWaitHonorsContext(t *testing.T) {
+	// The first run pins GOMAXPROCS and holds in its setup measurement
+	clock := &gatedClock{open: make(chan struct{})}
+	validationServer := server.NewValidationServer(server.WithClock(clock), server.WithGOMAXPROCSPinning(true))
+	req := &v1.BenchmarkRequest{Iterations: 2, DataSize: 10, PinGomaxprocs: true}
+
+	done := make(chan error, 1)
+	go func() {
+		_, err := validationServer.RunBenchmarks(context.Background(), req)
+		done <- err
+	}()
+	// Give the first run time to pin
+	time.Sleep(50 * time.Millisecond)
+
+	// A second pinned run gives up waiting when its deadline passes
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	if _, err := validationServer.RunBenchmarks(ctx, req); status.Code(err) != codes.DeadlineExceeded {
+		t.Errorf("Expected DeadlineExceeded while waiting for the pin, got %v", err)
+	}
+
+	close(clock.open)
+	if err := <-done; err != nil {
+		t.Errorf("Expected the first run to succeed, got %v", err)
+	}
+}
+
+func TestValidateRequestBoundsGOMAXPROCS(t *testing.T) {
+	for _, gomaxprocs := range []int32{-1, int32(runtime.NumCPU()) + 1} {
+		err := server.ValidateRequest(&v1.BenchmarkRequest{Iterations: 1, DataSize: 1, PinGomaxprocs: true, Gomaxprocs: gomaxprocs})
+		if !errors.Is(err, server.ErrInvalidGOMAXPROCS) {
+			t.Errorf("Gomaxprocs %d: expected ErrInvalidGOMAXPROCS, got %v", gomaxprocs, err)
+		}
+	}
+}
@@ -0,0 +1,103 @@
+package validation
+
+import (
+	"context"
+	"math"
+	"testing"
+	"time"
+
+	v1 "github.com/benjamin-rood/protogo-values-validation-demo/gen/api/validation/v1"
+	"github.com/benjamin-rood/protogo-values-validation-demo/internal/server"
+)
+
+func TestFormatRate(t *testing.T) {
+	tests := []struct {
+		rate     float64
+		unit     string
+		expected string
+	}{
+		{0, "ops", "0.0 ops/s"},
+		{950, "ops", "950.0 ops/s"},
+		{12345, "ops", "12.3K ops/s"},
+		{2500000, "items", "2.5M items/s"},
+		{7.5e9, "ops", "7.5G ops/s"},
+		{math.Inf(1), "ops", "0.0 ops/s"},
+		{math.NaN(), "ops", "0.0 ops/s"},
+	}
+
+	for _, tt := range tests {
+		if got := server.FormatRate(tt.rate, tt.unit); got != tt.expected {
+			t.Errorf("FormatRate(%v, %q): expected %q, got %q", tt.rate, tt.unit, tt.expected, got)
+		}
+	}
+}
+
+func TestStreamThroughputZeroDuration(t *testing.T) {
+	// A clock that never advances measures every operation as zero duration
+	client, cleanup := setupClockTestServer(t, &stepClock{now: time.Unix(0, 0)})
+	defer cleanup()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	stream, err := client.StreamValidation(ctx)
+	if err != nil {
+		t.Fatalf("Failed to create stream: %v", err)
+	}
+
+	err = stream.Send(&v1.StreamRequest{
+		RequestId: "zero",
+		TestData: &v1.ValidationTestMessage{
+			PointerSliceData: createDataPointPointers(10),
+		},
+	})
+	if err != nil {
+		t.Fatalf("Failed to send: %v", err)
+	}
+
+	resp, err := stream.Recv()
+	if err != nil {
+		t.Fatalf("Failed to receive: %v", err)
+	}
+	stream.CloseSend()
+
+	stats := resp.Stats
+	if stats.ProcessingTimeNs != 0 {
+		t.Fatalf("Expected zero processing time, got %d", stats.ProcessingTimeNs)
+	}
+	if math.IsInf(stats.Throughput, 0) || math.IsNaN(stats.Throughput) {
+		t.Errorf("Expected finite throughput, got %v", stats.Throughput)
+	}
+	if stats.Throughput != 0 {
+		t.Errorf("Expected throughput 0 for zero duration, got %v", stats.Throughput)
+	}
+	if stats.ThroughputHuman != "0.0 items/s" {
+		t.Errorf("Expected human throughput \"0.0 items/s\", got %q", stats.ThroughputHuman)
+	}
+}
+
+func TestBenchmarkZeroDurationsAreFinite(t *testing.T) {
+	s := server.NewValidationServer(server.WithClock(&stepClock{now: time.Unix(0, 0)}))
+
+	resp, err := s.RunBenchmarks(context.Background(), &v1.BenchmarkRequest{Iterations: 1, DataSize: 1})
+	if err != nil {
+		t.Fatalf("RunBenchmarks failed: %v", err)
+	}
+
+	for _, result := range resp.Results {
+		if result.Error != "" {
+			continue
+		}
+		if result.DurationNs != 0 {
+			t.Fatalf("%s: expected zero duration, got %v", result.Name, result.DurationNs)
+		}
+		if math.IsInf(result.OperationsPerSecond, 0) || math.IsNaN(result.OperationsPerSecond) || result.OperationsPerSecond != 0 {
+			t.Errorf("%s: expected 0 ops/sec for zero duration, got %v", result.Name, result.OperationsPerSecond)
+		}
+	}
+
+	ratio := resp.Summary.PerformanceImprovementRatio
+	if math.IsInf(ratio, 0) || math.IsNaN(ratio) || ratio != 1 {
+		t.Errorf("Expected improvement ratio 1 for zero durations, got %v", ratio)
+	}
+}
@@ -0,0 +1,181 @@
+package validation
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	v1 "github.com/benjamin-rood/protogo-values-validation-demo/gen/api/validation/v1"
+	"github.com/benjamin-rood/protogo-values-validation-demo/internal/server"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func TestRecommendRepresentation(t *testing.T) {
+	// Every timed interval is one step, so durations tie and each category is
+	// decided by allocations: building a pointer slice allocates per element
+	clock := &stepClock{now: time.Unix(0, 0), step: time.Millisecond}
+	validationServer := server.NewValidationServer(server.WithClock(clock))
+
+	conn, cleanup := setupCustomTestServer(t, func(s *grpc.Server) {
+		v1.RegisterValidationServiceServer(s, validationServer)
+	})
+	defer cleanup()
+	client := v1.NewValidationServiceClient(conn)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	resp, err := client.RecommendRepresentation(ctx, &v1.RecommendRepresentationRequest{DataSize: 1000, Iterations: 5})
+	if err != nil {
+		t.Fatalf("RecommendRepresentation failed: %v", err)
+	}
+
+	if resp.Recommendation != "value" {
+		t.Errorf("Expected value slices recommended, got %q: %s", resp.Recommendation, resp.Reasoning)
+	}
+	if resp.Confidence == "" {
+		t.Error("Expected a confidence")
+	}
+
+	categories := make(map[string]*v1.RepresentationDelta)
+	for _, delta := range resp.Deltas {
+		categories[delta.Category] = delta
+		if !strings.Contains(resp.Reasoning, delta.Category+":") {
+			t.Errorf("Expected reasoning to explain %s, got %q", delta.Category, resp.Reasoning)
+		}
+	}
+	for _, category := range []string{"iteration", "allocation", "serialization"} {
+		if _, ok := categories[category]; !ok {
+			t.Errorf("Expected a %s delta", category)
+		}
+	}
+
+	allocation := categories["allocation"]
+	if allocation.Winner != "value" || allocation.PointerAllocations < 1000 {
+		t.Errorf("Expected value slices to win allocation with >= 1000 pointer allocs, got %s with %d",
+			allocation.Winner, allocation.PointerAllocations)
+	}
+	if allocation.PointerToValueRatio != 1 {
+		t.Errorf("Expected equal stepped durations, got ratio %v", allocation.PointerToValueRatio)
+	}
+}
+
+func TestChooseRepresentation(t *testing.T) {
+	delta := func(category, winner string) *v1.RepresentationDelta {
+		return &v1.RepresentationDelta{Category: category, Winner: winner}
+	}
+
+	tests := []struct {
+		name           string
+		deltas         []*v1.RepresentationDelta
+		recommendation string
+		confidence     string
+	}{
+		{
+			name:           "value wins everything",
+			deltas:         []*v1.RepresentationDelta{delta("iteration", "value"), delta("allocation", "value"), delta("serialization", "value")},
+			recommendation: "value",
+			confidence:     "high",
+		},
+		{
+			name:           "pointer wins the majority",
+			deltas:         []*v1.RepresentationDelta{delta("iteration", "value"), delta("allocation", "pointer"), delta("serialization", "pointer")},
+			recommendation: "pointer",
+			confidence:     "medium",
+		},
+		{
+			name:           "split decided by iteration",
+			deltas:         []*v1.RepresentationDelta{delta("iteration", "pointer"), delta("allocation", "value"), delta("serialization", "tie")},
+			recommendation: "pointer",
+			confidence:     "low",
+		},
+		{
+			name:           "all ties default to value",
+			deltas:         []*v1.RepresentationDelta{delta("iteration", "tie"), delta("allocation", "tie"), delta("serialization", "tie")},
+			recommendation: "value",
+			confidence:     "low",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			recommendation, confidence, reasoning := server.ChooseRepresentation(tt.deltas)
+			if recommendation != tt.recommendation {
+				t.Errorf("Expected %s, got %s", tt.recommendation, recommendation)
+			}
+			if confidence != tt.confidence {
+				t.Errorf("Expected %s confidence, got %s", tt.confidence, confidence)
+			}
+			if reasoning == "" {
+				t.Error("Expected reasoning")
+			}
+
+			// Deterministic for the same deltas
+			again, _, againReasoning := server.ChooseRepresentation(tt.deltas)
+			if again != recommendation || againReasoning != reasoning {
+				t.Error("Expected the same recommendation for the same deltas")
+			}
+		})
+	}
+}
+
+func TestRecommendRepresentationInvalid(t *testing.T) {
+	validationServer := server.NewValidationServer()
+
+	for _, req := range []*v1.RecommendRepresentationRequest{
+		{DataSize: 0},
+		{DataSize: 10, Iterations: -1},
+		{DataSize: server.DefaultMaxDataSize + 1},
+		{DataSize: 10, Iterations: server.MaxRecommendIterations + 1},
+	} {
+		if _, err := validationServer.RecommendRepresentation(context.Background(), req); status.Code(err) != codes.InvalidArgument {
+			t.Errorf("Expected InvalidArgument for %v, got %v", req, err)
+		}
+	}
+}
+
+func TestRecommendRepresentationIterationLimit(t *testing.T) {
+	validationServer := server.NewValidationServer()
+
+	_, err := validationServer.RecommendRepresentation(context.Background(), &v1.RecommendRepresentationRequest{
+		DataSize:   1,
+		Iterations: server.MaxRecommendIterations + 1,
+	})
+	if !errors.Is(err, server.ErrTooManyIterations) || status.Code(err) != codes.InvalidArgument {
+		t.Errorf("Expected InvalidArgument ErrTooManyIterations past the limit, got %v", err)
+	}
+
+	if _, err := validationServer.RecommendRepresentation(context.Background(), &v1.RecommendRepresentationRequest{
+		DataSize:   1,
+		Iterations: server.MaxRecommendIterations,
+	}); err != nil {
+		t.Errorf("Expected the iteration limit to be accepted, got %v", err)
+	}
+}
+
+// cancelingClock cancels a context the first time a measurement starts
+type cancelingClock struct {
+	cancel context.CancelFunc
+}
+
+func (c *cancelingClock) Now() time.Time {
+	c.cancel()
+	return time.Unix(0, 0)
+}
+
+func (c *cancelingClock) Since(time.Time) time.Duration { return time.Microsecond }
+
+func TestRecommendRepresentationCanceled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	validationServer := server.NewValidationServer(server.WithClock(&cancelingClock{cancel: cancel}))
+
+	_, err := validationServer.RecommendRepresentation(ctx, &v1.RecommendRepresentationRequest{DataSize: 10, Iterations: 100})
+	if status.Code(err) != codes.Canceled {
+		t.Errorf("Expected Canceled once the request is canceled mid-run, got %v", err)
+	}
+}
@@ -0,0 +1,28 @@
+package validation
+
+import (
+	"testing"
+
+	v1 "github.com/benjamin-rood/protogo-values-validation-demo/gen/api/validation/v1"
+	"github.com/benjamin-rood/protogo-values-validation-demo/internal/server"
+	"google.golang.org/grpc"
+)
+
+func TestRegisterReflection(t *testing.T) {
+	for _, enabled := range []bool{false, true} {
+		s := grpc.NewServer()
+		v1.RegisterValidationServiceServer(s, server.NewValidationServer())
+		server.RegisterReflection(s, enabled)
+
+		services := s.GetServiceInfo()
+		if _, ok := services["validation.v1.ValidationService"]; !ok {
+			t.Errorf("Expected ValidationService registered, got %v", services)
+		}
+		for _, name := range []string{"grpc.reflection.v1.ServerReflection", "grpc.reflection.v1alpha.ServerReflection"} {
+			if _, ok := services[name]; ok != enabled {
+				t.Errorf("Expected %s registered only when enabled, got registered=%v with enabled=%v", name, ok, enabled)
+			}
+		}
+		s.Stop()
+	}
+}
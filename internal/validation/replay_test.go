@@ -0,0 +1,72 @@
+package validation
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	v1 "github.com/benjamin-rood/protogo-values-validation-demo/gen/api/validation/v1"
+	"github.com/benjamin-rood/protogo-values-validation-demo/internal/server"
+	"github.com/benjamin-rood/protogo-values-validation-demo/pkg/replay"
+	"google.golang.org/grpc"
+)
+
+func TestReplayFile(t *testing.T) {
+	conn, cleanup := setupCustomTestServer(t, func(s *grpc.Server) {
+		v1.RegisterValidationServiceServer(s, server.NewValidationServer())
+	})
+	defer cleanup()
+	client := v1.NewValidationServiceClient(conn)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	result, err := replay.ReplayFile(ctx, client, "testdata/stream_requests.ndjson")
+	if err != nil {
+		t.Fatalf("ReplayFile failed: %v", err)
+	}
+
+	if len(result.Responses) != 5 {
+		t.Fatalf("Expected 5 responses, got %d", len(result.Responses))
+	}
+
+	for i, resp := range result.Responses {
+		if resp.SequenceNumber != int32(i) {
+			t.Errorf("Expected response %d to have sequence %d, got %d", i, i, resp.SequenceNumber)
+		}
+		if i < 4 && resp.RequestId != fmt.Sprintf("replay_%d", i) {
+			t.Errorf("Expected response %d for replay_%d, got %q", i, i, resp.RequestId)
+		}
+	}
+
+	// The last recorded request has no request_id
+	if result.Failed != 1 || result.Responses[4].Success {
+		t.Errorf("Expected only the last response to fail, got %d failures", result.Failed)
+	}
+
+	if items := result.Responses[1].GetStats().GetItemsProcessed(); items != 2 {
+		t.Errorf("Expected 2 items processed for replay_1, got %d", items)
+	}
+
+	if result.Duration <= 0 || result.MaxLatency() <= 0 || result.MeanLatency() > result.MaxLatency() {
+		t.Errorf("Expected timing stats, got duration %v, mean %v, max %v",
+			result.Duration, result.MeanLatency(), result.MaxLatency())
+	}
+}
+
+func TestReplayReadRequestsErrors(t *testing.T) {
+	_, err := replay.ReadRequests(strings.NewReader("{\"requestId\": \"a\"}\n{not json}\n"))
+	if err == nil || !strings.Contains(err.Error(), "line 2") {
+		t.Errorf("Expected an error naming line 2, got %v", err)
+	}
+
+	requests, err := replay.ReadRequests(strings.NewReader(`{"requestId": "a", "sequenceNumber": 1}` + "\n" + `{"requestId": "b", "sequenceNumber": 1}`))
+	if err != nil {
+		t.Fatalf("ReadRequests failed: %v", err)
+	}
+	if _, err := replay.Replay(context.Background(), nil, requests); err == nil {
+		t.Error("Expected duplicate sequence numbers to be rejected")
+	}
+}
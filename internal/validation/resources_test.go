@@ -0,0 +1,169 @@
+package validation
+
+import (
+	"context"
+	"errors"
+	"runtime"
+	"testing"
+	"time"
+
+	v1 "github.com/benjamin-rood/protogo-values-validation-demo/gen/api/validation/v1"
+	"github.com/benjamin-rood/protogo-values-validation-demo/internal/server"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// snapshotStream is a server-side WatchResources stream that hands every
+// snapshot to the test
+type snapshotStream struct {
+	grpc.ServerStream
+	ctx       context.Context
+	snapshots chan *v1.ResourceSnapshot
+}
+
+func (s *snapshotStream) Context() context.Context { return s.ctx }
+
+func (s *snapshotStream) Send(snapshot *v1.ResourceSnapshot) error {
+	select {
+	case s.snapshots <- snapshot:
+		return nil
+	case <-s.ctx.Done():
+		return status.FromContextError(s.ctx.Err()).Err()
+	}
+}
+
+func TestWatchResourcesStream(t *testing.T) {
+	validationServer := server.NewValidationServer()
+
+	conn, cleanup := setupCustomTestServer(t, func(s *grpc.Server) {
+		v1.RegisterValidationServiceServer(s, validationServer)
+	})
+	defer cleanup()
+	client := v1.NewValidationServiceClient(conn)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	// An open StreamValidation stream shows up in the snapshots
+	open, err := openStream(t, ctx, client)
+	if err != nil {
+		t.Fatalf("Failed to open stream: %v", err)
+	}
+	defer closeStream(open)
+
+	watchCtx, stopWatching := context.WithCancel(ctx)
+	defer stopWatching()
+	watch, err := client.WatchResources(watchCtx, &v1.WatchResourcesRequest{IntervalMs: 10})
+	if err != nil {
+		t.Fatalf("WatchResources failed: %v", err)
+	}
+
+	var last int64
+	for i := 0; i < 3; i++ {
+		snapshot, err := watch.Recv()
+		if err != nil {
+			t.Fatalf("Failed to receive snapshot %d: %v", i, err)
+		}
+		if snapshot.TimestampUnixNano <= last {
+			t.Errorf("Expected snapshot %d to be later than %d, got %d", i, last, snapshot.TimestampUnixNano)
+		}
+		last = snapshot.TimestampUnixNano
+
+		if snapshot.Goroutines <= 0 || snapshot.HeapAllocBytes == 0 || snapshot.HeapInuseBytes == 0 {
+			t.Errorf("Expected non-zero goroutines and heap usage, got %v", snapshot)
+		}
+		if snapshot.ActiveStreams != 1 {
+			t.Errorf("Expected 1 active stream, got %d", snapshot.ActiveStreams)
+		}
+	}
+
+	stopWatching()
+
+	// Snapshots already in flight may still arrive before the cancellation
+	for {
+		if _, err = watch.Recv(); err != nil {
+			break
+		}
+	}
+	if status.Code(err) != codes.Canceled {
+		t.Errorf("Expected the stream to end with Canceled, got %v", err)
+	}
+}
+
+func TestWatchResourcesStopsOnCancel(t *testing.T) {
+	validationServer := server.NewValidationServer()
+	baseline := runtime.NumGoroutine()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	stream := &snapshotStream{ctx: ctx, snapshots: make(chan *v1.ResourceSnapshot)}
+	done := make(chan error, 1)
+	go func() { done <- validationServer.WatchResources(&v1.WatchResourcesRequest{IntervalMs: 10}, stream) }()
+
+	for i := 0; i < 3; i++ {
+		select {
+		case <-stream.snapshots:
+		case <-time.After(5 * time.Second):
+			t.Fatalf("Expected snapshot %d", i)
+		}
+	}
+
+	cancel()
+
+	select {
+	case err := <-done:
+		if status.Code(err) != codes.Canceled {
+			t.Errorf("Expected Canceled, got %v", err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("WatchResources did not return after the client cancelled")
+	}
+
+	if count := waitForGoroutines(baseline, time.Second); count > baseline {
+		t.Errorf("Expected no goroutine to outlive the stream, got %d running (baseline %d)", count, baseline)
+	}
+}
+
+func TestWatchResourcesInterval(t *testing.T) {
+	validationServer := server.NewValidationServer()
+
+	tests := []struct {
+		name     string
+		interval int32
+	}{
+		{"negative", -1},
+		{"below minimum", int32(server.MinWatchInterval.Milliseconds()) - 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			stream := &snapshotStream{ctx: context.Background(), snapshots: make(chan *v1.ResourceSnapshot, 1)}
+			err := validationServer.WatchResources(&v1.WatchResourcesRequest{IntervalMs: tt.interval}, stream)
+			if !errors.Is(err, server.ErrInvalidWatchInterval) {
+				t.Errorf("Expected ErrInvalidWatchInterval, got %v", err)
+			}
+			if status.Code(err) != codes.InvalidArgument {
+				t.Errorf("Expected InvalidArgument, got %v", status.Code(err))
+			}
+			if len(stream.snapshots) != 0 {
+				t.Error("Expected no snapshot for an invalid request")
+			}
+		})
+	}
+
+	// Unset uses the default, so the first snapshot is sent straight away
+	ctx, cancel := context.WithCancel(context.Background())
+	stream := &snapshotStream{ctx: ctx, snapshots: make(chan *v1.ResourceSnapshot, 1)}
+	done := make(chan error, 1)
+	go func() { done <- validationServer.WatchResources(&v1.WatchResourcesRequest{}, stream) }()
+
+	select {
+	case <-stream.snapshots:
+	case <-time.After(server.DefaultWatchInterval / 2):
+		t.Error("Expected the first snapshot before the first tick")
+	}
+	cancel()
+	<-done
+}
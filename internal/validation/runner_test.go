@@ -0,0 +1,110 @@
+package validation
+
+import (
+	"context"
+	"runtime"
+	"testing"
+	"time"
+
+	v1 "github.com/benjamin-rood/protogo-values-validation-demo/gen/api/validation/v1"
+	"github.com/benjamin-rood/protogo-values-validation-demo/internal/server"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// waitForGoroutines polls until at most n goroutines are running, returning
+// the last count sampled
+func waitForGoroutines(n int, timeout time.Duration) int {
+	deadline := time.Now().Add(timeout)
+	for {
+		count := runtime.NumGoroutine()
+		if count <= n || time.Now().After(deadline) {
+			return count
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+}
+
+func TestRunBenchmarksCancelMidRun(t *testing.T) {
+	for _, parallel := range []bool{true, false} {
+		name := "Sequential"
+		if parallel {
+			name = "Parallel"
+		}
+
+		t.Run(name, func(t *testing.T) {
+			validationServer := server.NewValidationServer()
+			baseline := runtime.NumGoroutine()
+
+			ctx, cancel := context.WithCancel(context.Background())
+			defer cancel()
+
+			// Far more iterations than could finish before the cancel
+			errc := make(chan error, 1)
+			go func() {
+				_, err := validationServer.RunBenchmarks(ctx, &v1.BenchmarkRequest{
+					Iterations: 100_000_000,
+					DataSize:   10_000,
+					Parallel:   parallel,
+				})
+				errc <- err
+			}()
+
+			// Sample until the stage workers are running: the caller plus at
+			// least one worker in either mode
+			deadline := time.Now().Add(5 * time.Second)
+			peak := runtime.NumGoroutine()
+			for peak < baseline+2 && time.Now().Before(deadline) {
+				time.Sleep(time.Millisecond)
+				peak = max(peak, runtime.NumGoroutine())
+			}
+			if peak < baseline+2 {
+				t.Fatalf("Expected stage workers to start, goroutines stayed at %d (baseline %d)", peak, baseline)
+			}
+
+			// Let the stages get underway before cancelling
+			time.Sleep(20 * time.Millisecond)
+			cancel()
+
+			select {
+			case err := <-errc:
+				if status.Code(err) != codes.Canceled {
+					t.Errorf("Expected Canceled, got %v", err)
+				}
+			case <-time.After(5 * time.Second):
+				t.Fatal("RunBenchmarks did not return after cancellation")
+			}
+
+			// Every worker has returned; only the caller's goroutine may still be exiting
+			if count := waitForGoroutines(baseline, time.Second); count > baseline {
+				t.Errorf("Expected goroutines to return to %d after the RPC, got %d", baseline, count)
+			}
+		})
+	}
+}
+
+func TestRunBenchmarksParallelResults(t *testing.T) {
+	validationServer := server.NewValidationServer()
+
+	sequential, err := validationServer.RunBenchmarks(context.Background(), &v1.BenchmarkRequest{Iterations: 2, DataSize: 100, Deterministic: true})
+	if err != nil {
+		t.Fatalf("Sequential RunBenchmarks failed: %v", err)
+	}
+	parallel, err := validationServer.RunBenchmarks(context.Background(), &v1.BenchmarkRequest{Iterations: 2, DataSize: 100, Deterministic: true, Parallel: true})
+	if err != nil {
+		t.Fatalf("Parallel RunBenchmarks failed: %v", err)
+	}
+
+	// Results keep stage order however the stages were scheduled
+	if len(parallel.Results) != len(sequential.Results) {
+		t.Fatalf("Expected %d results, got %d", len(sequential.Results), len(parallel.Results))
+	}
+	for i, result := range parallel.Results {
+		if result.Name != sequential.Results[i].Name {
+			t.Errorf("Expected result %d to be %s, got %s", i, sequential.Results[i].Name, result.Name)
+		}
+		if (result.Error == "") != (sequential.Results[i].Error == "") {
+			t.Errorf("Expected %s to fail the same way in both modes, got %q and %q", result.Name, sequential.Results[i].Error, result.Error)
+		}
+	}
+}
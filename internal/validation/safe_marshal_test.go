@@ -0,0 +1,94 @@
+package validation
+
+import (
+	"context"
+	"testing"
+
+	v1 "github.com/benjamin-rood/protogo-values-validation-demo/gen/api/validation/v1"
+	"github.com/benjamin-rood/protogo-values-validation-demo/internal/convert"
+	"github.com/benjamin-rood/protogo-values-validation-demo/internal/server"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/encoding/prototext"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/dynamicpb"
+)
+
+func TestSafeMarshalRoundTrip(t *testing.T) {
+	validationServer := server.NewValidationServer()
+
+	unmarshalers := map[v1.MarshalFormat]func([]byte, proto.Message) error{
+		v1.MarshalFormat_MARSHAL_FORMAT_BINARY: proto.Unmarshal,
+		v1.MarshalFormat_MARSHAL_FORMAT_JSON:   protojson.Unmarshal,
+		v1.MarshalFormat_MARSHAL_FORMAT_TEXT:   prototext.Unmarshal,
+	}
+
+	for _, messageType := range server.ExampleTypes() {
+		example, _ := server.ExampleMessage(messageType)
+		want, err := convert.MarshalSafeCopy(example)
+		if err != nil {
+			t.Fatalf("%s: MarshalSafeCopy failed: %v", messageType, err)
+		}
+
+		for format, unmarshal := range unmarshalers {
+			t.Run(messageType+"/"+format.String(), func(t *testing.T) {
+				resp, err := validationServer.SafeMarshal(context.Background(),
+					&v1.SafeMarshalRequest{MessageType: messageType, Format: format})
+				if err != nil {
+					t.Fatalf("SafeMarshal failed: %v", err)
+				}
+				if resp.Format != format || resp.MessageType != messageType {
+					t.Errorf("Expected %s as %v, got %s as %v", messageType, format, resp.MessageType, resp.Format)
+				}
+
+				// Decoded dynamically, since the generated type may have value-slice fields
+				got := dynamicpb.NewMessage(want.ProtoReflect().Descriptor())
+				if err := unmarshal(resp.Data, got); err != nil {
+					t.Fatalf("Unmarshal failed: %v", err)
+				}
+				if !proto.Equal(got, want) {
+					t.Errorf("Expected the round trip to equal the example, got %v", got)
+				}
+			})
+		}
+	}
+}
+
+func TestSafeMarshalDefaultsToBinary(t *testing.T) {
+	resp, err := server.NewValidationServer().SafeMarshal(context.Background(), &v1.SafeMarshalRequest{MessageType: "DataPoint"})
+	if err != nil {
+		t.Fatalf("SafeMarshal failed: %v", err)
+	}
+	if resp.Format != v1.MarshalFormat_MARSHAL_FORMAT_BINARY {
+		t.Errorf("Expected an unspecified format to resolve to binary, got %v", resp.Format)
+	}
+
+	var point v1.DataPoint
+	if err := proto.Unmarshal(resp.Data, &point); err != nil {
+		t.Fatalf("Unmarshal failed: %v", err)
+	}
+	if point.Id != "dp_0" {
+		t.Errorf("Expected the DataPoint example, got %v", &point)
+	}
+}
+
+func TestSafeMarshalErrors(t *testing.T) {
+	validationServer := server.NewValidationServer()
+
+	tests := []struct {
+		name string
+		req  *v1.SafeMarshalRequest
+		code codes.Code
+	}{
+		{"missing type", &v1.SafeMarshalRequest{}, codes.InvalidArgument},
+		{"unknown type", &v1.SafeMarshalRequest{MessageType: "NoSuchMessage"}, codes.NotFound},
+		{"unknown format", &v1.SafeMarshalRequest{MessageType: "DataPoint", Format: 99}, codes.InvalidArgument},
+	}
+	for _, tt := range tests {
+		_, err := validationServer.SafeMarshal(context.Background(), tt.req)
+		if status.Code(err) != tt.code {
+			t.Errorf("%s: expected %v, got %v", tt.name, tt.code, err)
+		}
+	}
+}
@@ -0,0 +1,147 @@
+package validation
+
+import (
+	"context"
+	"errors"
+	"math"
+	"sync"
+	"testing"
+	"time"
+
+	v1 "github.com/benjamin-rood/protogo-values-validation-demo/gen/api/validation/v1"
+	"github.com/benjamin-rood/protogo-values-validation-demo/internal/server"
+)
+
+// scriptedClock measures the scripted durations in order, then fallback
+type scriptedClock struct {
+	mu        sync.Mutex
+	durations []time.Duration
+	fallback  time.Duration
+}
+
+func (c *scriptedClock) Now() time.Time { return time.Unix(0, 0) }
+
+func (c *scriptedClock) Since(time.Time) time.Duration {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if len(c.durations) == 0 {
+		return c.fallback
+	}
+	d := c.durations[0]
+	c.durations = c.durations[1:]
+	return d
+}
+
+func TestRunBenchmarksSamples(t *testing.T) {
+	validationServer := server.NewValidationServer()
+
+	resp, err := validationServer.RunBenchmarks(context.Background(), &v1.BenchmarkRequest{Iterations: 10, DataSize: 10, Samples: 5})
+	if err != nil {
+		t.Fatalf("RunBenchmarks failed: %v", err)
+	}
+
+	for _, result := range resp.Results {
+		// Marshaling populated value slices panics on every call, so
+		// Serialization always fails, and a failed stage is not sampled
+		if result.Name == "Serialization" {
+			if result.Error == "" || result.Samples != 0 {
+				t.Errorf("Expected Serialization to fail unsampled, got error %q and %d samples", result.Error, result.Samples)
+			}
+			continue
+		}
+		if result.Error != "" {
+			t.Errorf("%s: expected no error, got %q", result.Name, result.Error)
+		}
+		if result.Samples != 5 {
+			t.Errorf("%s: expected 5 samples, got %d", result.Name, result.Samples)
+		}
+		if result.DurationNsStddev < 0 || math.IsNaN(result.DurationNsStddev) {
+			t.Errorf("%s: expected a non-negative stddev, got %v", result.Name, result.DurationNsStddev)
+		}
+		if result.DurationNsMin > result.DurationNs {
+			t.Errorf("%s: expected min %v to be at most the mean %v", result.Name, result.DurationNsMin, result.DurationNs)
+		}
+	}
+
+	if stddev := resp.Summary.PerformanceImprovementRatioStddev; stddev < 0 || math.IsNaN(stddev) {
+		t.Errorf("Expected a non-negative ratio stddev, got %v", stddev)
+	}
+}
+
+func TestRunBenchmarksSampleStatistics(t *testing.T) {
+	// Stages run in order and each sample measures one duration, after the setup
+	clock := &scriptedClock{
+		durations: []time.Duration{
+			time.Millisecond, // setup
+			100, 200, 300,    // ValueSlice_Iteration
+			300, 400, 500, // PointerSlice_Iteration
+		},
+		fallback: 100,
+	}
+	validationServer := server.NewValidationServer(server.WithClock(clock))
+
+	resp, err := validationServer.RunBenchmarks(context.Background(), &v1.BenchmarkRequest{Iterations: 10, DataSize: 10, Samples: 3})
+	if err != nil {
+		t.Fatalf("RunBenchmarks failed: %v", err)
+	}
+
+	expected := map[string]struct{ mean, stddev, min float64 }{
+		"ValueSlice_Iteration":   {200, 100, 100},
+		"PointerSlice_Iteration": {400, 100, 300},
+	}
+	for _, result := range resp.Results {
+		want, ok := expected[result.Name]
+		if !ok {
+			continue
+		}
+		if result.DurationNs != want.mean || result.DurationNsStddev != want.stddev || result.DurationNsMin != want.min {
+			t.Errorf("%s: expected mean %v, stddev %v, min %v, got %v, %v, %v", result.Name,
+				want.mean, want.stddev, want.min, result.DurationNs, result.DurationNsStddev, result.DurationNsMin)
+		}
+		// 10 iterations at the mean duration
+		if wantRate := 10 / (want.mean / 1e9); math.Abs(result.OperationsPerSecond-wantRate) > 1e-6*wantRate {
+			t.Errorf("%s: expected %v ops/s from the mean duration, got %v", result.Name, wantRate, result.OperationsPerSecond)
+		}
+	}
+
+	if resp.Summary.PerformanceImprovementRatio != 2 {
+		t.Errorf("Expected ratio 2, got %v", resp.Summary.PerformanceImprovementRatio)
+	}
+	// 2 * sqrt((100/400)^2 + (100/200)^2)
+	if got, want := resp.Summary.PerformanceImprovementRatioStddev, 2*math.Sqrt(0.0625+0.25); math.Abs(got-want) > 1e-9 {
+		t.Errorf("Expected ratio stddev %v, got %v", want, got)
+	}
+}
+
+func TestRunBenchmarksSingleSample(t *testing.T) {
+	validationServer := server.NewValidationServer()
+
+	for _, samples := range []int32{0, 1} {
+		resp, err := validationServer.RunBenchmarks(context.Background(), &v1.BenchmarkRequest{Iterations: 10, DataSize: 10, Samples: samples})
+		if err != nil {
+			t.Fatalf("RunBenchmarks failed: %v", err)
+		}
+
+		for _, result := range resp.Results {
+			if result.Samples != 0 || result.DurationNsStddev != 0 || result.DurationNsMin != 0 {
+				t.Errorf("samples=%d: expected %s to be a single unaggregated run, got %v", samples, result.Name, result)
+			}
+		}
+		if resp.Summary.PerformanceImprovementRatioStddev != 0 {
+			t.Errorf("samples=%d: expected no ratio stddev, got %v", samples, resp.Summary.PerformanceImprovementRatioStddev)
+		}
+	}
+}
+
+func TestValidateRequestSamples(t *testing.T) {
+	for _, samples := range []int32{-1, server.MaxSamples + 1} {
+		err := server.ValidateRequest(&v1.BenchmarkRequest{Iterations: 1, DataSize: 1, Samples: samples})
+		if !errors.Is(err, server.ErrInvalidSamples) {
+			t.Errorf("samples=%d: expected ErrInvalidSamples, got %v", samples, err)
+		}
+	}
+
+	if err := server.ValidateRequest(&v1.BenchmarkRequest{Iterations: 1, DataSize: 1, Samples: server.MaxSamples}); err != nil {
+		t.Errorf("Expected %d samples to be accepted, got %v", server.MaxSamples, err)
+	}
+}
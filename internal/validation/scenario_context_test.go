@@ -0,0 +1,45 @@
+package validation
+
+import (
+	"context"
+	"testing"
+
+	v1 "github.com/benjamin-rood/protogo-values-validation-demo/gen/api/validation/v1"
+	"github.com/benjamin-rood/protogo-values-validation-demo/internal/server"
+)
+
+func TestScenarioFuncReadsNameFromContext(t *testing.T) {
+	var seen string
+	validationServer := server.NewValidationServer(server.WithScenarioFunc("custom", func(ctx context.Context) []*v1.ValidationResult {
+		name, ok := server.ScenarioFromContext(ctx)
+		if !ok {
+			t.Error("Expected the scenario name in the context")
+		}
+		seen = name
+		return []*v1.ValidationResult{server.NewValidationResult(name, "[]string", "[]string")}
+	}))
+
+	resp, err := validationServer.ValidateTypes(context.Background(), &v1.ValidateTypesRequest{})
+	if err != nil {
+		t.Fatalf("ValidateTypes failed: %v", err)
+	}
+
+	if seen != "custom" {
+		t.Errorf("Expected scenario %q in the context, got %q", "custom", seen)
+	}
+	last := resp.Results[len(resp.Results)-1]
+	if last.Scenario != "custom" || !last.Passed {
+		t.Errorf("Expected the custom scenario's result last, got %v", last)
+	}
+}
+
+func TestScenarioFromContextUnset(t *testing.T) {
+	if name, ok := server.ScenarioFromContext(context.Background()); ok {
+		t.Errorf("Expected no scenario outside ValidateTypes, got %q", name)
+	}
+
+	ctx := server.ContextWithScenario(context.Background(), server.ScenarioScalarSlices)
+	if name, _ := server.ScenarioFromContext(ctx); name != server.ScenarioScalarSlices {
+		t.Errorf("Expected %q, got %q", server.ScenarioScalarSlices, name)
+	}
+}
@@ -0,0 +1,133 @@
+package validation
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"testing"
+	"time"
+
+	v1 "github.com/benjamin-rood/protogo-values-validation-demo/gen/api/validation/v1"
+	"github.com/benjamin-rood/protogo-values-validation-demo/internal/server"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// resultScenarios returns the scenario of each result, in order
+func resultScenarios(results []*v1.ValidationResult) []string {
+	scenarios := make([]string, len(results))
+	for i, result := range results {
+		scenarios[i] = result.Scenario
+	}
+	return scenarios
+}
+
+func TestValidateTypesDeduplicatesScenarios(t *testing.T) {
+	validationServer := server.NewValidationServer()
+
+	conn, cleanup := setupCustomTestServer(t, func(s *grpc.Server) {
+		v1.RegisterValidationServiceServer(s, validationServer)
+	})
+	defer cleanup()
+	client := v1.NewValidationServiceClient(conn)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	resp, err := client.ValidateTypes(ctx, &v1.ValidateTypesRequest{
+		TestScenarios: []string{"performance_test_message", "Performance_Test_Message", "validation_test_message", " PERFORMANCE_TEST_MESSAGE ", "", "performance_test_message"},
+	})
+	if err != nil {
+		t.Fatalf("ValidateTypes failed: %v", err)
+	}
+
+	// Each scenario runs once, in result order, and no other scenario runs
+	expected := []string{server.ScenarioValidationTestMessage, server.ScenarioPerformanceTestMessage}
+	if !slices.Equal(resp.ScenariosRun, expected) {
+		t.Errorf("Expected scenarios_run %v, got %v", expected, resp.ScenariosRun)
+	}
+	expectedResults := []string{
+		"ValidationTestMessage.ValueSliceData",
+		"ValidationTestMessage.PointerSliceData",
+		"ValidationTestMessage.Metrics",
+		"PerformanceTestMessage.ValueSliceData",
+		"PerformanceTestMessage.PointerSliceData",
+		"PerformanceTestMessage.Results",
+	}
+	if got := resultScenarios(resp.Results); !slices.Equal(got, expectedResults) {
+		t.Errorf("Expected results %v, got %v", expectedResults, got)
+	}
+
+	single, err := client.ValidateTypes(ctx, &v1.ValidateTypesRequest{TestScenarios: []string{"validation_test_message", "performance_test_message"}})
+	if err != nil {
+		t.Fatalf("ValidateTypes failed: %v", err)
+	}
+
+	// The same set is served from the cache rather than run again
+	if got := resultScenarios(single.Results); !slices.Equal(got, expectedResults) {
+		t.Errorf("Expected results %v, got %v", expectedResults, got)
+	}
+	if hits := validationServer.CacheHits(); hits != 1 {
+		t.Errorf("Expected the de-duplicated set to hit the cache once, got %d hits", hits)
+	}
+}
+
+func TestValidateTypesFiltersScenarios(t *testing.T) {
+	validationServer := server.NewValidationServer()
+	ctx := context.Background()
+
+	resp, err := validationServer.ValidateTypes(ctx, &v1.ValidateTypesRequest{TestScenarios: []string{server.ScenarioScalarSlices}})
+	if err != nil {
+		t.Fatalf("ValidateTypes failed: %v", err)
+	}
+	expectedResults := []string{"DataPoint.Tags", "ProcessingResult.ErrorMessages"}
+	if got := resultScenarios(resp.Results); !slices.Equal(got, expectedResults) {
+		t.Errorf("Expected results %v, got %v", expectedResults, got)
+	}
+
+	// No scenarios runs every scenario
+	all, err := validationServer.ValidateTypes(ctx, &v1.ValidateTypesRequest{})
+	if err != nil {
+		t.Fatalf("ValidateTypes failed: %v", err)
+	}
+	expected := []string{
+		server.ScenarioValidationTestMessage,
+		server.ScenarioPerformanceTestMessage,
+		server.ScenarioScalarSlices,
+		server.ScenarioServiceFields,
+		server.ScenarioTypeExpectations,
+	}
+	if !slices.Equal(all.ScenariosRun, expected) {
+		t.Errorf("Expected scenarios_run %v, got %v", expected, all.ScenariosRun)
+	}
+	if got := resultScenarios(all.Results); !slices.Contains(got, "DataPoint.Tags") || !slices.Contains(got, "ValidationTestMessage.ValueSliceData") {
+		t.Errorf("Expected results from every scenario, got %v", got)
+	}
+
+	// An unknown name is rejected rather than silently running nothing
+	_, err = validationServer.ValidateTypes(ctx, &v1.ValidateTypesRequest{TestScenarios: []string{server.ScenarioScalarSlices, "basic"}})
+	if !errors.Is(err, server.ErrUnknownScenario) || status.Code(err) != codes.InvalidArgument {
+		t.Errorf("Expected InvalidArgument wrapping ErrUnknownScenario, got %v", err)
+	}
+}
+
+func TestValidateTypesCountsOnlyMessageValueSlices(t *testing.T) {
+	validationServer := server.NewValidationServer(server.WithCache(false))
+
+	// ValidationTestMessage has two value slices and one pointer slice;
+	// DataPoint.Tags and ProcessingResult.ErrorMessages are repeated strings
+	resp, err := validationServer.ValidateTypes(context.Background(), &v1.ValidateTypesRequest{
+		TestScenarios: []string{server.ScenarioValidationTestMessage, server.ScenarioScalarSlices},
+	})
+	if err != nil {
+		t.Fatalf("ValidateTypes failed: %v", err)
+	}
+
+	if resp.ValueSliceCount != 2 {
+		t.Errorf("Expected value_slice_count 2, got %d", resp.ValueSliceCount)
+	}
+	if resp.PointerSliceCount != 1 {
+		t.Errorf("Expected pointer_slice_count 1, got %d", resp.PointerSliceCount)
+	}
+}
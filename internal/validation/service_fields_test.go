@@ -0,0 +1,116 @@
+package validation
+
+import (
+	"context"
+	"reflect"
+	"strings"
+	"testing"
+
+	v1 "github.com/benjamin-rood/protogo-values-validation-demo/gen/api/validation/v1"
+	"github.com/benjamin-rood/protogo-values-validation-demo/internal/server"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/reflect/protoregistry"
+)
+
+func TestValidateTypesServiceFieldScenarios(t *testing.T) {
+	validationServer := server.NewValidationServer()
+
+	resp, err := validationServer.ValidateTypes(context.Background(), &v1.ValidateTypesRequest{TestScenarios: []string{server.ScenarioServiceFields}})
+	if err != nil {
+		t.Fatalf("ValidateTypes failed: %v", err)
+	}
+
+	expected := map[string]string{
+		server.UntransformedScenarioPrefix + "BenchmarkResponse.Results":     "[]*v1.BenchmarkResult",
+		server.UntransformedScenarioPrefix + "StreamResponse.FieldErrors":    "[]*v1.FieldError",
+		server.UntransformedScenarioPrefix + "ValidateTypesResponse.Results": "[]*v1.ValidationResult",
+	}
+
+	for _, result := range resp.Results {
+		expectedType, ok := expected[result.Scenario]
+		if !ok {
+			continue
+		}
+		delete(expected, result.Scenario)
+
+		if result.ExpectedType != expectedType || result.ActualType != expectedType {
+			t.Errorf("%s: expected %s, got %s", result.Scenario, expectedType, result.ActualType)
+		}
+		if !result.Passed || result.Severity != v1.Severity_SEVERITY_INFO {
+			t.Errorf("%s: expected to pass with INFO severity, got passed=%v severity=%v",
+				result.Scenario, result.Passed, result.Severity)
+		}
+	}
+
+	for scenario := range expected {
+		t.Errorf("Expected a %s result", scenario)
+	}
+}
+
+func TestValidateTypesServiceFieldsIgnoreExpectations(t *testing.T) {
+	// An expectations file cannot relax the service field checks
+	expectations := server.DefaultTypeExpectations()
+	expectations["BenchmarkResponse.Results"] = "[]v1.BenchmarkResult"
+	validationServer := server.NewValidationServer(server.WithTypeExpectations(expectations))
+
+	resp, err := validationServer.ValidateTypes(context.Background(), &v1.ValidateTypesRequest{TestScenarios: []string{server.ScenarioServiceFields}})
+	if err != nil {
+		t.Fatalf("ValidateTypes failed: %v", err)
+	}
+
+	for _, result := range resp.Results {
+		if result.Scenario == server.UntransformedScenarioPrefix+"BenchmarkResponse.Results" && !result.Passed {
+			t.Errorf("Expected the service field check to keep expecting a pointer slice, got %v", result)
+		}
+	}
+}
+
+func TestServiceMessagesKeepPointerSlices(t *testing.T) {
+	// Every repeated message field of the service's messages is marshaled by
+	// gRPC, which panics on value slices; a transformed one breaks the service
+	messages := v1.File_api_validation_v1_validation_proto.Messages()
+	checked := 0
+
+	for i := 0; i < messages.Len(); i++ {
+		md := messages.Get(i)
+		mt, err := protoregistry.GlobalTypes.FindMessageByName(md.FullName())
+		if err != nil {
+			t.Fatalf("%s: %v", md.FullName(), err)
+		}
+		goType := reflect.TypeOf(mt.Zero().Interface()).Elem()
+
+		fields := md.Fields()
+		for j := 0; j < fields.Len(); j++ {
+			fd := fields.Get(j)
+			if !fd.IsList() || fd.Kind() != protoreflect.MessageKind {
+				continue
+			}
+
+			sf, ok := goFieldByProtoName(goType, fd.Name())
+			if !ok {
+				t.Errorf("%s: no Go field found", fd.FullName())
+				continue
+			}
+			if sf.Type.Elem().Kind() != reflect.Pointer {
+				t.Errorf("%s.%s was transformed to %s; service messages must keep pointer slices",
+					md.Name(), sf.Name, sf.Type)
+			}
+			checked++
+		}
+	}
+
+	if checked == 0 {
+		t.Error("Expected the service messages to have repeated message fields to check")
+	}
+}
+
+// goFieldByProtoName finds the Go struct field generated for a protobuf field
+func goFieldByProtoName(goType reflect.Type, name protoreflect.Name) (reflect.StructField, bool) {
+	tag := "name=" + string(name) + ","
+	for i := 0; i < goType.NumField(); i++ {
+		if strings.Contains(goType.Field(i).Tag.Get("protobuf"), tag) {
+			return goType.Field(i), true
+		}
+	}
+	return reflect.StructField{}, false
+}
@@ -0,0 +1,123 @@
+package validation
+
+import (
+	"testing"
+
+	v1 "github.com/benjamin-rood/protogo-values-validation-demo/gen/api/validation/v1"
+	"github.com/benjamin-rood/protogo-values-validation-demo/internal/server"
+)
+
+func TestValidationResultSeverity(t *testing.T) {
+	tests := []struct {
+		name             string
+		actualType       string
+		expectedType     string
+		expectedSeverity v1.Severity
+		expectedPassed   bool
+	}{
+		{
+			name:             "matching types are INFO",
+			actualType:       "[]v1.DataPoint",
+			expectedType:     "[]v1.DataPoint",
+			expectedSeverity: v1.Severity_SEVERITY_INFO,
+			expectedPassed:   true,
+		},
+		{
+			name:             "same type under another package qualifier is WARNING",
+			actualType:       "[]v1.DataPoint",
+			expectedType:     "[]validationv1.DataPoint",
+			expectedSeverity: v1.Severity_SEVERITY_WARNING,
+			expectedPassed:   true,
+		},
+		{
+			name:             "value slice of another type is ERROR",
+			actualType:       "[]v1.MetricPoint",
+			expectedType:     "[]v1.DataPoint",
+			expectedSeverity: v1.Severity_SEVERITY_ERROR,
+			expectedPassed:   false,
+		},
+		{
+			name:             "pointer slice of another type is ERROR",
+			actualType:       "[]*v1.Metadata",
+			expectedType:     "[]*v1.DataPoint",
+			expectedSeverity: v1.Severity_SEVERITY_ERROR,
+			expectedPassed:   false,
+		},
+		{
+			name:             "pointer slice where value slice expected is ERROR",
+			actualType:       "[]*v1.DataPoint",
+			expectedType:     "[]v1.DataPoint",
+			expectedSeverity: v1.Severity_SEVERITY_ERROR,
+			expectedPassed:   false,
+		},
+		{
+			name:             "non-slice type is ERROR",
+			actualType:       "map[string]string",
+			expectedType:     "[]*v1.Metadata",
+			expectedSeverity: v1.Severity_SEVERITY_ERROR,
+			expectedPassed:   false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result := server.NewValidationResult("Test.Field", tt.actualType, tt.expectedType)
+
+			if result.Severity != tt.expectedSeverity {
+				t.Errorf("Expected severity %v, got %v", tt.expectedSeverity, result.Severity)
+			}
+
+			if result.Passed != tt.expectedPassed {
+				t.Errorf("Expected passed=%v, got %v", tt.expectedPassed, result.Passed)
+			}
+		})
+	}
+}
+
+func TestSafeTypeString(t *testing.T) {
+	var nilDataPoints []*v1.DataPoint
+
+	tests := []struct {
+		name     string
+		value    any
+		expected string
+	}{
+		{"untyped nil", nil, server.InvalidTypeString},
+		{"typed nil slice", nilDataPoints, "[]*v1.DataPoint"},
+		{"value slice", []v1.DataPoint{}, "[]v1.DataPoint"},
+		{"map", map[string]string{}, "map[string]string"},
+		{"scalar", int32(1), "int32"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := server.SafeTypeString(tt.value); got != tt.expected {
+				t.Errorf("Expected %q, got %q", tt.expected, got)
+			}
+		})
+	}
+}
+
+func TestUnexpectedKindsDegradeToFailedResults(t *testing.T) {
+	tests := []struct {
+		name  string
+		value any
+	}{
+		{"field became a map", map[string]v1.DataPoint{}},
+		{"field became a oneof wrapper", struct{ Value *v1.DataPoint }{}},
+		{"field missing", nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result := server.NewValidationResult("Changed.Field", server.SafeTypeString(tt.value), "[]v1.DataPoint")
+
+			if result.Passed {
+				t.Error("Expected result to fail")
+			}
+			if result.Severity != v1.Severity_SEVERITY_ERROR {
+				t.Errorf("Expected SEVERITY_ERROR, got %v", result.Severity)
+			}
+		})
+	}
+}
@@ -0,0 +1,411 @@
+package validation
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	v1 "github.com/benjamin-rood/protogo-values-validation-demo/gen/api/validation/v1"
+	"github.com/benjamin-rood/protogo-values-validation-demo/internal/convert"
+	"github.com/benjamin-rood/protogo-values-validation-demo/internal/server"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/encoding/protowire"
+	"google.golang.org/protobuf/proto"
+)
+
+func newSingleMessageClient(t *testing.T, opts ...server.Option) v1.ValidationServiceClient {
+	t.Helper()
+	conn, cleanup := setupCustomTestServer(t, func(s *grpc.Server) {
+		v1.RegisterValidationServiceServer(s, server.NewValidationServer(opts...))
+	})
+	t.Cleanup(cleanup)
+	return v1.NewValidationServiceClient(conn)
+}
+
+func TestValidateSingleMessage(t *testing.T) {
+	client := newSingleMessageClient(t)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	// Clients marshal the pointer-slice form, which protobuf supports
+	encoded, err := proto.Marshal(&v1.ValidationTestMessage{
+		PointerSliceData: []*v1.DataPoint{
+			{Id: "dp_1", Value: 1.5, Timestamp: 1000, Tags: []string{"client"}},
+			{Id: "dp_2", Value: 3, Timestamp: 1001},
+		},
+	})
+	if err != nil {
+		t.Fatalf("Failed to marshal message: %v", err)
+	}
+
+	resp, err := client.ValidateSingleMessage(ctx, &v1.ValidateSingleMessageRequest{Message: encoded})
+	if err != nil {
+		t.Fatalf("ValidateSingleMessage failed: %v", err)
+	}
+
+	if !resp.Success {
+		t.Errorf("Expected success, got results %v and field errors %v", resp.Results, resp.FieldErrors)
+	}
+	if len(resp.FieldErrors) != 0 {
+		t.Errorf("Expected no field errors, got %v", resp.FieldErrors)
+	}
+
+	expected := map[string]string{
+		"ValidationTestMessage.ValueSliceData":   "[]v1.DataPoint",
+		"ValidationTestMessage.PointerSliceData": "[]*v1.DataPoint",
+		"ValidationTestMessage.Metrics":          "[]v1.MetricPoint",
+		"DataPoint.Tags":                         "[]string",
+	}
+	if len(resp.Results) != len(expected) {
+		t.Errorf("Expected %d results, got %d", len(expected), len(resp.Results))
+	}
+	for _, result := range resp.Results {
+		want, ok := expected[result.Scenario]
+		if !ok {
+			t.Errorf("Unexpected result for %s", result.Scenario)
+			continue
+		}
+		if result.ActualType != want || result.ExpectedType != want {
+			t.Errorf("Expected %s to be %s, got actual %s, expected %s", result.Scenario, want, result.ActualType, result.ExpectedType)
+		}
+		if !result.Passed {
+			t.Errorf("Expected %s to pass: %s", result.Scenario, result.ErrorMessage)
+		}
+	}
+}
+
+func TestValidateSingleMessageValueSliceField(t *testing.T) {
+	client := newSingleMessageClient(t)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	// value_slice_data shares the wire format of a repeated message field,
+	// followed by an undeclared varint field 99 that validation ignores
+	dataPoint, err := proto.Marshal(&v1.DataPoint{Id: "dp_1", Value: 2})
+	if err != nil {
+		t.Fatalf("Failed to marshal data point: %v", err)
+	}
+	raw := protowire.AppendTag(nil, 1, protowire.BytesType)
+	raw = protowire.AppendBytes(raw, dataPoint)
+	raw = protowire.AppendTag(raw, 99, protowire.VarintType)
+	raw = protowire.AppendVarint(raw, 7)
+
+	resp, err := client.ValidateSingleMessage(ctx, &v1.ValidateSingleMessageRequest{Message: raw})
+	if err != nil {
+		t.Fatalf("ValidateSingleMessage failed: %v", err)
+	}
+
+	for _, result := range resp.Results {
+		if !result.Passed {
+			t.Errorf("Expected %s to pass: %s", result.Scenario, result.ErrorMessage)
+		}
+	}
+	if len(resp.Results) != 4 {
+		t.Errorf("Expected DataPoint.Tags to be checked on the decoded value, got %d results", len(resp.Results))
+	}
+}
+
+func TestValidateSingleMessagePopulatedValueSlice(t *testing.T) {
+	two := 2
+	client := newSingleMessageClient(t, server.WithCountConstraints(server.CountConstraints{
+		"ValidationTestMessage.ValueSliceData": {Min: &two, Max: &two},
+	}))
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	// A client holding value slices marshals through MarshalSafeCopy
+	safe, err := convert.MarshalSafeCopy(&v1.ValidationTestMessage{
+		ValueSliceData: []v1.DataPoint{
+			{Id: "dp_1", Value: 1.5, Timestamp: 1000, Tags: []string{"a", "b"}},
+			{Id: "dp_2", Value: 3, Timestamp: 1001},
+		},
+		Metrics: []v1.MetricPoint{{Name: "cpu", Measurement: 0.5}},
+	})
+	if err != nil {
+		t.Fatalf("MarshalSafeCopy failed: %v", err)
+	}
+	encoded, err := proto.Marshal(safe)
+	if err != nil {
+		t.Fatalf("Failed to marshal message: %v", err)
+	}
+
+	resp, err := client.ValidateSingleMessage(ctx, &v1.ValidateSingleMessageRequest{Message: encoded})
+	if err != nil {
+		t.Fatalf("ValidateSingleMessage failed: %v", err)
+	}
+	if !resp.Success {
+		t.Errorf("Expected success, got results %v and field errors %v", resp.Results, resp.FieldErrors)
+	}
+
+	results := make(map[string]*v1.ValidationResult)
+	for _, result := range resp.Results {
+		results[result.Scenario] = result
+	}
+	if tags, ok := results["DataPoint.Tags"]; !ok || !tags.Passed || tags.ActualType != "[]string" {
+		t.Errorf("Expected DataPoint.Tags to be checked on the first value-slice entry, got %v", tags)
+	}
+	if count := results["ValidationTestMessage.ValueSliceData"]; count == nil || count.ActualCount != 2 {
+		t.Errorf("Expected 2 decoded value-slice entries, got %v", count)
+	}
+
+	// Presence is reported for the first value-slice entry
+	var tagsPresent bool
+	for _, presence := range resp.Presence {
+		if presence.Field == "validation.v1.DataPoint.tags" {
+			tagsPresent = presence.Present
+		}
+	}
+	if !tagsPresent {
+		t.Errorf("Expected DataPoint.tags to be present, got %v", resp.Presence)
+	}
+}
+
+func TestValidateSingleMessageInvalid(t *testing.T) {
+	client := newSingleMessageClient(t)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	// A length-delimited field whose length runs past the end of the input
+	truncated := protowire.AppendTag(nil, 2, protowire.BytesType)
+	truncated = protowire.AppendVarint(truncated, 100)
+
+	for name, message := range map[string][]byte{
+		"empty":     nil,
+		"truncated": truncated,
+		"garbage":   {0xff, 0xff, 0xff},
+	} {
+		_, err := client.ValidateSingleMessage(ctx, &v1.ValidateSingleMessageRequest{Message: message})
+		if status.Code(err) != codes.InvalidArgument {
+			t.Errorf("Expected InvalidArgument for %s input, got %v", name, err)
+		}
+	}
+}
+
+func TestValidateSingleMessageCountConstraints(t *testing.T) {
+	path := writeExpectations(t, "expected-types.yaml", `
+ValidationTestMessage.PointerSliceData:
+  type: "[]*v1.DataPoint"
+  min_count: 1
+  max_count: 3
+ValidationTestMessage.Metrics: {min_count: 1}
+DataPoint.Tags: {max_count: 0}
+`)
+	expectations, constraints, err := server.LoadExpectations(path)
+	if err != nil {
+		t.Fatalf("LoadExpectations failed: %v", err)
+	}
+	if _, ok := expectations["ValidationTestMessage.Metrics"]; ok {
+		t.Error("Expected a count-only entry to leave the type expectation unset")
+	}
+
+	client := newSingleMessageClient(t, server.WithTypeExpectations(expectations), server.WithCountConstraints(constraints))
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	metric := &v1.MetricPoint{Name: "cpu", Measurement: 1}
+
+	tests := []struct {
+		name     string
+		points   int
+		metrics  []*v1.MetricPoint
+		passed   bool
+		actual   int64
+		violated string
+	}{
+		{"under count", 0, []*v1.MetricPoint{metric}, false, 0, "has 0 entries, expected at least 1"},
+		{"in range", 2, []*v1.MetricPoint{metric}, true, 2, ""},
+		{"at max", 3, []*v1.MetricPoint{metric}, true, 3, ""},
+		{"over count", 4, []*v1.MetricPoint{metric}, false, 4, "has 4 entries, expected at most 3"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			raw := marshalMetrics(t, tt.metrics)
+			for i := 0; i < tt.points; i++ {
+				raw = appendPointerDataPoint(t, raw, &v1.DataPoint{Id: "dp", Value: float64(i)})
+			}
+
+			resp, err := client.ValidateSingleMessage(ctx, &v1.ValidateSingleMessageRequest{Message: raw})
+			if err != nil {
+				t.Fatalf("ValidateSingleMessage failed: %v", err)
+			}
+
+			results := make(map[string]*v1.ValidationResult)
+			for _, result := range resp.Results {
+				if result.ExpectedCount != "" {
+					results[result.Scenario] = result
+				}
+			}
+			// DataPoint.Tags constrains a nested message, so it is not checked here
+			if len(results) != 2 {
+				t.Fatalf("Expected 2 count results, got %v", results)
+			}
+
+			points := results["ValidationTestMessage.PointerSliceData"]
+			if points.Passed != tt.passed {
+				t.Errorf("Expected passed=%v, got %v: %s", tt.passed, points.Passed, points.ErrorMessage)
+			}
+			if points.ActualCount != tt.actual || points.ExpectedCount != "1..3" {
+				t.Errorf("Expected %d entries against 1..3, got %d against %s", tt.actual, points.ActualCount, points.ExpectedCount)
+			}
+			if !strings.HasSuffix(points.ErrorMessage, tt.violated) {
+				t.Errorf("Expected error message ending %q, got %q", tt.violated, points.ErrorMessage)
+			}
+			if resp.Success != tt.passed {
+				t.Errorf("Expected success=%v, got %v", tt.passed, resp.Success)
+			}
+
+			if metrics := results["ValidationTestMessage.Metrics"]; !metrics.Passed || metrics.ExpectedCount != ">= 1" {
+				t.Errorf("Expected the metrics constraint >= 1 to hold, got %+v", metrics)
+			}
+		})
+	}
+}
+
+func TestLoadExpectationsInvalidCounts(t *testing.T) {
+	cases := map[string]string{
+		"negative min":     `{"ValidationTestMessage.Metrics": {"min_count": -1}}`,
+		"min above max":    `{"ValidationTestMessage.Metrics": {"min_count": 3, "max_count": 2}}`,
+		"not a number":     `{"ValidationTestMessage.Metrics": {"max_count": "many"}}`,
+		"nothing to check": `{"ValidationTestMessage.Metrics": {}}`,
+	}
+	for name, content := range cases {
+		t.Run(name, func(t *testing.T) {
+			path := writeExpectations(t, "expected-types.json", content)
+			if _, _, err := server.LoadExpectations(path); err == nil {
+				t.Error("Expected an error")
+			}
+		})
+	}
+}
+
+// marshalMetrics encodes a ValidationTestMessage holding only metrics.
+// Metrics is a value slice, so each entry is appended in wire format.
+func marshalMetrics(t *testing.T, metrics []*v1.MetricPoint) []byte {
+	t.Helper()
+	var raw []byte
+	for _, metric := range metrics {
+		encoded, err := proto.Marshal(metric)
+		if err != nil {
+			t.Fatalf("Failed to marshal metric: %v", err)
+		}
+		raw = protowire.AppendTag(raw, 3, protowire.BytesType)
+		raw = protowire.AppendBytes(raw, encoded)
+	}
+	return raw
+}
+
+// appendPointerDataPoint appends one pointer_slice_data entry in wire format
+func appendPointerDataPoint(t *testing.T, raw []byte, dataPoint *v1.DataPoint) []byte {
+	t.Helper()
+	encoded, err := proto.Marshal(dataPoint)
+	if err != nil {
+		t.Fatalf("Failed to marshal data point: %v", err)
+	}
+	raw = protowire.AppendTag(raw, 2, protowire.BytesType)
+	return protowire.AppendBytes(raw, encoded)
+}
+
+func TestValidateSingleMessageInvalidUTF8(t *testing.T) {
+	client := newSingleMessageClient(t)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	// protobuf refuses to marshal invalid UTF-8, so the strings are encoded by hand
+	appendString := func(b []byte, num protowire.Number, s string) []byte {
+		b = protowire.AppendTag(b, num, protowire.BytesType)
+		return protowire.AppendString(b, s)
+	}
+	var dataPoint, metric, raw []byte
+	dataPoint = appendString(dataPoint, 1, "dp\xff")
+	dataPoint = appendString(dataPoint, 4, "valid")
+	dataPoint = appendString(dataPoint, 4, "bad\xfe")
+	metric = appendString(metric, 1, "\xc3\x28")
+
+	raw = appendPointerDataPoint(t, raw, &v1.DataPoint{Id: "clean", Tags: []string{"valid"}})
+	raw = protowire.AppendTag(raw, 2, protowire.BytesType)
+	raw = protowire.AppendBytes(raw, dataPoint)
+	raw = protowire.AppendTag(raw, 3, protowire.BytesType)
+	raw = protowire.AppendBytes(raw, metric)
+
+	resp, err := client.ValidateSingleMessage(ctx, &v1.ValidateSingleMessageRequest{Message: raw})
+	if err != nil {
+		t.Fatalf("ValidateSingleMessage failed: %v", err)
+	}
+
+	if resp.Success {
+		t.Error("Expected invalid UTF-8 to fail validation")
+	}
+
+	failed := make(map[string]*v1.ValidationResult)
+	for _, result := range resp.Results {
+		if !result.Passed {
+			failed[result.Scenario] = result
+		}
+	}
+	for _, path := range []string{
+		"message.pointer_slice_data[1].id",
+		"message.pointer_slice_data[1].tags[1]",
+		"message.metrics[0].name",
+	} {
+		result, ok := failed[path]
+		if !ok {
+			t.Errorf("Expected a failure for %s, got failures %v", path, failed)
+			continue
+		}
+		if result.Severity != v1.Severity_SEVERITY_ERROR || !strings.Contains(result.ErrorMessage, "UTF-8") {
+			t.Errorf("Expected an error-severity UTF-8 failure for %s, got %v", path, result)
+		}
+	}
+	if len(failed) != 3 {
+		t.Errorf("Expected only the 3 invalid strings to fail, got %v", failed)
+	}
+}
+
+func TestValidateSingleMessagePresence(t *testing.T) {
+	client := newSingleMessageClient(t)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	// A sparse message: no value slice or metrics, and a data point with its
+	// value and tags unset
+	encoded, err := proto.Marshal(&v1.ValidationTestMessage{
+		PointerSliceData: []*v1.DataPoint{{Id: "dp_1", Timestamp: 1000}},
+	})
+	if err != nil {
+		t.Fatalf("Failed to marshal message: %v", err)
+	}
+
+	resp, err := client.ValidateSingleMessage(ctx, &v1.ValidateSingleMessageRequest{Message: encoded})
+	if err != nil {
+		t.Fatalf("ValidateSingleMessage failed: %v", err)
+	}
+
+	expected := []*v1.FieldPresence{
+		{Field: "validation.v1.ValidationTestMessage.value_slice_data"},
+		{Field: "validation.v1.ValidationTestMessage.pointer_slice_data", Present: true},
+		{Field: "validation.v1.ValidationTestMessage.metrics"},
+		{Field: "validation.v1.DataPoint.id", Present: true},
+		{Field: "validation.v1.DataPoint.value"},
+		{Field: "validation.v1.DataPoint.timestamp", Present: true},
+		{Field: "validation.v1.DataPoint.tags"},
+	}
+	if len(resp.Presence) != len(expected) {
+		t.Fatalf("Expected %d presence entries, got %v", len(expected), resp.Presence)
+	}
+	for i, got := range resp.Presence {
+		if !proto.Equal(got, expected[i]) {
+			t.Errorf("Expected presence %v, got %v", expected[i], got)
+		}
+	}
+}
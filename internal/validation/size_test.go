@@ -0,0 +1,202 @@
+package validation
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	v1 "github.com/benjamin-rood/protogo-values-validation-demo/gen/api/validation/v1"
+	"github.com/benjamin-rood/protogo-values-validation-demo/internal/server"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
+)
+
+func TestEstimateMessageSizeMatchesMarshal(t *testing.T) {
+	msg := &v1.ValidationTestMessage{
+		PointerSliceData: createDataPointPointers(50),
+	}
+
+	data, err := proto.Marshal(msg)
+	if err != nil {
+		t.Fatalf("Marshal failed: %v", err)
+	}
+
+	size, err := server.EstimateMessageSize(msg)
+	if err != nil {
+		t.Fatalf("EstimateMessageSize failed: %v", err)
+	}
+
+	if size != len(data) {
+		t.Errorf("Expected estimate %d, got %d", len(data), size)
+	}
+}
+
+func TestEstimateMessageSizeValueSlices(t *testing.T) {
+	// value_slice_data (field 1) and pointer_slice_data (field 2) share an
+	// element type and a one-byte tag, so the pointer form marshals to the
+	// size the value form would have
+	pointers := createDataPointPointers(50)
+
+	valueMsg := &v1.ValidationTestMessage{
+		ValueSliceData: make([]v1.DataPoint, len(pointers)),
+	}
+	for i, dp := range pointers {
+		valueMsg.ValueSliceData[i] = v1.DataPoint{
+			Id:        dp.Id,
+			Value:     dp.Value,
+			Timestamp: dp.Timestamp,
+			Tags:      dp.Tags,
+		}
+	}
+
+	data, err := proto.Marshal(&v1.ValidationTestMessage{PointerSliceData: pointers})
+	if err != nil {
+		t.Fatalf("Marshal failed: %v", err)
+	}
+
+	size, err := server.EstimateMessageSize(valueMsg)
+	if err != nil {
+		t.Fatalf("EstimateMessageSize failed: %v", err)
+	}
+
+	if size != len(data) {
+		t.Errorf("Expected estimate %d, got %d", len(data), size)
+	}
+}
+
+func TestEstimateSizeRPC(t *testing.T) {
+	cleanup := setupTestServer()
+	defer cleanup()
+
+	client, closeConn := createTestClient(t)
+	defer closeConn()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	t.Run("ProvidedMessage", func(t *testing.T) {
+		msg := &v1.ValidationTestMessage{
+			PointerSliceData: createDataPointPointers(20),
+		}
+		data, err := proto.Marshal(msg)
+		if err != nil {
+			t.Fatalf("Marshal failed: %v", err)
+		}
+
+		resp, err := client.EstimateSize(ctx, &v1.EstimateSizeRequest{
+			Message: msg,
+			Count:   1000,
+		})
+		if err != nil {
+			t.Fatalf("EstimateSize failed: %v", err)
+		}
+
+		if resp.BytesPerMessage != int64(len(data)) {
+			t.Errorf("Expected %d bytes per message, got %d", len(data), resp.BytesPerMessage)
+		}
+		if resp.TotalBytes != int64(len(data))*1000 {
+			t.Errorf("Expected %d total bytes, got %d", int64(len(data))*1000, resp.TotalBytes)
+		}
+	})
+
+	t.Run("GeneratedMessage", func(t *testing.T) {
+		resp, err := client.EstimateSize(ctx, &v1.EstimateSizeRequest{
+			DataSize: 10,
+			Count:    5,
+		})
+		if err != nil {
+			t.Fatalf("EstimateSize failed: %v", err)
+		}
+
+		if resp.BytesPerMessage <= 0 {
+			t.Errorf("Expected positive size, got %d", resp.BytesPerMessage)
+		}
+		if resp.TotalBytes != resp.BytesPerMessage*5 {
+			t.Errorf("Expected %d total bytes, got %d", resp.BytesPerMessage*5, resp.TotalBytes)
+		}
+	})
+
+	t.Run("InvalidArguments", func(t *testing.T) {
+		requests := []*v1.EstimateSizeRequest{
+			{Count: 0, DataSize: 10},
+			{Count: 1},
+		}
+		for _, req := range requests {
+			_, err := client.EstimateSize(ctx, req)
+			if status.Code(err) != codes.InvalidArgument {
+				t.Errorf("Expected InvalidArgument for %v, got %v", req, err)
+			}
+		}
+	})
+}
+
+func TestCompareSerializedSize(t *testing.T) {
+	cleanup := setupTestServer()
+	defer cleanup()
+
+	client, closeConn := createTestClient(t)
+	defer closeConn()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	dataSizes := []int32{1, 7, 100, 1000, 5000}
+	resp, err := client.CompareSerializedSize(ctx, &v1.CompareSerializedSizeRequest{DataSizes: dataSizes})
+	if err != nil {
+		t.Fatalf("CompareSerializedSize failed: %v", err)
+	}
+
+	if !resp.AllIdentical {
+		t.Error("Expected value and pointer slices to serialize to identical sizes")
+	}
+	if len(resp.Comparisons) != len(dataSizes) {
+		t.Fatalf("Expected %d comparisons, got %d", len(dataSizes), len(resp.Comparisons))
+	}
+
+	for i, c := range resp.Comparisons {
+		if c.DataSize != dataSizes[i] {
+			t.Errorf("Expected data size %d, got %d", dataSizes[i], c.DataSize)
+		}
+		if c.ValueSliceBytes != c.PointerSliceBytes || !c.Identical {
+			t.Errorf("Data size %d: value slice %d bytes != pointer slice %d bytes", c.DataSize, c.ValueSliceBytes, c.PointerSliceBytes)
+		}
+		if c.EstimatedBytes != c.ValueSliceBytes {
+			t.Errorf("Data size %d: expected estimate %d, got %d", c.DataSize, c.ValueSliceBytes, c.EstimatedBytes)
+		}
+		if i > 0 && c.ValueSliceBytes <= resp.Comparisons[i-1].ValueSliceBytes {
+			t.Errorf("Expected size to grow with data size, got %d then %d", resp.Comparisons[i-1].ValueSliceBytes, c.ValueSliceBytes)
+		}
+	}
+
+	t.Run("Defaults", func(t *testing.T) {
+		resp, err := client.CompareSerializedSize(ctx, &v1.CompareSerializedSizeRequest{})
+		if err != nil {
+			t.Fatalf("CompareSerializedSize failed: %v", err)
+		}
+		if len(resp.Comparisons) == 0 || !resp.AllIdentical {
+			t.Errorf("Expected identical default comparisons, got %v", resp.Comparisons)
+		}
+	})
+
+	t.Run("TooManyDataSizes", func(t *testing.T) {
+		dataSizes := make([]int32, 17)
+		for i := range dataSizes {
+			dataSizes[i] = 1
+		}
+		_, err := client.CompareSerializedSize(ctx, &v1.CompareSerializedSizeRequest{DataSizes: dataSizes})
+		if status.Code(err) != codes.InvalidArgument || !strings.Contains(status.Convert(err).Message(), server.ErrTooManyDataSizes.Error()) {
+			t.Errorf("Expected InvalidArgument for 17 data sizes, got %v", err)
+		}
+	})
+
+	t.Run("InvalidDataSize", func(t *testing.T) {
+		for _, size := range []int32{0, -1, server.DefaultMaxDataSize + 1} {
+			_, err := client.CompareSerializedSize(ctx, &v1.CompareSerializedSizeRequest{DataSizes: []int32{10, size}})
+			if status.Code(err) != codes.InvalidArgument {
+				t.Errorf("Data size %d: expected InvalidArgument, got %v", size, err)
+			}
+		}
+	})
+}
@@ -0,0 +1,61 @@
+package validation
+
+import (
+	"errors"
+	"log/slog"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/benjamin-rood/protogo-values-validation-demo/internal/server"
+)
+
+// knownUnsafeTypes are the validated message types with value-slice fields
+var knownUnsafeTypes = []string{
+	"validation.v1.ValidationTestMessage",
+	"validation.v1.PerformanceTestMessage",
+}
+
+func TestMarshalUnsafeTypes(t *testing.T) {
+	// The marshal panic is not a one-off: every check after the startup one,
+	// as /ready and CheckMarshalCompatibility run, finds the same types
+	captureLogs(t, slog.LevelInfo)
+	server.CheckMarshalHazards(false)
+	for i := 0; i < 3; i++ {
+		if got := server.MarshalUnsafeTypes(); !slices.Equal(got, knownUnsafeTypes) {
+			t.Errorf("Check %d: expected unsafe types %v, got %v", i, knownUnsafeTypes, got)
+		}
+	}
+}
+
+func TestCheckMarshalHazardsWarns(t *testing.T) {
+	logs := captureLogs(t, slog.LevelInfo)
+
+	if err := server.CheckMarshalHazards(false); err != nil {
+		t.Fatalf("Expected warn-only mode to return nil, got %v", err)
+	}
+
+	output := logs.String()
+	if !strings.Contains(output, `"level":"WARN"`) {
+		t.Errorf("Expected a warning, got %q", output)
+	}
+	for _, messageType := range knownUnsafeTypes {
+		if !strings.Contains(output, messageType) {
+			t.Errorf("Expected the warning to list %s, got %q", messageType, output)
+		}
+	}
+}
+
+func TestCheckMarshalHazardsFatal(t *testing.T) {
+	captureLogs(t, slog.LevelInfo)
+
+	err := server.CheckMarshalHazards(true)
+	if !errors.Is(err, server.ErrMarshalUnsafe) {
+		t.Fatalf("Expected ErrMarshalUnsafe, got %v", err)
+	}
+	for _, messageType := range knownUnsafeTypes {
+		if !strings.Contains(err.Error(), messageType) {
+			t.Errorf("Expected the error to list %s, got %v", messageType, err)
+		}
+	}
+}
@@ -0,0 +1,285 @@
+package validation
+
+import (
+	"context"
+	"fmt"
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	v1 "github.com/benjamin-rood/protogo-values-validation-demo/gen/api/validation/v1"
+	"github.com/benjamin-rood/protogo-values-validation-demo/internal/server"
+	"google.golang.org/grpc"
+)
+
+var mutexTypes = map[reflect.Type]bool{
+	reflect.TypeOf(sync.Mutex{}):   true,
+	reflect.TypeOf(sync.RWMutex{}): true,
+}
+
+// constructionOnlyFields are written by options inside NewValidationServer and
+// only read afterwards, so they need no guard
+var constructionOnlyFields = map[string]bool{
+	"ValidationServer.expectations":     true,
+	"ValidationServer.countConstraints": true,
+	"ValidationServer.extraScenarios":   true,
+}
+
+// unguardedMutableFields returns the map and slice fields of struct type t
+// that have no sibling mutex guarding them. A mutex guards every field its
+// name, minus the "Mu" suffix, prefixes: cacheMu guards cache.
+func unguardedMutableFields(t reflect.Type) []string {
+	var guards []string
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		if mutexTypes[f.Type] && strings.HasSuffix(f.Name, "Mu") {
+			guards = append(guards, strings.TrimSuffix(f.Name, "Mu"))
+		}
+	}
+
+	var unguarded []string
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		if kind := f.Type.Kind(); kind != reflect.Map && kind != reflect.Slice {
+			continue
+		}
+		if constructionOnlyFields[t.Name()+"."+f.Name] {
+			continue
+		}
+
+		guarded := false
+		for _, prefix := range guards {
+			if strings.HasPrefix(f.Name, prefix) {
+				guarded = true
+				break
+			}
+		}
+		if !guarded {
+			unguarded = append(unguarded, t.Name()+"."+f.Name)
+		}
+	}
+	return unguarded
+}
+
+// exportedPackageVars returns exported package-level variables declared in
+// the Go files of dir, other than error sentinels. Callers of the package
+// could mutate them and race with every request.
+func exportedPackageVars(t *testing.T, dir string) []string {
+	t.Helper()
+
+	files, err := filepath.Glob(filepath.Join(dir, "*.go"))
+	if err != nil {
+		t.Fatalf("Failed to list %s: %v", dir, err)
+	}
+
+	var exported []string
+	fset := token.NewFileSet()
+	for _, path := range files {
+		if strings.HasSuffix(path, "_test.go") {
+			continue
+		}
+
+		file, err := parser.ParseFile(fset, path, nil, 0)
+		if err != nil {
+			t.Fatalf("Failed to parse %s: %v", path, err)
+		}
+
+		for _, decl := range file.Decls {
+			gen, ok := decl.(*ast.GenDecl)
+			if !ok || gen.Tok != token.VAR {
+				continue
+			}
+			for _, spec := range gen.Specs {
+				for _, name := range spec.(*ast.ValueSpec).Names {
+					if name.IsExported() && !strings.HasPrefix(name.Name, "Err") {
+						exported = append(exported, fmt.Sprintf("%s: %s", filepath.Base(path), name.Name))
+					}
+				}
+			}
+		}
+	}
+	return exported
+}
+
+// TestSharedStateIsGuarded fails when server state that outlives a request
+// could be mutated without synchronization
+func TestSharedStateIsGuarded(t *testing.T) {
+	for _, v := range []any{
+		server.ValidationServer{},
+		server.AdminServer{},
+		server.StreamTracker{},
+		server.InfluxSink{},
+		server.PayloadSizeMetrics{},
+	} {
+		if unguarded := unguardedMutableFields(reflect.TypeOf(v)); len(unguarded) > 0 {
+			t.Errorf("Expected every map and slice field to have a guarding mutex, unguarded: %v", unguarded)
+		}
+	}
+
+	if exported := exportedPackageVars(t, "../server"); len(exported) > 0 {
+		t.Errorf("Expected no exported mutable package state, found: %v", exported)
+	}
+}
+
+func TestUnguardedMutableFieldsDetectsMaps(t *testing.T) {
+	type unsafeServer struct {
+		cacheMu sync.Mutex
+		cache   map[string]int
+		seen    map[string]bool
+		order   []string
+	}
+
+	unguarded := unguardedMutableFields(reflect.TypeOf(unsafeServer{}))
+	expected := []string{"unsafeServer.seen", "unsafeServer.order"}
+	if !reflect.DeepEqual(unguarded, expected) {
+		t.Errorf("Expected %v, got %v", expected, unguarded)
+	}
+}
+
+// countingRecorder is a MetricsRecorder that counts results per benchmark
+type countingRecorder struct {
+	countsMu sync.Mutex
+	counts   map[string]int
+}
+
+func (r *countingRecorder) RecordBenchmark(result *v1.BenchmarkResult) {
+	r.countsMu.Lock()
+	defer r.countsMu.Unlock()
+	r.counts[result.Name]++
+}
+
+func (r *countingRecorder) total() int {
+	r.countsMu.Lock()
+	defer r.countsMu.Unlock()
+	total := 0
+	for _, n := range r.counts {
+		total += n
+	}
+	return total
+}
+
+// countingSink is a BenchmarkSink that counts reports
+type countingSink struct {
+	reports atomic.Int64
+}
+
+func (k *countingSink) ReportBenchmarks(context.Context, []*v1.BenchmarkResult, time.Time) error {
+	k.reports.Add(1)
+	return nil
+}
+
+// TestStatefulFeaturesConcurrentAccess hammers every feature that keeps state
+// across requests from many goroutines. Run it with -race.
+func TestStatefulFeaturesConcurrentAccess(t *testing.T) {
+	recorder := &countingRecorder{counts: make(map[string]int)}
+	sink := &countingSink{}
+	validationServer := server.NewValidationServer(
+		server.WithMetrics(recorder),
+		server.WithBenchmarkSink(sink),
+		server.WithMetricCardinality(8, true),
+		server.WithMaxStreams(1000),
+	)
+
+	conn, cleanup := setupCustomTestServer(t, func(s *grpc.Server) {
+		v1.RegisterValidationServiceServer(s, validationServer)
+	})
+	defer cleanup()
+	client := v1.NewValidationServiceClient(conn)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	// A few distinct cache keys so goroutines race on hits and fills
+	cacheScenarios := []string{
+		server.ScenarioValidationTestMessage,
+		server.ScenarioPerformanceTestMessage,
+		server.ScenarioScalarSlices,
+		server.ScenarioServiceFields,
+	}
+
+	const (
+		goroutines = 16
+		iterations = 10
+	)
+
+	var (
+		wg             sync.WaitGroup
+		benchmarkRuns  atomic.Int64
+		recordedPerRun atomic.Int64
+	)
+
+	for g := 0; g < goroutines; g++ {
+		wg.Add(1)
+		go func(g int) {
+			defer wg.Done()
+			for i := 0; i < iterations; i++ {
+				// Cache
+				scenario := cacheScenarios[(g+i)%len(cacheScenarios)]
+				if _, err := client.ValidateTypes(ctx, &v1.ValidateTypesRequest{TestScenarios: []string{scenario}, PageSize: 2}); err != nil {
+					t.Errorf("ValidateTypes failed: %v", err)
+					return
+				}
+				_ = validationServer.CacheHits()
+
+				// Metrics recorder and benchmark sink
+				if i%5 == 0 {
+					resp, err := client.RunBenchmarks(ctx, &v1.BenchmarkRequest{Iterations: 5, DataSize: 5})
+					if err != nil {
+						t.Errorf("RunBenchmarks failed: %v", err)
+						return
+					}
+					benchmarkRuns.Add(1)
+					recordedPerRun.Store(int64(len(resp.Results)))
+				}
+
+				// Aggregation with a shared cardinality configuration
+				points := distinctLabelPoints(10 + g)
+				if _, err := client.AggregateMetrics(ctx, &v1.AggregateMetricsRequest{Points: points}); err != nil {
+					t.Errorf("AggregateMetrics failed: %v", err)
+					return
+				}
+
+				// Stream tracker
+				stream, err := openStream(t, ctx, client)
+				if err != nil {
+					t.Errorf("Failed to open stream: %v", err)
+					return
+				}
+				_ = validationServer.ActiveStreams()
+				closeStream(stream)
+			}
+		}(g)
+	}
+	wg.Wait()
+
+	if t.Failed() {
+		return
+	}
+
+	expectedRecords := int(benchmarkRuns.Load() * recordedPerRun.Load())
+	if got := recorder.total(); got != expectedRecords {
+		t.Errorf("Expected %d recorded benchmark results, got %d", expectedRecords, got)
+	}
+
+	// Sink reports are asynchronous
+	deadline := time.Now().Add(5 * time.Second)
+	for sink.reports.Load() != benchmarkRuns.Load() && time.Now().Before(deadline) {
+		time.Sleep(5 * time.Millisecond)
+	}
+	if got := sink.reports.Load(); got != benchmarkRuns.Load() {
+		t.Errorf("Expected %d sink reports, got %d", benchmarkRuns.Load(), got)
+	}
+
+	waitForActiveStreams(t, validationServer, 0)
+
+	if validationServer.CacheHits() == 0 {
+		t.Error("Expected concurrent ValidateTypes calls to hit the cache")
+	}
+}
@@ -0,0 +1,167 @@
+package validation
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"runtime"
+	"strings"
+	"testing"
+	"time"
+
+	v1 "github.com/benjamin-rood/protogo-values-validation-demo/gen/api/validation/v1"
+	"github.com/benjamin-rood/protogo-values-validation-demo/internal/server"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// blockingSendStream is a server-side StreamValidation stream whose Send
+// blocks, as on a full transport window, until its context ends or sendErr
+// delivers a failure. Recv behaves like gRPC's: it returns the queued
+// requests, then blocks until the context ends.
+type blockingSendStream struct {
+	grpc.ServerStream
+	ctx      context.Context
+	requests chan *v1.StreamRequest
+	sending  chan struct{}
+	sendErr  chan error
+}
+
+func newBlockingSendStream(ctx context.Context, requests ...*v1.StreamRequest) *blockingSendStream {
+	stream := &blockingSendStream{
+		ctx:      ctx,
+		requests: make(chan *v1.StreamRequest, len(requests)),
+		sending:  make(chan struct{}, 1),
+		sendErr:  make(chan error, 1),
+	}
+	for _, req := range requests {
+		stream.requests <- req
+	}
+	return stream
+}
+
+func (s *blockingSendStream) Context() context.Context { return s.ctx }
+
+func (s *blockingSendStream) Recv() (*v1.StreamRequest, error) {
+	select {
+	case req := <-s.requests:
+		return req, nil
+	case <-s.ctx.Done():
+		return nil, status.FromContextError(s.ctx.Err()).Err()
+	}
+}
+
+func (s *blockingSendStream) Send(*v1.StreamResponse) error {
+	select {
+	case s.sending <- struct{}{}:
+	default:
+	}
+	select {
+	case err := <-s.sendErr:
+		return err
+	case <-s.ctx.Done():
+		return status.Error(codes.Canceled, "context canceled")
+	}
+}
+
+// runBlockedStream starts StreamValidation on stream and waits until the
+// handler is blocked delivering a response
+func runBlockedStream(t *testing.T, validationServer *server.ValidationServer, stream *blockingSendStream) <-chan error {
+	t.Helper()
+
+	done := make(chan error, 1)
+	go func() { done <- validationServer.StreamValidation(stream) }()
+
+	select {
+	case <-stream.sending:
+	case <-time.After(5 * time.Second):
+		t.Fatal("Expected the handler to start sending")
+	}
+	return done
+}
+
+func streamRequests(n int) []*v1.StreamRequest {
+	requests := make([]*v1.StreamRequest, n)
+	for i := range requests {
+		requests[i] = &v1.StreamRequest{RequestId: "req", SequenceNumber: int32(i), TestData: &v1.ValidationTestMessage{}}
+	}
+	return requests
+}
+
+func TestStreamClientCancelDuringSend(t *testing.T) {
+	logs := captureLogs(t, slog.LevelDebug)
+	validationServer := server.NewValidationServer()
+	baseline := runtime.NumGoroutine()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	// More requests than the queues hold, so workers are busy when Send blocks
+	stream := newBlockingSendStream(ctx, streamRequests(64)...)
+	done := runBlockedStream(t, validationServer, stream)
+
+	cancel()
+
+	select {
+	case err := <-done:
+		if status.Code(err) != codes.Canceled {
+			t.Errorf("Expected Canceled, got %v", err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("StreamValidation did not return after the client cancelled")
+	}
+
+	if active := validationServer.ActiveStreams(); active != 0 {
+		t.Errorf("Expected the stream to be released, got %d active", active)
+	}
+	if count := waitForGoroutines(baseline, time.Second); count > baseline {
+		t.Errorf("Expected stream goroutines to exit, got %d running (baseline %d)", count, baseline)
+	}
+
+	output := logs.String()
+	if !strings.Contains(output, "stream client went away during send") || !strings.Contains(output, `"level":"DEBUG"`) {
+		t.Errorf("Expected a debug log classifying the cancellation, got:\n%s", output)
+	}
+	if strings.Contains(output, "stream send failed") {
+		t.Errorf("Expected cancellation not to be logged as a send failure, got:\n%s", output)
+	}
+}
+
+func TestStreamSendFailure(t *testing.T) {
+	logs := captureLogs(t, slog.LevelInfo)
+	validationServer := server.NewValidationServer()
+	baseline := runtime.NumGoroutine()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	stream := newBlockingSendStream(ctx, streamRequests(64)...)
+	done := runBlockedStream(t, validationServer, stream)
+
+	stream.sendErr <- errors.New("connection reset by peer")
+
+	var err error
+	select {
+	case err = <-done:
+	case <-time.After(5 * time.Second):
+		t.Fatal("StreamValidation did not return after the send failed")
+	}
+	if status.Code(err) != codes.Unavailable || !strings.Contains(err.Error(), "connection reset by peer") {
+		t.Errorf("Expected Unavailable carrying the send error, got %v", err)
+	}
+
+	// gRPC ends the stream context once the handler returns
+	cancel()
+
+	if active := validationServer.ActiveStreams(); active != 0 {
+		t.Errorf("Expected the stream to be released, got %d active", active)
+	}
+	if count := waitForGoroutines(baseline, time.Second); count > baseline {
+		t.Errorf("Expected stream goroutines to exit, got %d running (baseline %d)", count, baseline)
+	}
+
+	if output := logs.String(); !strings.Contains(output, "stream send failed") || !strings.Contains(output, `"level":"ERROR"`) {
+		t.Errorf("Expected an error log for the send failure, got:\n%s", output)
+	}
+}
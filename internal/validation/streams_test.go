@@ -0,0 +1,306 @@
+package validation
+
+import (
+	"context"
+	"io"
+	"sync"
+	"testing"
+	"time"
+
+	v1 "github.com/benjamin-rood/protogo-values-validation-demo/gen/api/validation/v1"
+	"github.com/benjamin-rood/protogo-values-validation-demo/internal/server"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// openStream starts a StreamValidation stream and waits for one round trip,
+// which proves the server handler is running
+func openStream(t *testing.T, ctx context.Context, client v1.ValidationServiceClient) (v1.ValidationService_StreamValidationClient, error) {
+	t.Helper()
+
+	stream, err := client.StreamValidation(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if err := stream.Send(&v1.StreamRequest{RequestId: "open", TestData: &v1.ValidationTestMessage{}}); err != nil {
+		return nil, err
+	}
+	if _, err := stream.Recv(); err != nil {
+		return nil, err
+	}
+	return stream, nil
+}
+
+// closeStream half-closes the stream and drains it until the server returns
+func closeStream(stream v1.ValidationService_StreamValidationClient) {
+	stream.CloseSend()
+	for {
+		if _, err := stream.Recv(); err != nil {
+			return
+		}
+	}
+}
+
+// waitForActiveStreams polls until the server reports the expected count
+func waitForActiveStreams(t *testing.T, s *server.ValidationServer, expected int64) {
+	t.Helper()
+
+	deadline := time.Now().Add(2 * time.Second)
+	for s.ActiveStreams() != expected && time.Now().Before(deadline) {
+		time.Sleep(5 * time.Millisecond)
+	}
+	if active := s.ActiveStreams(); active != expected {
+		t.Errorf("Expected %d active streams, got %d", expected, active)
+	}
+}
+
+func TestActiveStreamCount(t *testing.T) {
+	validationServer := server.NewValidationServer()
+
+	conn, cleanup := setupCustomTestServer(t, func(s *grpc.Server) {
+		v1.RegisterValidationServiceServer(s, validationServer)
+	})
+	defer cleanup()
+	client := v1.NewValidationServiceClient(conn)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	const streams = 8
+	opened := make([]v1.ValidationService_StreamValidationClient, streams)
+
+	var wg sync.WaitGroup
+	for i := 0; i < streams; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			stream, err := openStream(t, ctx, client)
+			if err != nil {
+				t.Errorf("Failed to open stream %d: %v", i, err)
+				return
+			}
+			opened[i] = stream
+		}(i)
+	}
+	wg.Wait()
+
+	waitForActiveStreams(t, validationServer, streams)
+
+	for _, stream := range opened {
+		if stream != nil {
+			closeStream(stream)
+		}
+	}
+
+	waitForActiveStreams(t, validationServer, 0)
+}
+
+func TestActiveStreamLimit(t *testing.T) {
+	validationServer := server.NewValidationServer(server.WithMaxStreams(2))
+
+	conn, cleanup := setupCustomTestServer(t, func(s *grpc.Server) {
+		v1.RegisterValidationServiceServer(s, validationServer)
+	})
+	defer cleanup()
+	client := v1.NewValidationServiceClient(conn)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	first, err := openStream(t, ctx, client)
+	if err != nil {
+		t.Fatalf("Failed to open first stream: %v", err)
+	}
+	second, err := openStream(t, ctx, client)
+	if err != nil {
+		t.Fatalf("Failed to open second stream: %v", err)
+	}
+
+	_, err = openStream(t, ctx, client)
+	if status.Code(err) != codes.ResourceExhausted {
+		t.Errorf("Expected ResourceExhausted past the limit, got %v", err)
+	}
+
+	// Rejection must not leak a slot
+	waitForActiveStreams(t, validationServer, 2)
+
+	closeStream(first)
+	closeStream(second)
+	waitForActiveStreams(t, validationServer, 0)
+}
+
+func TestStreamLimitKeepsExistingStreams(t *testing.T) {
+	const limit = 3
+	validationServer := server.NewValidationServer(server.WithMaxStreams(limit))
+
+	conn, cleanup := setupCustomTestServer(t, func(s *grpc.Server) {
+		v1.RegisterValidationServiceServer(s, validationServer)
+	})
+	defer cleanup()
+	client := v1.NewValidationServiceClient(conn)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	opened := make([]v1.ValidationService_StreamValidationClient, limit)
+	for i := range opened {
+		stream, err := openStream(t, ctx, client)
+		if err != nil {
+			t.Fatalf("Failed to open stream %d: %v", i, err)
+		}
+		opened[i] = stream
+	}
+
+	// Every stream past the cap is rejected
+	for i := 0; i < 3; i++ {
+		if _, err := openStream(t, ctx, client); status.Code(err) != codes.ResourceExhausted {
+			t.Errorf("Expected ResourceExhausted for excess stream %d, got %v", i, err)
+		}
+	}
+
+	// Streams admitted before the cap was hit keep working
+	for i, stream := range opened {
+		if err := stream.Send(&v1.StreamRequest{RequestId: "after-rejection", TestData: &v1.ValidationTestMessage{}}); err != nil {
+			t.Fatalf("Stream %d failed to send after rejections: %v", i, err)
+		}
+		resp, err := stream.Recv()
+		if err != nil {
+			t.Fatalf("Stream %d failed to receive after rejections: %v", i, err)
+		}
+		if resp.RequestId != "after-rejection" {
+			t.Errorf("Expected response to after-rejection on stream %d, got %q", i, resp.RequestId)
+		}
+	}
+
+	// Closing a stream frees its slot for a new one
+	closeStream(opened[0])
+	waitForActiveStreams(t, validationServer, limit-1)
+	replacement, err := openStream(t, ctx, client)
+	if err != nil {
+		t.Fatalf("Expected a freed slot to admit a new stream, got %v", err)
+	}
+
+	closeStream(replacement)
+	for _, stream := range opened[1:] {
+		closeStream(stream)
+	}
+	waitForActiveStreams(t, validationServer, 0)
+}
+
+func TestStreamIdleTimeout(t *testing.T) {
+	const idleTimeout = 100 * time.Millisecond
+	validationServer := server.NewValidationServer(server.WithStreamIdleTimeout(idleTimeout))
+
+	conn, cleanup := setupCustomTestServer(t, func(s *grpc.Server) {
+		v1.RegisterValidationServiceServer(s, validationServer)
+	})
+	defer cleanup()
+	client := v1.NewValidationServiceClient(conn)
+
+	t.Run("silent client is disconnected", func(t *testing.T) {
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+
+		// Open the stream and never send
+		stream, err := client.StreamValidation(ctx)
+		if err != nil {
+			t.Fatalf("Failed to open stream: %v", err)
+		}
+
+		start := time.Now()
+		_, err = stream.Recv()
+		if status.Code(err) != codes.DeadlineExceeded {
+			t.Fatalf("Expected DeadlineExceeded, got %v", err)
+		}
+		if elapsed := time.Since(start); elapsed < idleTimeout {
+			t.Errorf("Expected the stream to stay open for at least %v, closed after %v", idleTimeout, elapsed)
+		}
+
+		waitForActiveStreams(t, validationServer, 0)
+	})
+
+	t.Run("each message resets the timeout", func(t *testing.T) {
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+
+		stream, err := client.StreamValidation(ctx)
+		if err != nil {
+			t.Fatalf("Failed to open stream: %v", err)
+		}
+
+		// Keep the stream busy for several idle windows
+		for i := 0; i < 6; i++ {
+			if err := stream.Send(&v1.StreamRequest{RequestId: "keepalive", SequenceNumber: int32(i), TestData: &v1.ValidationTestMessage{}}); err != nil {
+				t.Fatalf("Send %d failed: %v", i, err)
+			}
+			if _, err := stream.Recv(); err != nil {
+				t.Fatalf("Recv %d failed: %v", i, err)
+			}
+			time.Sleep(idleTimeout / 2)
+		}
+
+		stream.CloseSend()
+		if _, err := stream.Recv(); err != io.EOF {
+			t.Errorf("Expected a clean close, got %v", err)
+		}
+	})
+}
+
+func TestStreamMessageCap(t *testing.T) {
+	const maxMessages = 3
+	validationServer := server.NewValidationServer(server.WithMaxStreamMessages(maxMessages))
+
+	conn, cleanup := setupCustomTestServer(t, func(s *grpc.Server) {
+		v1.RegisterValidationServiceServer(s, validationServer)
+	})
+	defer cleanup()
+	client := v1.NewValidationServiceClient(conn)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	stream, err := client.StreamValidation(ctx)
+	if err != nil {
+		t.Fatalf("Failed to open stream: %v", err)
+	}
+
+	// Sends past the cap may fail once the server has ended the stream
+	for i := 0; i < maxMessages+2; i++ {
+		if err := stream.Send(&v1.StreamRequest{RequestId: "capped", SequenceNumber: int32(i), TestData: &v1.ValidationTestMessage{}}); err != nil {
+			break
+		}
+	}
+	stream.CloseSend()
+
+	var answered int
+	var final *v1.StreamResponse
+	for {
+		resp, err := stream.Recv()
+		if err != nil {
+			if status.Code(err) != codes.ResourceExhausted {
+				t.Errorf("Expected ResourceExhausted, got %v", err)
+			}
+			break
+		}
+		if final != nil {
+			t.Errorf("Expected no response after the cap-exceeded one, got %v", resp)
+		}
+		if resp.CapExceeded {
+			final = resp
+			continue
+		}
+		answered++
+	}
+
+	if answered != maxMessages {
+		t.Errorf("Expected the %d messages within the cap answered, got %d", maxMessages, answered)
+	}
+	if final == nil {
+		t.Fatal("Expected a final cap-exceeded response")
+	}
+	if final.Success {
+		t.Error("Expected the cap-exceeded response to report failure")
+	}
+	waitForActiveStreams(t, validationServer, 0)
+}
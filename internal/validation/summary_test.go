@@ -0,0 +1,208 @@
+package validation
+
+import (
+	"context"
+	"errors"
+	"math"
+	"reflect"
+	"testing"
+	"time"
+
+	v1 "github.com/benjamin-rood/protogo-values-validation-demo/gen/api/validation/v1"
+	"github.com/benjamin-rood/protogo-values-validation-demo/internal/server"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func TestSummarizeProcessingResults(t *testing.T) {
+	tests := []struct {
+		name           string
+		results        []v1.ProcessingResult
+		expectedTotal  int32
+		expectedFailed int32
+		expectedRate   float64
+		expectedMean   float64
+		expectedMax    float64
+		expectedErrors []string
+	}{
+		{
+			name: "mixed success and failure",
+			results: []v1.ProcessingResult{
+				{OperationId: "op1", Success: true, DurationMs: 10},
+				{OperationId: "op2", Success: false, DurationMs: 30, ErrorMessages: []string{"timeout", "retry exhausted"}},
+				{OperationId: "op3", Success: false, DurationMs: 20, ErrorMessages: []string{"timeout"}},
+				{OperationId: "op4", Success: true, DurationMs: 20},
+			},
+			expectedTotal:  4,
+			expectedFailed: 2,
+			expectedRate:   0.5,
+			expectedMean:   20,
+			expectedMax:    30,
+			expectedErrors: []string{"retry exhausted", "timeout"},
+		},
+		{
+			name: "all success",
+			results: []v1.ProcessingResult{
+				{OperationId: "op1", Success: true, DurationMs: 5},
+				{OperationId: "op2", Success: true, DurationMs: 15},
+			},
+			expectedTotal:  2,
+			expectedFailed: 0,
+			expectedRate:   1,
+			expectedMean:   10,
+			expectedMax:    15,
+			expectedErrors: []string{},
+		},
+		{
+			name:           "empty input",
+			results:        nil,
+			expectedErrors: []string{},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			summary := server.SummarizeProcessingResults(tt.results)
+
+			if summary.TotalCount != tt.expectedTotal {
+				t.Errorf("Expected total %d, got %d", tt.expectedTotal, summary.TotalCount)
+			}
+			if summary.FailedCount != tt.expectedFailed {
+				t.Errorf("Expected failed %d, got %d", tt.expectedFailed, summary.FailedCount)
+			}
+			if summary.SuccessRate != tt.expectedRate {
+				t.Errorf("Expected success rate %v, got %v", tt.expectedRate, summary.SuccessRate)
+			}
+			if summary.MeanDurationMs != tt.expectedMean {
+				t.Errorf("Expected mean duration %v, got %v", tt.expectedMean, summary.MeanDurationMs)
+			}
+			if summary.MaxDurationMs != tt.expectedMax {
+				t.Errorf("Expected max duration %v, got %v", tt.expectedMax, summary.MaxDurationMs)
+			}
+			if !reflect.DeepEqual(summary.DistinctErrors, tt.expectedErrors) {
+				t.Errorf("Expected errors %v, got %v", tt.expectedErrors, summary.DistinctErrors)
+			}
+		})
+	}
+}
+
+func TestSummarizeResultsRPC(t *testing.T) {
+	cleanup := setupTestServer()
+	defer cleanup()
+
+	client, closeConn := createTestClient(t)
+	defer closeConn()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	resp, err := client.SummarizeResults(ctx, &v1.SummarizeResultsRequest{
+		Results: []*v1.ProcessingResult{
+			{OperationId: "op1", Success: true, DurationMs: 4},
+			{OperationId: "op2", Success: false, DurationMs: 8, ErrorMessages: []string{"invalid input"}},
+		},
+	})
+	if err != nil {
+		t.Fatalf("SummarizeResults failed: %v", err)
+	}
+
+	if resp.TotalCount != 2 || resp.FailedCount != 1 {
+		t.Errorf("Expected 2 total and 1 failed, got %d and %d", resp.TotalCount, resp.FailedCount)
+	}
+	if resp.SuccessRate != 0.5 {
+		t.Errorf("Expected success rate 0.5, got %v", resp.SuccessRate)
+	}
+	if len(resp.DistinctErrors) != 1 || resp.DistinctErrors[0] != "invalid input" {
+		t.Errorf("Expected [invalid input], got %v", resp.DistinctErrors)
+	}
+}
+
+func TestSummarizeResultsDurationHistogram(t *testing.T) {
+	validationServer := server.NewValidationServer()
+
+	// Bimodal: a fast cluster around 2ms and a slow one around 200ms
+	durations := []float64{1, 2, 2, 3, 10, 150, 200, 200, 250, 900}
+	results := make([]*v1.ProcessingResult, len(durations))
+	for i, d := range durations {
+		results[i] = &v1.ProcessingResult{OperationId: "op", Success: true, DurationMs: d}
+	}
+
+	resp, err := validationServer.SummarizeResults(context.Background(), &v1.SummarizeResultsRequest{
+		Results:           results,
+		DurationBucketsMs: []float64{2, 10, 100, 250},
+	})
+	if err != nil {
+		t.Fatalf("SummarizeResults failed: %v", err)
+	}
+
+	// Bounds are inclusive upper bounds: 2 and 10 land in their own buckets
+	expected := []struct {
+		bound float64
+		count int32
+	}{{2, 3}, {10, 2}, {100, 0}, {250, 4}}
+	if len(resp.DurationHistogram) != len(expected) {
+		t.Fatalf("Expected %d buckets, got %d", len(expected), len(resp.DurationHistogram))
+	}
+	for i, want := range expected {
+		bucket := resp.DurationHistogram[i]
+		if bucket.UpperBoundMs != want.bound || bucket.Count != want.count {
+			t.Errorf("Expected bucket %d to be <= %v with %d, got <= %v with %d", i, want.bound, want.count, bucket.UpperBoundMs, bucket.Count)
+		}
+	}
+	if resp.DurationOverflowCount != 1 {
+		t.Errorf("Expected 1 duration above the last bound, got %d", resp.DurationOverflowCount)
+	}
+	if resp.MaxDurationMs != 900 {
+		t.Errorf("Expected the scalar stats alongside the histogram, got max %v", resp.MaxDurationMs)
+	}
+}
+
+func TestSummarizeResultsDefaultDurationBuckets(t *testing.T) {
+	validationServer := server.NewValidationServer()
+
+	resp, err := validationServer.SummarizeResults(context.Background(), &v1.SummarizeResultsRequest{
+		Results: []*v1.ProcessingResult{{DurationMs: 7}, {DurationMs: 5000}},
+	})
+	if err != nil {
+		t.Fatalf("SummarizeResults failed: %v", err)
+	}
+
+	var bounds []float64
+	var counted int32
+	for _, bucket := range resp.DurationHistogram {
+		bounds = append(bounds, bucket.UpperBoundMs)
+		counted += bucket.Count
+	}
+	if expected := []float64{1, 5, 10, 25, 50, 100, 250, 500, 1000}; !reflect.DeepEqual(bounds, expected) {
+		t.Errorf("Expected default bounds %v, got %v", expected, bounds)
+	}
+	if counted != 1 || resp.DurationOverflowCount != 1 {
+		t.Errorf("Expected 1 bucketed and 1 overflow duration, got %d and %d", counted, resp.DurationOverflowCount)
+	}
+
+	// An empty summary still lists the buckets
+	empty, err := validationServer.SummarizeResults(context.Background(), &v1.SummarizeResultsRequest{})
+	if err != nil {
+		t.Fatalf("SummarizeResults failed: %v", err)
+	}
+	if len(empty.DurationHistogram) != len(bounds) {
+		t.Errorf("Expected %d empty buckets, got %d", len(bounds), len(empty.DurationHistogram))
+	}
+}
+
+func TestSummarizeResultsInvalidDurationBuckets(t *testing.T) {
+	validationServer := server.NewValidationServer()
+
+	for name, bounds := range map[string][]float64{
+		"descending": {10, 5},
+		"duplicate":  {5, 5},
+		"infinite":   {1, math.Inf(1)},
+		"NaN":        {math.NaN()},
+		"too many":   make([]float64, 101),
+	} {
+		_, err := validationServer.SummarizeResults(context.Background(), &v1.SummarizeResultsRequest{DurationBucketsMs: bounds})
+		if !errors.Is(err, server.ErrInvalidDurationBuckets) || status.Code(err) != codes.InvalidArgument {
+			t.Errorf("Expected ErrInvalidDurationBuckets for %s bounds, got %v", name, err)
+		}
+	}
+}
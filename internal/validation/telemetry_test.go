@@ -0,0 +1,178 @@
+package validation
+
+import (
+	"context"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	v1 "github.com/benjamin-rood/protogo-values-validation-demo/gen/api/validation/v1"
+	"github.com/benjamin-rood/protogo-values-validation-demo/internal/server"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+)
+
+// wireEvents records every WireRecorder callback
+type wireEvents struct {
+	mu             sync.Mutex
+	connBegins     int
+	connEnds       [][2]int64
+	rpcBegins      []string
+	rpcEnds        map[string][]codes.Code
+	requestBytes   map[string]int
+	responseBytes  map[string]int
+	payloadsByKind map[string]int
+}
+
+func newWireEvents() *wireEvents {
+	return &wireEvents{
+		rpcEnds:        make(map[string][]codes.Code),
+		requestBytes:   make(map[string]int),
+		responseBytes:  make(map[string]int),
+		payloadsByKind: make(map[string]int),
+	}
+}
+
+func (e *wireEvents) ConnBegin() {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.connBegins++
+}
+
+func (e *wireEvents) ConnEnd(bytesIn, bytesOut int64) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.connEnds = append(e.connEnds, [2]int64{bytesIn, bytesOut})
+}
+
+func (e *wireEvents) RPCBegin(method string) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.rpcBegins = append(e.rpcBegins, method)
+}
+
+func (e *wireEvents) RPCEnd(method string, code codes.Code) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.rpcEnds[method] = append(e.rpcEnds[method], code)
+}
+
+func (e *wireEvents) Payload(method, direction string, wireBytes int) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.payloadsByKind[direction]++
+	if direction == "request" {
+		e.requestBytes[method] += wireBytes
+	} else {
+		e.responseBytes[method] += wireBytes
+	}
+}
+
+// waitFor polls until done reports true under the events lock
+func (e *wireEvents) waitFor(t *testing.T, what string, done func() bool) {
+	t.Helper()
+
+	deadline := time.Now().Add(2 * time.Second)
+	for {
+		e.mu.Lock()
+		ok := done()
+		e.mu.Unlock()
+		if ok {
+			return
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("Timed out waiting for %s", what)
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+}
+
+const validateTypesMethod = "/validation.v1.ValidationService/ValidateTypes"
+
+func TestWireStatsHandlerCallbacks(t *testing.T) {
+	events := newWireEvents()
+
+	conn, cleanup := setupCustomTestServer(t, func(s *grpc.Server) {
+		v1.RegisterValidationServiceServer(s, server.NewValidationServer())
+	}, grpc.StatsHandler(server.NewWireStatsHandler(events)), grpc.UnaryInterceptor(server.UnaryValidationInterceptor()))
+	client := v1.NewValidationServiceClient(conn)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	if _, err := client.ValidateTypes(ctx, &v1.ValidateTypesRequest{TestScenarios: []string{server.ScenarioValidationTestMessage}}); err != nil {
+		t.Fatalf("ValidateTypes failed: %v", err)
+	}
+	// A rejected call still begins and ends, with its status code
+	if _, err := client.ValidateTypes(ctx, &v1.ValidateTypesRequest{PageSize: -1}); err == nil {
+		t.Fatal("Expected a negative page size to be rejected")
+	}
+
+	// The server reports End after the client has its response
+	events.waitFor(t, "both RPCs to end", func() bool { return len(events.rpcEnds[validateTypesMethod]) == 2 })
+
+	events.mu.Lock()
+	if len(events.rpcBegins) != 2 || events.rpcBegins[0] != validateTypesMethod {
+		t.Errorf("Expected two ValidateTypes begins, got %v", events.rpcBegins)
+	}
+	if ends := events.rpcEnds[validateTypesMethod]; ends[0] != codes.OK || ends[1] != codes.InvalidArgument {
+		t.Errorf("Expected ends with OK then InvalidArgument, got %v", ends)
+	}
+	if events.payloadsByKind["request"] != 2 || events.payloadsByKind["response"] != 1 {
+		t.Errorf("Expected 2 request and 1 response payloads, got %v", events.payloadsByKind)
+	}
+	requestBytes, responseBytes := events.requestBytes[validateTypesMethod], events.responseBytes[validateTypesMethod]
+	if requestBytes <= 0 || responseBytes <= 0 {
+		t.Errorf("Expected wire bytes in both directions, got %d in and %d out", requestBytes, responseBytes)
+	}
+	if events.connBegins != 1 {
+		t.Errorf("Expected 1 connection, got %d", events.connBegins)
+	}
+	events.mu.Unlock()
+
+	// Closing the connection reports the bytes it carried
+	cleanup()
+	events.waitFor(t, "the connection to end", func() bool { return len(events.connEnds) == 1 })
+
+	events.mu.Lock()
+	defer events.mu.Unlock()
+	if got := events.connEnds[0]; got != [2]int64{int64(requestBytes), int64(responseBytes)} {
+		t.Errorf("Expected connection totals of %d in and %d out, got %d and %d",
+			requestBytes, responseBytes, got[0], got[1])
+	}
+}
+
+func TestWireMetricsExposition(t *testing.T) {
+	metrics := server.NewWireMetrics()
+	metrics.ConnBegin()
+	metrics.ConnBegin()
+	metrics.ConnEnd(10, 20)
+	metrics.RPCBegin(validateTypesMethod)
+	metrics.RPCBegin(validateTypesMethod)
+	metrics.Payload(validateTypesMethod, "request", 12)
+	metrics.Payload(validateTypesMethod, "request", 8)
+	metrics.Payload(validateTypesMethod, "response", 300)
+	metrics.RPCEnd(validateTypesMethod, codes.OK)
+	metrics.RPCEnd(validateTypesMethod, codes.InvalidArgument)
+
+	var b strings.Builder
+	if err := metrics.WritePrometheus(&b); err != nil {
+		t.Fatalf("WritePrometheus failed: %v", err)
+	}
+	output := b.String()
+
+	for _, line := range []string{
+		"grpc_server_connections_opened_total 2",
+		"grpc_server_connections_open 1",
+		`grpc_server_started_total{method="/validation.v1.ValidationService/ValidateTypes"} 2`,
+		`grpc_server_handled_total{method="/validation.v1.ValidationService/ValidateTypes",code="OK"} 1`,
+		`grpc_server_handled_total{method="/validation.v1.ValidationService/ValidateTypes",code="InvalidArgument"} 1`,
+		`grpc_server_wire_bytes_total{method="/validation.v1.ValidationService/ValidateTypes",direction="request"} 20`,
+		`grpc_server_wire_bytes_total{method="/validation.v1.ValidationService/ValidateTypes",direction="response"} 300`,
+	} {
+		if !strings.Contains(output, line+"\n") {
+			t.Errorf("Expected line %q, got:\n%s", line, output)
+		}
+	}
+}
@@ -0,0 +1,176 @@
+package validation
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"math/big"
+	"net"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	v1 "github.com/benjamin-rood/protogo-values-validation-demo/gen/api/validation/v1"
+	"github.com/benjamin-rood/protogo-values-validation-demo/internal/server"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials"
+)
+
+// testCertificate is a generated certificate with its key and PEM encodings
+type testCertificate struct {
+	cert    *x509.Certificate
+	key     *ecdsa.PrivateKey
+	certPEM []byte
+	keyPEM  []byte
+}
+
+// newTestCertificate creates a certificate signed by parent, or self-signed when parent is nil
+func newTestCertificate(t *testing.T, template *x509.Certificate, parent *testCertificate) *testCertificate {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatalf("Failed to generate key: %v", err)
+	}
+
+	signerCert, signerKey := template, key
+	if parent != nil {
+		signerCert, signerKey = parent.cert, parent.key
+	}
+
+	der, err := x509.CreateCertificate(rand.Reader, template, signerCert, &key.PublicKey, signerKey)
+	if err != nil {
+		t.Fatalf("Failed to create certificate: %v", err)
+	}
+
+	cert, err := x509.ParseCertificate(der)
+	if err != nil {
+		t.Fatalf("Failed to parse certificate: %v", err)
+	}
+
+	keyDER, err := x509.MarshalECPrivateKey(key)
+	if err != nil {
+		t.Fatalf("Failed to marshal key: %v", err)
+	}
+
+	return &testCertificate{
+		cert:    cert,
+		key:     key,
+		certPEM: pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}),
+		keyPEM:  pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDER}),
+	}
+}
+
+func certTemplate(serial int64, name string) *x509.Certificate {
+	return &x509.Certificate{
+		SerialNumber: big.NewInt(serial),
+		Subject:      pkix.Name{CommonName: name},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+	}
+}
+
+func writeTestFile(t *testing.T, dir, name string, data []byte) string {
+	path := filepath.Join(dir, name)
+	if err := os.WriteFile(path, data, 0o600); err != nil {
+		t.Fatalf("Failed to write %s: %v", name, err)
+	}
+	return path
+}
+
+// TestMutualTLS tests the gRPC server rejects clients without a valid certificate
+func TestMutualTLS(t *testing.T) {
+	caTemplate := certTemplate(1, "test-ca")
+	caTemplate.IsCA = true
+	caTemplate.BasicConstraintsValid = true
+	caTemplate.KeyUsage = x509.KeyUsageCertSign | x509.KeyUsageDigitalSignature
+	ca := newTestCertificate(t, caTemplate, nil)
+
+	serverTemplate := certTemplate(2, "localhost")
+	serverTemplate.IPAddresses = []net.IP{net.ParseIP("127.0.0.1")}
+	serverTemplate.ExtKeyUsage = []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth}
+	serverCert := newTestCertificate(t, serverTemplate, ca)
+
+	clientTemplate := certTemplate(3, "client")
+	clientTemplate.ExtKeyUsage = []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth}
+	clientCert := newTestCertificate(t, clientTemplate, ca)
+
+	dir := t.TempDir()
+	tlsConfig, err := server.NewServerTLSConfig(
+		writeTestFile(t, dir, "server.crt", serverCert.certPEM),
+		writeTestFile(t, dir, "server.key", serverCert.keyPEM),
+		writeTestFile(t, dir, "ca.crt", ca.certPEM),
+	)
+	if err != nil {
+		t.Fatalf("NewServerTLSConfig failed: %v", err)
+	}
+
+	// Real TCP loopback listener so the TLS handshake is exercised end to end
+	tcpLis, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("Failed to listen: %v", err)
+	}
+
+	s := grpc.NewServer(grpc.Creds(credentials.NewTLS(tlsConfig)))
+	v1.RegisterValidationServiceServer(s, server.NewValidationServer())
+	go s.Serve(tcpLis)
+	defer s.Stop()
+
+	roots := x509.NewCertPool()
+	roots.AddCert(ca.cert)
+
+	callValidateTypes := func(clientTLS *tls.Config) error {
+		conn, err := grpc.NewClient(tcpLis.Addr().String(),
+			grpc.WithTransportCredentials(credentials.NewTLS(clientTLS)))
+		if err != nil {
+			return err
+		}
+		defer conn.Close()
+
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+
+		_, err = v1.NewValidationServiceClient(conn).ValidateTypes(ctx, &v1.ValidateTypesRequest{
+			TestScenarios: []string{server.ScenarioValidationTestMessage},
+		})
+		return err
+	}
+
+	t.Run("RejectsClientWithoutCertificate", func(t *testing.T) {
+		err := callValidateTypes(&tls.Config{RootCAs: roots})
+		if err == nil {
+			t.Error("Expected client without certificate to be rejected")
+		}
+	})
+
+	t.Run("RejectsUntrustedClientCertificate", func(t *testing.T) {
+		rogueCA := newTestCertificate(t, caTemplate, nil)
+		rogueClient := newTestCertificate(t, clientTemplate, rogueCA)
+
+		pair, err := tls.X509KeyPair(rogueClient.certPEM, rogueClient.keyPEM)
+		if err != nil {
+			t.Fatalf("Failed to load client key pair: %v", err)
+		}
+
+		err = callValidateTypes(&tls.Config{RootCAs: roots, Certificates: []tls.Certificate{pair}})
+		if err == nil {
+			t.Error("Expected client with untrusted certificate to be rejected")
+		}
+	})
+
+	t.Run("AcceptsValidClientCertificate", func(t *testing.T) {
+		pair, err := tls.X509KeyPair(clientCert.certPEM, clientCert.keyPEM)
+		if err != nil {
+			t.Fatalf("Failed to load client key pair: %v", err)
+		}
+
+		if err := callValidateTypes(&tls.Config{RootCAs: roots, Certificates: []tls.Certificate{pair}}); err != nil {
+			t.Errorf("Expected client with valid certificate to succeed, got %v", err)
+		}
+	})
+}
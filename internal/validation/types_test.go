@@ -95,6 +95,42 @@ func TestPerformanceTestMessageTypes(t *testing.T) {
 	}
 }
 
+func TestScalarRepeatedFieldsUntouched(t *testing.T) {
+	tests := []struct {
+		name          string
+		fieldName     string
+		expectedType  string
+		getActualType func() any
+	}{
+		{
+			name:         "DataPoint.Tags should remain a string slice",
+			fieldName:    "Tags",
+			expectedType: "[]string",
+			getActualType: func() any {
+				return v1.DataPoint{}.Tags
+			},
+		},
+		{
+			name:         "ProcessingResult.ErrorMessages should remain a string slice",
+			fieldName:    "ErrorMessages",
+			expectedType: "[]string",
+			getActualType: func() any {
+				return v1.ProcessingResult{}.ErrorMessages
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			actualType := reflect.TypeOf(tt.getActualType()).String()
+			if actualType != tt.expectedType {
+				t.Errorf("Field %s has type %s, expected %s",
+					tt.fieldName, actualType, tt.expectedType)
+			}
+		})
+	}
+}
+
 func TestPluginIntegrationWorking(t *testing.T) {
 	// Verify our test message can be created and used
 	msg := &v1.ValidationTestMessage{
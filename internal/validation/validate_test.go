@@ -0,0 +1,129 @@
+package validation
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	v1 "github.com/benjamin-rood/protogo-values-validation-demo/gen/api/validation/v1"
+	"github.com/benjamin-rood/protogo-values-validation-demo/internal/server"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func TestValidationInterceptorRejectsBeforeHandler(t *testing.T) {
+	// Counts the calls that get past validation to the handler
+	var reached atomic.Int32
+	countHandler := func(ctx context.Context, req any, _ *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+		reached.Add(1)
+		return handler(ctx, req)
+	}
+
+	conn, cleanup := setupCustomTestServer(t, func(s *grpc.Server) {
+		v1.RegisterValidationServiceServer(s, server.NewValidationServer())
+	}, grpc.ChainUnaryInterceptor(server.UnaryValidationInterceptor(), countHandler))
+	defer cleanup()
+	client := v1.NewValidationServiceClient(conn)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	tests := []struct {
+		name     string
+		call     func() error
+		expected error
+	}{
+		{"zero iterations", func() error {
+			_, err := client.RunBenchmarks(ctx, &v1.BenchmarkRequest{Iterations: 0, DataSize: 10})
+			return err
+		}, server.ErrInvalidIterations},
+		{"negative data size", func() error {
+			_, err := client.RunBenchmarks(ctx, &v1.BenchmarkRequest{Iterations: 1, DataSize: -1})
+			return err
+		}, server.ErrInvalidDataSize},
+		{"too many tags", func() error {
+			_, err := client.RunBenchmarks(ctx, &v1.BenchmarkRequest{Iterations: 1, DataSize: 10, TagsPerItem: server.MaxEntriesPerItem + 1})
+			return err
+		}, server.ErrInvalidItemEntries},
+		{"negative page size", func() error {
+			_, err := client.ValidateTypes(ctx, &v1.ValidateTypesRequest{TestScenarios: []string{server.ScenarioValidationTestMessage}, PageSize: -1})
+			return err
+		}, server.ErrInvalidPageSize},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.call()
+			st, ok := status.FromError(err)
+			if !ok || st.Code() != codes.InvalidArgument {
+				t.Fatalf("Expected InvalidArgument, got %v", err)
+			}
+			if st.Message() == "" || st.Message() == tt.expected.Error() {
+				t.Errorf("Expected the message to describe the bad value, got %q", st.Message())
+			}
+		})
+	}
+
+	if n := reached.Load(); n != 0 {
+		t.Errorf("Expected no invalid request to reach the handler, got %d", n)
+	}
+
+	// Valid requests pass through
+	if _, err := client.ValidateTypes(ctx, &v1.ValidateTypesRequest{TestScenarios: []string{server.ScenarioValidationTestMessage}}); err != nil {
+		t.Fatalf("ValidateTypes failed: %v", err)
+	}
+	if n := reached.Load(); n != 1 {
+		t.Errorf("Expected the valid request to reach the handler, got %d calls", n)
+	}
+}
+
+func TestValidateRequestSentinels(t *testing.T) {
+	tests := []struct {
+		name     string
+		req      any
+		expected error
+	}{
+		{"zero iterations", &v1.BenchmarkRequest{DataSize: 10}, server.ErrInvalidIterations},
+		{"zero data size", &v1.BenchmarkRequest{Iterations: 1}, server.ErrInvalidDataSize},
+		{"negative attributes", &v1.BenchmarkRequest{Iterations: 1, DataSize: 10, AttributesPerItem: -1}, server.ErrInvalidItemEntries},
+		{"negative page size", &v1.ValidateTypesRequest{PageSize: -1}, server.ErrInvalidPageSize},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := server.ValidateRequest(tt.req)
+			if !errors.Is(err, tt.expected) {
+				t.Errorf("Expected errors.Is(%v, %v)", err, tt.expected)
+			}
+			if status.Code(err) != codes.InvalidArgument {
+				t.Errorf("Expected InvalidArgument, got %v", status.Code(err))
+			}
+		})
+	}
+
+	// Requests without constraints, and valid ones, pass
+	for _, req := range []any{
+		&v1.BenchmarkRequest{Iterations: 1, DataSize: 1, TagsPerItem: server.MaxEntriesPerItem},
+		&v1.ValidateTypesRequest{},
+		&v1.EstimateSizeRequest{},
+	} {
+		if err := server.ValidateRequest(req); err != nil {
+			t.Errorf("Expected %T to pass, got %v", req, err)
+		}
+	}
+}
+
+// rejectingRequest implements Validator directly
+type rejectingRequest struct{}
+
+func (rejectingRequest) Validate() error { return errors.New("always invalid") }
+
+func TestValidateRequestUsesValidateMethod(t *testing.T) {
+	err := server.ValidateRequest(rejectingRequest{})
+	if status.Code(err) != codes.InvalidArgument {
+		t.Errorf("Expected InvalidArgument from the request's own Validate, got %v", err)
+	}
+}
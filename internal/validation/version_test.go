@@ -0,0 +1,42 @@
+package validation
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"runtime"
+	"testing"
+
+	"github.com/benjamin-rood/protogo-values-validation-demo/internal/buildinfo"
+)
+
+func TestVersionHandler(t *testing.T) {
+	rec := httptest.NewRecorder()
+	buildinfo.Handler()(rec, httptest.NewRequest(http.MethodGet, "/version", nil))
+
+	if rec.Code != http.StatusOK {
+		t.Fatalf("Expected status 200, got %d", rec.Code)
+	}
+
+	if ct := rec.Header().Get("Content-Type"); ct != "application/json" {
+		t.Errorf("Expected application/json, got %q", ct)
+	}
+
+	var body map[string]any
+	if err := json.Unmarshal(rec.Body.Bytes(), &body); err != nil {
+		t.Fatalf("Expected valid JSON: %v", err)
+	}
+
+	for _, field := range []string{"version", "commit", "buildDate", "goVersion"} {
+		if value, ok := body[field].(string); !ok || value == "" {
+			t.Errorf("Expected non-empty %s, got %v", field, body[field])
+		}
+	}
+
+	if body["goVersion"] != runtime.Version() {
+		t.Errorf("Expected goVersion %q, got %v", runtime.Version(), body["goVersion"])
+	}
+	if body["version"] != buildinfo.Version {
+		t.Errorf("Expected version %q, got %v", buildinfo.Version, body["version"])
+	}
+}
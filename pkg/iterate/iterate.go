@@ -0,0 +1,32 @@
+// Package iterate holds the canonical read-only passes over generated slice
+// fields, the operations the value-slice benchmarks measure. None of them
+// allocate.
+package iterate
+
+import (
+	v1 "github.com/benjamin-rood/protogo-values-validation-demo/gen/api/validation/v1"
+)
+
+// SumValues returns the sum of Value across data. It reads each element in
+// place through its index, so a value slice is traversed as one contiguous
+// block without copying elements.
+func SumValues(data []v1.DataPoint) float64 {
+	var sum float64
+	for i := range data {
+		sum += data[i].Value
+	}
+	return sum
+}
+
+// CountNonEmpty returns how many elements of data are non-nil with a
+// non-empty Key. Each element of a pointer slice is a separate heap object,
+// so this dereferences one pointer per element.
+func CountNonEmpty(data []*v1.Metadata) int {
+	var count int
+	for _, meta := range data {
+		if meta != nil && meta.Key != "" {
+			count++
+		}
+	}
+	return count
+}
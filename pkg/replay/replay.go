@@ -0,0 +1,196 @@
+// Package replay streams recorded StreamRequest messages to StreamValidation,
+// for load testing with captured traffic.
+package replay
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"sync"
+	"time"
+
+	v1 "github.com/benjamin-rood/protogo-values-validation-demo/gen/api/validation/v1"
+	"google.golang.org/protobuf/encoding/protojson"
+)
+
+// maxLineSize bounds a single recorded request
+const maxLineSize = 16 << 20
+
+// Result holds the responses to a replay and its timing
+type Result struct {
+	// Responses are in the order their requests were sent
+	Responses []*v1.StreamResponse
+	// Latencies[i] is the time from sending request i to receiving its response
+	Latencies []time.Duration
+	// Duration runs from opening the stream to receiving the last response
+	Duration time.Duration
+	// Failed counts responses with Success unset
+	Failed int
+}
+
+// MeanLatency is the average request-to-response time
+func (r *Result) MeanLatency() time.Duration {
+	if len(r.Latencies) == 0 {
+		return 0
+	}
+	var total time.Duration
+	for _, latency := range r.Latencies {
+		total += latency
+	}
+	return total / time.Duration(len(r.Latencies))
+}
+
+// MaxLatency is the slowest request-to-response time
+func (r *Result) MaxLatency() time.Duration {
+	var slowest time.Duration
+	for _, latency := range r.Latencies {
+		slowest = max(slowest, latency)
+	}
+	return slowest
+}
+
+// RequestsPerSecond is the replay's overall throughput
+func (r *Result) RequestsPerSecond() float64 {
+	if r.Duration <= 0 {
+		return 0
+	}
+	return float64(len(r.Responses)) / r.Duration.Seconds()
+}
+
+// ReadRequests decodes newline-delimited protojson StreamRequests. Blank
+// lines are skipped.
+func ReadRequests(r io.Reader) ([]*v1.StreamRequest, error) {
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 0, 64<<10), maxLineSize)
+
+	var requests []*v1.StreamRequest
+	for line := 1; scanner.Scan(); line++ {
+		text := bytes.TrimSpace(scanner.Bytes())
+		if len(text) == 0 {
+			continue
+		}
+
+		req := &v1.StreamRequest{}
+		if err := protojson.Unmarshal(text, req); err != nil {
+			return nil, fmt.Errorf("line %d: %w", line, err)
+		}
+		requests = append(requests, req)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+
+	return requests, nil
+}
+
+// ReadFile reads the requests recorded in path
+func ReadFile(path string) ([]*v1.StreamRequest, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	requests, err := ReadRequests(f)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	return requests, nil
+}
+
+// ReplayFile streams the requests recorded in path to StreamValidation
+func ReplayFile(ctx context.Context, client v1.ValidationServiceClient, path string) (*Result, error) {
+	requests, err := ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	return Replay(ctx, client, requests)
+}
+
+// Replay sends requests on one StreamValidation stream while concurrently
+// collecting the responses. The server may answer out of order, so responses
+// are matched to requests by sequence number, which must be unique.
+func Replay(ctx context.Context, client v1.ValidationServiceClient, requests []*v1.StreamRequest) (*Result, error) {
+	index := make(map[int32]int, len(requests))
+	for i, req := range requests {
+		if _, ok := index[req.SequenceNumber]; ok {
+			return nil, fmt.Errorf("request %d: duplicate sequence_number %d", i, req.SequenceNumber)
+		}
+		index[req.SequenceNumber] = i
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	start := time.Now()
+	stream, err := client.StreamValidation(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	var (
+		sentMu sync.Mutex
+		sentAt = make([]time.Time, len(requests))
+	)
+
+	// Sender: runs alongside the receiver so the server's queue never stalls
+	sendErr := make(chan error, 1)
+	go func() {
+		for i, req := range requests {
+			sentMu.Lock()
+			sentAt[i] = time.Now()
+			sentMu.Unlock()
+
+			if err := stream.Send(req); err != nil {
+				// The receiver sees the stream's real error
+				sendErr <- nil
+				return
+			}
+		}
+		sendErr <- stream.CloseSend()
+	}()
+
+	result := &Result{
+		Responses: make([]*v1.StreamResponse, len(requests)),
+		Latencies: make([]time.Duration, len(requests)),
+	}
+	received := 0
+	for {
+		resp, err := stream.Recv()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("after %d of %d responses: %w", received, len(requests), err)
+		}
+
+		i, ok := index[resp.SequenceNumber]
+		if !ok || result.Responses[i] != nil {
+			return nil, fmt.Errorf("unexpected response for sequence_number %d", resp.SequenceNumber)
+		}
+
+		sentMu.Lock()
+		result.Latencies[i] = time.Since(sentAt[i])
+		sentMu.Unlock()
+
+		result.Responses[i] = resp
+		received++
+		if !resp.Success {
+			result.Failed++
+		}
+	}
+	result.Duration = time.Since(start)
+
+	if err := <-sendErr; err != nil {
+		return nil, err
+	}
+	if received != len(requests) {
+		return nil, fmt.Errorf("received %d of %d responses", received, len(requests))
+	}
+
+	return result, nil
+}